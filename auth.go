@@ -0,0 +1,258 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+// Roles of RPC credentials.  Tenant credentials use the wallets of their
+// tenant, while admin credentials administer the daemon through the
+// AdminService, which is refused to tenants.
+const (
+	roleTenant = "tenant"
+	roleAdmin  = "admin"
+)
+
+// authorizationHeader is the metadata key holding the bearer token of a
+// request, as "Bearer <token>".
+const authorizationHeader = "authorization"
+
+// adminServicePrefix is the prefix of the full method names of the
+// AdminService, which may only be called with admin credentials.
+const adminServicePrefix = "/walletdrpc.AdminService/"
+
+// publicMethods are the methods which may be called without a token, such as
+// by health checks, or with the token of any role.  Every other method of the
+// WalletDaemonService uses the wallets of a tenant, and so requires tenant
+// credentials.
+var publicMethods = map[string]struct{}{
+	"/walletdrpc.VersionService/Version":   {},
+	"/walletdrpc.WalletDaemonService/Ping": {},
+}
+
+var (
+	// errUnauthenticated is returned to requests without a valid token.
+	errUnauthenticated = grpc.Errorf(codes.Unauthenticated,
+		"missing or invalid authorization token")
+
+	// errPermissionDenied is returned to requests for a method which is
+	// not allowed to the role of their credential.
+	errPermissionDenied = grpc.Errorf(codes.PermissionDenied,
+		"method not allowed to this credential")
+)
+
+// credential is the identity authenticated by a token.
+type credential struct {
+	role string
+	name string
+}
+
+// credentialKey is the context key of the credential of a request.
+type credentialKey struct{}
+
+// credentialFromContext returns the credential of the request of ctx.
+func credentialFromContext(ctx context.Context) (credential, bool) {
+	c, ok := ctx.Value(credentialKey{}).(credential)
+	return c, ok
+}
+
+// authenticator authenticates requests with the tokens listed in the
+// authfile.  Only the SHA-256 hashes of the tokens are known to the daemon.
+type authenticator struct {
+	mu    sync.RWMutex
+	creds map[[sha256.Size]byte]credential
+}
+
+// readAuthFile reads the credentials of an authfile.  Every line which is
+// neither empty nor a # comment holds a role, tenant or admin, a name and the
+// hex encoded SHA-256 hash of the token, separated by spaces, such as
+//
+//   tenant acme 9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08
+//
+// Tenant names must be unique, since they identify the wallets of the tenant.
+func readAuthFile(path string) (map[[sha256.Size]byte]credential, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	creds := make(map[[sha256.Size]byte]credential)
+	tenants := make(map[string]struct{})
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("%s:%d: expected a role, a name "+
+				"and a token hash", path, n)
+		}
+		role, name := fields[0], fields[1]
+		if role != roleTenant && role != roleAdmin {
+			return nil, fmt.Errorf("%s:%d: unknown role %s", path,
+				n, role)
+		}
+		var hash [sha256.Size]byte
+		b, err := hex.DecodeString(fields[2])
+		if err != nil || len(b) != sha256.Size {
+			return nil, fmt.Errorf("%s:%d: invalid token hash", path,
+				n)
+		}
+		copy(hash[:], b)
+		if _, ok := creds[hash]; ok {
+			return nil, fmt.Errorf("%s:%d: duplicate token", path, n)
+		}
+		if role == roleTenant {
+			if _, ok := tenants[name]; ok {
+				return nil, fmt.Errorf("%s:%d: duplicate tenant "+
+					"%s", path, n, name)
+			}
+			tenants[name] = struct{}{}
+		}
+		creds[hash] = credential{role: role, name: name}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return creds, nil
+}
+
+// newAuthenticator creates an authenticator using the credentials of the
+// authfile at path.  The authfile must exist, so that a daemon upgraded from a
+// version which did not authenticate clients refuses to start, rather than
+// refusing every request, until one is created.
+func newAuthenticator(path string) (*authenticator, error) {
+	a := &authenticator{}
+	if err := a.load(path); err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("authfile %s does not exist -- "+
+				"create it with a line for every RPC client, as "+
+				"shown by wltctl gentoken", path)
+		}
+		return nil, err
+	}
+	if len(a.creds) == 0 {
+		log.Warnf("No RPC credentials in %s, every request other than "+
+			"Ping and Version will be refused", path)
+	}
+	return a, nil
+}
+
+// load replaces the credentials with those of the authfile at path.  The
+// credentials are left untouched when the file cannot be read.
+func (a *authenticator) load(path string) error {
+	creds, err := readAuthFile(path)
+	if err != nil {
+		return err
+	}
+	a.mu.Lock()
+	a.creds = creds
+	a.mu.Unlock()
+	return nil
+}
+
+// authenticate returns the credential of the bearer token of the request of
+// ctx.
+func (a *authenticator) authenticate(ctx context.Context) (credential, error) {
+	md, ok := metadata.FromContext(ctx)
+	if !ok || len(md[authorizationHeader]) == 0 {
+		return credential{}, errUnauthenticated
+	}
+	token := md[authorizationHeader][0]
+	if !strings.HasPrefix(token, "Bearer ") {
+		return credential{}, errUnauthenticated
+	}
+	hash := sha256.Sum256([]byte(strings.TrimPrefix(token, "Bearer ")))
+
+	a.mu.RLock()
+	c, ok := a.creds[hash]
+	a.mu.RUnlock()
+	if !ok {
+		return credential{}, errUnauthenticated
+	}
+	return c, nil
+}
+
+// authorize returns whether the role of c may call method.
+func authorize(c credential, method string) error {
+	if _, ok := publicMethods[method]; ok {
+		return nil
+	}
+	role := roleTenant
+	if strings.HasPrefix(method, adminServicePrefix) {
+		role = roleAdmin
+	}
+	if c.role != role {
+		return errPermissionDenied
+	}
+	return nil
+}
+
+// check returns the context of a request for method with the credential of
+// its token attached, or an error if the request has no valid token or the
+// method is not allowed to its credential.  Requests for public methods
+// without a valid token are allowed without a credential.
+func (a *authenticator) check(ctx context.Context, method string) (context.Context, error) {
+	c, err := a.authenticate(ctx)
+	if err != nil {
+		if _, ok := publicMethods[method]; ok {
+			return ctx, nil
+		}
+		return nil, err
+	}
+	if err := authorize(c, method); err != nil {
+		return nil, err
+	}
+	return context.WithValue(ctx, credentialKey{}, c), nil
+}
+
+// unaryInterceptor refuses unary requests which fail check, and attaches the
+// credential of the others to their context.  It must run before
+// contextUnaryInterceptor, which identifies the tenant from the credential.
+func (a *authenticator) unaryInterceptor(ctx context.Context, req interface{},
+	info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+
+	ctx, err := a.check(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// authServerStream attaches the credential of a request to a server stream.
+type authServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// streamInterceptor is the streaming equivalent of unaryInterceptor.
+func (a *authenticator) streamInterceptor(srv interface{}, ss grpc.ServerStream,
+	info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+	ctx, err := a.check(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authServerStream{ServerStream: ss, ctx: ctx})
+}
@@ -0,0 +1,211 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+// tokenHash returns the hex encoded SHA-256 hash of a token, as written in
+// the authfile.
+func tokenHash(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// writeAuthFile writes the lines to an authfile in a temporary directory,
+// and returns its path and a function removing the directory.
+func writeAuthFile(t *testing.T, lines ...string) (string, func()) {
+	dir, err := ioutil.TempDir("", "wltd-auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "auth")
+	err = ioutil.WriteFile(path, []byte(strings.Join(lines, "\n")), 0600)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return path, func() { os.RemoveAll(dir) }
+}
+
+func TestReadAuthFile(t *testing.T) {
+	path, cleanup := writeAuthFile(t,
+		"# wltd clients",
+		"",
+		"tenant acme "+tokenHash("acme-token"),
+		"  tenant   globex   "+strings.ToUpper(tokenHash("globex-token")),
+		"admin ops "+tokenHash("ops-token"),
+		"admin acme "+tokenHash("acme-admin-token"))
+	defer cleanup()
+
+	creds, err := readAuthFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]credential{
+		"acme-token":       {roleTenant, "acme"},
+		"globex-token":     {roleTenant, "globex"},
+		"ops-token":        {roleAdmin, "ops"},
+		"acme-admin-token": {roleAdmin, "acme"},
+	}
+	if len(creds) != len(want) {
+		t.Errorf("read %d credentials, want %d", len(creds), len(want))
+	}
+	for token, c := range want {
+		if got := creds[sha256.Sum256([]byte(token))]; got != c {
+			t.Errorf("credential of %s is %+v, want %+v", token, got,
+				c)
+		}
+	}
+
+	invalid := []string{
+		"tenant acme",
+		"tenant acme " + tokenHash("acme-token") + " extra",
+		"user acme " + tokenHash("acme-token"),
+		"tenant acme " + tokenHash("acme-token")[2:],
+		"tenant acme " + "zz" + tokenHash("acme-token")[2:],
+		"tenant acme " + tokenHash("acme-token") + "\n" +
+			"admin ops " + tokenHash("acme-token"),
+		"tenant acme " + tokenHash("acme-token") + "\n" +
+			"tenant acme " + tokenHash("other-token"),
+	}
+	for _, contents := range invalid {
+		path, cleanup := writeAuthFile(t, contents)
+		creds, err := readAuthFile(path)
+		cleanup()
+		if err == nil {
+			t.Errorf("%q: read %d credentials, want an error",
+				contents, len(creds))
+		}
+	}
+
+	_, err = readAuthFile(filepath.Join(filepath.Dir(path), "missing"))
+	if !os.IsNotExist(err) {
+		t.Errorf("missing authfile: %v", err)
+	}
+	if _, err := newAuthenticator(filepath.Join(filepath.Dir(path),
+		"missing")); err == nil {
+		t.Error("created an authenticator without an authfile")
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	const (
+		ping     = "/walletdrpc.WalletDaemonService/Ping"
+		version  = "/walletdrpc.VersionService/Version"
+		create   = "/walletdrpc.WalletDaemonService/CreateWallet"
+		network  = "/walletdrpc.WalletDaemonService/Network"
+		setLevel = "/walletdrpc.AdminService/SetLogLevel"
+	)
+	tenant := credential{roleTenant, "acme"}
+	admin := credential{roleAdmin, "ops"}
+	tests := []struct {
+		c       credential
+		method  string
+		allowed bool
+	}{
+		{tenant, ping, true},
+		{tenant, version, true},
+		{tenant, create, true},
+		{tenant, network, true},
+		{tenant, setLevel, false},
+		{admin, ping, true},
+		{admin, version, true},
+		{admin, create, false},
+		{admin, network, false},
+		{admin, setLevel, true},
+		{credential{}, ping, true},
+		{credential{}, create, false},
+		{credential{}, setLevel, false},
+	}
+	for _, test := range tests {
+		err := authorize(test.c, test.method)
+		if test.allowed && err != nil {
+			t.Errorf("%+v %s: %v", test.c, test.method, err)
+		}
+		if !test.allowed && grpc.Code(err) != codes.PermissionDenied {
+			t.Errorf("%+v %s: %v, want PermissionDenied", test.c,
+				test.method, err)
+		}
+	}
+}
+
+func TestAuthenticatorCheck(t *testing.T) {
+	path, cleanup := writeAuthFile(t,
+		"tenant acme "+tokenHash("acme-token"),
+		"admin ops "+tokenHash("ops-token"))
+	defer cleanup()
+	a, err := newAuthenticator(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	withToken := func(authorization string) context.Context {
+		return metadata.NewContext(context.Background(),
+			metadata.Pairs(authorizationHeader, authorization))
+	}
+	const (
+		ping     = "/walletdrpc.WalletDaemonService/Ping"
+		create   = "/walletdrpc.WalletDaemonService/CreateWallet"
+		setLevel = "/walletdrpc.AdminService/SetLogLevel"
+	)
+	tests := []struct {
+		ctx    context.Context
+		method string
+		code   codes.Code
+		cred   credential
+	}{
+		{withToken("Bearer acme-token"), create, codes.OK,
+			credential{roleTenant, "acme"}},
+		{withToken("Bearer acme-token"), ping, codes.OK,
+			credential{roleTenant, "acme"}},
+		{withToken("Bearer ops-token"), setLevel, codes.OK,
+			credential{roleAdmin, "ops"}},
+		{withToken("Bearer ops-token"), create,
+			codes.PermissionDenied, credential{}},
+		{withToken("Bearer acme-token"), setLevel,
+			codes.PermissionDenied, credential{}},
+		{withToken("Bearer unknown-token"), create,
+			codes.Unauthenticated, credential{}},
+		{withToken("acme-token"), create, codes.Unauthenticated,
+			credential{}},
+		{withToken(tokenHash("acme-token")), create,
+			codes.Unauthenticated, credential{}},
+		{context.Background(), create, codes.Unauthenticated,
+			credential{}},
+		{context.Background(), setLevel, codes.Unauthenticated,
+			credential{}},
+		{context.Background(), ping, codes.OK, credential{}},
+		{withToken("Bearer unknown-token"), ping, codes.OK,
+			credential{}},
+	}
+	for _, test := range tests {
+		ctx, err := a.check(test.ctx, test.method)
+		if code := grpc.Code(err); code != test.code {
+			t.Errorf("%s: %v, want %v", test.method, err, test.code)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		c, ok := credentialFromContext(ctx)
+		if c != test.cred || ok != (test.cred != credential{}) {
+			t.Errorf("%s: credential %+v, want %+v", test.method, c,
+				test.cred)
+		}
+	}
+}
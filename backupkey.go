@@ -0,0 +1,69 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// loadBackupKey reads the hex encoded private key wallet backups are encrypted
+// to from the named file.  A new key is generated and written to the file
+// when it does not exist.
+func loadBackupKey(filename string) (*[32]byte, error) {
+	var key [32]byte
+	b, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return generateBackupKey(filename)
+	}
+	if err != nil {
+		return nil, err
+	}
+	b = bytes.TrimSpace(b)
+	if hex.DecodedLen(len(b)) != len(key) {
+		return nil, fmt.Errorf("backup key %s is not a hex encoded "+
+			"32 byte key", filename)
+	}
+	if _, err := hex.Decode(key[:], b); err != nil {
+		return nil, fmt.Errorf("backup key %s is not a hex encoded "+
+			"32 byte key", filename)
+	}
+	return &key, nil
+}
+
+// generateBackupKey generates a new backup key and writes it to the named
+// file, which must not exist.
+func generateBackupKey(filename string) (*[32]byte, error) {
+	log.Infof("Generating backup key")
+
+	var key [32]byte
+	if _, err := rand.Read(key[:]); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, err
+	}
+	_, err = fmt.Fprintln(f, hex.EncodeToString(key[:]))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(filename)
+		return nil, err
+	}
+
+	log.Infof("Done generating backup key %s -- keep a copy of it to "+
+		"restore wallet backups", filename)
+	return &key, nil
+}
@@ -0,0 +1,289 @@
+// Copyright (c) 2013-2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcutil"
+)
+
+// certWatchInterval is how often the certificate and key files are checked
+// for changes.
+const certWatchInterval = 10 * time.Second
+
+// certManager owns the TLS keypair presented by the RPC server.  The keypair is
+// reloaded when the certificate or key file changes on disk, and may be
+// regenerated or replaced while the server is running, in which case new
+// connections use the new keypair.
+type certManager struct {
+	mu         sync.RWMutex
+	cert       *tls.Certificate
+	certFile   string
+	keyFile    string
+	oneTimeKey bool
+	extraHosts []string
+	validity   time.Duration
+
+	// certMod and keyMod are the modification times of the certificate
+	// and key files when they were last checked.
+	certMod time.Time
+	keyMod  time.Time
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newCertManager creates or loads the RPC TLS keypair at the passed paths.
+// When oneTimeKey is set, a new keypair is generated and only the certificate
+// is written to disk.  Autogenerated certificates are also valid for the
+// passed extra IP addresses and domain names, and expire after validity.
+func newCertManager(certFile, keyFile string, oneTimeKey bool,
+	extraHosts []string, validity time.Duration) (*certManager, error) {
+
+	m := &certManager{
+		certFile:   certFile,
+		keyFile:    keyFile,
+		oneTimeKey: oneTimeKey,
+		extraHosts: extraHosts,
+		validity:   validity,
+		quit:       make(chan struct{}),
+	}
+	if err := m.open(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// open creates or loads the keypair.
+//
+// If one time TLS keys are enabled but a key already exists, this function
+// errors since it's possible that a persistent certificate was copied to a
+// remote machine.  Otherwise, a new keypair is generated when the key is
+// missing.  When generating new persistent keys, overwriting an existing cert
+// is acceptable if the previous execution used a one time TLS key.  Otherwise,
+// both the cert and key are read from disk.  If the cert is missing, the read
+// error will occur in LoadX509KeyPair.
+func (m *certManager) open() error {
+	_, e := os.Stat(m.keyFile)
+	keyExists := !os.IsNotExist(e)
+	switch {
+	case m.oneTimeKey && keyExists:
+		return fmt.Errorf("one time TLS keys are enabled, but TLS key "+
+			"`%s` already exists", m.keyFile)
+	case m.oneTimeKey:
+		_, err := m.generate(false)
+		return err
+	case !keyExists:
+		_, err := m.generate(true)
+		return err
+	default:
+		return m.load()
+	}
+}
+
+// load reads the keypair from the certificate and key files.
+func (m *certManager) load() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	certMod, keyMod := fileModTime(m.certFile), fileModTime(m.keyFile)
+	keyPair, err := tls.LoadX509KeyPair(m.certFile, m.keyFile)
+	if err != nil {
+		return err
+	}
+	m.cert = &keyPair
+	m.certMod, m.keyMod = certMod, keyMod
+	return nil
+}
+
+// generate generates a new self-signed keypair and writes the cert and
+// possibly also the key in PEM format to the certificate and key files.  If
+// successful, the new keypair replaces the current one and the PEM encoded
+// certificate is returned.
+func (m *certManager) generate(writeKey bool) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	log.Infof("Generating TLS certificates...")
+
+	// Create directories for cert and key files if they do not yet exist.
+	certDir, _ := filepath.Split(m.certFile)
+	keyDir, _ := filepath.Split(m.keyFile)
+	err := os.MkdirAll(certDir, 0700)
+	if err != nil {
+		return nil, err
+	}
+	err = os.MkdirAll(keyDir, 0700)
+	if err != nil {
+		return nil, err
+	}
+
+	// Generate cert pair.
+	org := "wltd autogenerated cert"
+	validUntil := time.Now().Add(m.validity)
+	cert, key, err := btcutil.NewTLSCertPair(org, validUntil, m.extraHosts)
+	if err != nil {
+		return nil, err
+	}
+	keyPair, err := tls.X509KeyPair(cert, key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Write cert and (potentially) the key files.
+	err = ioutil.WriteFile(m.certFile, cert, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if writeKey {
+		err = ioutil.WriteFile(m.keyFile, key, 0600)
+		if err != nil {
+			rmErr := os.Remove(m.certFile)
+			if rmErr != nil {
+				log.Warnf("Cannot remove written certificates: %v",
+					rmErr)
+			}
+			return nil, err
+		}
+	}
+
+	m.cert = &keyPair
+	m.certMod, m.keyMod = fileModTime(m.certFile), fileModTime(m.keyFile)
+
+	log.Info("Done generating TLS certificates")
+	return cert, nil
+}
+
+// GetCertificate returns the current keypair.  It implements the
+// GetCertificate callback of tls.Config.
+func (m *certManager) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.cert, nil
+}
+
+// Regenerate replaces the keypair with a new self-signed keypair, overwriting
+// the certificate and key files, and returns the PEM encoded certificate.  The
+// key is not written to disk when one time TLS keys are enabled.
+func (m *certManager) Regenerate() ([]byte, error) {
+	m.mu.RLock()
+	writeKey := !m.oneTimeKey
+	m.mu.RUnlock()
+
+	return m.generate(writeKey)
+}
+
+// SetFiles loads the keypair from new certificate and key files, which are
+// watched for changes from then on.  The current keypair and files are kept
+// when the new keypair cannot be loaded.  One time TLS keys are never written
+// to disk, so nothing is changed when they are enabled.
+func (m *certManager) SetFiles(certFile, keyFile string) error {
+	if m.oneTimeKey {
+		return nil
+	}
+
+	certMod, keyMod := fileModTime(certFile), fileModTime(keyFile)
+	keyPair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.cert = &keyPair
+	m.certFile, m.keyFile = certFile, keyFile
+	m.certMod, m.keyMod = certMod, keyMod
+	m.mu.Unlock()
+	return nil
+}
+
+// SetExtraHosts changes the extra IP addresses and domain names of
+// certificates generated from now on.
+func (m *certManager) SetExtraHosts(extraHosts []string) {
+	m.mu.Lock()
+	m.extraHosts = extraHosts
+	m.mu.Unlock()
+}
+
+// SetValidity changes how long certificates generated from now on are valid
+// for.
+func (m *certManager) SetValidity(validity time.Duration) {
+	m.mu.Lock()
+	m.validity = validity
+	m.mu.Unlock()
+}
+
+// Start starts watching the certificate and key files for changes.
+func (m *certManager) Start() {
+	// The key of one time keypairs only exists in memory, so there is
+	// nothing to watch.
+	if m.oneTimeKey {
+		return
+	}
+	m.wg.Add(1)
+	go m.watcher()
+}
+
+// Stop stops watching the certificate and key files and waits for the watcher
+// to exit.
+func (m *certManager) Stop() {
+	close(m.quit)
+	m.wg.Wait()
+}
+
+// watcher periodically reloads the keypair when the certificate or key file
+// has been modified.  It must be run as a goroutine.
+func (m *certManager) watcher() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(certWatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.reloadModified()
+		case <-m.quit:
+			return
+		}
+	}
+}
+
+// reloadModified reloads the keypair if either file changed since it was last
+// checked.  Files are usually replaced one after the other, so a keypair
+// which fails to load is retried once the other file changes as well.
+func (m *certManager) reloadModified() {
+	m.mu.Lock()
+	certFile, keyFile := m.certFile, m.keyFile
+	certMod, keyMod := fileModTime(certFile), fileModTime(keyFile)
+	if certMod.Equal(m.certMod) && keyMod.Equal(m.keyMod) {
+		m.mu.Unlock()
+		return
+	}
+	m.certMod, m.keyMod = certMod, keyMod
+	m.mu.Unlock()
+
+	if err := m.load(); err != nil {
+		log.Warnf("Cannot reload modified TLS keypair: %v", err)
+		return
+	}
+	log.Infof("Reloaded TLS keypair from %s", certFile)
+}
+
+// fileModTime returns the modification time of the named file, or the zero
+// time if it cannot be read.
+func fileModTime(name string) time.Time {
+	fi, err := os.Stat(name)
+	if err != nil {
+		return time.Time{}
+	}
+	return fi.ModTime()
+}
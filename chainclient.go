@@ -0,0 +1,76 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+
+	"github.com/btcsuite/btcwallet/chain"
+	"github.com/tuxcanfly/wltd/walletd"
+)
+
+// newChainClients creates the btcd RPC clients of the networks with an
+// rpcconnect option, keyed by network name.  The clients do not connect until
+// they are started.
+func newChainClients() (map[string]chain.Interface, error) {
+	clients := make(map[string]chain.Interface)
+	if len(cfg.chainServers) == 0 {
+		log.Warn("No rpcconnect option, wallets will not sync with " +
+			"the chain")
+		return clients, nil
+	}
+
+	// The system roots are used when the certificate of btcd is missing,
+	// such as for a btcd with a certificate signed by a public authority.
+	certs, err := ioutil.ReadFile(cfg.CAFile)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+		log.Warnf("Cannot read btcd certificate %s, using the system "+
+			"roots", cfg.CAFile)
+		certs = nil
+	}
+
+	for net, addr := range cfg.chainServers {
+		params, err := walletd.ParamsForNet(net)
+		if err != nil {
+			return nil, err
+		}
+		// Zero reconnect attempts reconnect forever.
+		client, err := chain.NewRPCClient(params, addr,
+			cfg.BtcdUsername, cfg.BtcdPassword, certs, false, 0)
+		if err != nil {
+			return nil, err
+		}
+		clients[net] = client
+	}
+	return clients, nil
+}
+
+// startChainClients connects every chain client in the background, so that
+// the daemon serves requests while btcd is unreachable, and stops them on
+// shutdown.  The wallet daemon must be stopped first, which its interrupt
+// handler, added later, ensures.
+func startChainClients(clients map[string]chain.Interface) {
+	for net, client := range clients {
+		net, client := net, client
+		log.Infof("Connecting to btcd at %s for %s", cfg.chainServers[net],
+			net)
+		go func() {
+			if err := client.Start(); err != nil {
+				log.Errorf("Unable to connect to btcd for %s: %v",
+					net, err)
+			}
+		}()
+	}
+	addInterruptHandler(func() {
+		for _, client := range clients {
+			client.Stop()
+			client.WaitForShutdown()
+		}
+	})
+}
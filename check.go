@@ -0,0 +1,53 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/tuxcanfly/wltd/walletd"
+)
+
+// errCheckFailed is returned by runCheck when a problem was found, so that the
+// process exits with an error status.
+var errCheckFailed = errors.New("check found problems")
+
+// checkLockTimeout is how long the check waits for a database held open by
+// another process, such as a running daemon, before giving up on it.
+const checkLockTimeout = 5 * time.Second
+
+// runCheck checks the registry and every wallet of the data directory and
+// writes the report to stdout as JSON.  The databases are only read, so they
+// are not migrated, and the check does not wait on a running daemon for
+// longer than checkLockTimeout.  The CheckWallet RPC checks the wallets of a
+// running daemon.
+func runCheck() error {
+	log.Info("Checking wallets")
+	report, err := walletd.Check(&walletd.CheckConfig{
+		DataDir:       cfg.AppDataDir,
+		DBDriver:      cfg.DBDriver,
+		PubPassphrase: []byte(cfg.WalletPass),
+		LockTimeout:   checkLockTimeout,
+	})
+	if err != nil {
+		log.Errorf("Unable to check wallets: %v", err)
+		return err
+	}
+	b, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	os.Stdout.Write(append(b, '\n'))
+
+	if !report.OK() {
+		log.Errorf("Check found problems in the wallets")
+		return errCheckFailed
+	}
+	log.Info("Check found no problems")
+	return nil
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// verifyauditlog checks the hash chain of a wltd audit log without a running
+// daemon.  It prints the hash of the last record, which may be recorded
+// elsewhere to later detect the removal of records from the end of the log.
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/btcsuite/btcutil"
+	flags "github.com/jessevdk/go-flags"
+	"github.com/tuxcanfly/wltd/walletd"
+)
+
+var defaultAuditLog = filepath.Join(btcutil.AppDataDir("wltd", false),
+	walletd.AuditLogName)
+
+type config struct {
+	AuditLog string `short:"f" long:"auditlog" description:"Path to the audit log"`
+}
+
+func main() {
+	os.Exit(mainInt())
+}
+
+func mainInt() int {
+	cfg := config{AuditLog: defaultAuditLog}
+	_, err := flags.Parse(&cfg)
+	if err != nil {
+		return 1
+	}
+
+	n, lastHash, err := walletd.VerifyAuditLog(cfg.AuditLog)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Audit log is invalid after %d valid "+
+			"records: %v\n", n, err)
+		return 1
+	}
+	fmt.Printf("Verified %d records\n", n)
+	fmt.Printf("Last hash: %s\n", lastHash)
+	return 0
+}
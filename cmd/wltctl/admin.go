@@ -0,0 +1,705 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/btcsuite/btcutil"
+	pb "github.com/tuxcanfly/wltd/rpc/walletdrpc"
+)
+
+// restoreChunkSize is the size of the backup parts sent to RestoreWallet.
+const restoreChunkSize = 64 * 1024
+
+type adminCommand struct {
+	RegenCert    adminRegenCertCommand    `command:"regencert" description:"Replace the RPC certificate of wltd with a new self-signed certificate"`
+	LogLevels    adminLogLevelsCommand    `command:"loglevels" description:"Show the log level of every subsystem"`
+	SetLogLevel  adminSetLogLevelCommand  `command:"setloglevel" description:"Set the log level of a subsystem or of every subsystem"`
+	TailLogs     adminTailLogsCommand     `command:"taillogs" description:"Stream the log lines of wltd until interrupted"`
+	Audit        adminAuditCommand        `command:"audit" description:"Query the audit log"`
+	SetQuota     adminSetQuotaCommand     `command:"setquota" description:"Set the quota of a tenant"`
+	Usage        adminUsageCommand        `command:"usage" description:"Show the usage and quota of a tenant or of every tenant"`
+	Backup       adminBackupCommand       `command:"backup" description:"Write an encrypted backup of a wallet"`
+	Restore      adminRestoreCommand      `command:"restore" description:"Restore a wallet from an encrypted backup to a tenant"`
+	ListBackups  adminListBackupsCommand  `command:"listbackups" description:"List the backups in the backup directory of wltd"`
+	VerifyBackup adminVerifyBackupCommand `command:"verifybackup" description:"Verify a backup against its manifest"`
+	Check        adminCheckCommand        `command:"check" description:"Check a wallet, or the registry and every wallet, for corruption"`
+}
+
+type adminRegenCertCommand struct {
+	Out string `short:"o" long:"out" description:"Write the new certificate to this file instead of standard output"`
+}
+
+// certResult holds the new certificate, or the file it was written to.
+type certResult struct {
+	Certificate string `json:"certificate,omitempty"`
+	File        string `json:"file,omitempty"`
+}
+
+func (cmd *adminRegenCertCommand) Execute(args []string) error {
+	c, err := adminClient()
+	if err != nil {
+		return err
+	}
+	resp, err := c.RegenerateCertificate(requestContext(),
+		&pb.RegenerateCertificateRequest{})
+	if err != nil {
+		return err
+	}
+	if cmd.Out != "" {
+		out := cleanAndExpandPath(cmd.Out)
+		err := ioutil.WriteFile(out, resp.Certificate, 0644)
+		if err != nil {
+			return err
+		}
+		return printResult(certResult{File: out}, nil)
+	}
+	result := certResult{Certificate: string(resp.Certificate)}
+	return printResult(result, func(w io.Writer) error {
+		_, err := io.WriteString(w, result.Certificate)
+		return err
+	})
+}
+
+type adminLogLevelsCommand struct{}
+
+type logLevelsResult struct {
+	Levels map[string]string `json:"levels"`
+}
+
+func (*adminLogLevelsCommand) Execute(args []string) error {
+	c, err := adminClient()
+	if err != nil {
+		return err
+	}
+	resp, err := c.GetLogLevels(requestContext(), &pb.GetLogLevelsRequest{})
+	if err != nil {
+		return err
+	}
+	result := logLevelsResult{Levels: resp.Levels}
+	if result.Levels == nil {
+		result.Levels = map[string]string{}
+	}
+	return printResult(result, func(w io.Writer) error {
+		subsystems := make([]string, 0, len(result.Levels))
+		for subsystem := range result.Levels {
+			subsystems = append(subsystems, subsystem)
+		}
+		sort.Strings(subsystems)
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		for _, subsystem := range subsystems {
+			fmt.Fprintf(tw, "%s\t%s\n", subsystem,
+				result.Levels[subsystem])
+		}
+		return tw.Flush()
+	})
+}
+
+type adminSetLogLevelCommand struct {
+	Subsystem string `short:"S" long:"subsystem" description:"Subsystem to change, such as WLTD (default every subsystem)"`
+	Args      struct {
+		Level string `positional-arg-name:"level" description:"One of trace, debug, info, warn, error or critical"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+// logLevelResult is the new log level of a subsystem, or of every subsystem
+// when the subsystem is empty.
+type logLevelResult struct {
+	Subsystem string `json:"subsystem"`
+	Level     string `json:"level"`
+}
+
+func (cmd *adminSetLogLevelCommand) Execute(args []string) error {
+	c, err := adminClient()
+	if err != nil {
+		return err
+	}
+	_, err = c.SetLogLevel(requestContext(), &pb.SetLogLevelRequest{
+		Subsystem: cmd.Subsystem,
+		Level:     cmd.Args.Level,
+	})
+	if err != nil {
+		return err
+	}
+	return printResult(logLevelResult{
+		Subsystem: cmd.Subsystem,
+		Level:     cmd.Args.Level,
+	}, nil)
+}
+
+type adminTailLogsCommand struct {
+	MinLevel   string   `short:"l" long:"minlevel" description:"Lowest level of the streamed lines (default every level)"`
+	Subsystems []string `short:"S" long:"subsystem" description:"Only stream the lines of this subsystem -- may be repeated"`
+}
+
+// logLineResult is a streamed log line.  Lines are written as they arrive,
+// one JSON object per line or one YAML document each.
+type logLineResult struct {
+	Subsystem string `json:"subsystem"`
+	Level     string `json:"level"`
+	Line      string `json:"line"`
+	Dropped   uint64 `json:"dropped"`
+}
+
+func (cmd *adminTailLogsCommand) Execute(args []string) error {
+	if cfg.Batch || inShell {
+		return errEndlessCommand
+	}
+	c, err := adminClient()
+	if err != nil {
+		return err
+	}
+	stream, err := c.TailLogs(requestContext(), &pb.TailLogsRequest{
+		MinLevel:   cmd.MinLevel,
+		Subsystems: cmd.Subsystems,
+	})
+	if err != nil {
+		return err
+	}
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		line := strings.TrimRight(resp.Line, "\n")
+		switch cfg.Output {
+		case outputJSON:
+			err = json.NewEncoder(os.Stdout).Encode(logLineResult{
+				Subsystem: resp.Subsystem,
+				Level:     resp.Level,
+				Line:      line,
+				Dropped:   resp.Dropped,
+			})
+		case outputYAML:
+			fmt.Println("---")
+			err = writeYAML(os.Stdout, logLineResult{
+				Subsystem: resp.Subsystem,
+				Level:     resp.Level,
+				Line:      line,
+				Dropped:   resp.Dropped,
+			})
+		default:
+			if resp.Dropped != 0 {
+				fmt.Fprintf(os.Stderr, "%d lines dropped\n",
+					resp.Dropped)
+			}
+			_, err = fmt.Println(line)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+type adminAuditCommand struct {
+	Tenant string `long:"filter-tenant" description:"Only show the records of this tenant"`
+	Method string `long:"method" description:"Only show the records of this method"`
+	Wallet string `short:"w" long:"wallet" description:"Only show the records of this wallet"`
+	Since  string `long:"since" description:"Only show the records since this RFC 3339 time"`
+	Until  string `long:"until" description:"Only show the records until this RFC 3339 time"`
+	Limit  uint32 `long:"limit" description:"Maximum number of records to show, counting from the most recent (default 1000)"`
+}
+
+// parseTime parses an optional RFC 3339 time into a unix time in seconds.
+func parseTime(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0, err
+	}
+	return t.Unix(), nil
+}
+
+// auditRecordResult is a record of the audit log.  The time is a unix time
+// in nanoseconds.
+type auditRecordResult struct {
+	Sequence  uint64 `json:"sequence"`
+	Time      int64  `json:"time"`
+	Tenant    string `json:"tenant"`
+	Method    string `json:"method"`
+	Wallet    string `json:"wallet_uuid"`
+	RequestID string `json:"request_id"`
+	Success   bool   `json:"success"`
+	Error     string `json:"error"`
+	Hash      string `json:"hash"`
+}
+
+type auditResult struct {
+	Records []auditRecordResult `json:"records"`
+}
+
+func (cmd *adminAuditCommand) Execute(args []string) error {
+	since, err := parseTime(cmd.Since)
+	if err != nil {
+		return err
+	}
+	until, err := parseTime(cmd.Until)
+	if err != nil {
+		return err
+	}
+	c, err := adminClient()
+	if err != nil {
+		return err
+	}
+	resp, err := c.QueryAuditLog(requestContext(), &pb.QueryAuditLogRequest{
+		Tenant:     cmd.Tenant,
+		Method:     cmd.Method,
+		WalletUuid: cmd.Wallet,
+		Since:      since,
+		Until:      until,
+		Limit:      cmd.Limit,
+	})
+	if err != nil {
+		return err
+	}
+	result := auditResult{
+		Records: make([]auditRecordResult, 0, len(resp.Records)),
+	}
+	for _, r := range resp.Records {
+		result.Records = append(result.Records, auditRecordResult{
+			Sequence:  r.Sequence,
+			Time:      r.Timestamp,
+			Tenant:    r.Tenant,
+			Method:    r.Method,
+			Wallet:    r.WalletUuid,
+			RequestID: r.RequestId,
+			Success:   r.Success,
+			Error:     r.Error,
+			Hash:      r.Hash,
+		})
+	}
+	return printResult(result, func(w io.Writer) error {
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(tw, "SEQ\tTIME\tTENANT\tMETHOD\tWALLET\tRESULT")
+		for _, r := range result.Records {
+			status := "ok"
+			if !r.Success {
+				status = r.Error
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n", r.Sequence,
+				time.Unix(0, r.Time).Format(time.RFC3339), r.Tenant,
+				r.Method, r.Wallet, status)
+		}
+		return tw.Flush()
+	})
+}
+
+type adminSetQuotaCommand struct {
+	MaxWallets     uint64 `long:"maxwallets" description:"Maximum number of wallets (0 for no limit)"`
+	MaxDiskBytes   uint64 `long:"maxdisk" description:"Total size of the wallet files and labels in bytes above which no wallet may be created or label set (0 for no limit)"`
+	MaxOpenWallets uint64 `long:"maxopen" description:"Maximum number of open wallets (0 for no limit)"`
+	Args           struct {
+		Tenant string `positional-arg-name:"tenant"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+// quotaResult is the quota of a tenant, where zero limits are not enforced.
+type quotaResult struct {
+	Tenant         string `json:"tenant"`
+	MaxWallets     uint64 `json:"max_wallets"`
+	MaxDiskBytes   uint64 `json:"max_disk_bytes"`
+	MaxOpenWallets uint64 `json:"max_open_wallets"`
+}
+
+func (cmd *adminSetQuotaCommand) Execute(args []string) error {
+	c, err := adminClient()
+	if err != nil {
+		return err
+	}
+	_, err = c.SetTenantQuota(requestContext(), &pb.SetTenantQuotaRequest{
+		Tenant: cmd.Args.Tenant,
+		Quota: &pb.TenantQuota{
+			MaxWallets:     cmd.MaxWallets,
+			MaxDiskBytes:   cmd.MaxDiskBytes,
+			MaxOpenWallets: cmd.MaxOpenWallets,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return printResult(quotaResult{
+		Tenant:         cmd.Args.Tenant,
+		MaxWallets:     cmd.MaxWallets,
+		MaxDiskBytes:   cmd.MaxDiskBytes,
+		MaxOpenWallets: cmd.MaxOpenWallets,
+	}, nil)
+}
+
+type adminUsageCommand struct {
+	Args struct {
+		Tenant string `positional-arg-name:"tenant"`
+	} `positional-args:"yes"`
+}
+
+// formatLimit formats a quota limit, which is zero when not enforced.
+func formatLimit(v uint64) string {
+	if v == 0 {
+		return "-"
+	}
+	return fmt.Sprint(v)
+}
+
+// tenantUsageResult is the usage and quota of a tenant, where zero limits are
+// not enforced.
+type tenantUsageResult struct {
+	Tenant         string `json:"tenant"`
+	Wallets        uint64 `json:"wallets"`
+	DiskBytes      uint64 `json:"disk_bytes"`
+	OpenWallets    uint64 `json:"open_wallets"`
+	MaxWallets     uint64 `json:"max_wallets"`
+	MaxDiskBytes   uint64 `json:"max_disk_bytes"`
+	MaxOpenWallets uint64 `json:"max_open_wallets"`
+}
+
+type usageResult struct {
+	Tenants []tenantUsageResult `json:"tenants"`
+}
+
+func (cmd *adminUsageCommand) Execute(args []string) error {
+	c, err := adminClient()
+	if err != nil {
+		return err
+	}
+	resp, err := c.GetTenantUsage(requestContext(),
+		&pb.GetTenantUsageRequest{Tenant: cmd.Args.Tenant})
+	if err != nil {
+		return err
+	}
+	result := usageResult{
+		Tenants: make([]tenantUsageResult, 0, len(resp.Tenants)),
+	}
+	for _, u := range resp.Tenants {
+		q := u.Quota
+		if q == nil {
+			q = &pb.TenantQuota{}
+		}
+		result.Tenants = append(result.Tenants, tenantUsageResult{
+			Tenant:         u.Tenant,
+			Wallets:        u.Wallets,
+			DiskBytes:      u.DiskBytes,
+			OpenWallets:    u.OpenWallets,
+			MaxWallets:     q.MaxWallets,
+			MaxDiskBytes:   q.MaxDiskBytes,
+			MaxOpenWallets: q.MaxOpenWallets,
+		})
+	}
+	return printResult(result, func(w io.Writer) error {
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(tw, "TENANT\tWALLETS\tDISK BYTES\tOPEN WALLETS")
+		for _, u := range result.Tenants {
+			fmt.Fprintf(tw, "%s\t%d/%s\t%d/%s\t%d/%s\n", u.Tenant,
+				u.Wallets, formatLimit(u.MaxWallets),
+				u.DiskBytes, formatLimit(u.MaxDiskBytes),
+				u.OpenWallets, formatLimit(u.MaxOpenWallets))
+		}
+		return tw.Flush()
+	})
+}
+
+type adminBackupCommand struct {
+	Out  string     `short:"o" long:"out" description:"File to write the backup to" required:"yes"`
+	Args walletArgs `positional-args:"yes" required:"yes"`
+}
+
+type backupResult struct {
+	UUID string `json:"uuid"`
+	File string `json:"file"`
+}
+
+func (cmd *adminBackupCommand) Execute(args []string) error {
+	c, err := adminClient()
+	if err != nil {
+		return err
+	}
+	stream, err := c.BackupWallet(requestContext(),
+		&pb.BackupWalletRequest{WalletUuid: cmd.Args.UUID})
+	if err != nil {
+		return err
+	}
+
+	// The backup is written under a temporary name, so an interrupted
+	// backup is not mistaken for a complete one.
+	out := cleanAndExpandPath(cmd.Out)
+	tmp := out + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	for {
+		var resp *pb.BackupWalletResponse
+		resp, err = stream.Recv()
+		if err != nil {
+			break
+		}
+		if _, err = f.Write(resp.Data); err != nil {
+			break
+		}
+	}
+	if err == io.EOF {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, out)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	result := backupResult{UUID: cmd.Args.UUID, File: out}
+	return printResult(result, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "Wrote backup of wallet %s to %s\n",
+			result.UUID, result.File)
+		return err
+	})
+}
+
+type adminRestoreCommand struct {
+	NewUUID bool `long:"newuuid" description:"Restore the wallet under a new UUID instead of its original one"`
+	Args    struct {
+		Tenant string `positional-arg-name:"tenant"`
+		File   string `positional-arg-name:"file"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+func (cmd *adminRestoreCommand) Execute(args []string) error {
+	f, err := os.Open(cleanAndExpandPath(cmd.Args.File))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	c, err := adminClient()
+	if err != nil {
+		return err
+	}
+	stream, err := c.RestoreWallet(requestContext())
+	if err != nil {
+		return err
+	}
+	buf := make([]byte, restoreChunkSize)
+	first := true
+	for {
+		n, err := f.Read(buf)
+		if n > 0 || first {
+			req := &pb.RestoreWalletRequest{Data: buf[:n]}
+			if first {
+				req.NewUuid = cmd.NewUUID
+				req.Tenant = cmd.Args.Tenant
+				first = false
+			}
+			if err := stream.Send(req); err != nil {
+				break
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			stream.CloseSend()
+			return err
+		}
+	}
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+	result := walletIDResult{UUID: resp.WalletUuid}
+	return printResult(result, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "Wallet id: %s\n", result.UUID)
+		return err
+	})
+}
+
+type adminListBackupsCommand struct{}
+
+// backupInfoResult describes a daemon backup.  The time is a unix time in
+// seconds and the size is in bytes.
+type backupInfoResult struct {
+	Name  string `json:"name"`
+	Time  int64  `json:"time"`
+	Files uint32 `json:"files"`
+	Size  uint64 `json:"size"`
+}
+
+type backupsResult struct {
+	Backups []backupInfoResult `json:"backups"`
+}
+
+func (*adminListBackupsCommand) Execute(args []string) error {
+	c, err := adminClient()
+	if err != nil {
+		return err
+	}
+	resp, err := c.ListBackups(requestContext(), &pb.ListBackupsRequest{})
+	if err != nil {
+		return err
+	}
+	result := backupsResult{
+		Backups: make([]backupInfoResult, 0, len(resp.Backups)),
+	}
+	for _, b := range resp.Backups {
+		result.Backups = append(result.Backups, backupInfoResult{
+			Name:  b.Name,
+			Time:  b.Timestamp,
+			Files: b.Files,
+			Size:  b.Size,
+		})
+	}
+	return printResult(result, func(w io.Writer) error {
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(tw, "NAME\tTIME\tFILES\tSIZE")
+		for _, b := range result.Backups {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%d\n", b.Name,
+				formatUnix(b.Time), b.Files, b.Size)
+		}
+		return tw.Flush()
+	})
+}
+
+type adminVerifyBackupCommand struct {
+	Args struct {
+		Name string `positional-arg-name:"name"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+// errProblemsFound is returned by commands which found problems, after they
+// have been reported, so that wltctl exits with an error status.
+var errProblemsFound = errors.New("problems found")
+
+type verifyBackupResult struct {
+	Name     string   `json:"name"`
+	Intact   bool     `json:"intact"`
+	Problems []string `json:"problems"`
+}
+
+func (cmd *adminVerifyBackupCommand) Execute(args []string) error {
+	c, err := adminClient()
+	if err != nil {
+		return err
+	}
+	resp, err := c.VerifyBackup(requestContext(),
+		&pb.VerifyBackupRequest{Name: cmd.Args.Name})
+	if err != nil {
+		return err
+	}
+	result := verifyBackupResult{
+		Name:     cmd.Args.Name,
+		Intact:   len(resp.Problems) == 0,
+		Problems: stringList(resp.Problems),
+	}
+	err = printResult(result, func(w io.Writer) error {
+		if result.Intact {
+			_, err := fmt.Fprintf(w, "Backup %s is intact\n",
+				result.Name)
+			return err
+		}
+		for _, p := range result.Problems {
+			fmt.Fprintln(w, p)
+		}
+		return nil
+	})
+	if err == nil && !result.Intact {
+		err = errProblemsFound
+	}
+	return err
+}
+
+type adminCheckCommand struct {
+	Args struct {
+		UUID string `positional-arg-name:"uuid"`
+	} `positional-args:"yes"`
+}
+
+// walletCheckResult is the result of checking a wallet.  The balance is in
+// satoshis.
+type walletCheckResult struct {
+	UUID           string   `json:"uuid"`
+	Network        string   `json:"network"`
+	Accounts       uint32   `json:"accounts"`
+	Addresses      uint32   `json:"addresses"`
+	UnspentOutputs uint32   `json:"unspent_outputs"`
+	Balance        int64    `json:"balance"`
+	Problems       []string `json:"problems"`
+}
+
+type checkResult struct {
+	Wallets             []walletCheckResult `json:"wallets"`
+	UnregisteredWallets []string            `json:"unregistered_wallets"`
+	MissingWallets      []string            `json:"missing_wallets"`
+}
+
+func (cmd *adminCheckCommand) Execute(args []string) error {
+	c, err := adminClient()
+	if err != nil {
+		return err
+	}
+	resp, err := c.CheckWallet(requestContext(),
+		&pb.CheckWalletRequest{WalletUuid: cmd.Args.UUID})
+	if err != nil {
+		return err
+	}
+	result := checkResult{
+		Wallets:             make([]walletCheckResult, 0, len(resp.Wallets)),
+		UnregisteredWallets: stringList(resp.UnregisteredWallets),
+		MissingWallets:      stringList(resp.MissingWallets),
+	}
+	ok := len(resp.UnregisteredWallets) == 0 && len(resp.MissingWallets) == 0
+	for _, w := range resp.Wallets {
+		result.Wallets = append(result.Wallets, walletCheckResult{
+			UUID:           w.WalletUuid,
+			Network:        w.Network,
+			Accounts:       w.Accounts,
+			Addresses:      w.Addresses,
+			UnspentOutputs: w.UnspentOutputs,
+			Balance:        w.Balance,
+			Problems:       stringList(w.Problems),
+		})
+		if len(w.Problems) != 0 {
+			ok = false
+		}
+	}
+	err = printResult(result, func(w io.Writer) error {
+		for _, wlt := range result.Wallets {
+			fmt.Fprintf(w, "Wallet %s (%s): %d accounts, %d "+
+				"addresses, %d unspent outputs, balance %v\n",
+				wlt.UUID, wlt.Network, wlt.Accounts,
+				wlt.Addresses, wlt.UnspentOutputs,
+				btcutil.Amount(wlt.Balance))
+			for _, p := range wlt.Problems {
+				fmt.Fprintf(w, "  %s\n", p)
+			}
+		}
+		for _, id := range result.UnregisteredWallets {
+			fmt.Fprintf(w, "Unregistered wallet directory: %s\n", id)
+		}
+		for _, id := range result.MissingWallets {
+			fmt.Fprintf(w, "Registered wallet without directory: "+
+				"%s\n", id)
+		}
+		return nil
+	})
+	if err == nil && !ok {
+		err = errProblemsFound
+	}
+	return err
+}
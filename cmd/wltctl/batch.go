@@ -0,0 +1,96 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+
+	flags "github.com/jessevdk/go-flags"
+	"google.golang.org/grpc"
+)
+
+// errNoBatchCommand is returned for batch requests without arguments.
+var errNoBatchCommand = errors.New("no command specified")
+
+// batchRequest is a command read in batch mode, such as
+//
+//   {"id": 1, "args": ["wallet", "balance", "<uuid>"]}
+//
+// The arguments are those of the command line after the global options.  The
+// global options, such as the RPC server, token and passphrase source, are
+// shared by all the commands.
+type batchRequest struct {
+	ID   *json.RawMessage `json:"id"`
+	Args []string         `json:"args"`
+}
+
+// batchResponse is written as a line of JSON for every batch request.  The ID
+// is copied from the request, so that responses may be matched with their
+// request.  The result is the one written by the json output format.
+type batchResponse struct {
+	ID       *json.RawMessage `json:"id,omitempty"`
+	ExitCode int              `json:"exit_code"`
+	Result   interface{}      `json:"result,omitempty"`
+	Error    string           `json:"error,omitempty"`
+}
+
+// runBatch runs every command read from r, one JSON request per line, and
+// writes their responses to w.  Every command is run, even after others
+// failed, and the returned exit status is the one of the last command which
+// failed.
+func runBatch(r io.Reader, w io.Writer) int {
+	enc := json.NewEncoder(w)
+	status := exitSuccess
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var req batchRequest
+		var resp batchResponse
+		err := json.Unmarshal(line, &req)
+		if err == nil {
+			resp.ID = req.ID
+			resp.Result, err = runBatchCommand(req.Args)
+		} else {
+			err = &flags.Error{
+				Type:    flags.ErrUnknown,
+				Message: "invalid request: " + err.Error(),
+			}
+		}
+		if err != nil {
+			resp.ExitCode = exitCode(err)
+			resp.Error = grpc.ErrorDesc(err)
+			status = resp.ExitCode
+		}
+		if err := enc.Encode(resp); err != nil {
+			return exitFailure
+		}
+	}
+	if scanner.Err() != nil {
+		return exitFailure
+	}
+	return status
+}
+
+// runBatchCommand parses and runs the command of a batch request, returning
+// its result.
+func runBatchCommand(args []string) (interface{}, error) {
+	if len(args) == 0 {
+		return nil, errNoBatchCommand
+	}
+
+	// The parser of a request only knows the commands, as the global
+	// options are fixed for the whole batch.
+	batchResult = nil
+	_, err := newCommandParser().ParseArgs(args)
+	return batchResult, err
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	pb "github.com/tuxcanfly/wltd/rpc/walletdrpc"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+)
+
+const (
+	// authorizationHeader is the metadata key of the token of a request.
+	// It must match the key read by wltd.
+	authorizationHeader = "authorization"
+
+	// dialTimeout is how long connecting to wltd may take.
+	dialTimeout = 10 * time.Second
+)
+
+// errEmptyToken is returned when the token file holds no token.
+var errEmptyToken = errors.New("the token file is empty")
+
+// bearerToken authenticates every request with a token, which wltd maps to
+// a tenant or an admin.
+type bearerToken string
+
+func (t bearerToken) GetRequestMetadata(ctx context.Context,
+	uri ...string) (map[string]string, error) {
+
+	return map[string]string{authorizationHeader: "Bearer " + string(t)}, nil
+}
+
+func (bearerToken) RequireTransportSecurity() bool {
+	return true
+}
+
+// readToken reads the token from the first line of the file at path.
+func readToken(path string) (bearerToken, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	line, err := bufio.NewReader(f).ReadString('\n')
+	token := strings.TrimSpace(line)
+	if token == "" {
+		if err != nil {
+			return "", err
+		}
+		return "", errEmptyToken
+	}
+	return bearerToken(token), nil
+}
+
+// conn is the connection to wltd shared by every command run by the process.
+// It is created by the first command which needs it.
+var conn *grpc.ClientConn
+
+// connection returns the connection to wltd, connecting on first use.
+func connection() (*grpc.ClientConn, error) {
+	if conn != nil {
+		return conn, nil
+	}
+	if err := normalizeConfig(&cfg); err != nil {
+		return nil, err
+	}
+
+	var creds credentials.TransportCredentials
+	if cfg.TLSSkipVerify {
+		creds = credentials.NewTLS(&tls.Config{InsecureSkipVerify: true})
+	} else {
+		var err error
+		creds, err = credentials.NewClientTLSFromFile(cfg.RPCCert, "")
+		if err != nil {
+			return nil, err
+		}
+	}
+	token, err := readToken(cfg.TokenFile)
+	if err != nil {
+		return nil, grpc.Errorf(codes.Unauthenticated, "cannot read "+
+			"token: %v", err)
+	}
+	c, err := grpc.Dial(cfg.RPCServer, grpc.WithTransportCredentials(creds),
+		grpc.WithPerRPCCredentials(token), grpc.WithBlock(),
+		grpc.WithTimeout(dialTimeout))
+	if err != nil {
+		// Failing to connect is reported like an unavailable server,
+		// so that it exits with the same status.
+		return nil, grpc.Errorf(codes.Unavailable, "cannot connect to "+
+			"%s: %v", cfg.RPCServer, err)
+	}
+	conn = c
+	return conn, nil
+}
+
+// closeConnection closes the connection to wltd if one was made.
+func closeConnection() {
+	if conn != nil {
+		conn.Close()
+		conn = nil
+	}
+}
+
+// requestContext returns the context of a request.  The token identifying
+// the tenant is added by the connection.
+func requestContext() context.Context {
+	return context.Background()
+}
+
+// walletDaemonClient returns a client of the WalletDaemonService.
+func walletDaemonClient() (pb.WalletDaemonServiceClient, error) {
+	c, err := connection()
+	if err != nil {
+		return nil, err
+	}
+	return pb.NewWalletDaemonServiceClient(c), nil
+}
+
+// adminClient returns a client of the AdminService.
+func adminClient() (pb.AdminServiceClient, error) {
+	c, err := connection()
+	if err != nil {
+		return nil, err
+	}
+	return pb.NewAdminServiceClient(c), nil
+}
+
+// versionClient returns a client of the VersionService.
+func versionClient() (pb.VersionServiceClient, error) {
+	c, err := connection()
+	if err != nil {
+		return nil, err
+	}
+	return pb.NewVersionServiceClient(c), nil
+}
@@ -0,0 +1,207 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+	flags "github.com/jessevdk/go-flags"
+	pb "github.com/tuxcanfly/wltd/rpc/walletdrpc"
+)
+
+var (
+	errInvalidRole      = errors.New("the role must be tenant or admin")
+	errInvalidTokenName = errors.New("the name must be non-empty and " +
+		"contain no spaces")
+)
+
+// addCommands registers every command with the parser.
+func addCommands(parser *flags.Parser) {
+	parser.AddCommand("version", "Show the RPC API version of wltd",
+		"Show the version of the RPC API implemented by wltd.",
+		&versionCommand{})
+	parser.AddCommand("gentoken", "Generate a token",
+		"Generate a random token and show it with the line of the wltd "+
+			"authfile which grants it to a tenant or an admin.  The "+
+			"token is meant for the wltctl token file, and only "+
+			"the authfile line, which holds its hash, for wltd.",
+		&genTokenCommand{})
+	parser.AddCommand("ping", "Check that wltd is reachable",
+		"Send a ping request to wltd.", &pingCommand{})
+	parser.AddCommand("network", "Show the networks served by wltd",
+		"Show the default network of wltd, or of a wallet, and every "+
+			"network it serves.", &networkCommand{})
+	parser.AddCommand("wallet", "Manage wallets",
+		"Create, open, list, inspect and delete the wallets of the "+
+			"tenant.", &walletCommand{})
+	parser.AddCommand("tx", "Send and list transactions",
+		"Send transactions from a wallet and list its transactions.",
+		&txCommand{})
+	parser.AddCommand("label", "Label transactions, addresses and outputs",
+		"Set, show, export and import the labels of the transactions, "+
+			"addresses and outputs of a wallet.  Labels are "+
+			"exported and imported in the BIP329 format.",
+		&labelCommand{})
+	parser.AddCommand("admin", "Administer the daemon",
+		"Manage certificates, logging, the audit log, quotas, backups "+
+			"and wallet checks.", &adminCommand{})
+	parser.AddCommand("shell", "Run commands interactively",
+		"Run commands in an interactive shell over a single connection "+
+			"to wltd.  Wallet UUIDs are completed with the tab key and "+
+			"@ stands for the current wallet selected with the use "+
+			"command.  Wallet events are shown as they happen.",
+		&shellCommand{})
+}
+
+// newCommandParser creates a parser of the commands only.  It parses the
+// commands read in batch mode and by the shell, whose global options are
+// those of the wltctl invocation.
+func newCommandParser() *flags.Parser {
+	parser := flags.NewNamedParser("wltctl",
+		flags.HelpFlag|flags.PassDoubleDash)
+	addCommands(parser)
+	return parser
+}
+
+// netName returns the name of the network with the passed magic, or the magic
+// itself for unknown networks.
+func netName(net uint32) string {
+	nets := []*chaincfg.Params{&chaincfg.MainNetParams,
+		&chaincfg.TestNet3Params, &chaincfg.RegressionNetParams,
+		&chaincfg.SimNetParams}
+	for _, params := range nets {
+		if params.Net == wire.BitcoinNet(net) {
+			return params.Name
+		}
+	}
+	return fmt.Sprintf("unknown (%d)", net)
+}
+
+type versionCommand struct{}
+
+type versionResult struct {
+	Version string `json:"version"`
+}
+
+func (*versionCommand) Execute(args []string) error {
+	c, err := versionClient()
+	if err != nil {
+		return err
+	}
+	resp, err := c.Version(requestContext(), &pb.VersionRequest{})
+	if err != nil {
+		return err
+	}
+	result := versionResult{Version: resp.VersionString}
+	return printResult(result, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "Version: %s\n", result.Version)
+		return err
+	})
+}
+
+// tokenSize is the number of random bytes of a generated token.
+const tokenSize = 32
+
+type genTokenCommand struct {
+	Args struct {
+		Role string `positional-arg-name:"tenant|admin"`
+		Name string `positional-arg-name:"name"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+type genTokenResult struct {
+	Token        string `json:"token"`
+	AuthFileLine string `json:"authfile_line"`
+}
+
+func (cmd *genTokenCommand) Execute(args []string) error {
+	role, name := cmd.Args.Role, cmd.Args.Name
+	if role != "tenant" && role != "admin" {
+		return errInvalidRole
+	}
+	if name == "" || strings.IndexFunc(name, unicode.IsSpace) != -1 {
+		return errInvalidTokenName
+	}
+	var b [tokenSize]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return err
+	}
+	token := hex.EncodeToString(b[:])
+	hash := sha256.Sum256([]byte(token))
+	result := genTokenResult{
+		Token: token,
+		AuthFileLine: fmt.Sprintf("%s %s %x", role, name,
+			hash[:]),
+	}
+	return printResult(result, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "Token: %s\nAuthfile line: %s\n",
+			result.Token, result.AuthFileLine)
+		return err
+	})
+}
+
+type pingCommand struct{}
+
+type pingResult struct {
+	Pong bool `json:"pong"`
+}
+
+func (*pingCommand) Execute(args []string) error {
+	c, err := walletDaemonClient()
+	if err != nil {
+		return err
+	}
+	_, err = c.Ping(requestContext(), &pb.PingRequest{})
+	if err != nil {
+		return err
+	}
+	return printResult(pingResult{Pong: true}, func(w io.Writer) error {
+		_, err := fmt.Fprintln(w, "Pong")
+		return err
+	})
+}
+
+type networkCommand struct {
+	Wallet string `short:"w" long:"wallet" description:"Show the network of this wallet instead of the default network"`
+}
+
+type networkResult struct {
+	Network        string   `json:"network"`
+	ServedNetworks []string `json:"served_networks"`
+}
+
+func (cmd *networkCommand) Execute(args []string) error {
+	c, err := walletDaemonClient()
+	if err != nil {
+		return err
+	}
+	resp, err := c.Network(requestContext(),
+		&pb.NetworkRequest{WalletUuid: cmd.Wallet})
+	if err != nil {
+		return err
+	}
+	result := networkResult{
+		Network:        netName(resp.ActiveNetwork),
+		ServedNetworks: make([]string, 0, len(resp.Networks)),
+	}
+	for _, net := range resp.Networks {
+		result.ServedNetworks = append(result.ServedNetworks, netName(net))
+	}
+	return printResult(result, func(w io.Writer) error {
+		fmt.Fprintf(w, "Network: %s\n", result.Network)
+		_, err := fmt.Fprintf(w, "Served networks: %s\n",
+			strings.Join(result.ServedNetworks, ", "))
+		return err
+	})
+}
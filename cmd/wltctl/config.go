@@ -0,0 +1,184 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil"
+	flags "github.com/jessevdk/go-flags"
+)
+
+const defaultConfigFilename = "wltctl.conf"
+
+var (
+	wltdHomeDir         = btcutil.AppDataDir("wltd", false)
+	wltctlHomeDir       = btcutil.AppDataDir("wltctl", false)
+	defaultConfigFile   = filepath.Join(wltctlHomeDir, defaultConfigFilename)
+	defaultRPCCertFile  = filepath.Join(wltdHomeDir, "rpc.cert")
+	defaultTokenFile    = filepath.Join(wltctlHomeDir, "token")
+	errMultipleNetworks = errors.New("the testnet, regtest and simnet " +
+		"params can't be used together -- choose one")
+	errMultiplePassphraseSources = errors.New("the passphrase-file, " +
+		"passphrase-fd and passphrase-env options can't be used " +
+		"together -- choose one")
+)
+
+// defaultPorts maps each network name to the default RPC port of wltd for
+// that network.  These must match the ports used by wltd.
+var defaultPorts = map[string]string{
+	"mainnet":  "8335",
+	"testnet3": "18335",
+	"regtest":  "18446",
+	"simnet":   "18557",
+}
+
+// config holds the options shared by every command.  Options may also be set
+// in the configuration file, which is read before the command line.
+type config struct {
+	ConfigFile    string `short:"C" long:"configfile" description:"Path to configuration file"`
+	RPCServer     string `short:"s" long:"rpcserver" description:"RPC server to connect to (default localhost on the network's wltd port)"`
+	RPCCert       string `short:"c" long:"rpccert" description:"RPC server certificate chain for validation"`
+	TLSSkipVerify bool   `long:"skipverify" description:"Do not verify the RPC server certificate -- NOTE: This is only meant for testing"`
+	TokenFile     string `short:"t" long:"tokenfile" description:"Read the token authenticating the requests from the first line of this file (default ~/.wltctl/token)"`
+	TestNet3      bool   `long:"testnet" description:"Connect to the testnet wltd (default mainnet)"`
+	SimNet        bool   `long:"simnet" description:"Connect to the simnet wltd (default mainnet)"`
+	RegTest       bool   `long:"regtest" description:"Connect to the regtest wltd (default mainnet)"`
+	Output        string `long:"output" default:"table" choice:"table" choice:"json" choice:"yaml" description:"Format of the command output -- amounts are in satoshis and times are unix times in the json and yaml formats"`
+	Batch         bool   `long:"batch" no-ini:"true" description:"Read newline-delimited JSON commands from standard input and run them over a single connection"`
+
+	// Sources of the private passphrase of commands which need one.  It
+	// is prompted for on the terminal when none is set.
+	PassphraseFile string `long:"passphrase-file" description:"Read the private passphrase from the first line of this file"`
+	PassphraseFD   int    `long:"passphrase-fd" default:"-1" description:"Read the private passphrase from the first line of this file descriptor"`
+	PassphraseEnv  string `long:"passphrase-env" description:"Read the private passphrase from this environment variable -- NOTE: the environment may be visible to other users of the system"`
+
+	// activeNet is the network selected by the network options.
+	activeNet *chaincfg.Params
+}
+
+// cleanAndExpandPath expands environement variables and leading ~ in the
+// passed path, cleans the result, and returns it.
+func cleanAndExpandPath(path string) string {
+	// Expand initial ~ to OS specific home directory.
+	if strings.HasPrefix(path, "~") {
+		var homeDir string
+
+		u, err := user.Current()
+		if err == nil {
+			homeDir = u.HomeDir
+		} else {
+			homeDir = os.Getenv("HOME")
+		}
+
+		path = strings.Replace(path, "~", homeDir, 1)
+	}
+
+	// NOTE: The os.ExpandEnv doesn't work with Windows-style %VARIABLE%,
+	// but the variables can still be expanded via POSIX-style $VARIABLE.
+	return filepath.Clean(os.ExpandEnv(path))
+}
+
+// newParser creates the command line parser of the options and every command.
+// The commands are not added in batch mode, where they are read from standard
+// input instead.
+func newParser(cfg *config) *flags.Parser {
+	// Errors are printed by main, which also reports help to stdout.
+	parser := flags.NewParser(cfg, flags.HelpFlag|flags.PassDoubleDash)
+	if !cfg.Batch {
+		addCommands(parser)
+	}
+	return parser
+}
+
+// loadConfig reads the configuration file into cfg before the command line is
+// parsed.  Options given on the command line override the file.  A missing
+// configuration file is only an error when one is explicitly specified.
+func loadConfig(cfg *config, args []string) error {
+	// Pre-parse the command line options to see if an alternative config
+	// file was specified, and whether commands are read in batch mode.
+	// Commands and their options are ignored.
+	preCfg := struct {
+		ConfigFile string `short:"C" long:"configfile"`
+		Batch      bool   `long:"batch"`
+	}{ConfigFile: defaultConfigFile}
+	preParser := flags.NewParser(&preCfg, flags.IgnoreUnknown)
+	if _, err := preParser.ParseArgs(args); err != nil {
+		return err
+	}
+
+	cfg.Batch = preCfg.Batch
+
+	configFile := cleanAndExpandPath(preCfg.ConfigFile)
+	iniParser := flags.NewIniParser(flags.NewParser(cfg, flags.IgnoreUnknown))
+	err := iniParser.ParseFile(configFile)
+	if err != nil {
+		if _, ok := err.(*os.PathError); !ok || preCfg.ConfigFile != defaultConfigFile {
+			return fmt.Errorf("cannot read configuration file: %v", err)
+		}
+	}
+	return nil
+}
+
+// normalizeConfig validates the options and fills in the defaults which
+// depend on the network.
+func normalizeConfig(cfg *config) error {
+	cfg.activeNet = &chaincfg.MainNetParams
+	numNets := 0
+	if cfg.TestNet3 {
+		cfg.activeNet = &chaincfg.TestNet3Params
+		numNets++
+	}
+	if cfg.SimNet {
+		cfg.activeNet = &chaincfg.SimNetParams
+		numNets++
+	}
+	if cfg.RegTest {
+		cfg.activeNet = &chaincfg.RegressionNetParams
+		numNets++
+	}
+	if numNets > 1 {
+		return errMultipleNetworks
+	}
+
+	if cfg.RPCServer == "" {
+		cfg.RPCServer = net.JoinHostPort("localhost",
+			defaultPorts[cfg.activeNet.Name])
+	} else if _, _, err := net.SplitHostPort(cfg.RPCServer); err != nil {
+		cfg.RPCServer = net.JoinHostPort(cfg.RPCServer,
+			defaultPorts[cfg.activeNet.Name])
+	}
+
+	sources := 0
+	if cfg.PassphraseFile != "" {
+		sources++
+	}
+	if cfg.PassphraseFD >= 0 {
+		sources++
+	}
+	if cfg.PassphraseEnv != "" {
+		sources++
+	}
+	if sources > 1 {
+		return errMultiplePassphraseSources
+	}
+
+	if cfg.RPCCert == "" {
+		cfg.RPCCert = defaultRPCCertFile
+	}
+	cfg.RPCCert = cleanAndExpandPath(cfg.RPCCert)
+	if cfg.TokenFile == "" {
+		cfg.TokenFile = defaultTokenFile
+	}
+	cfg.TokenFile = cleanAndExpandPath(cfg.TokenFile)
+	return nil
+}
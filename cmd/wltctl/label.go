@@ -0,0 +1,300 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	pb "github.com/tuxcanfly/wltd/rpc/walletdrpc"
+)
+
+// importChunkSize is the size of the export parts sent to ImportLabels.
+const importChunkSize = 64 * 1024
+
+type labelCommand struct {
+	Set    labelSetCommand    `command:"set" description:"Set the label of a transaction, address or output of a wallet"`
+	Remove labelRemoveCommand `command:"remove" description:"Remove the label of a transaction, address or output of a wallet"`
+	Get    labelGetCommand    `command:"get" description:"Show the labels of a wallet"`
+	Export labelExportCommand `command:"export" description:"Export the labels of a wallet in the BIP329 format"`
+	Import labelImportCommand `command:"import" description:"Import labels in the BIP329 format into a wallet"`
+}
+
+// labelResult is a label of a wallet.
+type labelResult struct {
+	Type  string `json:"type"`
+	Ref   string `json:"ref"`
+	Label string `json:"label"`
+}
+
+type labelsResult struct {
+	Labels []labelResult `json:"labels"`
+}
+
+// labelArgs are the positional arguments of commands operating on the label
+// of a transaction, address or output of a wallet.
+type labelArgs struct {
+	UUID string `positional-arg-name:"uuid"`
+	Type string `positional-arg-name:"tx|addr|output"`
+	Ref  string `positional-arg-name:"ref" description:"Transaction hash, address, or transaction hash and output index as txid:index"`
+}
+
+type labelSetCommand struct {
+	Args struct {
+		UUID  string `positional-arg-name:"uuid"`
+		Type  string `positional-arg-name:"tx|addr|output"`
+		Ref   string `positional-arg-name:"ref" description:"Transaction hash, address, or transaction hash and output index as txid:index"`
+		Label string `positional-arg-name:"label"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+func (cmd *labelSetCommand) Execute(args []string) error {
+	a := labelArgs{UUID: cmd.Args.UUID, Type: cmd.Args.Type,
+		Ref: cmd.Args.Ref}
+	return setLabel(&a, cmd.Args.Label)
+}
+
+type labelRemoveCommand struct {
+	Args labelArgs `positional-args:"yes" required:"yes"`
+}
+
+func (cmd *labelRemoveCommand) Execute(args []string) error {
+	return setLabel(&cmd.Args, "")
+}
+
+// setLabel sets the label of the ref of a wallet, or removes it when label is
+// empty.
+func setLabel(a *labelArgs, label string) error {
+	c, err := walletDaemonClient()
+	if err != nil {
+		return err
+	}
+	_, err = c.SetLabels(requestContext(), &pb.SetLabelsRequest{
+		WalletUuid: a.UUID,
+		Labels: []*pb.Label{{
+			Type:  a.Type,
+			Ref:   a.Ref,
+			Label: label,
+		}},
+	})
+	if err != nil {
+		return err
+	}
+	result := labelResult{Type: a.Type, Ref: a.Ref, Label: label}
+	return printResult(result, func(w io.Writer) error {
+		var err error
+		if label == "" {
+			_, err = fmt.Fprintf(w, "Removed label of %s %s\n",
+				result.Type, result.Ref)
+		} else {
+			_, err = fmt.Fprintf(w, "Set label of %s %s\n",
+				result.Type, result.Ref)
+		}
+		return err
+	})
+}
+
+type labelGetCommand struct {
+	Type string     `long:"type" choice:"tx" choice:"addr" choice:"output" description:"Only show labels of this type"`
+	Refs []string   `long:"ref" description:"Only show the label of this ref, which requires --type -- may be repeated"`
+	Args walletArgs `positional-args:"yes" required:"yes"`
+}
+
+func (cmd *labelGetCommand) Execute(args []string) error {
+	c, err := walletDaemonClient()
+	if err != nil {
+		return err
+	}
+	resp, err := c.GetLabels(requestContext(), &pb.GetLabelsRequest{
+		WalletUuid: cmd.Args.UUID,
+		Type:       cmd.Type,
+		Refs:       cmd.Refs,
+	})
+	if err != nil {
+		return err
+	}
+	result := labelsResult{
+		Labels: make([]labelResult, 0, len(resp.Labels)),
+	}
+	for _, l := range resp.Labels {
+		result.Labels = append(result.Labels, labelResult{
+			Type:  l.Type,
+			Ref:   l.Ref,
+			Label: l.Label,
+		})
+	}
+	return printResult(result, func(w io.Writer) error {
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(tw, "TYPE\tREF\tLABEL")
+		for _, l := range result.Labels {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", l.Type, l.Ref, l.Label)
+		}
+		return tw.Flush()
+	})
+}
+
+// errExportOut is returned when exporting labels to standard output in batch
+// mode, where it holds the responses.
+var errExportOut = errors.New("labels may only be exported to a file with " +
+	"--out in batch mode")
+
+type labelExportCommand struct {
+	Out  string     `short:"o" long:"out" description:"File to write the labels to (default standard output)"`
+	Args walletArgs `positional-args:"yes" required:"yes"`
+}
+
+type labelExportResult struct {
+	UUID   string `json:"uuid"`
+	File   string `json:"file"`
+	Labels int    `json:"labels"`
+}
+
+func (cmd *labelExportCommand) Execute(args []string) error {
+	if cmd.Out == "" && cfg.Batch {
+		return errExportOut
+	}
+	c, err := walletDaemonClient()
+	if err != nil {
+		return err
+	}
+	stream, err := c.ExportLabels(requestContext(),
+		&pb.ExportLabelsRequest{WalletUuid: cmd.Args.UUID})
+	if err != nil {
+		return err
+	}
+
+	// Without an output file the export is written as it is received,
+	// since it is the output of the command.
+	if cmd.Out == "" {
+		for {
+			resp, err := stream.Recv()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if _, err := os.Stdout.Write(resp.Data); err != nil {
+				return err
+			}
+		}
+	}
+
+	// The export is written under a temporary name, so an interrupted
+	// export is not mistaken for a complete one.
+	out := cleanAndExpandPath(cmd.Out)
+	tmp := out + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	labels := 0
+	for {
+		var resp *pb.ExportLabelsResponse
+		resp, err = stream.Recv()
+		if err != nil {
+			break
+		}
+		labels += bytes.Count(resp.Data, []byte{'\n'})
+		if _, err = f.Write(resp.Data); err != nil {
+			break
+		}
+	}
+	if err == io.EOF {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, out)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	result := labelExportResult{UUID: cmd.Args.UUID, File: out,
+		Labels: labels}
+	return printResult(result, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "Wrote %d labels of wallet %s to %s\n",
+			result.Labels, result.UUID, result.File)
+		return err
+	})
+}
+
+type labelImportCommand struct {
+	Args struct {
+		UUID string `positional-arg-name:"uuid"`
+		File string `positional-arg-name:"file" description:"BIP329 file to import, or - for standard input"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+type labelImportResult struct {
+	UUID     string `json:"uuid"`
+	Imported uint32 `json:"imported"`
+	Skipped  uint32 `json:"skipped"`
+}
+
+func (cmd *labelImportCommand) Execute(args []string) error {
+	in := os.Stdin
+	if cmd.Args.File != "-" {
+		f, err := os.Open(cleanAndExpandPath(cmd.Args.File))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	c, err := walletDaemonClient()
+	if err != nil {
+		return err
+	}
+	stream, err := c.ImportLabels(requestContext())
+	if err != nil {
+		return err
+	}
+	buf := make([]byte, importChunkSize)
+	first := true
+	for {
+		n, err := in.Read(buf)
+		if n > 0 || first {
+			req := &pb.ImportLabelsRequest{Data: buf[:n]}
+			if first {
+				req.WalletUuid = cmd.Args.UUID
+				first = false
+			}
+			if err := stream.Send(req); err != nil {
+				break
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			stream.CloseSend()
+			return err
+		}
+	}
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+	result := labelImportResult{
+		UUID:     cmd.Args.UUID,
+		Imported: resp.Imported,
+		Skipped:  resp.Skipped,
+	}
+	return printResult(result, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "Imported %d labels into wallet %s, "+
+			"skipped %d records\n", result.Imported, result.UUID,
+			result.Skipped)
+		return err
+	})
+}
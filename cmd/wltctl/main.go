@@ -1,114 +1,113 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// wltctl is the command line client of wltd.  Every RPC of the daemon is
+// available as a command, such as "wltctl wallet create" or "wltctl admin
+// loglevels".  Run "wltctl --help" for the list of commands.
+//
+// The output of commands is human-readable by default.  Scripts should select
+// the json or yaml format with --output, or run commands in batch mode with
+// --batch, and rely on the exit status, which is one of:
+//
+//   0      success
+//   1      failure not reported by wltd, or with an unknown status code
+//   2      invalid command line or configuration
+//   3      a check or verification found problems
+//   20+N   wltd returned an error with gRPC status code N, such as 25 for
+//          NotFound, 29 for FailedPrecondition, 33 for Internal or 34 for
+//          Unavailable, which is also returned when wltd cannot be reached
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
 
-	walletpb "github.com/btcsuite/btcwallet/rpc/walletrpc"
-	flags "github.com/btcsuite/go-flags"
-	wltdpb "github.com/tuxcanfly/wltd/rpc/walletdrpc"
-	"golang.org/x/net/context"
+	flags "github.com/jessevdk/go-flags"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/codes"
+)
 
-	"github.com/btcsuite/btcutil"
+// Exit statuses of wltctl.  Errors returned by wltd exit with exitRPCBase plus
+// their gRPC status code, up to maxRPCCode.
+const (
+	exitSuccess       = 0
+	exitFailure       = 1
+	exitUsage         = 2
+	exitProblemsFound = 3
+	exitRPCBase       = 20
+
+	// maxRPCCode is the highest gRPC status code.  Errors with a higher
+	// code exit with exitFailure, so that they cannot be mistaken for
+	// other exit statuses.
+	maxRPCCode = codes.Unauthenticated
 )
 
-type config struct {
-	Create     string `short:"c" long:"create" description:"Create new Wallet"`
-	GetBalance string `short:"b" long:"balance" description:"Get Wallet Balance"`
-}
+// errBatchArgs is returned when commands are given on the command line in
+// batch mode.
+var errBatchArgs = errors.New("commands are read from standard input in " +
+	"batch mode and may not be given on the command line")
 
-func createWallet(pass string) (string, error) {
-	certificateFile := filepath.Join(btcutil.AppDataDir("wltd", false), "rpc.cert")
-	creds, err := credentials.NewClientTLSFromFile(certificateFile, "localhost")
-	if err != nil {
-		return "", err
-	}
-	conn, err := grpc.Dial("localhost:18335", grpc.WithTransportCredentials(creds))
-	if err != nil {
-		return "", err
-	}
-	defer conn.Close()
+// cfg holds the options shared by every command.  It is set before any
+// command is executed.
+var cfg config
 
-	c := wltdpb.NewWalletDaemonServiceClient(conn)
-	req := &wltdpb.CreateWalletRequest{pass}
-	resp, err := c.CreateWallet(context.Background(), req)
-	if err != nil {
-		return "", err
-	}
-
-	return resp.Uuid, nil
+func main() {
+	os.Exit(mainInt())
 }
 
-func getBalance(wid string) (int64, error) {
-	certificateFile := filepath.Join(btcutil.AppDataDir("btcwallet", false), "rpc.cert")
-	creds, err := credentials.NewClientTLSFromFile(certificateFile, "localhost")
-	if err != nil {
-		return 0, err
+func mainInt() int {
+	if err := loadConfig(&cfg, os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
 	}
-	conn, err := grpc.Dial("localhost:18332", grpc.WithTransportCredentials(creds))
-	if err != nil {
-		return 0, err
-	}
-	defer conn.Close()
 
-	wltDir := filepath.Join(btcutil.AppDataDir("wltd", false), "wallets", wid, "testnet")
-	c := walletpb.NewWalletLoaderServiceClient(conn)
-	req := &walletpb.OpenWalletRequest{Path: wltDir}
-	_, err = c.OpenWallet(context.Background(), req)
-	if err != nil {
-		return 0, err
-	}
-
-	w := walletpb.NewWalletServiceClient(conn)
-	breq := &walletpb.BalanceRequest{}
-	bresp, err := w.Balance(context.Background(), breq)
-	if err != nil {
-		return 0, err
-	}
-
-	creq := &walletpb.CloseWalletRequest{Path: wltDir}
-	_, err = c.CloseWallet(context.Background(), creq)
-	if err != nil {
-		return 0, err
+	parser := newParser(&cfg)
+	args, err := parser.Parse()
+	if err == nil && cfg.Batch {
+		if len(args) != 0 {
+			err = errBatchArgs
+		} else {
+			code := runBatch(os.Stdin, os.Stdout)
+			closeConnection()
+			return code
+		}
 	}
-
-	return bresp.Total, nil
-}
-
-func main() {
-	cfg := config{}
-	parser := flags.NewParser(&cfg, flags.Default)
-	_, err := parser.Parse()
+	closeConnection()
 	if err != nil {
-		if e, ok := err.(*flags.Error); !ok || e.Type != flags.ErrHelp {
-			parser.WriteHelp(os.Stderr)
+		if e, ok := err.(*flags.Error); ok && e.Type == flags.ErrHelp {
+			fmt.Fprintln(os.Stdout, err)
+			return exitSuccess
 		}
-		return
+		fmt.Fprintln(os.Stderr, grpc.ErrorDesc(err))
+		return exitCode(err)
 	}
+	return exitSuccess
+}
 
-	if cfg.Create == "" && cfg.GetBalance == "" {
-		fmt.Fprintln(os.Stderr, "no cmd specified")
-		os.Exit(1)
+// exitCode returns the exit status of a command which failed with err.
+func exitCode(err error) int {
+	switch err {
+	case nil:
+		return exitSuccess
+	case errProblemsFound:
+		return exitProblemsFound
+	case errBatchArgs, errNoBatchCommand, errMultipleNetworks,
+		errMultiplePassphraseSources, errInvalidCount, errInvalidMetadata,
+		errNoMetadataUpdate, errNameConflict, errExportOut,
+		errInvalidRole, errInvalidTokenName:
+		return exitUsage
 	}
-
-	if cfg.Create != "" {
-		wid, err := createWallet(cfg.Create)
-		if err != nil {
-			fmt.Fprintln(os.Stderr, err.Error())
-			os.Exit(1)
-		}
-		fmt.Printf("Wallet id: %v\n", wid)
+	if _, ok := err.(*flags.Error); ok {
+		return exitUsage
 	}
 
-	if cfg.GetBalance != "" {
-		balance, err := getBalance(cfg.GetBalance)
-		if err != nil {
-			fmt.Fprintln(os.Stderr, err.Error())
-			os.Exit(1)
-		}
-		fmt.Printf("Balance: %v\n", balance)
+	// Errors which did not come from wltd have the Unknown code, and so
+	// does any internal error of wltd which was not classified.
+	code := grpc.Code(err)
+	if code == codes.Unknown || code > maxRPCCode {
+		return exitFailure
 	}
+	return exitRPCBase + int(code)
 }
@@ -0,0 +1,182 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Output formats of the results of commands.
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+// batchResult is the result of the command run by the current line in batch
+// mode.  Results are collected rather than printed, so that they are written
+// as part of the response to the line.
+var batchResult interface{}
+
+// printResult writes the result of a command to standard output in the
+// selected output format.  The json and yaml formats marshal v, while the
+// table format is written by table, which may be nil for commands without
+// human-readable output.
+func printResult(v interface{}, table func(w io.Writer) error) error {
+	if cfg.Batch {
+		batchResult = v
+		return nil
+	}
+	switch cfg.Output {
+	case outputJSON:
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(append(b, '\n'))
+		return err
+	case outputYAML:
+		return writeYAML(os.Stdout, v)
+	default:
+		if table == nil {
+			return nil
+		}
+		return table(os.Stdout)
+	}
+}
+
+// writeYAML writes v as a YAML document.  The document is built from the
+// JSON encoding of v, so both formats use the same field names.
+func writeYAML(w io.Writer, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	switch doc := doc.(type) {
+	case map[string]interface{}:
+		if len(doc) == 0 {
+			buf.WriteString("{}\n")
+			break
+		}
+		writeYAMLMap(&buf, doc, 0)
+	case []interface{}:
+		if len(doc) == 0 {
+			buf.WriteString("[]\n")
+			break
+		}
+		writeYAMLList(&buf, doc, 0)
+	default:
+		buf.WriteString(yamlScalar(doc))
+		buf.WriteByte('\n')
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// writeYAMLMap writes the entries of a mapping, sorted by key, each on a line
+// indented by indent spaces.
+func writeYAMLMap(buf *bytes.Buffer, m map[string]interface{}, indent int) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		buf.WriteString(strings.Repeat(" ", indent))
+		buf.WriteString(yamlScalar(k))
+		buf.WriteByte(':')
+		writeYAMLValue(buf, m[k], indent+2)
+	}
+}
+
+// writeYAMLList writes the elements of a sequence, each on a line indented
+// by indent spaces.
+func writeYAMLList(buf *bytes.Buffer, l []interface{}, indent int) {
+	for _, e := range l {
+		buf.WriteString(strings.Repeat(" ", indent))
+		buf.WriteByte('-')
+		writeYAMLValue(buf, e, indent+2)
+	}
+}
+
+// writeYAMLValue writes a value following the key or sequence indicator
+// already written to the current line.  Nested collections start on the
+// next line, indented by indent spaces.
+func writeYAMLValue(buf *bytes.Buffer, v interface{}, indent int) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			buf.WriteString(" {}\n")
+			return
+		}
+		buf.WriteByte('\n')
+		writeYAMLMap(buf, v, indent)
+	case []interface{}:
+		if len(v) == 0 {
+			buf.WriteString(" []\n")
+			return
+		}
+		buf.WriteByte('\n')
+		writeYAMLList(buf, v, indent)
+	default:
+		buf.WriteByte(' ')
+		buf.WriteString(yamlScalar(v))
+		buf.WriteByte('\n')
+	}
+}
+
+// plainYAMLString matches the strings which may be written without quotes.
+var plainYAMLString = regexp.MustCompile(`^[A-Za-z_/][A-Za-z0-9_./-]*$`)
+
+// yamlScalar formats a JSON scalar as a YAML scalar.  Strings which could be
+// read as another type are quoted.
+func yamlScalar(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		if v {
+			return "true"
+		}
+		return "false"
+	case json.Number:
+		return v.String()
+	case string:
+		switch strings.ToLower(v) {
+		case "true", "false", "yes", "no", "on", "off", "null", "y", "n":
+			return `"` + v + `"`
+		}
+		if plainYAMLString.MatchString(v) {
+			return v
+		}
+		// JSON strings are valid double-quoted YAML scalars.
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+	return ""
+}
+
+// stringList returns l, or an empty list when l is nil, so that lists are
+// never encoded as null.
+func stringList(l []string) []string {
+	if l == nil {
+		return []string{}
+	}
+	return l
+}
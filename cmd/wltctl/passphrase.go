@@ -0,0 +1,147 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+var (
+	// errNoPassphraseSource is returned when a command needs a passphrase
+	// which cannot be prompted for.
+	errNoPassphraseSource = errors.New("standard input is not a terminal, " +
+		"provide the passphrase with --passphrase-file, --passphrase-fd " +
+		"or --passphrase-env")
+
+	// errPassphraseMismatch is returned when the confirmation of a new
+	// passphrase differs.
+	errPassphraseMismatch = errors.New("passphrases do not match")
+
+	// errEmptyPassphrase is returned when the passphrase read is empty.
+	errEmptyPassphrase = errors.New("passphrase may not be empty")
+)
+
+// passphrase caches the passphrase read from a file, descriptor or
+// environment variable, which may only be readable once.
+var passphrase []byte
+
+// readPassphrase returns the private passphrase of a wallet.  It is read from
+// the source configured by the passphrase options, or prompted for when none
+// is configured and standard input is a terminal.  New passphrases are
+// prompted for twice when confirm is set.
+func readPassphrase(confirm bool) ([]byte, error) {
+	if passphrase != nil {
+		return passphrase, nil
+	}
+
+	var pass []byte
+	var err error
+	switch {
+	case cfg.PassphraseFile != "":
+		pass, err = readPassphraseFile(cleanAndExpandPath(cfg.PassphraseFile))
+	case cfg.PassphraseFD >= 0:
+		f := os.NewFile(uintptr(cfg.PassphraseFD), "passphrase-fd")
+		if f == nil {
+			return nil, fmt.Errorf("invalid passphrase file descriptor %d",
+				cfg.PassphraseFD)
+		}
+		pass, err = readPassphraseLine(f)
+		f.Close()
+	case cfg.PassphraseEnv != "":
+		fmt.Fprintf(os.Stderr, "Warning: reading the passphrase from "+
+			"the environment variable %s, which may be visible to "+
+			"other processes\n", cfg.PassphraseEnv)
+		v, ok := os.LookupEnv(cfg.PassphraseEnv)
+		if !ok {
+			return nil, fmt.Errorf("environment variable %s is not set",
+				cfg.PassphraseEnv)
+		}
+		pass = []byte(v)
+	default:
+		// Prompted passphrases are not cached, so every command of a
+		// session confirms its own passphrase.
+		return promptPassphrase(confirm)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(pass) == 0 {
+		return nil, errEmptyPassphrase
+	}
+	passphrase = pass
+	return pass, nil
+}
+
+// readPassphraseFile reads the passphrase from the first line of the named
+// file.  A warning is written when the file may be read by other users.
+func readPassphraseFile(name string) ([]byte, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if fi, err := f.Stat(); err == nil && fi.Mode().Perm()&0077 != 0 {
+		fmt.Fprintf(os.Stderr, "Warning: passphrase file %s is "+
+			"accessible by other users\n", name)
+	}
+	return readPassphraseLine(f)
+}
+
+// readPassphraseLine reads the first line of r, without its line ending.
+func readPassphraseLine(r io.Reader) ([]byte, error) {
+	line, err := bufio.NewReader(r).ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	return bytes.TrimRight(line, "\r\n"), nil
+}
+
+// promptPassphrase prompts for the passphrase on the terminal without echoing
+// it, asking for it twice when confirm is set.
+func promptPassphrase(confirm bool) ([]byte, error) {
+	fd := int(os.Stdin.Fd())
+	if !terminal.IsTerminal(fd) {
+		return nil, errNoPassphraseSource
+	}
+
+	prompt := "Private passphrase: "
+	if confirm {
+		prompt = "Enter the private passphrase of the new wallet: "
+	}
+	pass, err := promptLine(fd, prompt)
+	if err != nil {
+		return nil, err
+	}
+	if len(pass) == 0 {
+		return nil, errEmptyPassphrase
+	}
+	if !confirm {
+		return pass, nil
+	}
+	again, err := promptLine(fd, "Confirm passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(pass, again) {
+		return nil, errPassphraseMismatch
+	}
+	return pass, nil
+}
+
+// promptLine writes the prompt to standard error and reads a line from the
+// terminal without echoing it.
+func promptLine(fd int, prompt string) ([]byte, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	line, err := terminal.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	return line, err
+}
@@ -0,0 +1,586 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	flags "github.com/jessevdk/go-flags"
+	pb "github.com/tuxcanfly/wltd/rpc/walletdrpc"
+	"golang.org/x/crypto/ssh/terminal"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+const (
+	// shellHistoryFilename is the name of the file in the wltctl home
+	// directory which the commands of the shell are appended to.
+	shellHistoryFilename = "shell_history"
+
+	// walletPlaceholder stands for the current wallet in shell commands.
+	walletPlaceholder = "@"
+
+	// redacted replaces secrets in the shell history.
+	redacted = "<redacted>"
+
+	// walletCacheTimeout is how long the wallets listed for completion are
+	// reused before being listed again.
+	walletCacheTimeout = 10 * time.Second
+
+	// completionTimeout is how long listing the wallets for completion
+	// may take.
+	completionTimeout = 5 * time.Second
+)
+
+var (
+	// errEndlessCommand is returned by commands which stream until they
+	// are interrupted when they are run in batch mode or the shell.
+	errEndlessCommand = errors.New("this command does not end and cannot " +
+		"be run in batch mode or the shell")
+
+	// errNoCurrentWallet is returned when a shell command refers to the
+	// current wallet before one is selected.
+	errNoCurrentWallet = errors.New("no current wallet -- select one " +
+		"with use <uuid>")
+)
+
+// shellBuiltins are the commands implemented by the shell itself.
+var shellBuiltins = []string{"exit", "help", "history", "quit", "use"}
+
+// inShell is set while the shell runs, so that commands which never end, and
+// the shell itself, are refused.
+var inShell bool
+
+type shellCommand struct {
+	NoEvents bool `long:"noevents" description:"Do not show wallet events while the shell runs"`
+}
+
+func (cmd *shellCommand) Execute(args []string) error {
+	if cfg.Batch || inShell {
+		return errors.New("the shell cannot be started in batch mode " +
+			"or from another shell")
+	}
+	fd := int(os.Stdin.Fd())
+	if !terminal.IsTerminal(fd) {
+		return errors.New("the shell needs a terminal -- use --batch " +
+			"to run commands from a script")
+	}
+
+	// Connecting first reports an unreachable server before the
+	// terminal is changed.
+	c, err := walletDaemonClient()
+	if err != nil {
+		return err
+	}
+
+	state, err := terminal.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer terminal.Restore(fd, state)
+
+	sh := &shell{
+		fd:     fd,
+		state:  state,
+		client: c,
+		parser: newCommandParser(),
+	}
+	sh.term = terminal.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{os.Stdin, os.Stdout}, "")
+	if width, height, err := terminal.GetSize(fd); err == nil {
+		sh.term.SetSize(width, height)
+	}
+	sh.term.AutoCompleteCallback = sh.complete
+	sh.openHistory()
+	if sh.history != nil {
+		defer sh.history.Close()
+	}
+
+	inShell = true
+	defer func() { inShell = false }()
+
+	if !cmd.NoEvents {
+		ctx, cancel := context.WithCancel(requestContext())
+		defer cancel()
+		go sh.streamEvents(ctx)
+	}
+	return sh.run()
+}
+
+// shell reads commands from the terminal and runs them over the connection
+// of the process.
+type shell struct {
+	fd     int
+	client pb.WalletDaemonServiceClient
+	term   *terminal.Terminal
+
+	// parser describes the commands for help and completion.
+	parser *flags.Parser
+
+	// state is the state of the terminal before the shell made it raw.  It
+	// is restored while commands run, so they may prompt for passphrases
+	// and write their output as usual.
+	state *terminal.State
+
+	// history is the file the commands are appended to, and lines the
+	// commands of this session.  Both are redacted.
+	history *os.File
+	lines   []string
+
+	// wallet is the UUID of the current wallet, which replaces the
+	// wallet placeholder.
+	wallet string
+
+	// wallets are the UUIDs completed by the tab key, which were listed at
+	// walletsListed.
+	wallets       []string
+	walletsListed time.Time
+
+	// mu protects the fields below.  Events are queued while a command
+	// runs, so they are not mixed with its output.
+	mu      sync.Mutex
+	busy    bool
+	pending []string
+}
+
+// run reads and runs commands until the terminal is closed or the shell is
+// exited.
+func (sh *shell) run() error {
+	sh.printf("Connected to %s.  Type help for the shell commands, exit "+
+		"to leave.\n", cfg.RPCServer)
+	for {
+		sh.term.SetPrompt(sh.prompt())
+		line, err := sh.term.ReadLine()
+		if err == io.EOF {
+			sh.printf("\n")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		args, err := splitShellLine(line)
+		if err != nil {
+			sh.printf("%v\n", err)
+			continue
+		}
+		if len(args) == 0 {
+			continue
+		}
+		sh.record(args)
+		if exit := sh.execute(args); exit {
+			return nil
+		}
+	}
+}
+
+// prompt returns the prompt, which shows the beginning of the UUID of the
+// current wallet.
+func (sh *shell) prompt() string {
+	if sh.wallet == "" {
+		return "wltctl> "
+	}
+	id := sh.wallet
+	if len(id) > 8 {
+		id = id[:8]
+	}
+	return "wltctl [" + id + "]> "
+}
+
+// printf writes to the terminal while no command runs.
+func (sh *shell) printf(format string, args ...interface{}) {
+	fmt.Fprintf(sh.term, format, args...)
+}
+
+// execute runs a builtin or a wltctl command.  It returns true when the shell
+// is exited.
+func (sh *shell) execute(args []string) bool {
+	switch args[0] {
+	case "exit", "quit":
+		return true
+	case "help":
+		if len(args) == 1 {
+			sh.help()
+			return false
+		}
+		args = append(args[1:], "--help")
+	case "history":
+		for i, line := range sh.lines {
+			sh.printf("%5d  %s\n", i+1, line)
+		}
+		return false
+	case "use":
+		sh.use(args[1:])
+		return false
+	}
+
+	for i, arg := range args {
+		if arg != walletPlaceholder {
+			continue
+		}
+		if sh.wallet == "" {
+			sh.printf("%v\n", errNoCurrentWallet)
+			return false
+		}
+		args[i] = sh.wallet
+	}
+
+	// Every command is parsed by a new parser, so that options set by
+	// an earlier command are not kept.
+	sh.setBusy(true)
+	terminal.Restore(sh.fd, sh.state)
+	_, err := newCommandParser().ParseArgs(args)
+	if err != nil {
+		if e, ok := err.(*flags.Error); ok && e.Type == flags.ErrHelp {
+			fmt.Fprintln(os.Stdout, err)
+		} else {
+			fmt.Fprintf(os.Stderr, "%s (exit status %d)\n",
+				grpc.ErrorDesc(err), exitCode(err))
+		}
+	}
+	if _, err := terminal.MakeRaw(sh.fd); err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot restore the shell terminal: "+
+			"%v\n", err)
+		return true
+	}
+	sh.setBusy(false)
+	return false
+}
+
+// help writes the builtins and commands of the shell.
+func (sh *shell) help() {
+	sh.printf("Shell commands:\n" +
+		"  use [uuid]      select the current wallet, or clear it\n" +
+		"  history         show the commands of this session\n" +
+		"  help [command]  show this help or the help of a command\n" +
+		"  exit            leave the shell\n" +
+		"\nwltctl commands:\n")
+	for _, c := range sh.parser.Commands() {
+		if c.Name == "shell" {
+			continue
+		}
+		sh.printf("  %-14s  %s\n", c.Name, c.ShortDescription)
+	}
+	sh.printf("\n%s stands for the current wallet, and the tab key "+
+		"completes commands, options and wallet UUIDs.\n",
+		walletPlaceholder)
+}
+
+// use selects the current wallet, which must be a wallet of the tenant, or
+// clears it when no UUID is passed.
+func (sh *shell) use(args []string) {
+	switch len(args) {
+	case 0:
+		sh.wallet = ""
+		sh.printf("No current wallet\n")
+		return
+	case 1:
+	default:
+		sh.printf("usage: use [uuid]\n")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(requestContext(), completionTimeout)
+	defer cancel()
+	resp, err := sh.client.GetWalletInfo(ctx,
+		&pb.GetWalletInfoRequest{WalletUuid: args[0]})
+	if err != nil {
+		sh.printf("%s\n", grpc.ErrorDesc(err))
+		return
+	}
+	sh.wallet = resp.Wallet.Uuid
+	sh.printf("Current wallet: %s (%s)\n", sh.wallet, resp.Wallet.Network)
+}
+
+// setBusy marks whether a command runs.  Events queued while it ran are
+// written once it is done.
+func (sh *shell) setBusy(busy bool) {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	sh.busy = busy
+	if busy {
+		return
+	}
+	for _, line := range sh.pending {
+		sh.printf("%s\n", line)
+	}
+	sh.pending = nil
+}
+
+// printEvent writes a line describing an event, or queues it while a command
+// runs.
+func (sh *shell) printEvent(line string) {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	if sh.busy {
+		sh.pending = append(sh.pending, line)
+		return
+	}
+	sh.printf("%s\n", line)
+}
+
+// streamEvents writes the events of the wallets of the tenant until ctx is
+// canceled.  It must be run as a goroutine.
+func (sh *shell) streamEvents(ctx context.Context) {
+	stream, err := sh.client.WalletEvents(ctx, &pb.WalletEventsRequest{})
+	if err != nil {
+		sh.printEvent(fmt.Sprintf("Cannot stream wallet events: %s",
+			grpc.ErrorDesc(err)))
+		return
+	}
+	for {
+		e, err := stream.Recv()
+		if err != nil {
+			if ctx.Err() == nil && err != io.EOF {
+				sh.printEvent(fmt.Sprintf("Wallet events ended: %s",
+					grpc.ErrorDesc(err)))
+			}
+			return
+		}
+		sh.printEvent(formatEvent(e))
+	}
+}
+
+// openHistory opens the history file, creating it readable by the user only.
+// The history is only kept in memory when the file cannot be opened.
+func (sh *shell) openHistory() {
+	err := os.MkdirAll(wltctlHomeDir, 0700)
+	if err == nil {
+		name := filepath.Join(wltctlHomeDir, shellHistoryFilename)
+		sh.history, err = os.OpenFile(name,
+			os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	}
+	if err != nil {
+		sh.printf("Cannot open the shell history: %v\n", err)
+	}
+}
+
+// record adds a command to the history, with its secrets redacted.
+func (sh *shell) record(args []string) {
+	line := joinShellArgs(redactArgs(args))
+	sh.lines = append(sh.lines, line)
+	if sh.history != nil {
+		fmt.Fprintln(sh.history, line)
+	}
+}
+
+// secretOption returns whether the value of the named long option is a
+// secret.  The options naming where a passphrase is read from are not.
+func secretOption(name string) bool {
+	name = strings.ToLower(name)
+	switch name {
+	case "passphrase-file", "passphrase-fd", "passphrase-env":
+		return false
+	}
+	return strings.Contains(name, "pass") || strings.Contains(name, "seed") ||
+		strings.Contains(name, "secret")
+}
+
+// redactArgs returns a copy of the arguments of a command with the values of
+// secret options replaced.
+func redactArgs(args []string) []string {
+	out := make([]string, len(args))
+	copy(out, args)
+	for i := 0; i < len(out); i++ {
+		if !strings.HasPrefix(out[i], "--") {
+			continue
+		}
+		name := out[i][2:]
+		if eq := strings.Index(name, "="); eq != -1 {
+			if secretOption(name[:eq]) {
+				out[i] = "--" + name[:eq+1] + redacted
+			}
+			continue
+		}
+		if secretOption(name) && i+1 < len(out) {
+			i++
+			out[i] = redacted
+		}
+	}
+	return out
+}
+
+// splitShellLine splits a command line into arguments separated by spaces.
+// Single and double quotes group characters, and a backslash escapes the
+// next character outside single quotes.
+func splitShellLine(line string) ([]string, error) {
+	var args []string
+	var arg bytes.Buffer
+	inArg, escaped := false, false
+	var quote rune
+	for _, r := range line {
+		switch {
+		case escaped:
+			arg.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inArg = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, errors.New("unterminated quote or escape")
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
+
+// joinShellArgs joins arguments into a command line, quoting the arguments
+// which splitShellLine would otherwise split or change.
+func joinShellArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "" || strings.IndexFunc(arg, func(r rune) bool {
+			return unicode.IsSpace(r) || strings.ContainsRune(`'"\`, r)
+		}) != -1 {
+			arg = strconv.Quote(arg)
+		}
+		quoted[i] = arg
+	}
+	return strings.Join(quoted, " ")
+}
+
+// complete is the completion callback of the terminal.  The word before the
+// cursor is completed with the names of commands, their long options or the
+// wallet UUIDs of the tenant, depending on where it is in the line.  Every
+// match is listed when there is more than one.
+func (sh *shell) complete(line string, pos int, key rune) (string, int, bool) {
+	if key != '\t' {
+		return "", 0, false
+	}
+	head := line[:pos]
+	words := strings.Fields(head)
+	var partial string
+	if len(words) != 0 && !unicode.IsSpace(rune(head[len(head)-1])) {
+		partial = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+
+	var matches []string
+	for _, c := range sh.candidates(words, partial) {
+		if strings.HasPrefix(c, partial) {
+			matches = append(matches, c)
+		}
+	}
+	if len(matches) == 0 {
+		return "", 0, false
+	}
+	sort.Strings(matches)
+
+	completion := matches[0]
+	for _, m := range matches[1:] {
+		for !strings.HasPrefix(m, completion) {
+			completion = completion[:len(completion)-1]
+		}
+	}
+	switch {
+	case len(matches) == 1:
+		completion += " "
+	case completion == partial:
+		sh.printf("%s\n", strings.Join(matches, "  "))
+		return "", 0, false
+	}
+	start := len(head) - len(partial)
+	return head[:start] + completion + line[pos:],
+		start + len(completion), true
+}
+
+// candidates returns the possible completions of the word following words.
+func (sh *shell) candidates(words []string, partial string) []string {
+	if len(words) != 0 && words[0] == "use" {
+		return sh.walletUUIDs()
+	}
+
+	cmd := sh.parser.Command
+	positional := false
+	for _, w := range words {
+		if strings.HasPrefix(w, "-") {
+			continue
+		}
+		sub := cmd.Find(w)
+		if sub == nil {
+			positional = true
+			break
+		}
+		cmd = sub
+	}
+
+	if strings.HasPrefix(partial, "-") {
+		var names []string
+		for _, opt := range cmd.Options() {
+			if opt.LongName != "" {
+				names = append(names, "--"+opt.LongName)
+			}
+		}
+		return names
+	}
+	if !positional && len(cmd.Commands()) != 0 {
+		var names []string
+		for _, c := range cmd.Commands() {
+			if c.Name != "shell" {
+				names = append(names, c.Name)
+			}
+		}
+		if cmd == sh.parser.Command {
+			names = append(names, shellBuiltins...)
+		}
+		return names
+	}
+	return sh.walletUUIDs()
+}
+
+// walletUUIDs returns the UUIDs of the wallets of the tenant, which are listed
+// again once the cached ones are too old.  Wallets which cannot be listed are
+// not completed.
+func (sh *shell) walletUUIDs() []string {
+	if time.Since(sh.walletsListed) < walletCacheTimeout {
+		return sh.wallets
+	}
+
+	ctx, cancel := context.WithTimeout(requestContext(), completionTimeout)
+	defer cancel()
+	resp, err := sh.client.ListWallets(ctx, &pb.ListWalletsRequest{})
+	if err != nil {
+		return nil
+	}
+	sh.wallets = make([]string, 0, len(resp.Wallets))
+	for _, w := range resp.Wallets {
+		sh.wallets = append(sh.wallets, w.Uuid)
+	}
+	sh.walletsListed = time.Now()
+	return sh.wallets
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/btcsuite/btcutil"
+	pb "github.com/tuxcanfly/wltd/rpc/walletdrpc"
+)
+
+type txCommand struct {
+	Send txSendCommand `command:"send" description:"Send a transaction from a wallet"`
+	List txListCommand `command:"list" description:"List the most recent transactions of a wallet"`
+}
+
+type txSendCommand struct {
+	Account uint32 `long:"account" description:"Account paying the transaction"`
+	MinConf int32  `long:"minconf" default:"1" description:"Minimum number of confirmations of the spent outputs"`
+	Args    struct {
+		UUID    string   `positional-arg-name:"uuid"`
+		Outputs []string `positional-arg-name:"address=amount" description:"Output paying an amount in BTC to an address -- may be repeated"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+// parseOutput parses an output of the form address=amount, where the amount
+// is in BTC.
+func parseOutput(s string) (*pb.TransactionOutput, error) {
+	eq := strings.LastIndex(s, "=")
+	if eq == -1 {
+		return nil, fmt.Errorf("output '%s' is not of the form "+
+			"address=amount", s)
+	}
+	f, err := strconv.ParseFloat(s[eq+1:], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid amount in output '%s'", s)
+	}
+	amount, err := btcutil.NewAmount(f)
+	if err != nil || amount <= 0 {
+		return nil, fmt.Errorf("invalid amount in output '%s'", s)
+	}
+	return &pb.TransactionOutput{Address: s[:eq], Amount: int64(amount)}, nil
+}
+
+type sendResult struct {
+	Txid string `json:"txid"`
+}
+
+func (cmd *txSendCommand) Execute(args []string) error {
+	if len(cmd.Args.Outputs) == 0 {
+		return fmt.Errorf("no outputs specified")
+	}
+	outputs := make([]*pb.TransactionOutput, 0, len(cmd.Args.Outputs))
+	for _, s := range cmd.Args.Outputs {
+		o, err := parseOutput(s)
+		if err != nil {
+			return err
+		}
+		outputs = append(outputs, o)
+	}
+
+	c, err := walletDaemonClient()
+	if err != nil {
+		return err
+	}
+	pass, err := readPassphrase(false)
+	if err != nil {
+		return err
+	}
+	resp, err := c.SendTransaction(requestContext(),
+		&pb.SendTransactionRequest{
+			WalletUuid:            cmd.Args.UUID,
+			Passphrase:            pass,
+			Outputs:               outputs,
+			Account:               cmd.Account,
+			RequiredConfirmations: cmd.MinConf,
+		})
+	if err != nil {
+		return err
+	}
+	result := sendResult{Txid: resp.Txid}
+	return printResult(result, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "Transaction id: %s\n", result.Txid)
+		return err
+	})
+}
+
+type txListCommand struct {
+	From  uint32     `long:"from" description:"Number of most recent transactions to skip"`
+	Count uint32     `long:"count" default:"10" description:"Maximum number of transactions to list"`
+	Args  walletArgs `positional-args:"yes" required:"yes"`
+}
+
+// transactionResult describes a transaction output of a wallet.  Amounts are
+// in satoshis and times are unix times in seconds.
+type transactionResult struct {
+	Txid          string `json:"txid"`
+	Vout          uint32 `json:"vout"`
+	Address       string `json:"address"`
+	Category      string `json:"category"`
+	Amount        int64  `json:"amount"`
+	Fee           int64  `json:"fee"`
+	Confirmations int64  `json:"confirmations"`
+	BlockHash     string `json:"block_hash"`
+	Time          int64  `json:"time"`
+}
+
+type transactionsResult struct {
+	Transactions []transactionResult `json:"transactions"`
+}
+
+func (cmd *txListCommand) Execute(args []string) error {
+	c, err := walletDaemonClient()
+	if err != nil {
+		return err
+	}
+	resp, err := c.ListTransactions(requestContext(),
+		&pb.ListTransactionsRequest{
+			WalletUuid: cmd.Args.UUID,
+			From:       cmd.From,
+			Count:      cmd.Count,
+		})
+	if err != nil {
+		return err
+	}
+	result := transactionsResult{
+		Transactions: make([]transactionResult, 0, len(resp.Transactions)),
+	}
+	for _, tx := range resp.Transactions {
+		result.Transactions = append(result.Transactions,
+			transactionResult{
+				Txid:          tx.Txid,
+				Vout:          tx.Vout,
+				Address:       tx.Address,
+				Category:      tx.Category,
+				Amount:        tx.Amount,
+				Fee:           tx.Fee,
+				Confirmations: tx.Confirmations,
+				BlockHash:     tx.BlockHash,
+				Time:          tx.Timestamp,
+			})
+	}
+	return printResult(result, func(w io.Writer) error {
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(tw, "TXID\tVOUT\tCATEGORY\tAMOUNT\tCONFIRMATIONS\tTIME")
+		for _, tx := range result.Transactions {
+			fmt.Fprintf(tw, "%s\t%d\t%s\t%v\t%d\t%s\n", tx.Txid,
+				tx.Vout, tx.Category, btcutil.Amount(tx.Amount),
+				tx.Confirmations, formatUnix(tx.Time))
+		}
+		return tw.Flush()
+	})
+}
@@ -0,0 +1,650 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/btcsuite/btcutil"
+	pb "github.com/tuxcanfly/wltd/rpc/walletdrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// walletArgs are the positional arguments of commands operating on a wallet.
+type walletArgs struct {
+	UUID string `positional-arg-name:"uuid"`
+}
+
+type walletCommand struct {
+	Create   walletCreateCommand   `command:"create" description:"Create a new wallet"`
+	Open     walletOpenCommand     `command:"open" description:"Open a wallet"`
+	List     walletListCommand     `command:"list" description:"List the wallets of the tenant"`
+	Info     walletInfoCommand     `command:"info" description:"Describe a wallet"`
+	Update   walletUpdateCommand   `command:"update" description:"Change the display name, tags and metadata of a wallet"`
+	Delete   walletDeleteCommand   `command:"delete" description:"Close and delete a wallet"`
+	Autoload walletAutoloadCommand `command:"autoload" description:"Set whether a wallet is opened when wltd starts"`
+	Balance  walletBalanceCommand  `command:"balance" description:"Show the balance of a wallet"`
+	Events   walletEventsCommand   `command:"events" description:"Stream the events of the wallets of the tenant until interrupted"`
+}
+
+// walletIDResult is the result of commands operating on a single wallet.
+type walletIDResult struct {
+	UUID string `json:"uuid"`
+}
+
+// walletResult describes a wallet.  Times are unix times in seconds, which
+// are zero when unknown.
+type walletResult struct {
+	UUID     string `json:"uuid"`
+	Network  string `json:"network"`
+	Tenant   string `json:"tenant"`
+	Created  int64  `json:"created"`
+	LastUsed int64  `json:"last_used"`
+	Open     bool   `json:"open"`
+	Autoload bool   `json:"autoload"`
+
+	Name     string            `json:"name"`
+	Tags     []string          `json:"tags"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+func newWalletResult(w *pb.WalletInfo) walletResult {
+	metadata := w.Metadata
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	return walletResult{
+		UUID:     w.Uuid,
+		Network:  w.Network,
+		Tenant:   w.Tenant,
+		Created:  w.Created,
+		LastUsed: w.LastUsed,
+		Open:     w.Open,
+		Autoload: w.Autoload,
+		Name:     w.Name,
+		Tags:     stringList(w.Tags),
+		Metadata: metadata,
+	}
+}
+
+// writeWalletResult writes the description of a wallet in the table format.
+func writeWalletResult(w io.Writer, result *walletResult) error {
+	fmt.Fprintf(w, "Wallet id: %s\n", result.UUID)
+	if result.Name != "" {
+		fmt.Fprintf(w, "Name: %s\n", result.Name)
+	}
+	fmt.Fprintf(w, "Network: %s\n", result.Network)
+	if result.Tenant != "" {
+		fmt.Fprintf(w, "Tenant: %s\n", result.Tenant)
+	}
+	fmt.Fprintf(w, "Created: %s\n", formatUnix(result.Created))
+	fmt.Fprintf(w, "Last used: %s\n", formatUnix(result.LastUsed))
+	fmt.Fprintf(w, "Open: %v\n", result.Open)
+	fmt.Fprintf(w, "Autoload: %v\n", result.Autoload)
+	if len(result.Tags) != 0 {
+		fmt.Fprintf(w, "Tags: %s\n", strings.Join(result.Tags, ", "))
+	}
+	if len(result.Metadata) != 0 {
+		fmt.Fprintln(w, "Metadata:")
+		keys := make([]string, 0, len(result.Metadata))
+		for k := range result.Metadata {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(w, "  %s: %s\n", k, result.Metadata[k])
+		}
+	}
+	return nil
+}
+
+// errInvalidMetadata is returned for metadata options which are not of the
+// form key=value.
+var errInvalidMetadata = errors.New("metadata must be given as key=value")
+
+// parseMetadata parses metadata options of the form key=value.
+func parseMetadata(opts []string) (map[string]string, error) {
+	if len(opts) == 0 {
+		return nil, nil
+	}
+	metadata := make(map[string]string, len(opts))
+	for _, opt := range opts {
+		i := strings.IndexByte(opt, '=')
+		if i <= 0 {
+			return nil, errInvalidMetadata
+		}
+		metadata[opt[:i]] = opt[i+1:]
+	}
+	return metadata, nil
+}
+
+type walletCreateCommand struct {
+	Network string `short:"n" long:"network" description:"Network of the wallet, such as testnet3 (default the selected network)"`
+	Count   int    `long:"count" default:"1" description:"Number of wallets to create, which all use the same passphrase"`
+	Out     string `short:"o" long:"out" description:"File to write the UUIDs of the created wallets to, one per line"`
+}
+
+// errInvalidCount is returned when wallet create is asked for no wallets.
+var errInvalidCount = errors.New("the wallet count must be positive")
+
+func (cmd *walletCreateCommand) Execute(args []string) error {
+	if cmd.Count < 1 {
+		return errInvalidCount
+	}
+	c, err := walletDaemonClient()
+	if err != nil {
+		return err
+	}
+	pass, err := readPassphrase(true)
+	if err != nil {
+		return err
+	}
+	network := cmd.Network
+	if network == "" {
+		network = cfg.activeNet.Name
+	}
+	if cmd.Count > 1 || cmd.Out != "" {
+		return cmd.createWallets(c, network, pass)
+	}
+	resp, err := c.CreateWallet(requestContext(), &pb.CreateWalletRequest{
+		Pass:    string(pass),
+		Network: network,
+	})
+	if err != nil {
+		return err
+	}
+	return printResult(walletIDResult{UUID: resp.Uuid}, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "Wallet id: %v\n", resp.Uuid)
+		return err
+	})
+}
+
+// createdWalletResult is the outcome of creating one of the wallets of a
+// CreateWallets request.  Index is the position of the wallet in the request.
+type createdWalletResult struct {
+	Index int    `json:"index"`
+	UUID  string `json:"uuid,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+type createWalletsResult struct {
+	Network string                `json:"network"`
+	Created int                   `json:"created"`
+	Failed  int                   `json:"failed"`
+	File    string                `json:"file,omitempty"`
+	Wallets []createdWalletResult `json:"wallets"`
+}
+
+// createWallets creates the wallets of the command with a single
+// CreateWallets request.  The UUIDs of the created wallets are written to the
+// output file even when some wallets could not be created, in which case the
+// status code of the first failure is returned.
+func (cmd *walletCreateCommand) createWallets(c pb.WalletDaemonServiceClient,
+	network string, pass []byte) error {
+
+	passphrases := make([][]byte, cmd.Count)
+	for i := range passphrases {
+		passphrases[i] = pass
+	}
+	stream, err := c.CreateWallets(requestContext(),
+		&pb.CreateWalletsRequest{
+			Network:     network,
+			Passphrases: passphrases,
+		})
+	if err != nil {
+		return err
+	}
+
+	result := createWalletsResult{Network: network}
+	var failure error
+	for {
+		var resp *pb.CreateWalletsResponse
+		resp, err = stream.Recv()
+		if err != nil {
+			break
+		}
+		result.Wallets = append(result.Wallets, createdWalletResult{
+			Index: int(resp.Index),
+			UUID:  resp.Uuid,
+			Error: resp.Error,
+		})
+		if resp.ErrorCode == 0 {
+			result.Created++
+			continue
+		}
+		result.Failed++
+		if failure == nil {
+			failure = grpc.Errorf(codes.Code(resp.ErrorCode),
+				"%s", resp.Error)
+		}
+	}
+	if err == io.EOF {
+		err = nil
+	}
+	sort.Sort(createdWalletsByIndex(result.Wallets))
+
+	// The wallets created before the stream failed are still written, so
+	// that they are not lost.
+	if cmd.Out != "" && (err == nil || result.Created != 0) {
+		result.File = cleanAndExpandPath(cmd.Out)
+		uuids := make([]string, 0, result.Created)
+		for _, r := range result.Wallets {
+			if r.UUID != "" {
+				uuids = append(uuids, r.UUID)
+			}
+		}
+		if writeErr := writeLines(result.File, uuids); writeErr != nil {
+			return writeErr
+		}
+	}
+	printErr := printResult(result, func(w io.Writer) error {
+		for _, r := range result.Wallets {
+			if r.Error != "" {
+				fmt.Fprintf(w, "Wallet %d: %s\n", r.Index, r.Error)
+			} else if cmd.Out == "" {
+				fmt.Fprintln(w, r.UUID)
+			}
+		}
+		_, err := fmt.Fprintf(w, "Created %d of %d wallets\n",
+			result.Created, cmd.Count)
+		if err == nil && result.File != "" {
+			_, err = fmt.Fprintf(w, "Wrote wallet ids to %s\n",
+				result.File)
+		}
+		return err
+	})
+	switch {
+	case err != nil:
+		return err
+	case printErr != nil:
+		return printErr
+	case failure != nil:
+		return grpc.Errorf(grpc.Code(failure), "%d of %d wallets could "+
+			"not be created: %s", result.Failed, cmd.Count,
+			grpc.ErrorDesc(failure))
+	}
+	return nil
+}
+
+// createdWalletsByIndex sorts created wallets in request order.
+type createdWalletsByIndex []createdWalletResult
+
+func (s createdWalletsByIndex) Len() int           { return len(s) }
+func (s createdWalletsByIndex) Less(i, j int) bool { return s[i].Index < s[j].Index }
+func (s createdWalletsByIndex) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// writeLines writes lines to the file named name, replacing it.  The file is
+// written under a temporary name first, so that an interrupted write does
+// not leave a truncated file.
+func writeLines(name string, lines []string) error {
+	tmp := name + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for _, line := range lines {
+		w.WriteString(line)
+		w.WriteByte('\n')
+	}
+	err = w.Flush()
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, name)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
+
+type walletOpenCommand struct {
+	Args walletArgs `positional-args:"yes" required:"yes"`
+}
+
+func (cmd *walletOpenCommand) Execute(args []string) error {
+	c, err := walletDaemonClient()
+	if err != nil {
+		return err
+	}
+	_, err = c.OpenWallet(requestContext(),
+		&pb.OpenWalletRequest{WalletUuid: cmd.Args.UUID})
+	if err != nil {
+		return err
+	}
+	result := walletIDResult{UUID: cmd.Args.UUID}
+	return printResult(result, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "Opened wallet %s\n", result.UUID)
+		return err
+	})
+}
+
+type walletListCommand struct {
+	Tags     []string `long:"tag" description:"Only list wallets with this tag -- may be repeated"`
+	Metadata []string `long:"meta" description:"Only list wallets with this metadata entry, given as key=value -- may be repeated"`
+}
+
+type walletsResult struct {
+	Wallets []walletResult `json:"wallets"`
+}
+
+func (cmd *walletListCommand) Execute(args []string) error {
+	metadata, err := parseMetadata(cmd.Metadata)
+	if err != nil {
+		return err
+	}
+	c, err := walletDaemonClient()
+	if err != nil {
+		return err
+	}
+	resp, err := c.ListWallets(requestContext(), &pb.ListWalletsRequest{
+		Tags:     cmd.Tags,
+		Metadata: metadata,
+	})
+	if err != nil {
+		return err
+	}
+	result := walletsResult{
+		Wallets: make([]walletResult, 0, len(resp.Wallets)),
+	}
+	for _, w := range resp.Wallets {
+		result.Wallets = append(result.Wallets, newWalletResult(w))
+	}
+	return printResult(result, func(w io.Writer) error {
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(tw, "UUID\tNAME\tNETWORK\tOPEN\tAUTOLOAD\tLAST USED\tTAGS")
+		for _, wlt := range result.Wallets {
+			name := wlt.Name
+			if name == "" {
+				name = "-"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%v\t%v\t%s\t%s\n", wlt.UUID,
+				name, wlt.Network, wlt.Open, wlt.Autoload,
+				formatUnix(wlt.LastUsed),
+				strings.Join(wlt.Tags, ","))
+		}
+		return tw.Flush()
+	})
+}
+
+type walletInfoCommand struct {
+	Args walletArgs `positional-args:"yes" required:"yes"`
+}
+
+func (cmd *walletInfoCommand) Execute(args []string) error {
+	c, err := walletDaemonClient()
+	if err != nil {
+		return err
+	}
+	resp, err := c.GetWalletInfo(requestContext(),
+		&pb.GetWalletInfoRequest{WalletUuid: cmd.Args.UUID})
+	if err != nil {
+		return err
+	}
+	result := newWalletResult(resp.Wallet)
+	return printResult(result, func(w io.Writer) error {
+		return writeWalletResult(w, &result)
+	})
+}
+
+type walletUpdateCommand struct {
+	Name           string     `long:"name" description:"Set the display name of the wallet"`
+	ClearName      bool       `long:"clear-name" description:"Remove the display name of the wallet"`
+	AddTags        []string   `long:"tag" description:"Add a tag to the wallet -- may be repeated"`
+	RemoveTags     []string   `long:"untag" description:"Remove a tag from the wallet -- may be repeated"`
+	SetMetadata    []string   `long:"meta" description:"Set a metadata entry of the wallet, given as key=value -- may be repeated"`
+	DeleteMetadata []string   `long:"unmeta" description:"Remove the metadata entry of this key from the wallet -- may be repeated"`
+	Args           walletArgs `positional-args:"yes" required:"yes"`
+}
+
+// errNoMetadataUpdate is returned when wallet update is not given any change.
+var errNoMetadataUpdate = errors.New("nothing to update, see the options " +
+	"of wallet update")
+
+// errNameConflict is returned when wallet update is asked to both set and
+// remove the display name.
+var errNameConflict = errors.New("the name and clear-name options may not " +
+	"be used together")
+
+func (cmd *walletUpdateCommand) Execute(args []string) error {
+	if cmd.Name != "" && cmd.ClearName {
+		return errNameConflict
+	}
+	setMetadata, err := parseMetadata(cmd.SetMetadata)
+	if err != nil {
+		return err
+	}
+	req := &pb.UpdateWalletMetadataRequest{
+		WalletUuid:     cmd.Args.UUID,
+		SetName:        cmd.Name != "" || cmd.ClearName,
+		Name:           cmd.Name,
+		AddTags:        cmd.AddTags,
+		RemoveTags:     cmd.RemoveTags,
+		SetMetadata:    setMetadata,
+		DeleteMetadata: cmd.DeleteMetadata,
+	}
+	if !req.SetName && len(req.AddTags) == 0 && len(req.RemoveTags) == 0 &&
+		len(req.SetMetadata) == 0 && len(req.DeleteMetadata) == 0 {
+		return errNoMetadataUpdate
+	}
+
+	c, err := walletDaemonClient()
+	if err != nil {
+		return err
+	}
+	resp, err := c.UpdateWalletMetadata(requestContext(), req)
+	if err != nil {
+		return err
+	}
+	result := newWalletResult(resp.Wallet)
+	return printResult(result, func(w io.Writer) error {
+		return writeWalletResult(w, &result)
+	})
+}
+
+type walletDeleteCommand struct {
+	Yes  bool       `short:"y" long:"yes" description:"Confirm deleting the wallet and every file of it"`
+	Args walletArgs `positional-args:"yes" required:"yes"`
+}
+
+func (cmd *walletDeleteCommand) Execute(args []string) error {
+	if !cmd.Yes {
+		return errors.New("deleting a wallet cannot be undone, " +
+			"confirm with --yes")
+	}
+	c, err := walletDaemonClient()
+	if err != nil {
+		return err
+	}
+	_, err = c.DeleteWallet(requestContext(),
+		&pb.DeleteWalletRequest{WalletUuid: cmd.Args.UUID})
+	if err != nil {
+		return err
+	}
+	result := walletIDResult{UUID: cmd.Args.UUID}
+	return printResult(result, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "Deleted wallet %s\n", result.UUID)
+		return err
+	})
+}
+
+type walletAutoloadCommand struct {
+	Args struct {
+		UUID  string `positional-arg-name:"uuid"`
+		State string `positional-arg-name:"on|off"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+type autoloadResult struct {
+	UUID     string `json:"uuid"`
+	Autoload bool   `json:"autoload"`
+}
+
+func (cmd *walletAutoloadCommand) Execute(args []string) error {
+	var autoload bool
+	switch cmd.Args.State {
+	case "on":
+		autoload = true
+	case "off":
+	default:
+		return fmt.Errorf("invalid autoload state '%s' -- expected on "+
+			"or off", cmd.Args.State)
+	}
+	c, err := walletDaemonClient()
+	if err != nil {
+		return err
+	}
+	_, err = c.SetWalletAutoload(requestContext(),
+		&pb.SetWalletAutoloadRequest{
+			WalletUuid: cmd.Args.UUID,
+			Autoload:   autoload,
+		})
+	if err != nil {
+		return err
+	}
+	result := autoloadResult{UUID: cmd.Args.UUID, Autoload: autoload}
+	return printResult(result, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "Autoload of wallet %s: %s\n",
+			result.UUID, cmd.Args.State)
+		return err
+	})
+}
+
+type walletBalanceCommand struct {
+	MinConf int32      `long:"minconf" default:"1" description:"Minimum number of confirmations of the counted outputs"`
+	Args    walletArgs `positional-args:"yes" required:"yes"`
+}
+
+// balanceResult is the balance of a wallet in satoshis.
+type balanceResult struct {
+	UUID    string `json:"uuid"`
+	Balance int64  `json:"balance"`
+}
+
+func (cmd *walletBalanceCommand) Execute(args []string) error {
+	c, err := walletDaemonClient()
+	if err != nil {
+		return err
+	}
+	resp, err := c.Balance(requestContext(), &pb.BalanceRequest{
+		WalletUuid:            cmd.Args.UUID,
+		RequiredConfirmations: cmd.MinConf,
+	})
+	if err != nil {
+		return err
+	}
+	result := balanceResult{UUID: cmd.Args.UUID, Balance: resp.Balance}
+	return printResult(result, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "Balance: %v\n",
+			btcutil.Amount(result.Balance))
+		return err
+	})
+}
+
+type walletEventsCommand struct {
+	Args struct {
+		UUID string `positional-arg-name:"uuid" description:"Only stream the events of this wallet and the chain events of its network"`
+	} `positional-args:"yes"`
+}
+
+// eventResult is a streamed wallet or chain event.  Events are written as they
+// arrive, one JSON object per line or one YAML document each.
+type eventResult struct {
+	Kind    string `json:"kind"`
+	Time    int64  `json:"time"`
+	UUID    string `json:"uuid"`
+	Network string `json:"network"`
+	Hash    string `json:"hash"`
+	Height  int32  `json:"height"`
+	Dropped uint64 `json:"dropped"`
+}
+
+func (cmd *walletEventsCommand) Execute(args []string) error {
+	if cfg.Batch || inShell {
+		return errEndlessCommand
+	}
+	c, err := walletDaemonClient()
+	if err != nil {
+		return err
+	}
+	stream, err := c.WalletEvents(requestContext(),
+		&pb.WalletEventsRequest{WalletUuid: cmd.Args.UUID})
+	if err != nil {
+		return err
+	}
+	for {
+		e, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		result := eventResult{
+			Kind:    e.Kind,
+			Time:    e.Timestamp,
+			UUID:    e.WalletUuid,
+			Network: e.Network,
+			Hash:    e.Hash,
+			Height:  e.Height,
+			Dropped: e.Dropped,
+		}
+		switch cfg.Output {
+		case outputJSON:
+			err = json.NewEncoder(os.Stdout).Encode(result)
+		case outputYAML:
+			fmt.Println("---")
+			err = writeYAML(os.Stdout, result)
+		default:
+			_, err = fmt.Println(formatEvent(e))
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// formatEvent formats a wallet or chain event as a line of text.
+func formatEvent(e *pb.WalletEventsResponse) string {
+	var s string
+	switch e.Kind {
+	case "block_connected", "block_disconnected":
+		s = fmt.Sprintf("%s block %s at height %d (%s)",
+			strings.TrimPrefix(e.Kind, "block_"), e.Hash, e.Height,
+			e.Network)
+	case "transaction_sent":
+		s = fmt.Sprintf("wallet %s sent transaction %s", e.WalletUuid,
+			e.Hash)
+	default:
+		s = fmt.Sprintf("wallet %s %s", e.WalletUuid, e.Kind)
+	}
+	if e.Dropped != 0 {
+		s += fmt.Sprintf(" (%d earlier events dropped)", e.Dropped)
+	}
+	return fmt.Sprintf("[%s] %s", time.Unix(e.Timestamp, 0).Format("15:04:05"), s)
+}
+
+// formatUnix formats a unix time in seconds, which is zero when unknown.
+func formatUnix(t int64) string {
+	if t == 0 {
+		return "-"
+	}
+	return time.Unix(t, 0).Format(time.RFC3339)
+}
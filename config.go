@@ -13,17 +13,29 @@ import (
 	"runtime"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcwallet/wallet"
 	flags "github.com/jessevdk/go-flags"
+	"github.com/tuxcanfly/wltd/walletd"
 )
 
 const (
-	defaultConfigFilename = "wltd.conf"
-	defaultLogLevel       = "info"
-	defaultLogDirname     = "logs"
-	defaultLogFilename    = "wltd.log"
+	defaultConfigFilename    = "wltd.conf"
+	defaultLogLevel          = "info"
+	defaultLogDirname        = "logs"
+	defaultLogFilename       = "wltd.log"
+	defaultLogFormat         = logFormatText
+	defaultLogMaxSize        = 10 // MB
+	defaultLogMaxRolls       = 3
+	defaultMaxExpensiveOps   = 4
+	defaultBackupSchedule    = "@daily"
+	defaultBackupRetention   = 7
+	defaultShutdownTimeout   = 30 * time.Second
+	defaultWalletIdleTimeout = time.Hour
+	defaultCertValidity      = 10 * 365 * 24 * time.Hour
 
 	walletdDbName = "walletd.db"
 )
@@ -33,27 +45,82 @@ var (
 	defaultConfigFile  = filepath.Join(defaultAppDataDir, defaultConfigFilename)
 	defaultRPCKeyFile  = filepath.Join(defaultAppDataDir, "rpc.key")
 	defaultRPCCertFile = filepath.Join(defaultAppDataDir, "rpc.cert")
+	defaultBackupKey   = filepath.Join(defaultAppDataDir, "backup.key")
+	defaultAuthFile    = filepath.Join(defaultAppDataDir, "auth")
 	defaultLogDir      = filepath.Join(defaultAppDataDir, defaultLogDirname)
+	defaultCAFile      = filepath.Join(btcutil.AppDataDir("btcd", false), "rpc.cert")
 )
 
 type config struct {
 	// General application behavior
-	ConfigFile    string `short:"C" long:"configfile" description:"Path to configuration file"`
-	ShowVersion   bool   `short:"V" long:"version" description:"Display version information and exit"`
-	AppDataDir    string `short:"A" long:"appdata" description:"Application data directory for wallet config, databases and logs"`
-	TestNet3      bool   `long:"testnet" description:"Use the test Bitcoin network (version 3) (default mainnet)"`
-	SimNet        bool   `long:"simnet" description:"Use the simulation test network (default mainnet)"`
-	NoInitialLoad bool   `long:"noinitialload" description:"Defer wallet creation/opening on startup and enable loading wallets over RPC"`
-	DebugLevel    string `short:"d" long:"debuglevel" description:"Logging level {trace, debug, info, warn, error, critical}"`
-	LogDir        string `long:"logdir" description:"Directory to log output."`
-	Profile       string `long:"profile" description:"Enable HTTP profiling on given port -- NOTE port must be between 1024 and 65536"`
+	ConfigFile        string        `short:"C" long:"configfile" description:"Path to configuration file"`
+	ShowVersion       bool          `short:"V" long:"version" description:"Display version information and exit"`
+	Check             bool          `long:"check" description:"Check the registry and every wallet for corruption, print a JSON report and exit"`
+	MigrateDryRun     bool          `long:"migrate-dry-run" description:"Print the registry migrations which would be applied at startup and exit"`
+	AppDataDir        string        `short:"A" long:"appdata" description:"Application data directory for wallet config, databases and logs"`
+	DBDriver          string        `long:"dbdriver" description:"Database driver of the registry and wallets {bdb, memdb} -- memdb keeps everything in memory and is only meant for testing"`
+	TestNet3          bool          `long:"testnet" description:"Use the test Bitcoin network (version 3) (default mainnet)"`
+	SimNet            bool          `long:"simnet" description:"Use the simulation test network (default mainnet)"`
+	RegTest           bool          `long:"regtest" description:"Use the regression test network (default mainnet)"`
+	ExtraNets         []string      `long:"servenet" description:"Also serve wallets on this network {mainnet, testnet3, regtest, simnet} -- may be repeated"`
+	NoInitialLoad     bool          `long:"noinitialload" description:"Do not open the wallets marked for autoloading on startup, such as for maintenance"`
+	CreateWorkers     int           `long:"createworkers" description:"Number of wallets created concurrently by a CreateWallets request, and of autoload wallets opened concurrently on startup (0 for the number of CPUs)"`
+	WalletPass        string        `long:"walletpass" default-mask:"-" description:"The public wallet passphrase wallets are created and opened with -- Only required if not the default"`
+	DebugLevel        string        `short:"d" long:"debuglevel" description:"Logging level {trace, debug, info, warn, error, critical}"`
+	LogDir            string        `long:"logdir" description:"Directory to log output."`
+	LogMaxSize        int64         `long:"logmaxsize" description:"Roll the log file over once it exceeds this size in MB (0 to disable)"`
+	LogMaxRolls       int           `long:"logmaxrolls" description:"Number of rolled log files to keep (0 to keep all)"`
+	LogRotateInterval time.Duration `long:"logrotateinterval" description:"Roll the log file over once it has been written to for this long (0 to disable)"`
+	LogCompress       bool          `long:"logcompress" description:"Gzip rolled log files in the background"`
+	NoFileLogging     bool          `long:"nofilelogging" description:"Only log to standard output"`
+	Profile           string        `long:"profile" description:"Enable HTTP profiling on given port -- NOTE port must be between 1024 and 65536"`
+	WalletIdleTimeout time.Duration `long:"walletidletimeout" description:"Close open wallets which have not been used for this long (0 to keep wallets open)"`
+	BackupKey         string        `long:"backupkey" description:"File containing the private key wallet backups are encrypted to, generated if missing"`
+	BackupDir         string        `long:"backupdir" description:"Directory to write scheduled backups of the registry and every wallet to (disabled when empty)"`
+	BackupSchedule    string        `long:"backupschedule" description:"Crontab schedule of backups to backupdir, such as \"30 2 * * *\" or @daily"`
+	BackupRetention   int           `long:"backupretention" description:"Number of scheduled backups to keep (0 to keep all)"`
 
 	// RPC server options
-	RPCCert       string   `long:"rpccert" description:"File containing the certificate file"`
-	RPCKey        string   `long:"rpckey" description:"File containing the certificate key"`
-	OneTimeTLSKey bool     `long:"onetimetlskey" description:"Generate a new TLS certpair at startup, but only write the certificate to disk"`
-	DisableTLS    bool     `long:"notls" description:"Disable TLS for the RPC server -- NOTE: This is only allowed if the RPC server is bound to localhost"`
-	RPCListeners  []string `long:"rpclisten" description:"Listen for RPC connections on this interface/port"`
+	RPCCert         string        `long:"rpccert" description:"File containing the certificate file"`
+	RPCKey          string        `long:"rpckey" description:"File containing the certificate key"`
+	AuthFile        string        `long:"authfile" description:"File listing the role, name and SHA-256 token hash of every RPC client, one per line -- requests without a listed token, other than Ping and Version, are refused, and wltd does not start without it"`
+	OneTimeTLSKey   bool          `long:"onetimetlskey" description:"Generate a new TLS certpair at startup, but only write the certificate to disk"`
+	DisableTLS      bool          `long:"notls" description:"Disable TLS for the RPC server -- NOTE: This is only allowed if the RPC server is bound to localhost"`
+	TLSExtraIPs     []string      `long:"tlsextraip" description:"Add an IP address to the autogenerated RPC certificate -- may be repeated"`
+	TLSExtraDomains []string      `long:"tlsextradomain" description:"Add a domain name to the autogenerated RPC certificate -- may be repeated"`
+	CertValidity    time.Duration `long:"certvalidity" description:"How long autogenerated RPC certificates are valid for"`
+	RPCListeners    []string      `long:"rpclisten" description:"Listen for RPC connections on this interface/port"`
+	ShutdownTimeout time.Duration `long:"shutdowntimeout" description:"Time to wait for in-flight RPC requests to finish on shutdown before cancelling them"`
+	RateLimits      []string      `long:"ratelimit" description:"Limit the rate of RPC requests per tenant or admin as [tenant@]method=rate[/burst], in requests per second, where method is a method name such as CreateWallet or * for every method -- may be repeated"`
+	MaxExpensiveOps int           `long:"maxexpensiveops" description:"Maximum number of expensive RPC operations, such as wallet creation, running at once (0 for no limit)"`
+
+	// RPC client options
+	RPCConnect   []string `long:"rpcconnect" description:"btcd RPC server of a served network as [network=]host[:port], where the network defaults to the active one -- may be repeated, and wallets of networks without one do not sync"`
+	CAFile       string   `long:"cafile" description:"File containing root certificates to authenticate TLS connections with btcd"`
+	BtcdUsername string   `long:"btcdusername" description:"Username for btcd authentication"`
+	BtcdPassword string   `long:"btcdpassword" default-mask:"-" description:"Password for btcd authentication"`
+
+	// Networks resolved from the network options.
+	activeNet *chaincfg.Params
+	extraNets []*chaincfg.Params
+
+	// Rate limits parsed from the ratelimit options.
+	rateLimits []rateLimitRule
+
+	// btcd RPC servers parsed from the rpcconnect options, keyed by
+	// network name.
+	chainServers map[string]string
+
+	// Schedule parsed from the backupschedule option.
+	backupSchedule *walletd.Schedule
+}
+
+// tlsExtraHosts returns the extra IP addresses and domain names of
+// autogenerated RPC certificates.
+func tlsExtraHosts(cfg *config) []string {
+	hosts := make([]string, 0, len(cfg.TLSExtraIPs)+len(cfg.TLSExtraDomains))
+	hosts = append(hosts, cfg.TLSExtraIPs...)
+	return append(hosts, cfg.TLSExtraDomains...)
 }
 
 // cleanAndExpandPath expands environement variables and leading ~ in the
@@ -234,7 +301,7 @@ func normalizeAddresses(addrs []string, defaultPort string) []string {
 }
 
 // loadConfig initializes and parses the config using a config file and command
-// line options.
+// line options, and initializes logging and the active network accordingly.
 //
 // The configuration proceeds as follows:
 //      1) Start with a default config with sane settings
@@ -246,14 +313,88 @@ func normalizeAddresses(addrs []string, defaultPort string) []string {
 // settings while still allowing the user to override settings with config files
 // and command line options.  Command line options always take precedence.
 func loadConfig() (*config, []string, error) {
+	cfg, remainingArgs, configFileError, err := parseConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	activeNet = cfg.activeNet
+	extraNets = cfg.extraNets
+
+	// Special show command to list supported subsystems and exit.
+	if cfg.DebugLevel == "show" {
+		fmt.Println("Supported subsystems", supportedSubsystems())
+		os.Exit(0)
+	}
+
+	// Initialize log rotation.  After log rotation has been initialized, the
+	// logger variables may be used.  Without file logging, the loggers only
+	// write to standard output and may be used right away.
+	if !cfg.NoFileLogging {
+		initLogRotator(filepath.Join(cfg.LogDir, defaultLogFilename),
+			cfg.LogMaxSize*1024*1024, cfg.LogMaxRolls,
+			cfg.LogCompress, cfg.LogRotateInterval)
+	}
+	jsonLogging = cfg.LogFormat == logFormatJSON
+
+	// The check report and migration plan are written to standard output,
+	// so log to standard error instead.
+	if cfg.Check || cfg.MigrateDryRun {
+		logConsole = os.Stderr
+	}
+
+	// Parse, validate, and set debug log level(s).
+	if err := parseAndSetDebugLevels(cfg.DebugLevel); err != nil {
+		err := fmt.Errorf("%s: %v", "loadConfig", err.Error())
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage())
+		return nil, nil, err
+	}
+
+	// Warn about missing config file after the final command line parse
+	// succeeds.  This prevents the warning on help messages and invalid
+	// options.
+	if configFileError != nil {
+		log.Warnf("%v", configFileError)
+	}
+
+	return cfg, remainingArgs, nil
+}
+
+// usageMessage returns the message pointing users to the usage help.
+func usageMessage() string {
+	appName := filepath.Base(os.Args[0])
+	appName = strings.TrimSuffix(appName, filepath.Ext(appName))
+	return fmt.Sprintf("Use %s -h to show usage", appName)
+}
+
+// parseConfig parses and validates the config from the config file and
+// command line options as described by loadConfig.  Unlike loadConfig, it does
+// not modify any global state, so it may also be used to reload the
+// configuration of a running process.  A missing config file does not prevent
+// the configuration from being parsed, and is returned as the third result.
+func parseConfig() (*config, []string, error, error) {
 	// Default config.
 	cfg := config{
-		DebugLevel: defaultLogLevel,
-		ConfigFile: defaultConfigFile,
-		AppDataDir: defaultAppDataDir,
-		LogDir:     defaultLogDir,
-		RPCKey:     defaultRPCKeyFile,
-		RPCCert:    defaultRPCCertFile,
+		DebugLevel:        defaultLogLevel,
+		LogFormat:         defaultLogFormat,
+		LogMaxSize:        defaultLogMaxSize,
+		LogMaxRolls:       defaultLogMaxRolls,
+		ConfigFile:        defaultConfigFile,
+		AppDataDir:        defaultAppDataDir,
+		DBDriver:          walletd.DefaultDBDriver,
+		LogDir:            defaultLogDir,
+		RPCKey:            defaultRPCKeyFile,
+		RPCCert:           defaultRPCCertFile,
+		BackupKey:         defaultBackupKey,
+		AuthFile:          defaultAuthFile,
+		BackupSchedule:    defaultBackupSchedule,
+		BackupRetention:   defaultBackupRetention,
+		ShutdownTimeout:   defaultShutdownTimeout,
+		WalletIdleTimeout: defaultWalletIdleTimeout,
+		MaxExpensiveOps:   defaultMaxExpensiveOps,
+		CertValidity:      defaultCertValidity,
+		CAFile:            defaultCAFile,
+		WalletPass:        wallet.InsecurePubPassphrase,
 	}
 
 	// Pre-parse the command line options to see if an alternative config
@@ -265,14 +406,14 @@ func loadConfig() (*config, []string, error) {
 		if e, ok := err.(*flags.Error); !ok || e.Type != flags.ErrHelp {
 			preParser.WriteHelp(os.Stderr)
 		}
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	// Show the version and exit if the version flag was specified.
 	funcName := "loadConfig"
 	appName := filepath.Base(os.Args[0])
 	appName = strings.TrimSuffix(appName, filepath.Ext(appName))
-	usageMessage := fmt.Sprintf("Use %s -h to show usage", appName)
+	usage := usageMessage()
 	if preCfg.ShowVersion {
 		fmt.Println(appName, "version", version())
 		os.Exit(0)
@@ -295,7 +436,7 @@ func loadConfig() (*config, []string, error) {
 		if _, ok := err.(*os.PathError); !ok {
 			fmt.Fprintln(os.Stderr, err)
 			parser.WriteHelp(os.Stderr)
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
 		configFileError = err
 	}
@@ -306,7 +447,7 @@ func loadConfig() (*config, []string, error) {
 		if e, ok := err.(*flags.Error); !ok || e.Type != flags.ErrHelp {
 			parser.WriteHelp(os.Stderr)
 		}
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	// If an alternate data directory was specified, and paths with defaults
@@ -320,51 +461,126 @@ func loadConfig() (*config, []string, error) {
 		if cfg.RPCCert == "" {
 			cfg.RPCCert = filepath.Join(cfg.AppDataDir, "rpc.cert")
 		}
+		if cfg.BackupKey == "" {
+			cfg.BackupKey = filepath.Join(cfg.AppDataDir, "backup.key")
+		}
+		if cfg.AuthFile == "" {
+			cfg.AuthFile = filepath.Join(cfg.AppDataDir, "auth")
+		}
 	}
 
 	// Choose the active network params based on the selected network.
 	// Multiple networks can't be selected simultaneously.
 	numNets := 0
+	cfg.activeNet = &chaincfg.MainNetParams
 	if cfg.TestNet3 {
-		activeNet = &chaincfg.TestNet3Params
+		cfg.activeNet = &chaincfg.TestNet3Params
 		numNets++
 	}
 	if cfg.SimNet {
-		activeNet = &chaincfg.SimNetParams
+		cfg.activeNet = &chaincfg.SimNetParams
+		numNets++
+	}
+	if cfg.RegTest {
+		cfg.activeNet = &chaincfg.RegressionNetParams
 		numNets++
 	}
 	if numNets > 1 {
-		str := "%s: The testnet and simnet params can't be used " +
-			"together -- choose one"
+		str := "%s: The testnet, regtest and simnet params can't be " +
+			"used together -- choose one"
 		err := fmt.Errorf(str, "loadConfig")
 		fmt.Fprintln(os.Stderr, err)
 		parser.WriteHelp(os.Stderr)
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
-	// Append the network type to the log directory so it is "namespaced"
-	// per network.
-	cfg.LogDir = cleanAndExpandPath(cfg.LogDir)
-	cfg.LogDir = filepath.Join(cfg.LogDir, activeNet.Name)
+	// Resolve the networks served in addition to the active network.
+	for _, name := range cfg.ExtraNets {
+		params, err := walletd.ParamsForNet(name)
+		if err != nil {
+			str := "%s: unknown network '%s' -- supported networks " +
+				"are mainnet, testnet3, regtest and simnet"
+			err := fmt.Errorf(str, funcName, name)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usage)
+			return nil, nil, nil, err
+		}
+		if params != cfg.activeNet {
+			cfg.extraNets = append(cfg.extraNets, params)
+		}
+	}
 
-	// Special show command to list supported subsystems and exit.
-	if cfg.DebugLevel == "show" {
-		fmt.Println("Supported subsystems", supportedSubsystems())
-		os.Exit(0)
+	// Resolve the btcd RPC server of every network.
+	served := map[string]bool{cfg.activeNet.Name: true}
+	for _, params := range cfg.extraNets {
+		served[params.Name] = true
+	}
+	cfg.chainServers = make(map[string]string)
+	for _, s := range cfg.RPCConnect {
+		net, addr := cfg.activeNet.Name, s
+		if eq := strings.Index(s, "="); eq != -1 {
+			net, addr = s[:eq], s[eq+1:]
+		}
+		if !served[net] {
+			str := "%s: rpcconnect '%s' is not for a served network"
+			err := fmt.Errorf(str, funcName, s)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usage)
+			return nil, nil, nil, err
+		}
+		if _, ok := cfg.chainServers[net]; ok {
+			str := "%s: more than one rpcconnect for network %s"
+			err := fmt.Errorf(str, funcName, net)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usage)
+			return nil, nil, nil, err
+		}
+		cfg.chainServers[net] = normalizeAddress(addr, btcdRPCPorts[net])
 	}
 
-	// Initialize log rotation.  After log rotation has been initialized, the
-	// logger variables may be used.
-	initLogRotator(filepath.Join(cfg.LogDir, defaultLogFilename))
+	// Validate the log rotation policy.
+	if cfg.LogMaxSize < 0 || cfg.LogMaxRolls < 0 || cfg.LogRotateInterval < 0 {
+		str := "%s: the logmaxsize, logmaxrolls and logrotateinterval " +
+			"options may not be negative"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usage)
+		return nil, nil, nil, err
+	}
 
-	// Parse, validate, and set debug log level(s).
-	if err := parseAndSetDebugLevels(cfg.DebugLevel); err != nil {
-		err := fmt.Errorf("%s: %v", "loadConfig", err.Error())
+	// Validate the log format.
+	if cfg.LogFormat != logFormatText && cfg.LogFormat != logFormatJSON {
+		str := "%s: the specified log format [%v] is invalid -- " +
+			"supported formats are text and json"
+		err := fmt.Errorf(str, funcName, cfg.LogFormat)
 		fmt.Fprintln(os.Stderr, err)
-		parser.WriteHelp(os.Stderr)
-		return nil, nil, err
+		fmt.Fprintln(os.Stderr, usage)
+		return nil, nil, nil, err
 	}
 
+	// Validate the database driver.
+	validDriver := false
+	for _, driver := range walletd.DBDrivers() {
+		if cfg.DBDriver == driver {
+			validDriver = true
+			break
+		}
+	}
+	if !validDriver {
+		str := "%s: the specified database driver [%v] is invalid -- " +
+			"supported drivers are %s"
+		err := fmt.Errorf(str, funcName, cfg.DBDriver,
+			strings.Join(walletd.DBDrivers(), ", "))
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usage)
+		return nil, nil, nil, err
+	}
+
+	// Append the network type to the log directory so it is "namespaced"
+	// per network.
+	cfg.LogDir = cleanAndExpandPath(cfg.LogDir)
+	cfg.LogDir = filepath.Join(cfg.LogDir, cfg.activeNet.Name)
+
 	localhostListeners := map[string]struct{}{
 		"localhost": {},
 		"127.0.0.1": {},
@@ -381,17 +597,17 @@ func loadConfig() (*config, []string, error) {
 	if len(cfg.RPCListeners) == 0 {
 		addrs, err := net.LookupHost("localhost")
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
 		cfg.RPCListeners = make([]string, 0, len(addrs))
 		for _, addr := range addrs {
-			addr = net.JoinHostPort(addr, defaultPorts[activeNet.Name])
+			addr = net.JoinHostPort(addr, defaultPorts[cfg.activeNet.Name])
 			cfg.RPCListeners = append(cfg.RPCListeners, addr)
 		}
 	}
 
 	cfg.RPCListeners = normalizeAddresses(
-		cfg.RPCListeners, defaultPorts[activeNet.Name])
+		cfg.RPCListeners, defaultPorts[cfg.activeNet.Name])
 
 	// Only allow TLS to be disabled if the RPC server is bound to
 	// localhost addresses.
@@ -403,8 +619,8 @@ func loadConfig() (*config, []string, error) {
 					"invalid: %v"
 				err := fmt.Errorf(str, funcName, addr, err)
 				fmt.Fprintln(os.Stderr, err)
-				fmt.Fprintln(os.Stderr, usageMessage)
-				return nil, nil, err
+				fmt.Fprintln(os.Stderr, usage)
+				return nil, nil, nil, err
 			}
 			if _, ok := localhostListeners[host]; !ok {
 				str := "%s: the --notls option may not be used " +
@@ -412,22 +628,91 @@ func loadConfig() (*config, []string, error) {
 					"addresses: %s"
 				err := fmt.Errorf(str, funcName, addr)
 				fmt.Fprintln(os.Stderr, err)
-				fmt.Fprintln(os.Stderr, usageMessage)
-				return nil, nil, err
+				fmt.Fprintln(os.Stderr, usage)
+				return nil, nil, nil, err
 			}
 		}
 	}
 
+	// Parse the rate limits.
+	for _, s := range cfg.RateLimits {
+		rule, err := parseRateLimit(s)
+		if err != nil {
+			err := fmt.Errorf("%s: %v", funcName, err)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usage)
+			return nil, nil, nil, err
+		}
+		cfg.rateLimits = append(cfg.rateLimits, rule)
+	}
+	if cfg.CreateWorkers < 0 {
+		str := "%s: the createworkers option may not be negative"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usage)
+		return nil, nil, nil, err
+	}
+	if cfg.ShutdownTimeout <= 0 {
+		str := "%s: the shutdowntimeout option must be positive"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usage)
+		return nil, nil, nil, err
+	}
+	if cfg.CertValidity <= 0 {
+		str := "%s: the certvalidity option must be positive"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usage)
+		return nil, nil, nil, err
+	}
+	if cfg.MaxExpensiveOps < 0 {
+		str := "%s: the maxexpensiveops option may not be negative"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usage)
+		return nil, nil, nil, err
+	}
+
+	// Parse the backup schedule.
+	if cfg.BackupDir != "" {
+		cfg.backupSchedule, err = walletd.ParseSchedule(cfg.BackupSchedule)
+		if err != nil {
+			err := fmt.Errorf("%s: %v", funcName, err)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usage)
+			return nil, nil, nil, err
+		}
+	}
+	if cfg.BackupRetention < 0 {
+		str := "%s: the backupretention option may not be negative"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usage)
+		return nil, nil, nil, err
+	}
+
+	// Validate the extra IP addresses of autogenerated certificates.
+	for _, ip := range cfg.TLSExtraIPs {
+		if net.ParseIP(ip) == nil {
+			str := "%s: the --tlsextraip option '%s' is not a " +
+				"valid IP address"
+			err := fmt.Errorf(str, funcName, ip)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usage)
+			return nil, nil, nil, err
+		}
+	}
+
 	// Expand environment variable and leading ~ for filepaths.
 	cfg.RPCCert = cleanAndExpandPath(cfg.RPCCert)
 	cfg.RPCKey = cleanAndExpandPath(cfg.RPCKey)
-
-	// Warn about missing config file after the final command line parse
-	// succeeds.  This prevents the warning on help messages and invalid
-	// options.
-	if configFileError != nil {
-		log.Warnf("%v", configFileError)
+	cfg.BackupKey = cleanAndExpandPath(cfg.BackupKey)
+	cfg.AuthFile = cleanAndExpandPath(cfg.AuthFile)
+	cfg.CAFile = cleanAndExpandPath(cfg.CAFile)
+	if cfg.BackupDir != "" {
+		cfg.BackupDir = cleanAndExpandPath(cfg.BackupDir)
 	}
 
-	return &cfg, remainingArgs, nil
+	return &cfg, remainingArgs, configFileError, nil
 }
@@ -9,19 +9,29 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/btcsuite/btclog"
-	"github.com/jrick/logrotate/rotator"
+	"github.com/btcsuite/btcwallet/chain"
 	"github.com/tuxcanfly/wltd/rpc/rpcserver"
+	"github.com/tuxcanfly/wltd/walletd"
 )
 
-// logWriter implements an io.Writer that outputs to both standard output and
-// the write-end pipe of an initialized log rotator.
+// logConsole is the console output of the log.  It is standard error when
+// standard output is reserved for a report.
+var logConsole io.Writer = os.Stdout
+
+// logWriter implements an io.Writer that outputs to both the console and the
+// log rotator, unless file logging is disabled, and publishes the output to log
+// tail subscribers.
 type logWriter struct{}
 
 func (logWriter) Write(p []byte) (n int, err error) {
-	os.Stdout.Write(p)
-	logRotatorPipe.Write(p)
+	logConsole.Write(p)
+	if logRotator != nil {
+		logRotator.Write(p)
+	}
+	logTails.publish(p)
 	return len(p), nil
 }
 
@@ -39,50 +49,52 @@ var (
 	// or data races and/or nil pointer dereferences will occur.
 	backendLog = btclog.NewBackend(logWriter{})
 
-	// logRotator is one of the logging outputs.  It should be closed on
-	// application shutdown.
-	logRotator *rotator.Rotator
-
-	// logRotatorPipe is the write-end pipe for writing to the log rotator.  It
-	// is written to by the Write method of the logWriter type.
-	logRotatorPipe *io.PipeWriter
+	// logRotator is one of the logging outputs.  It is nil when file
+	// logging is disabled, and should be closed on application shutdown.
+	logRotator *rotatingLogFile
 
-	log     = backendLog.Logger("WLTD")
-	grpcLog = backendLog.Logger("GRPC")
+	log        = newSubsystemLogger("WLTD")
+	grpcLog    = newSubsystemLogger("GRPC")
+	walletdLog = newSubsystemLogger("WDMN")
+	chainLog   = newSubsystemLogger("CHNS")
 )
 
 // Initialize package-global logger variables.
 func init() {
 	rpcserver.UseLogger(grpcLog)
+	walletd.UseLogger(walletdLog)
+	chain.UseLogger(chainLog)
 }
 
 // subsystemLoggers maps each subsystem identifier to its associated logger.
 var subsystemLoggers = map[string]btclog.Logger{
 	"WLTD": log,
 	"GRPC": grpcLog,
+	"WDMN": walletdLog,
+	"CHNS": chainLog,
 }
 
 // initLogRotator initializes the logging rotater to write logs to logFile and
-// create roll files in the same directory.  It must be called before the
-// package-global log rotater variables are used.
-func initLogRotator(logFile string) {
+// create roll files in the same directory according to the passed rotation
+// policy.  It must be called before the package-global log rotater variables
+// are used.
+func initLogRotator(logFile string, maxSize int64, maxRolls int,
+	compress bool, interval time.Duration) {
+
 	logDir, _ := filepath.Split(logFile)
 	err := os.MkdirAll(logDir, 0700)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to create log directory: %v\n", err)
 		os.Exit(1)
 	}
-	pr, pw := io.Pipe()
-	r, err := rotator.New(pr, logFile, 10*1024, false, 3)
+	r, err := newRotatingLogFile(logFile, maxSize, maxRolls, compress,
+		interval)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to create file rotator: %v\n", err)
 		os.Exit(1)
 	}
 
-	go r.Run()
-
 	logRotator = r
-	logRotatorPipe = pw
 }
 
 // setLogLevel sets the logging level for provided subsystem.  Invalid
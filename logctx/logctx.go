@@ -0,0 +1,80 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package logctx carries the logging context of a request, such as its request
+// ID and the wallet it operates on, through a context.Context to the loggers
+// which include it in their output.  It also masks the secret fields of
+// request messages so that requests may be logged.
+package logctx
+
+import (
+	"github.com/btcsuite/btclog"
+	"golang.org/x/net/context"
+)
+
+// Fields describes what a log line belongs to.  Empty fields are omitted from
+// the output.
+type Fields struct {
+	RequestID  string
+	Method     string
+	Tenant     string
+	WalletUUID string
+
+	// Admin is the name of the administrator making the request, which
+	// is made for no tenant.
+	Admin string
+}
+
+// fieldsKey is the context key of the Fields of a context.
+type fieldsKey struct{}
+
+// NewContext returns a copy of ctx carrying the passed fields.
+func NewContext(ctx context.Context, f Fields) context.Context {
+	return context.WithValue(ctx, fieldsKey{}, f)
+}
+
+// FromContext returns the fields carried by ctx, or empty fields if there are
+// none.
+func FromContext(ctx context.Context) Fields {
+	f, _ := ctx.Value(fieldsKey{}).(Fields)
+	return f
+}
+
+// WithWallet returns a copy of ctx whose fields also describe the passed
+// wallet.
+func WithWallet(ctx context.Context, walletUUID string) context.Context {
+	f := FromContext(ctx)
+	f.WalletUUID = walletUUID
+	return NewContext(ctx, f)
+}
+
+// Logger is implemented by loggers which include Fields in their output.
+type Logger interface {
+	btclog.Logger
+
+	// WithFields returns a logger which includes the passed fields in
+	// every line.  The returned logger shares the level of the logger it
+	// was created from.
+	WithFields(f Fields) btclog.Logger
+}
+
+// Log returns a logger which includes the fields carried by ctx, or l itself
+// when it does not implement Logger.
+func Log(ctx context.Context, l btclog.Logger) btclog.Logger {
+	fl, ok := l.(Logger)
+	if !ok {
+		return l
+	}
+	return fl.WithFields(FromContext(ctx))
+}
+
+// WalletLog returns a logger which includes the passed wallet UUID, or l
+// itself when it does not implement Logger.  It is used outside of requests.
+func WalletLog(l btclog.Logger, walletUUID string) btclog.Logger {
+	fl, ok := l.(Logger)
+	if !ok {
+		return l
+	}
+	return fl.WithFields(Fields{WalletUUID: walletUUID})
+}
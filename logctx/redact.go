@@ -0,0 +1,135 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package logctx
+
+import (
+	"reflect"
+)
+
+// Redacted replaces secrets in logged messages.
+const Redacted = "[REDACTED]"
+
+// secretFields are the names of the request message fields which hold
+// secrets.  Handlers must never log these fields, and requests are only logged
+// after masking them with MaskSecrets.
+var secretFields = map[string]struct{}{
+	"Pass":               {},
+	"Passphrase":         {},
+	"Passphrases":        {},
+	"PrivatePassphrase":  {},
+	"PublicPassphrase":   {},
+	"OldPassphrase":      {},
+	"NewPassphrase":      {},
+	"Seed":               {},
+	"Xpriv":              {},
+	"ExtendedPrivateKey": {},
+}
+
+// MaskSecrets returns a copy of a request message, a pointer to a struct, with
+// the non-empty fields listed in secretFields replaced by Redacted, so that it
+// may be logged.  Every element of repeated fields is replaced, and the fields
+// of nested messages are masked too.  The message itself is not modified, and
+// other values are returned as is.
+func MaskSecrets(msg interface{}) interface{} {
+	v := reflect.ValueOf(msg)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return msg
+	}
+	return maskNested(v).Interface()
+}
+
+// maskNested returns a copy of a struct, or of the struct pointed to, with its
+// secret fields and those of its nested structs masked.  Slices and maps of
+// structs are copied with their elements masked.  Values of other types, which
+// are shared with the message, are returned as is.
+func maskNested(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() || v.Elem().Kind() != reflect.Struct {
+			return v
+		}
+		masked := reflect.New(v.Elem().Type())
+		masked.Elem().Set(maskNested(v.Elem()))
+		return masked
+
+	case reflect.Struct:
+		masked := reflect.New(v.Type()).Elem()
+		masked.Set(v)
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := masked.Field(i)
+			if !f.CanSet() {
+				continue
+			}
+			if _, ok := secretFields[t.Field(i).Name]; ok {
+				f.Set(maskSecret(f))
+				continue
+			}
+			f.Set(maskNested(f))
+		}
+		return masked
+
+	case reflect.Slice:
+		if v.IsNil() || !hasNested(v.Type().Elem()) {
+			return v
+		}
+		// The elements are masked in a new slice, since the copy
+		// shares the elements of the message.
+		masked := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			masked.Index(i).Set(maskNested(v.Index(i)))
+		}
+		return masked
+
+	case reflect.Map:
+		if v.IsNil() || !hasNested(v.Type().Elem()) {
+			return v
+		}
+		masked := reflect.MakeMap(v.Type())
+		for _, k := range v.MapKeys() {
+			masked.SetMapIndex(k, maskNested(v.MapIndex(k)))
+		}
+		return masked
+	}
+	return v
+}
+
+// hasNested returns whether values of type t are structs or pointers to
+// structs, whose fields may need to be masked.
+func hasNested(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+// maskSecret returns the value of a secret field with every string or byte
+// slice it holds replaced by Redacted.
+func maskSecret(v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 {
+		if v.IsNil() {
+			return v
+		}
+		elems := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			elems.Index(i).Set(maskValue(v.Index(i)))
+		}
+		return elems
+	}
+	return maskValue(v)
+}
+
+// maskValue returns Redacted as a value of the type of a string or byte slice
+// value, unless it is empty.  Values of other types are returned as is.
+func maskValue(v reflect.Value) reflect.Value {
+	switch {
+	case v.Kind() == reflect.String && v.Len() != 0:
+		return reflect.ValueOf(Redacted).Convert(v.Type())
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 &&
+		v.Len() != 0:
+		return reflect.ValueOf([]byte(Redacted)).Convert(v.Type())
+	}
+	return v
+}
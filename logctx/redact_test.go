@@ -0,0 +1,198 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package logctx
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	pb "github.com/tuxcanfly/wltd/rpc/walletdrpc"
+)
+
+// secret is the value every field is filled with before masking.
+const secret = "secret"
+
+type nestedSecret struct {
+	Seed []byte
+	Name string
+}
+
+type nestedRequest struct {
+	Pass        string
+	Passphrases [][]byte
+	Name        string
+	Inner       *nestedSecret
+	Items       []*nestedSecret
+	ByName      map[string]*nestedSecret
+}
+
+func TestMaskSecretsNested(t *testing.T) {
+	req := &nestedRequest{
+		Pass:        secret,
+		Passphrases: [][]byte{[]byte(secret), nil},
+		Name:        "name",
+		Inner:       &nestedSecret{[]byte(secret), "inner"},
+		Items:       []*nestedSecret{{[]byte(secret), "item"}},
+		ByName: map[string]*nestedSecret{
+			"key": {[]byte(secret), "value"},
+		},
+	}
+	masked := MaskSecrets(req).(*nestedRequest)
+
+	if masked.Pass != Redacted {
+		t.Errorf("Pass not masked: %q", masked.Pass)
+	}
+	if string(masked.Passphrases[0]) != Redacted ||
+		masked.Passphrases[1] != nil {
+		t.Errorf("Passphrases not masked: %q", masked.Passphrases)
+	}
+	if string(masked.Inner.Seed) != Redacted ||
+		string(masked.Items[0].Seed) != Redacted ||
+		string(masked.ByName["key"].Seed) != Redacted {
+		t.Error("secrets of nested structs not masked")
+	}
+	if masked.Name != "name" || masked.Inner.Name != "inner" ||
+		masked.Items[0].Name != "item" ||
+		masked.ByName["key"].Name != "value" {
+		t.Error("fields which are not secret were changed")
+	}
+
+	// The request itself is left untouched.
+	if req.Pass != secret || string(req.Passphrases[0]) != secret ||
+		string(req.Inner.Seed) != secret ||
+		string(req.Items[0].Seed) != secret ||
+		string(req.ByName["key"].Seed) != secret {
+		t.Error("the request was modified")
+	}
+
+	if MaskSecrets("text") != "text" {
+		t.Error("a value which is not a message was changed")
+	}
+}
+
+// secretName matches the names of fields which look like they hold secrets.
+var secretName = regexp.MustCompile(`(?i)pass|seed|xpriv|key`)
+
+// requestTypes returns the request message types of every method of the
+// passed service server interfaces, including the messages received from
+// client streams.
+func requestTypes(servers ...interface{}) []reflect.Type {
+	var types []reflect.Type
+	for _, server := range servers {
+		iface := reflect.TypeOf(server).Elem()
+		for i := 0; i < iface.NumMethod(); i++ {
+			m := iface.Method(i).Type
+			for j := 0; j < m.NumIn(); j++ {
+				in := m.In(j)
+				if in.Kind() == reflect.Ptr &&
+					strings.HasSuffix(in.Elem().Name(), "Request") {
+					types = append(types, in)
+					continue
+				}
+				if recv, ok := in.MethodByName("Recv"); ok &&
+					in.Kind() == reflect.Interface {
+					types = append(types, recv.Type.Out(0))
+				}
+			}
+		}
+	}
+	return types
+}
+
+// fill sets every string and byte slice of v, a struct, and of its nested
+// structs to secret.
+func fill(v reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		if !f.CanSet() {
+			continue
+		}
+		switch t := f.Type(); {
+		case t.Kind() == reflect.String:
+			f.SetString(secret)
+		case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+			f.SetBytes([]byte(secret))
+		case t.Kind() == reflect.Slice:
+			elems := reflect.MakeSlice(t, 1, 1)
+			fillElem(elems.Index(0))
+			f.Set(elems)
+		case t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct:
+			f.Set(reflect.New(t.Elem()))
+			fill(f.Elem())
+		}
+	}
+}
+
+// fillElem sets an element of a repeated field to secret.
+func fillElem(v reflect.Value) {
+	switch t := v.Type(); {
+	case t.Kind() == reflect.String:
+		v.SetString(secret)
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		v.SetBytes([]byte(secret))
+	case t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct:
+		v.Set(reflect.New(t.Elem()))
+		fill(v.Elem())
+	}
+}
+
+// checkMasked fails the test for every field of v, a struct, and of its
+// nested structs whose name looks secret and which still holds secret.
+func checkMasked(t *testing.T, path string, v reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		name := path + "." + v.Type().Field(i).Name
+		looksSecret := secretName.MatchString(v.Type().Field(i).Name)
+		switch {
+		case f.Kind() == reflect.Ptr && !f.IsNil() &&
+			f.Elem().Kind() == reflect.Struct:
+			checkMasked(t, name, f.Elem())
+		case f.Kind() == reflect.Slice && f.Type().Elem().Kind() != reflect.Uint8:
+			for j := 0; j < f.Len(); j++ {
+				e := f.Index(j)
+				if e.Kind() == reflect.Ptr && !e.IsNil() {
+					checkMasked(t, name, e.Elem())
+				} else if looksSecret && holdsSecret(e) {
+					t.Errorf("%s is not masked", name)
+				}
+			}
+		case looksSecret && holdsSecret(f):
+			t.Errorf("%s is not masked", name)
+		}
+	}
+}
+
+// holdsSecret returns whether v is a string or byte slice holding secret.
+func holdsSecret(v reflect.Value) bool {
+	switch {
+	case v.Kind() == reflect.String:
+		return v.String() == secret
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		return string(v.Bytes()) == secret
+	}
+	return false
+}
+
+// TestMaskSecretsRequests checks that every field of the RPC requests whose
+// name looks like it holds a secret is masked, so that secrets added to the
+// API without being listed in secretFields are caught.
+func TestMaskSecretsRequests(t *testing.T) {
+	types := requestTypes(
+		(*pb.VersionServiceServer)(nil),
+		(*pb.WalletDaemonServiceServer)(nil),
+		(*pb.AdminServiceServer)(nil),
+	)
+	if len(types) == 0 {
+		t.Fatal("no request types found")
+	}
+	for _, typ := range types {
+		req := reflect.New(typ.Elem())
+		fill(req.Elem())
+		masked := reflect.ValueOf(MaskSecrets(req.Interface()))
+		checkMasked(t, typ.Elem().Name(), masked.Elem())
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btclog"
+	"github.com/tuxcanfly/wltd/logctx"
+)
+
+// Supported values of the logformat option.
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+// jsonLogging selects JSON log output.  It is set once during startup before
+// any logging is performed.
+var jsonLogging bool
+
+// jsonLogMu serializes writes of JSON log lines to the log writer.
+var jsonLogMu sync.Mutex
+
+// subsystemLogger is the logger of a subsystem.  Messages are written either
+// as text by the btclog backend or as JSON objects including the logging
+// context of the line.  It implements logctx.Logger.
+type subsystemLogger struct {
+	tag    string
+	fields logctx.Fields
+
+	// backend writes text lines and holds the level of the subsystem,
+	// which is shared with the loggers created by WithFields.
+	backend btclog.Logger
+}
+
+// newSubsystemLogger creates the logger of a subsystem from the backend
+// logger.
+func newSubsystemLogger(tag string) *subsystemLogger {
+	return &subsystemLogger{tag: tag, backend: backendLog.Logger(tag)}
+}
+
+// jsonLogLine is the JSON encoding of a log line.
+type jsonLogLine struct {
+	Time       string `json:"time"`
+	Level      string `json:"level"`
+	Subsystem  string `json:"subsystem"`
+	Message    string `json:"message"`
+	RequestID  string `json:"request_id,omitempty"`
+	Method     string `json:"method,omitempty"`
+	Tenant     string `json:"tenant,omitempty"`
+	Admin      string `json:"admin,omitempty"`
+	WalletUUID string `json:"wallet_uuid,omitempty"`
+}
+
+// write outputs a message of the passed level.
+func (l *subsystemLogger) write(level btclog.Level, msg string) {
+	if !jsonLogging {
+		switch level {
+		case btclog.LevelTrace:
+			l.backend.Trace(msg)
+		case btclog.LevelDebug:
+			l.backend.Debug(msg)
+		case btclog.LevelInfo:
+			l.backend.Info(msg)
+		case btclog.LevelWarn:
+			l.backend.Warn(msg)
+		case btclog.LevelError:
+			l.backend.Error(msg)
+		default:
+			l.backend.Critical(msg)
+		}
+		return
+	}
+
+	line, err := json.Marshal(&jsonLogLine{
+		Time:       time.Now().UTC().Format("2006-01-02T15:04:05.000Z"),
+		Level:      levelName(level),
+		Subsystem:  l.tag,
+		Message:    msg,
+		RequestID:  l.fields.RequestID,
+		Method:     l.fields.Method,
+		Tenant:     l.fields.Tenant,
+		Admin:      l.fields.Admin,
+		WalletUUID: l.fields.WalletUUID,
+	})
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+
+	jsonLogMu.Lock()
+	logWriter{}.Write(line)
+	jsonLogMu.Unlock()
+}
+
+// print formats the arguments like the btclog backend and writes the message
+// if the level is enabled.
+func (l *subsystemLogger) print(level btclog.Level, args ...interface{}) {
+	if level < l.backend.Level() {
+		return
+	}
+	l.write(level, strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+}
+
+// printf formats the arguments according to the format specifier and writes
+// the message if the level is enabled.
+func (l *subsystemLogger) printf(level btclog.Level, format string, args ...interface{}) {
+	if level < l.backend.Level() {
+		return
+	}
+	l.write(level, fmt.Sprintf(format, args...))
+}
+
+// WithFields returns a logger of the same subsystem which includes the passed
+// fields in JSON output.
+func (l *subsystemLogger) WithFields(f logctx.Fields) btclog.Logger {
+	return &subsystemLogger{tag: l.tag, fields: f, backend: l.backend}
+}
+
+func (l *subsystemLogger) Trace(v ...interface{}) {
+	l.print(btclog.LevelTrace, v...)
+}
+
+func (l *subsystemLogger) Debug(v ...interface{}) {
+	l.print(btclog.LevelDebug, v...)
+}
+
+func (l *subsystemLogger) Info(v ...interface{}) {
+	l.print(btclog.LevelInfo, v...)
+}
+
+func (l *subsystemLogger) Warn(v ...interface{}) {
+	l.print(btclog.LevelWarn, v...)
+}
+
+func (l *subsystemLogger) Error(v ...interface{}) {
+	l.print(btclog.LevelError, v...)
+}
+
+func (l *subsystemLogger) Critical(v ...interface{}) {
+	l.print(btclog.LevelCritical, v...)
+}
+
+func (l *subsystemLogger) Tracef(format string, params ...interface{}) {
+	l.printf(btclog.LevelTrace, format, params...)
+}
+
+func (l *subsystemLogger) Debugf(format string, params ...interface{}) {
+	l.printf(btclog.LevelDebug, format, params...)
+}
+
+func (l *subsystemLogger) Infof(format string, params ...interface{}) {
+	l.printf(btclog.LevelInfo, format, params...)
+}
+
+func (l *subsystemLogger) Warnf(format string, params ...interface{}) {
+	l.printf(btclog.LevelWarn, format, params...)
+}
+
+func (l *subsystemLogger) Errorf(format string, params ...interface{}) {
+	l.printf(btclog.LevelError, format, params...)
+}
+
+func (l *subsystemLogger) Criticalf(format string, params ...interface{}) {
+	l.printf(btclog.LevelCritical, format, params...)
+}
+
+// Level returns the current logging level of the subsystem.
+func (l *subsystemLogger) Level() btclog.Level {
+	return l.backend.Level()
+}
+
+// SetLevel changes the logging level of the subsystem.
+func (l *subsystemLogger) SetLevel(level btclog.Level) {
+	l.backend.SetLevel(level)
+}
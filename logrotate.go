@@ -0,0 +1,249 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rollTimeFormat is the format of the UTC timestamp appended to the name of a
+// rolled log file.  It sorts in chronological order, which local times do not
+// across daylight saving time changes.
+const rollTimeFormat = "2006-01-02T15-04-05.000"
+
+// errLogFileClosed is returned when writing to a closed log file.
+var errLogFileClosed = errors.New("log file is closed")
+
+// rotatingLogFile is a log file which is rolled over once it exceeds a size
+// or has been written to for longer than an interval.  Rolled files are named
+// after the log file with the time of the roll appended, and may be gzipped.
+type rotatingLogFile struct {
+	mu       sync.Mutex
+	filename string
+	maxSize  int64
+	maxRolls int
+	compress bool
+	interval time.Duration
+
+	file   *os.File
+	size   int64
+	opened time.Time
+
+	// Rolled files are compressed and pruned in the background, so that
+	// writes are not held up.  rollMu serializes the background work of
+	// successive rolls, and Close waits for it with rollWg.
+	rollMu sync.Mutex
+	rollWg sync.WaitGroup
+}
+
+// newRotatingLogFile opens the log file for appending, creating it if
+// necessary.  A maxSize or interval of zero disables rotation by size or time,
+// and a maxRolls of zero keeps every rolled file.
+func newRotatingLogFile(filename string, maxSize int64, maxRolls int,
+	compress bool, interval time.Duration) (*rotatingLogFile, error) {
+
+	f := &rotatingLogFile{
+		filename: filename,
+		maxSize:  maxSize,
+		maxRolls: maxRolls,
+		compress: compress,
+		interval: interval,
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// open opens the log file and records its current size.
+func (f *rotatingLogFile) open() error {
+	file, err := os.OpenFile(f.filename,
+		os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	fi, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file = file
+	f.size = fi.Size()
+	f.opened = time.Now()
+	return nil
+}
+
+// Write writes p to the log file, rolling the file over first when it is due.
+// Rotation errors are reported on stderr since they cannot be logged.
+func (f *rotatingLogFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, errLogFileClosed
+	}
+	if f.due(len(p)) {
+		if err := f.rotate(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to rotate log file: %v\n",
+				err)
+		}
+		// The log file could not be reopened.
+		if f.file == nil {
+			return 0, errLogFileClosed
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// due returns whether the log file must be rolled over before writing n more
+// bytes.  Empty files are never rolled over.
+func (f *rotatingLogFile) due(n int) bool {
+	if f.size == 0 {
+		return false
+	}
+	if f.maxSize > 0 && f.size+int64(n) > f.maxSize {
+		return true
+	}
+	return f.interval > 0 && time.Since(f.opened) >= f.interval
+}
+
+// rotate rolls the log file over, and starts compressing the rolled file and
+// removing the oldest rolled files beyond the maximum number of rolls in the
+// background.
+func (f *rotatingLogFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+
+	rollName := f.filename + "." + time.Now().UTC().Format(rollTimeFormat)
+	if err := os.Rename(f.filename, rollName); err != nil {
+		// Keep writing to the current file.
+		if openErr := f.open(); openErr != nil {
+			return openErr
+		}
+		return err
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	f.rollWg.Add(1)
+	go f.finishRoll(rollName)
+	return nil
+}
+
+// finishRoll compresses a rolled file, when enabled, and prunes the rolled
+// files.  Errors are reported on stderr since they cannot be logged.
+//
+// This must be run as a goroutine.
+func (f *rotatingLogFile) finishRoll(rollName string) {
+	defer f.rollWg.Done()
+
+	f.rollMu.Lock()
+	defer f.rollMu.Unlock()
+
+	if f.compress {
+		if err := gzipFile(rollName); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to compress rolled log "+
+				"file: %v\n", err)
+		}
+	}
+	if err := f.prune(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to remove rolled log files: "+
+			"%v\n", err)
+	}
+}
+
+// prune removes the oldest rolled files beyond the maximum number of rolls.
+func (f *rotatingLogFile) prune() error {
+	if f.maxRolls <= 0 {
+		return nil
+	}
+	dir, base := filepath.Split(f.filename)
+	if dir == "" {
+		dir = "."
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	names, err := d.Readdirnames(-1)
+	d.Close()
+	if err != nil {
+		return err
+	}
+
+	var rolls []string
+	for _, name := range names {
+		if strings.HasPrefix(name, base+".") {
+			rolls = append(rolls, name)
+		}
+	}
+	if len(rolls) <= f.maxRolls {
+		return nil
+	}
+	sort.Strings(rolls)
+	for _, name := range rolls[:len(rolls)-f.maxRolls] {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the log file, and waits for rolled files to be compressed.
+func (f *rotatingLogFile) Close() error {
+	f.mu.Lock()
+	var err error
+	if f.file != nil {
+		err = f.file.Close()
+		f.file = nil
+	}
+	f.mu.Unlock()
+
+	f.rollWg.Wait()
+	return err
+}
+
+// gzipFile compresses the named file to a file of the same name with a .gz
+// suffix and removes the original.
+func gzipFile(name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	dst, err := os.OpenFile(name+".gz", os.O_WRONLY|os.O_CREATE|os.O_EXCL,
+		0600)
+	if err != nil {
+		src.Close()
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, src)
+	if err == nil {
+		err = zw.Close()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	src.Close()
+	if err != nil {
+		os.Remove(name + ".gz")
+		return err
+	}
+	return os.Remove(name)
+}
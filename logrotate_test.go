@@ -0,0 +1,154 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestRotatingLogFileDue(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		size     int64
+		maxSize  int64
+		interval time.Duration
+		opened   time.Time
+		n        int
+		due      bool
+	}{
+		{"empty file", 0, 10, time.Second, now.Add(-time.Hour), 100, false},
+		{"below max size", 5, 10, 0, now, 5, false},
+		{"above max size", 5, 10, 0, now, 6, true},
+		{"no max size", 5, 0, 0, now, 100, false},
+		{"interval not elapsed", 5, 0, time.Hour, now, 1, false},
+		{"interval elapsed", 5, 0, time.Hour, now.Add(-time.Hour), 1, true},
+	}
+	for _, test := range tests {
+		f := &rotatingLogFile{
+			size:     test.size,
+			maxSize:  test.maxSize,
+			interval: test.interval,
+			opened:   test.opened,
+		}
+		if due := f.due(test.n); due != test.due {
+			t.Errorf("%s: due %v, want %v", test.name, due, test.due)
+		}
+	}
+}
+
+func TestRotatingLogFilePrune(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logrotate-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The rolls straddle the end of daylight saving time, when the local
+	// time of the later rolls is earlier.
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("no time zone database: %v", err)
+	}
+	start := time.Date(2016, 11, 6, 1, 30, 0, 0, loc)
+	var rolls []string
+	for i := 0; i < 4; i++ {
+		roll := start.Add(time.Duration(i) * 20 * time.Minute)
+		rolls = append(rolls, "walletd.log."+
+			roll.UTC().Format(rollTimeFormat))
+	}
+	rolls[1] += ".gz"
+	names := append([]string{"walletd.log", "other.log"}, rolls...)
+	for _, name := range names {
+		err := ioutil.WriteFile(filepath.Join(dir, name), nil, 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f := &rotatingLogFile{
+		filename: filepath.Join(dir, "walletd.log"),
+		maxRolls: 2,
+	}
+	if err := f.prune(); err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := d.Readdirnames(-1)
+	d.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(got)
+	want := []string{"other.log", "walletd.log", rolls[2], rolls[3]}
+	sort.Strings(want)
+	if len(got) != len(want) {
+		t.Fatalf("kept %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("kept %v, want %v", got, want)
+		}
+	}
+}
+
+func TestGzipFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logrotate-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "walletd.log.roll")
+	content := bytes.Repeat([]byte("log line\n"), 1000)
+	if err := ioutil.WriteFile(name, content, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzipFile(name); err != nil {
+		t.Fatalf("gzipFile: %v", err)
+	}
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Fatalf("compressed file not removed: %v", err)
+	}
+
+	zf, err := os.Open(name + ".gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zf.Close()
+	zr, err := gzip.NewReader(zf)
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+	got, err := ioutil.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("reading compressed file: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Fatal("compressed file does not hold the original content")
+	}
+
+	// An existing compressed file is never overwritten, and the original
+	// is then kept.
+	if err := ioutil.WriteFile(name, content, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzipFile(name); err == nil {
+		t.Fatal("gzipFile overwrote an existing compressed file")
+	}
+	if _, err := os.Stat(name); err != nil {
+		t.Fatalf("original file removed after failure: %v", err)
+	}
+}
@@ -0,0 +1,229 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/btcsuite/btclog"
+	"github.com/tuxcanfly/wltd/rpc/rpcserver"
+)
+
+// logTailBufferSize is the number of log lines which may be queued for a tail
+// subscriber.  Lines written while the queue is full are dropped rather than
+// blocking the logging backend.
+const logTailBufferSize = 256
+
+// logSubscription is a single tail of the log output.
+type logSubscription struct {
+	minLevel   btclog.Level
+	subsystems map[string]struct{}
+	lines      chan rpcserver.LogLine
+
+	// dropped is the number of lines dropped since the last queued line.
+	// It is protected by the logTailer mutex.
+	dropped uint64
+}
+
+// logTailer publishes the lines written to the logging backend to tail
+// subscribers.  It implements rpcserver.LogManager.
+type logTailer struct {
+	mu     sync.Mutex
+	subs   map[*logSubscription]struct{}
+	closed bool
+}
+
+// logTails publishes the output of backendLog.  It is written to by the Write
+// method of the logWriter type.
+var logTails = &logTailer{subs: make(map[*logSubscription]struct{})}
+
+// publish queues a line written by the logging backend for every matching
+// subscriber.  It must never log, since it is called with the backend mutex
+// held.
+func (t *logTailer) publish(p []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.subs) == 0 {
+		return
+	}
+	level, subsystem, ok := parseLogHeader(p)
+	if !ok {
+		return
+	}
+
+	// The backend reuses its buffers, so the line must be copied.
+	line := string(bytes.TrimRight(p, "\n"))
+	for sub := range t.subs {
+		if level < sub.minLevel {
+			continue
+		}
+		if len(sub.subsystems) != 0 {
+			if _, ok := sub.subsystems[subsystem]; !ok {
+				continue
+			}
+		}
+		l := rpcserver.LogLine{
+			Subsystem: subsystem,
+			Level:     levelName(level),
+			Line:      line,
+			Dropped:   sub.dropped,
+		}
+		select {
+		case sub.lines <- l:
+			sub.dropped = 0
+		default:
+			sub.dropped++
+		}
+	}
+}
+
+// LogLevels returns the log level of every subsystem, keyed by subsystem
+// identifier.
+func (t *logTailer) LogLevels() map[string]string {
+	levels := make(map[string]string, len(subsystemLoggers))
+	for subsystemID, logger := range subsystemLoggers {
+		levels[subsystemID] = levelName(logger.Level())
+	}
+	return levels
+}
+
+// SetLogLevel sets the log level of a subsystem, or of every subsystem when
+// subsystemID is empty.
+func (t *logTailer) SetLogLevel(subsystemID, logLevel string) error {
+	if !validLogLevel(logLevel) {
+		return fmt.Errorf("the specified debug level [%v] is invalid",
+			logLevel)
+	}
+	if subsystemID == "" {
+		setLogLevels(logLevel)
+		return nil
+	}
+	if _, exists := subsystemLoggers[subsystemID]; !exists {
+		return fmt.Errorf("the specified subsystem [%v] is invalid -- "+
+			"supported subsytems %v", subsystemID,
+			supportedSubsystems())
+	}
+	setLogLevel(subsystemID, logLevel)
+	return nil
+}
+
+// TailLogs subscribes to log lines of at least minLevel written by the passed
+// subsystems, or by every subsystem when none are passed.  The returned
+// channel is closed when cancel is called or the tailer is closed.
+func (t *logTailer) TailLogs(minLevel string, subsystems []string) (
+	<-chan rpcserver.LogLine, func(), error) {
+
+	level := btclog.LevelTrace
+	if minLevel != "" {
+		if !validLogLevel(minLevel) {
+			return nil, nil, fmt.Errorf("the specified level [%v] "+
+				"is invalid", minLevel)
+		}
+		level, _ = btclog.LevelFromString(minLevel)
+	}
+	sub := &logSubscription{
+		minLevel:   level,
+		subsystems: make(map[string]struct{}, len(subsystems)),
+		lines:      make(chan rpcserver.LogLine, logTailBufferSize),
+	}
+	for _, subsystemID := range subsystems {
+		if _, exists := subsystemLoggers[subsystemID]; !exists {
+			return nil, nil, fmt.Errorf("the specified subsystem "+
+				"[%v] is invalid -- supported subsytems %v",
+				subsystemID, supportedSubsystems())
+		}
+		sub.subsystems[subsystemID] = struct{}{}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		close(sub.lines)
+		return sub.lines, func() {}, nil
+	}
+	t.subs[sub] = struct{}{}
+
+	cancel := func() {
+		t.mu.Lock()
+		if _, ok := t.subs[sub]; ok {
+			delete(t.subs, sub)
+			close(sub.lines)
+		}
+		t.mu.Unlock()
+	}
+	return sub.lines, cancel, nil
+}
+
+// close ends every tail and refuses new ones.  It is used on shutdown so that
+// tails do not hold up draining the RPC server.
+func (t *logTailer) close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.closed = true
+	for sub := range t.subs {
+		delete(t.subs, sub)
+		close(sub.lines)
+	}
+}
+
+// parseLogHeader returns the level and subsystem of a line formatted by the
+// btclog backend, which starts with "<date> <time> [LVL] SUBS: ", or of a JSON
+// log line.
+func parseLogHeader(p []byte) (btclog.Level, string, bool) {
+	if len(p) != 0 && p[0] == '{' {
+		var line jsonLogLine
+		if err := json.Unmarshal(p, &line); err != nil {
+			return 0, "", false
+		}
+		level, ok := btclog.LevelFromString(line.Level)
+		return level, line.Subsystem, ok
+	}
+
+	start := bytes.Index(p, []byte(" ["))
+	if start == -1 {
+		return 0, "", false
+	}
+	p = p[start+2:]
+	end := bytes.Index(p, []byte("] "))
+	if end == -1 {
+		return 0, "", false
+	}
+	level, ok := btclog.LevelFromString(string(p[:end]))
+	if !ok {
+		return 0, "", false
+	}
+	p = p[end+2:]
+	end = bytes.IndexAny(p, ": ")
+	if end == -1 {
+		return 0, "", false
+	}
+	return level, string(p[:end]), true
+}
+
+// levelName returns the name of a log level as accepted by the debuglevel
+// option.
+func levelName(level btclog.Level) string {
+	switch level {
+	case btclog.LevelTrace:
+		return "trace"
+	case btclog.LevelDebug:
+		return "debug"
+	case btclog.LevelInfo:
+		return "info"
+	case btclog.LevelWarn:
+		return "warn"
+	case btclog.LevelError:
+		return "error"
+	case btclog.LevelCritical:
+		return "critical"
+	default:
+		return "off"
+	}
+}
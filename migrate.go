@@ -0,0 +1,39 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/tuxcanfly/wltd/walletd"
+)
+
+// runMigrateDryRun writes the registry migrations which would be applied when
+// the daemon is started to stdout.  The daemon must not be started, and is
+// closed once the plan has been written.
+func runMigrateDryRun(walletDaemon *walletd.WalletDaemon) error {
+	defer func() {
+		if err := walletDaemon.Close(); err != nil {
+			log.Errorf("Unable to close wallet registry and audit log: %v",
+				err)
+		}
+	}()
+
+	version, steps, err := walletDaemon.PendingMigrations()
+	if err != nil {
+		log.Errorf("Unable to read registry schema version: %v", err)
+		return err
+	}
+	fmt.Printf("Registry schema version: %d\n", version)
+	if len(steps) == 0 {
+		fmt.Println("Registry is up to date, no migrations pending")
+		return nil
+	}
+	fmt.Println("Pending migrations (the registry is backed up first):")
+	for _, step := range steps {
+		fmt.Printf("  version %d: %s\n", step.Version, step.Description)
+	}
+	return nil
+}
@@ -7,3 +7,16 @@ package main
 import "github.com/btcsuite/btcd/chaincfg"
 
 var activeNet = &chaincfg.MainNetParams
+
+// extraNets holds the networks served in addition to activeNet, which remains
+// the default network of new wallets.
+var extraNets []*chaincfg.Params
+
+// btcdRPCPorts maps each network name to the default RPC port of btcd for that
+// network.
+var btcdRPCPorts = map[string]string{
+	"mainnet":  "8334",
+	"testnet3": "18334",
+	"regtest":  "18334",
+	"simnet":   "18556",
+}
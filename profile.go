@@ -0,0 +1,51 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net"
+	"net/http"
+	_ "net/http/pprof"
+)
+
+// profileListener is the listener of the running profile server, or nil when
+// profiling is disabled.  It is only accessed by walletdMain and the reload
+// handlers, which never run concurrently.
+var profileListener net.Listener
+
+// startProfileServer starts serving the HTTP profile handlers on the passed
+// port.  Requests to other paths are redirected to the profile index.
+func startProfileServer(port string) error {
+	listenAddr := net.JoinHostPort("", port)
+	listener, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		return err
+	}
+	profileListener = listener
+
+	// The pprof handlers register themselves with the default mux.
+	mux := http.NewServeMux()
+	mux.Handle("/debug/pprof/", http.DefaultServeMux)
+	mux.Handle("/", http.RedirectHandler("/debug/pprof",
+		http.StatusSeeOther))
+
+	log.Infof("Profile server listening on %s", listener.Addr())
+	go func() {
+		err := http.Serve(listener, mux)
+		log.Tracef("Finished serving profile requests: %v", err)
+	}()
+	return nil
+}
+
+// stopProfileServer stops the running profile server, if any.
+func stopProfileServer() {
+	if profileListener == nil {
+		return
+	}
+	if err := profileListener.Close(); err != nil {
+		log.Errorf("Cannot close profile server: %v", err)
+	}
+	profileListener = nil
+}
@@ -0,0 +1,408 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+
+	pb "github.com/tuxcanfly/wltd/rpc/walletdrpc"
+)
+
+// retryAfterTrailer is the trailer metadata key holding the number of
+// milliseconds after which a rate limited request may be retried.
+const retryAfterTrailer = "retry-after-ms"
+
+const (
+	// maxBuckets is the number of token buckets above which the least
+	// recently used buckets are removed.
+	maxBuckets = 10000
+
+	// bucketIdleTimeout is how long a token bucket may go unused before
+	// it is removed.  Buckets which refilled completely behave exactly
+	// like new ones, and are removed even sooner.
+	bucketIdleTimeout = 10 * time.Minute
+
+	// bucketPruneInterval is how often idle token buckets are removed.
+	bucketPruneInterval = time.Minute
+)
+
+// expensiveMethods are the methods whose concurrency is limited by the
+// maxexpensiveops option.
+var expensiveMethods = map[string]struct{}{
+	"/walletdrpc.WalletDaemonService/CreateWallet":    {},
+	"/walletdrpc.WalletDaemonService/CreateWallets":   {},
+	"/walletdrpc.WalletDaemonService/SendTransaction": {},
+	"/walletdrpc.AdminService/BackupWallet":           {},
+	"/walletdrpc.AdminService/RestoreWallet":          {},
+	"/walletdrpc.AdminService/CheckWallet":            {},
+}
+
+// rateLimitRule limits the rate of requests of a tenant to a method.  An
+// empty tenant matches every tenant, and the method "*" matches every method.
+// Every tenant matched by a rule has its own token bucket.
+type rateLimitRule struct {
+	tenant string
+	method string
+	rate   float64
+	burst  float64
+}
+
+// parseRateLimit parses a rule in the [tenant@]method=rate[/burst] format of
+// the ratelimit option.  The burst defaults to the rate, rounded up.
+func parseRateLimit(s string) (rateLimitRule, error) {
+	var rule rateLimitRule
+	eq := strings.LastIndex(s, "=")
+	if eq == -1 {
+		return rule, fmt.Errorf("rate limit '%s' is not in the "+
+			"[tenant@]method=rate[/burst] format", s)
+	}
+	target, limit := s[:eq], s[eq+1:]
+
+	rule.method = target
+	if at := strings.LastIndex(target, "@"); at != -1 {
+		rule.tenant, rule.method = target[:at], target[at+1:]
+	}
+	if rule.method == "" {
+		return rule, fmt.Errorf("rate limit '%s' has no method", s)
+	}
+
+	rateStr, burstStr := limit, ""
+	if slash := strings.Index(limit, "/"); slash != -1 {
+		rateStr, burstStr = limit[:slash], limit[slash+1:]
+	}
+	rate, err := strconv.ParseFloat(rateStr, 64)
+	if err != nil || rate <= 0 {
+		return rule, fmt.Errorf("rate limit '%s' has an invalid rate", s)
+	}
+	rule.rate = rate
+	rule.burst = math.Ceil(rate)
+	if burstStr != "" {
+		burst, err := strconv.ParseUint(burstStr, 10, 32)
+		if err != nil || burst == 0 {
+			return rule, fmt.Errorf("rate limit '%s' has an "+
+				"invalid burst", s)
+		}
+		rule.burst = float64(burst)
+	}
+	return rule, nil
+}
+
+// matches returns whether the rule applies to the method, which is either a
+// full gRPC method name or its last element.
+func (r *rateLimitRule) matches(fullMethod string) bool {
+	if r.method == "*" || r.method == fullMethod {
+		return true
+	}
+	return r.method == fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+}
+
+// tokenBucket holds the tokens of a tenant for a rule.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// bucketKey identifies the token bucket of a client for a rule.  The client
+// is the identity authenticated by the token of its requests.
+type bucketKey struct {
+	client string
+	rule   *rateLimitRule
+}
+
+// rateLimiter limits the rate of requests per client and method using token
+// buckets.
+type rateLimiter struct {
+	mu        sync.Mutex
+	rules     []*rateLimitRule
+	buckets   map[bucketKey]*tokenBucket
+	lastPrune time.Time
+}
+
+// newRateLimiter creates a rate limiter enforcing the passed rules.
+func newRateLimiter(rules []rateLimitRule) *rateLimiter {
+	l := &rateLimiter{buckets: make(map[bucketKey]*tokenBucket)}
+	for i := range rules {
+		l.rules = append(l.rules, &rules[i])
+	}
+	return l
+}
+
+// rule returns the most specific rule applying to a request of the tenant to
+// the method, or nil if the request is not limited.  Rules naming the tenant
+// take precedence over rules for every tenant, and rules naming the method
+// take precedence over rules for every method.
+func (l *rateLimiter) rule(tenant, fullMethod string) *rateLimitRule {
+	var best *rateLimitRule
+	bestScore := -1
+	for _, r := range l.rules {
+		if r.tenant != "" && r.tenant != tenant {
+			continue
+		}
+		if !r.matches(fullMethod) {
+			continue
+		}
+		score := 0
+		if r.tenant != "" {
+			score += 2
+		}
+		if r.method != "*" {
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = r, score
+		}
+	}
+	return best
+}
+
+// allow takes cost tokens for a request of the client, acting for the tenant,
+// to the method.  Admin clients act for no tenant.  A request costing more
+// than the burst of its rule is allowed once the bucket is full, and leaves
+// the bucket in debt until it has refilled.  When not enough tokens are
+// available, it returns false and the duration after which they will be.
+func (l *rateLimiter) allow(client, tenant, fullMethod string, cost float64) (bool, time.Duration) {
+	rule := l.rule(tenant, fullMethod)
+	if rule == nil {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.lastPrune) >= bucketPruneInterval {
+		l.pruneBuckets(now)
+	}
+	key := bucketKey{client, rule}
+	b, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= maxBuckets {
+			l.pruneBuckets(now)
+		}
+		b = &tokenBucket{tokens: rule.burst, last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens += now.Sub(b.last).Seconds() * rule.rate
+	if b.tokens > rule.burst {
+		b.tokens = rule.burst
+	}
+	b.last = now
+
+	need := cost
+	if need > rule.burst {
+		need = rule.burst
+	}
+	if b.tokens < need {
+		wait := time.Duration((need - b.tokens) / rule.rate * float64(time.Second))
+		return false, wait
+	}
+	b.tokens -= cost
+	return true, 0
+}
+
+// requestCost returns the number of tokens a request message costs.  Requests
+// creating several wallets cost a token per wallet, and other requests a
+// single token.
+func requestCost(msg interface{}) float64 {
+	if req, ok := msg.(*pb.CreateWalletsRequest); ok && len(req.Passphrases) > 1 {
+		return float64(len(req.Passphrases))
+	}
+	return 1
+}
+
+// pruneBuckets removes the buckets which have refilled completely or have
+// been idle for bucketIdleTimeout, unless they are still in debt.  When there
+// are still maxBuckets buckets, the least recently used half of them is
+// removed too.
+func (l *rateLimiter) pruneBuckets(now time.Time) {
+	l.lastPrune = now
+	for key, b := range l.buckets {
+		idle := now.Sub(b.last)
+		tokens := b.tokens + idle.Seconds()*key.rule.rate
+		if tokens >= key.rule.burst ||
+			(idle >= bucketIdleTimeout && tokens >= 0) {
+			delete(l.buckets, key)
+		}
+	}
+	if len(l.buckets) < maxBuckets {
+		return
+	}
+
+	lastUses := make([]time.Time, 0, len(l.buckets))
+	for _, b := range l.buckets {
+		lastUses = append(lastUses, b.last)
+	}
+	sort.Sort(timeSlice(lastUses))
+	cutoff := lastUses[len(lastUses)-maxBuckets/2]
+	for key, b := range l.buckets {
+		if b.last.Before(cutoff) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// timeSlice sorts times in increasing order.
+type timeSlice []time.Time
+
+func (s timeSlice) Len() int           { return len(s) }
+func (s timeSlice) Less(i, j int) bool { return s[i].Before(s[j]) }
+func (s timeSlice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// rateLimitClient returns the client of the request of ctx, which is the
+// identity authenticated by authenticator, and the tenant it acts for.  Admin
+// identities are kept apart from tenants with the same name.
+func rateLimitClient(ctx context.Context) (client, tenant string) {
+	c, _ := credentialFromContext(ctx)
+	if c.role == roleTenant {
+		return c.name, c.name
+	}
+	return c.role + ":" + c.name, ""
+}
+
+// limitedError returns the error of a rate limited request.
+func limitedError(wait time.Duration) error {
+	return grpc.Errorf(codes.ResourceExhausted, "rate limit exceeded, "+
+		"retry after %v", wait)
+}
+
+// retryAfter returns the trailer holding the retry hint of a rate limited
+// request.
+func retryAfter(wait time.Duration) metadata.MD {
+	ms := (wait + time.Millisecond - 1) / time.Millisecond
+	return metadata.Pairs(retryAfterTrailer, strconv.FormatInt(int64(ms), 10))
+}
+
+// unaryInterceptor refuses unary requests exceeding their rate limit.  It must
+// run after the authenticator, which identifies the client.
+func (l *rateLimiter) unaryInterceptor(ctx context.Context, req interface{},
+	info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+
+	client, tenant := rateLimitClient(ctx)
+	if ok, wait := l.allow(client, tenant, info.FullMethod, requestCost(req)); !ok {
+		grpc.SetTrailer(ctx, retryAfter(wait))
+		return nil, limitedError(wait)
+	}
+	return handler(ctx, req)
+}
+
+// streamInterceptor refuses streaming requests exceeding their rate limit.  It
+// must run after the authenticator, which identifies the client.  Requests
+// streaming only responses are charged the cost of their request message when
+// the handler receives it, and other streams a single token up front.
+func (l *rateLimiter) streamInterceptor(srv interface{}, ss grpc.ServerStream,
+	info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+	client, tenant := rateLimitClient(ss.Context())
+	if !info.IsClientStream {
+		return handler(srv, &rateLimitedStream{
+			ServerStream: ss,
+			limiter:      l,
+			client:       client,
+			tenant:       tenant,
+			method:       info.FullMethod,
+		})
+	}
+	if ok, wait := l.allow(client, tenant, info.FullMethod, 1); !ok {
+		ss.SetTrailer(retryAfter(wait))
+		return limitedError(wait)
+	}
+	return handler(srv, ss)
+}
+
+// rateLimitedStream charges the cost of the request message of a stream when
+// it is received.
+type rateLimitedStream struct {
+	grpc.ServerStream
+	limiter        *rateLimiter
+	client, tenant string
+	method         string
+	charged        bool
+}
+
+func (s *rateLimitedStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if s.charged {
+		return nil
+	}
+	s.charged = true
+	ok, wait := s.limiter.allow(s.client, s.tenant, s.method, requestCost(m))
+	if !ok {
+		s.SetTrailer(retryAfter(wait))
+		return limitedError(wait)
+	}
+	return nil
+}
+
+// concurrencyLimiter limits the number of expensive operations which run at
+// once.  Requests beyond the limit wait for a running operation to finish.
+type concurrencyLimiter struct {
+	slots chan struct{}
+}
+
+// newConcurrencyLimiter creates a limiter allowing max expensive operations
+// at once, or any number of them when max is zero.
+func newConcurrencyLimiter(max int) *concurrencyLimiter {
+	l := &concurrencyLimiter{}
+	if max > 0 {
+		l.slots = make(chan struct{}, max)
+	}
+	return l
+}
+
+// acquire waits for a slot to run an expensive operation of the method.  The
+// returned function releases the slot.
+func (l *concurrencyLimiter) acquire(ctx context.Context, fullMethod string) (func(), error) {
+	if _, ok := expensiveMethods[fullMethod]; !ok || l.slots == nil {
+		return func() {}, nil
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return func() { <-l.slots }, nil
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, grpc.Errorf(codes.DeadlineExceeded,
+				"%s", ctx.Err().Error())
+		}
+		return nil, grpc.Errorf(codes.Canceled, "%s", ctx.Err().Error())
+	}
+}
+
+// unaryInterceptor limits the concurrency of expensive unary requests.
+func (l *concurrencyLimiter) unaryInterceptor(ctx context.Context, req interface{},
+	info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+
+	release, err := l.acquire(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return handler(ctx, req)
+}
+
+// streamInterceptor limits the concurrency of expensive streaming requests.
+func (l *concurrencyLimiter) streamInterceptor(srv interface{}, ss grpc.ServerStream,
+	info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+	release, err := l.acquire(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return handler(srv, ss)
+}
@@ -0,0 +1,203 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	pb "github.com/tuxcanfly/wltd/rpc/walletdrpc"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	tests := []struct {
+		s    string
+		rule rateLimitRule
+		err  bool
+	}{
+		{s: "CreateWallet=0.2/5", rule: rateLimitRule{"", "CreateWallet", 0.2, 5}},
+		{s: "*=50", rule: rateLimitRule{"", "*", 50, 50}},
+		{s: "*=2.5", rule: rateLimitRule{"", "*", 2.5, 3}},
+		{s: "acme@CreateWallet=1/10", rule: rateLimitRule{"acme", "CreateWallet", 1, 10}},
+		{s: "a@b@Ping=1", rule: rateLimitRule{"a@b", "Ping", 1, 1}},
+		{s: "/walletdrpc.WalletDaemonService/Ping=1",
+			rule: rateLimitRule{"", "/walletdrpc.WalletDaemonService/Ping", 1, 1}},
+		{s: "CreateWallet", err: true},
+		{s: "=1", err: true},
+		{s: "acme@=1", err: true},
+		{s: "Ping=0", err: true},
+		{s: "Ping=-1", err: true},
+		{s: "Ping=fast", err: true},
+		{s: "Ping=1/0", err: true},
+		{s: "Ping=1/1.5", err: true},
+	}
+	for _, test := range tests {
+		rule, err := parseRateLimit(test.s)
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: parsed as %+v, want an error", test.s,
+					rule)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.s, err)
+			continue
+		}
+		if rule != test.rule {
+			t.Errorf("%s: parsed as %+v, want %+v", test.s, rule,
+				test.rule)
+		}
+	}
+}
+
+func TestRateLimitRulePrecedence(t *testing.T) {
+	const (
+		create = "/walletdrpc.WalletDaemonService/CreateWallet"
+		ping   = "/walletdrpc.WalletDaemonService/Ping"
+	)
+	rules := []rateLimitRule{
+		{"", "*", 1, 1},
+		{"", "CreateWallet", 2, 2},
+		{"acme", "*", 3, 3},
+		{"acme", create, 4, 4},
+	}
+	l := newRateLimiter(rules)
+
+	tests := []struct {
+		tenant, method string
+		rate           float64
+	}{
+		{"acme", create, 4},
+		{"acme", ping, 3},
+		{"other", create, 2},
+		{"other", ping, 1},
+		{"", ping, 1},
+	}
+	for _, test := range tests {
+		rule := l.rule(test.tenant, test.method)
+		if rule == nil || rule.rate != test.rate {
+			t.Errorf("%s@%s: got rule %+v, want the rule with rate %v",
+				test.tenant, test.method, rule, test.rate)
+		}
+	}
+
+	// Requests matched by no rule are not limited.
+	l = newRateLimiter([]rateLimitRule{{"acme", "Ping", 1, 1}})
+	if rule := l.rule("other", ping); rule != nil {
+		t.Errorf("got rule %+v for an unlimited request", rule)
+	}
+}
+
+func TestRateLimiterRefill(t *testing.T) {
+	const method = "/walletdrpc.WalletDaemonService/Ping"
+	l := newRateLimiter([]rateLimitRule{{"", "*", 2, 3}})
+
+	// A new client may send a burst of requests.
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.allow("acme", "acme", method, 1); !ok {
+			t.Fatalf("request %d of the burst refused", i)
+		}
+	}
+	ok, wait := l.allow("acme", "acme", method, 1)
+	if ok {
+		t.Fatal("request beyond the burst allowed")
+	}
+	if wait <= 0 || wait > 500*time.Millisecond {
+		t.Fatalf("retry after %v, want at most 500ms", wait)
+	}
+	if ms := retryAfter(wait)[retryAfterTrailer]; len(ms) != 1 || ms[0] == "0" {
+		t.Fatalf("retry-after trailer %v", ms)
+	}
+
+	// Other clients have their own buckets.
+	if ok, _ := l.allow("other", "other", method, 1); !ok {
+		t.Fatal("request of another client refused")
+	}
+
+	// Tokens are refilled at the rate, up to the burst.
+	l.mu.Lock()
+	for key, b := range l.buckets {
+		if key.client == "acme" {
+			b.last = b.last.Add(-time.Second)
+		}
+	}
+	l.mu.Unlock()
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.allow("acme", "acme", method, 1); !ok {
+			t.Fatalf("request %d after refilling refused", i)
+		}
+	}
+	if ok, _ := l.allow("acme", "acme", method, 1); ok {
+		t.Fatal("more requests allowed than refilled")
+	}
+}
+
+func TestRateLimiterCost(t *testing.T) {
+	const method = "/walletdrpc.WalletDaemonService/CreateWallets"
+	l := newRateLimiter([]rateLimitRule{{"", "*", 1, 5}})
+
+	req := &pb.CreateWalletsRequest{Passphrases: make([][]byte, 20)}
+	if cost := requestCost(req); cost != 20 {
+		t.Fatalf("CreateWallets of 20 wallets costs %v, want 20", cost)
+	}
+	if cost := requestCost(&pb.PingRequest{}); cost != 1 {
+		t.Fatalf("Ping costs %v, want 1", cost)
+	}
+
+	// A request costing more than the burst is allowed with a full
+	// bucket, and the following requests wait until the debt is repaid.
+	if ok, _ := l.allow("acme", "acme", method, 2000); !ok {
+		t.Fatal("request costing more than the burst refused")
+	}
+	ok, wait := l.allow("acme", "acme", method, 1)
+	if ok {
+		t.Fatal("request allowed while in debt")
+	}
+	if wait < 1995*time.Second || wait > 1996*time.Second {
+		t.Fatalf("retry after %v, want 1996s", wait)
+	}
+
+	// Buckets in debt are not removed when pruned.
+	l.pruneBuckets(time.Now().Add(bucketIdleTimeout))
+	if len(l.buckets) != 1 {
+		t.Fatal("bucket in debt removed")
+	}
+}
+
+func TestRateLimiterEviction(t *testing.T) {
+	const method = "/walletdrpc.WalletDaemonService/Ping"
+	l := newRateLimiter([]rateLimitRule{{"", "*", 0.001, 2}})
+
+	// Fill the limiter with buckets which are still refilling, the
+	// oldest first.
+	start := time.Now().Add(-time.Second)
+	for i := 0; i < maxBuckets; i++ {
+		client := fmt.Sprintf("client%d", i)
+		if ok, _ := l.allow(client, client, method, 1); !ok {
+			t.Fatalf("first request of %s refused", client)
+		}
+		l.buckets[bucketKey{client, l.rules[0]}].last =
+			start.Add(time.Duration(i) * time.Microsecond)
+	}
+	l.lastPrune = time.Now()
+
+	// A new client evicts the least recently used half of the buckets.
+	if ok, _ := l.allow("new", "new", method, 1); !ok {
+		t.Fatal("request of a new client refused")
+	}
+	if n := len(l.buckets); n > maxBuckets/2+1 {
+		t.Fatalf("%d buckets after eviction, want at most %d", n,
+			maxBuckets/2+1)
+	}
+	newest := fmt.Sprintf("client%d", maxBuckets-1)
+	if _, ok := l.buckets[bucketKey{newest, l.rules[0]}]; !ok {
+		t.Fatal("most recently used bucket evicted")
+	}
+	if _, ok := l.buckets[bucketKey{"client0", l.rules[0]}]; ok {
+		t.Fatal("least recently used bucket kept")
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"reflect"
+
+	"github.com/tuxcanfly/wltd/walletd"
+)
+
+// restartOptions are the options which cannot be changed while the process is
+// running.  Changes to them are reported when the configuration is reloaded,
+// but only take effect after a restart.
+var restartOptions = []struct {
+	name  string
+	value func(*config) interface{}
+}{
+	{"appdata", func(c *config) interface{} { return c.AppDataDir }},
+	{"dbdriver", func(c *config) interface{} { return c.DBDriver }},
+	{"testnet", func(c *config) interface{} { return c.TestNet3 }},
+	{"simnet", func(c *config) interface{} { return c.SimNet }},
+	{"regtest", func(c *config) interface{} { return c.RegTest }},
+	{"servenet", func(c *config) interface{} { return c.ExtraNets }},
+	{"noinitialload", func(c *config) interface{} { return c.NoInitialLoad }},
+	{"createworkers", func(c *config) interface{} { return c.CreateWorkers }},
+	{"walletpass", func(c *config) interface{} { return c.WalletPass }},
+	{"backupkey", func(c *config) interface{} { return c.BackupKey }},
+	{"backupdir", func(c *config) interface{} { return c.BackupDir }},
+	{"backupschedule", func(c *config) interface{} { return c.BackupSchedule }},
+	{"backupretention", func(c *config) interface{} { return c.BackupRetention }},
+	{"logdir", func(c *config) interface{} { return c.LogDir }},
+	{"logformat", func(c *config) interface{} { return c.LogFormat }},
+	{"logmaxsize", func(c *config) interface{} { return c.LogMaxSize }},
+	{"logmaxrolls", func(c *config) interface{} { return c.LogMaxRolls }},
+	{"logrotateinterval", func(c *config) interface{} { return c.LogRotateInterval }},
+	{"logcompress", func(c *config) interface{} { return c.LogCompress }},
+	{"nofilelogging", func(c *config) interface{} { return c.NoFileLogging }},
+	{"rpclisten", func(c *config) interface{} { return c.RPCListeners }},
+	{"notls", func(c *config) interface{} { return c.DisableTLS }},
+	{"onetimetlskey", func(c *config) interface{} { return c.OneTimeTLSKey }},
+	{"ratelimit", func(c *config) interface{} { return c.RateLimits }},
+	{"maxexpensiveops", func(c *config) interface{} { return c.MaxExpensiveOps }},
+	{"rpcconnect", func(c *config) interface{} { return c.RPCConnect }},
+	{"cafile", func(c *config) interface{} { return c.CAFile }},
+	{"btcdusername", func(c *config) interface{} { return c.BtcdUsername }},
+	{"btcdpassword", func(c *config) interface{} { return c.BtcdPassword }},
+}
+
+// reloadConfig reparses the config file and command line options and applies
+// the options which can safely be changed while the process is running:
+//
+//   - debuglevel
+//   - rpccert and rpckey, which are reread from disk even when unchanged
+//   - authfile, which is reread from disk even when unchanged
+//   - tlsextraip, tlsextradomain and certvalidity, used by certificates
+//     generated later
+//   - profile
+//   - walletidletimeout
+//   - shutdowntimeout
+//
+// Changes to any other option are logged and ignored.  When the new
+// configuration is invalid, the running configuration is left untouched.
+func reloadConfig(walletDaemon *walletd.WalletDaemon, certs *certManager,
+	auth *authenticator) {
+
+	newCfg, _, configFileError, err := parseConfig()
+	if err != nil {
+		log.Errorf("Cannot reload configuration: %v", err)
+		return
+	}
+	if configFileError != nil {
+		log.Warnf("%v", configFileError)
+	}
+
+	for _, opt := range restartOptions {
+		if !reflect.DeepEqual(opt.value(cfg), opt.value(newCfg)) {
+			log.Warnf("Option %s was changed, but a restart is "+
+				"required for the change to take effect", opt.name)
+		}
+	}
+
+	if newCfg.DebugLevel != cfg.DebugLevel {
+		if err := parseAndSetDebugLevels(newCfg.DebugLevel); err != nil {
+			log.Errorf("Cannot set debug level: %v", err)
+
+			// Some subsystems may have been changed before the
+			// invalid entry was found, so restore all of them.
+			parseAndSetDebugLevels(cfg.DebugLevel)
+		} else {
+			cfg.DebugLevel = newCfg.DebugLevel
+		}
+	}
+
+	if err := certs.SetFiles(newCfg.RPCCert, newCfg.RPCKey); err != nil {
+		log.Errorf("Cannot reload RPC TLS keypair: %v", err)
+	} else {
+		cfg.RPCCert, cfg.RPCKey = newCfg.RPCCert, newCfg.RPCKey
+	}
+	if err := auth.load(newCfg.AuthFile); err != nil {
+		log.Errorf("Cannot reload RPC credentials: %v", err)
+	} else {
+		cfg.AuthFile = newCfg.AuthFile
+	}
+
+	cfg.TLSExtraIPs = newCfg.TLSExtraIPs
+	cfg.TLSExtraDomains = newCfg.TLSExtraDomains
+	certs.SetExtraHosts(tlsExtraHosts(cfg))
+	cfg.CertValidity = newCfg.CertValidity
+	certs.SetValidity(cfg.CertValidity)
+
+	if newCfg.Profile != cfg.Profile {
+		stopProfileServer()
+		cfg.Profile = newCfg.Profile
+		if cfg.Profile != "" {
+			if err := startProfileServer(cfg.Profile); err != nil {
+				log.Errorf("Cannot start profile server: %v", err)
+			}
+		}
+	}
+
+	if newCfg.WalletIdleTimeout != cfg.WalletIdleTimeout {
+		cfg.WalletIdleTimeout = newCfg.WalletIdleTimeout
+		walletDaemon.SetIdleTimeout(cfg.WalletIdleTimeout)
+	}
+
+	cfg.ShutdownTimeout = newCfg.ShutdownTimeout
+
+	log.Info("Configuration reloaded")
+}
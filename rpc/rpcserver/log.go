@@ -23,8 +23,13 @@ import (
 	"github.com/btcsuite/btclog"
 )
 
-// UseLogger sets the logger to use for the gRPC server.
+// log is the logger of the RPC services.  Logging is disabled until
+// UseLogger is called.
+var log = btclog.Disabled
+
+// UseLogger sets the logger to use for the gRPC server and the RPC services.
 func UseLogger(l btclog.Logger) {
+	log = l
 	grpclog.SetLogger(logger{l})
 }
 
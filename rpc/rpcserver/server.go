@@ -8,21 +8,28 @@
 // Full documentation of the API implemented by this package is maintained in a
 // language-agnostic document:
 //
-//   https://github.com/btcsuite/btcwallet/blob/master/rpc/documentation/api.md
+//	https://github.com/btcsuite/btcwallet/blob/master/rpc/documentation/api.md
 //
 // Any API changes must be performed according to the steps listed here:
 //
-//   https://github.com/btcsuite/btcwallet/blob/master/rpc/documentation/serverchanges.md
+//	https://github.com/btcsuite/btcwallet/blob/master/rpc/documentation/serverchanges.md
 package rpcserver
 
 import (
+	"io"
+	"strings"
+	"time"
+
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 
-	"github.com/btcsuite/btcd/chaincfg"
-	"github.com/btcsuite/btcwallet/wallet"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcwallet/waddrmgr"
+	"github.com/tuxcanfly/wltd/logctx"
 	pb "github.com/tuxcanfly/wltd/rpc/walletdrpc"
 	"github.com/tuxcanfly/wltd/walletd"
+	"github.com/tuxcanfly/wltd/walletd/memdb"
 )
 
 // Public API version constants
@@ -40,8 +47,86 @@ type versionServer struct {
 
 // walletDaemonServer provides wallet services for RPC clients.
 type walletDaemonServer struct {
-	walletd   *walletd.WalletDaemon
-	activeNet *chaincfg.Params
+	walletd *walletd.WalletDaemon
+}
+
+// CertificateManager manages the TLS keypair presented by the RPC server.
+type CertificateManager interface {
+	// Regenerate replaces the keypair with a new self-signed keypair and
+	// returns the PEM encoded certificate.
+	Regenerate() ([]byte, error)
+}
+
+// LogLine is a line written to the daemon log.
+type LogLine struct {
+	Subsystem string
+	Level     string
+	Line      string
+
+	// Dropped is the number of lines dropped before this one because the
+	// subscriber did not keep up.
+	Dropped uint64
+}
+
+// LogManager controls the logging of the running daemon.
+type LogManager interface {
+	// LogLevels returns the log level of every subsystem, keyed by
+	// subsystem identifier.
+	LogLevels() map[string]string
+
+	// SetLogLevel sets the log level of a subsystem, or of every
+	// subsystem when subsystem is empty.
+	SetLogLevel(subsystem, level string) error
+
+	// TailLogs subscribes to the log lines of at least minLevel written
+	// by the passed subsystems, or by every subsystem when none are
+	// passed.  The returned channel is closed when cancel is called or
+	// the daemon shuts down.
+	TailLogs(minLevel string, subsystems []string) (lines <-chan LogLine,
+		cancel func(), err error)
+}
+
+// adminServer provides RPC clients with the ability to administer the
+// running daemon.
+type adminServer struct {
+	certs   CertificateManager
+	logs    LogManager
+	walletd *walletd.WalletDaemon
+	audit   *walletd.AuditLog
+}
+
+// defaultAuditQueryLimit is the number of audit records returned when a query
+// does not specify a limit.
+const defaultAuditQueryLimit = 1000
+
+// errAuditFailed is returned to requests whose operation could not be
+// recorded in the audit log.
+var errAuditFailed = grpc.Errorf(codes.Internal, "cannot write audit record")
+
+// audit records the outcome err of the operation of the request in ctx on a
+// wallet in the audit log.  walletUUID may be empty for operations which do not
+// concern a single wallet.  It returns err, or errAuditFailed when the record
+// cannot be written, in which case the request must fail even though the
+// operation succeeded.
+func audit(l *walletd.AuditLog, ctx context.Context, walletUUID string, err error) error {
+	f := logctx.FromContext(ctx)
+	rec := &walletd.AuditRecord{
+		Tenant:     f.Tenant,
+		Admin:      f.Admin,
+		Method:     f.Method,
+		WalletUUID: walletUUID,
+		RequestID:  f.RequestID,
+		Success:    err == nil,
+	}
+	if err != nil {
+		rec.Error = grpc.ErrorDesc(err)
+	}
+	if appendErr := l.Append(rec); appendErr != nil {
+		logctx.Log(ctx, log).Errorf("Cannot write audit record: %v",
+			appendErr)
+		return errAuditFailed
+	}
+	return err
 }
 
 // StartVersionService creates an implementation of the VersionService and
@@ -61,9 +146,8 @@ func (*versionServer) Version(ctx context.Context, req *pb.VersionRequest) (*pb.
 
 // StartWalletDaemonService creates an implementation of the WalletDaemonService and
 // registers it with the gRPC server.
-func StartWalletDaemonService(server *grpc.Server, walletd *walletd.WalletDaemon,
-	activeNet *chaincfg.Params) {
-	service := &walletDaemonServer{walletd, activeNet}
+func StartWalletDaemonService(server *grpc.Server, walletd *walletd.WalletDaemon) {
+	service := &walletDaemonServer{walletd}
 	pb.RegisterWalletDaemonServiceServer(server, service)
 }
 
@@ -71,18 +155,887 @@ func (s *walletDaemonServer) Ping(ctx context.Context, req *pb.PingRequest) (*pb
 	return &pb.PingResponse{}, nil
 }
 
+// Network returns the network of the requested wallet, or the default network
+// of the daemon when no wallet is specified, along with every network served
+// by the daemon.
 func (s *walletDaemonServer) Network(ctx context.Context, req *pb.NetworkRequest) (
 	*pb.NetworkResponse, error) {
 
-	return &pb.NetworkResponse{ActiveNetwork: uint32(s.walletd.ChainParams().Net)}, nil
+	params := s.walletd.ChainParams()
+	if req.WalletUuid != "" {
+		var err error
+		tenant := logctx.FromContext(ctx).Tenant
+		params, err = s.walletd.WalletChainParams(tenant, req.WalletUuid)
+		if err == walletd.ErrWalletNotFound {
+			return nil, grpc.Errorf(codes.NotFound, "%s", err.Error())
+		}
+		if err != nil {
+			return nil, grpc.Errorf(codes.Internal, "%s", err.Error())
+		}
+	}
+
+	nets := s.walletd.Networks()
+	networks := make([]uint32, 0, len(nets))
+	for _, n := range nets {
+		networks = append(networks, uint32(n.Net))
+	}
+
+	return &pb.NetworkResponse{
+		ActiveNetwork: uint32(params.Net),
+		Networks:      networks,
+	}, nil
+}
+
+// walletError converts an error returned by the wallet daemon to a gRPC error.
+func walletError(err error) error {
+	switch err {
+	case walletd.ErrShuttingDown:
+		return grpc.Errorf(codes.Unavailable, "%s", err.Error())
+	case walletd.ErrUnknownNetwork:
+		return grpc.Errorf(codes.InvalidArgument, "%s", err.Error())
+	case walletd.ErrWalletNotFound:
+		return grpc.Errorf(codes.NotFound, "%s", err.Error())
+	case walletd.ErrWalletExists:
+		return grpc.Errorf(codes.AlreadyExists, "%s", err.Error())
+	case walletd.ErrInvalidBackup:
+		return grpc.Errorf(codes.InvalidArgument, "%s", err.Error())
+	case walletd.ErrNoBackupKey, walletd.ErrNoBackupDir,
+		walletd.ErrNotPersistent, memdb.ErrCopyNotSupported:
+		return grpc.Errorf(codes.FailedPrecondition, "%s", err.Error())
+	case walletd.ErrMigrationPending, walletd.ErrNewerSchema:
+		return grpc.Errorf(codes.FailedPrecondition, "%s", err.Error())
+	case walletd.ErrBackupNotFound:
+		return grpc.Errorf(codes.NotFound, "%s", err.Error())
+	case walletd.ErrWalletInUse:
+		return grpc.Errorf(codes.FailedPrecondition, "%s", err.Error())
+	case nil:
+		return nil
+	}
+	switch err.(type) {
+	case *walletd.QuotaExceededError:
+		return grpc.Errorf(codes.ResourceExhausted, "%s", err.Error())
+	case *walletd.InvalidAddressError:
+		return grpc.Errorf(codes.InvalidArgument, "%s", err.Error())
+	case *walletd.InvalidMetadataError:
+		return grpc.Errorf(codes.InvalidArgument, "%s", err.Error())
+	case *walletd.InvalidLabelError:
+		return grpc.Errorf(codes.InvalidArgument, "%s", err.Error())
+	}
+	if waddrmgr.IsError(err, waddrmgr.ErrWrongPassphrase) {
+		return grpc.Errorf(codes.InvalidArgument, "%s", err.Error())
+	}
+	return grpc.Errorf(codes.Internal, "%s", err.Error())
 }
 
 func (s *walletDaemonServer) CreateWallet(ctx context.Context,
 	req *pb.CreateWalletRequest) (*pb.CreateWalletResponse, error) {
-	uuid, err := s.walletd.CreateWallet([]byte(wallet.InsecurePubPassphrase), []byte(req.Pass), nil)
+	tenant := logctx.FromContext(ctx).Tenant
+	uuid, err := s.walletd.CreateWallet(tenant, req.Network,
+		[]byte(req.Pass), nil)
+	err = walletError(err)
+	err = audit(s.walletd.AuditLog(), ctx, uuid, err)
 	if err != nil {
-		// TODO: error codes
-		return nil, grpc.Errorf(3200, "%s", err.Error())
+		return nil, err
 	}
+	logctx.Log(logctx.WithWallet(ctx, uuid), log).Infof("Created wallet %s",
+		uuid)
 	return &pb.CreateWalletResponse{uuid}, nil
 }
+
+// maxCreateWallets is the number of wallets a CreateWallets request may
+// create.
+const maxCreateWallets = 10000
+
+// CreateWallets creates a wallet of the tenant of the request for every
+// passphrase of the request, streaming the result of every wallet as it is
+// finished.  Wallets which could not be created are reported in their
+// response, and do not fail the request.
+func (s *walletDaemonServer) CreateWallets(req *pb.CreateWalletsRequest,
+	svr pb.WalletDaemonService_CreateWalletsServer) error {
+
+	n := len(req.Passphrases)
+	if n == 0 || n > maxCreateWallets {
+		return grpc.Errorf(codes.InvalidArgument,
+			"between 1 and %d wallets may be created", maxCreateWallets)
+	}
+
+	ctx := svr.Context()
+	tenant := logctx.FromContext(ctx).Tenant
+	err := s.walletd.CreateWallets(tenant, req.Network, req.Passphrases,
+		func(r *walletd.CreateResult) error {
+			err := audit(s.walletd.AuditLog(), ctx, r.UUID,
+				walletError(r.Err))
+			if err == errAuditFailed {
+				return err
+			}
+			resp := &pb.CreateWalletsResponse{
+				Index: uint32(r.Index),
+				Uuid:  r.UUID,
+			}
+			if err != nil {
+				resp.ErrorCode = uint32(grpc.Code(err))
+				resp.Error = grpc.ErrorDesc(err)
+			} else {
+				logctx.Log(logctx.WithWallet(ctx, r.UUID),
+					log).Infof("Created wallet %s", r.UUID)
+			}
+			return svr.Send(resp)
+		})
+	if err == walletd.ErrUnknownNetwork {
+		return walletError(err)
+	}
+	// Any other error is the one of sending a response.
+	return err
+}
+
+// OpenWallet opens a wallet of the tenant of the request, such as one closed
+// after being idle.
+func (s *walletDaemonServer) OpenWallet(ctx context.Context,
+	req *pb.OpenWalletRequest) (*pb.OpenWalletResponse, error) {
+
+	tenant := logctx.FromContext(ctx).Tenant
+	err := walletError(s.walletd.OpenWallet(tenant, req.WalletUuid))
+	err = audit(s.walletd.AuditLog(), ctx, req.WalletUuid, err)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.OpenWalletResponse{}, nil
+}
+
+// SetWalletAutoload changes whether a wallet of the tenant of the request is
+// opened when the daemon is started.
+func (s *walletDaemonServer) SetWalletAutoload(ctx context.Context,
+	req *pb.SetWalletAutoloadRequest) (*pb.SetWalletAutoloadResponse, error) {
+
+	tenant := logctx.FromContext(ctx).Tenant
+	err := walletError(s.walletd.SetAutoload(tenant, req.WalletUuid,
+		req.Autoload))
+	err = audit(s.walletd.AuditLog(), ctx, req.WalletUuid, err)
+	if err != nil {
+		return nil, err
+	}
+	logctx.Log(logctx.WithWallet(ctx, req.WalletUuid), log).Infof(
+		"Set autoload of wallet %s to %v", req.WalletUuid, req.Autoload)
+	return &pb.SetWalletAutoloadResponse{}, nil
+}
+
+// defaultListTransactionsCount is the number of transactions returned when a
+// request does not specify a count.
+const defaultListTransactionsCount = 10
+
+// marshalWalletInfo converts a wallet description to its RPC message.
+func marshalWalletInfo(info *walletd.WalletInfo) *pb.WalletInfo {
+	return &pb.WalletInfo{
+		Uuid:     info.UUID,
+		Network:  info.Net,
+		Tenant:   info.Tenant,
+		Created:  info.Created.Unix(),
+		LastUsed: info.LastUsed.Unix(),
+		Autoload: info.Autoload,
+		Open:     info.Open,
+		Name:     info.Name,
+		Tags:     info.Tags,
+		Metadata: info.Metadata,
+	}
+}
+
+// ListWallets lists the wallets of the tenant of the request.
+func (s *walletDaemonServer) ListWallets(ctx context.Context,
+	req *pb.ListWalletsRequest) (*pb.ListWalletsResponse, error) {
+
+	tenant := logctx.FromContext(ctx).Tenant
+	infos, err := s.walletd.ListWallets(tenant, &walletd.WalletFilter{
+		Tags:     req.Tags,
+		Metadata: req.Metadata,
+	})
+	if err != nil {
+		return nil, walletError(err)
+	}
+	wallets := make([]*pb.WalletInfo, 0, len(infos))
+	for _, info := range infos {
+		wallets = append(wallets, marshalWalletInfo(info))
+	}
+	return &pb.ListWalletsResponse{Wallets: wallets}, nil
+}
+
+// GetWalletInfo describes a wallet of the tenant of the request.
+func (s *walletDaemonServer) GetWalletInfo(ctx context.Context,
+	req *pb.GetWalletInfoRequest) (*pb.GetWalletInfoResponse, error) {
+
+	tenant := logctx.FromContext(ctx).Tenant
+	info, err := s.walletd.WalletInfo(tenant, req.WalletUuid)
+	if err != nil {
+		return nil, walletError(err)
+	}
+	return &pb.GetWalletInfoResponse{Wallet: marshalWalletInfo(info)}, nil
+}
+
+// UpdateWalletMetadata changes the display name, tags and metadata of a wallet
+// of the tenant of the request.
+func (s *walletDaemonServer) UpdateWalletMetadata(ctx context.Context,
+	req *pb.UpdateWalletMetadataRequest) (*pb.UpdateWalletMetadataResponse, error) {
+
+	update := &walletd.MetadataUpdate{
+		AddTags:        req.AddTags,
+		RemoveTags:     req.RemoveTags,
+		SetMetadata:    req.SetMetadata,
+		DeleteMetadata: req.DeleteMetadata,
+	}
+	if req.SetName {
+		update.Name = &req.Name
+	}
+	tenant := logctx.FromContext(ctx).Tenant
+	info, err := s.walletd.UpdateWalletMetadata(tenant, req.WalletUuid,
+		update)
+	err = walletError(err)
+	err = audit(s.walletd.AuditLog(), ctx, req.WalletUuid, err)
+	if err != nil {
+		return nil, err
+	}
+	logctx.Log(logctx.WithWallet(ctx, req.WalletUuid), log).Infof(
+		"Updated metadata of wallet %s", req.WalletUuid)
+	return &pb.UpdateWalletMetadataResponse{
+		Wallet: marshalWalletInfo(info),
+	}, nil
+}
+
+// DeleteWallet closes and removes a wallet of the tenant of the request.
+func (s *walletDaemonServer) DeleteWallet(ctx context.Context,
+	req *pb.DeleteWalletRequest) (*pb.DeleteWalletResponse, error) {
+
+	tenant := logctx.FromContext(ctx).Tenant
+	err := walletError(s.walletd.DeleteWallet(tenant, req.WalletUuid))
+	err = audit(s.walletd.AuditLog(), ctx, req.WalletUuid, err)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.DeleteWalletResponse{}, nil
+}
+
+// Balance returns the balance of a wallet of the tenant of the request.
+func (s *walletDaemonServer) Balance(ctx context.Context,
+	req *pb.BalanceRequest) (*pb.BalanceResponse, error) {
+
+	tenant := logctx.FromContext(ctx).Tenant
+	balance, err := s.walletd.Balance(tenant, req.WalletUuid,
+		req.RequiredConfirmations)
+	if err != nil {
+		return nil, walletError(err)
+	}
+	return &pb.BalanceResponse{Balance: int64(balance)}, nil
+}
+
+// ListTransactions lists the most recent transactions of a wallet of the
+// tenant of the request.
+func (s *walletDaemonServer) ListTransactions(ctx context.Context,
+	req *pb.ListTransactionsRequest) (*pb.ListTransactionsResponse, error) {
+
+	count := int(req.Count)
+	if count == 0 {
+		count = defaultListTransactionsCount
+	}
+	tenant := logctx.FromContext(ctx).Tenant
+	results, err := s.walletd.ListTransactions(tenant, req.WalletUuid,
+		int(req.From), count)
+	if err != nil {
+		return nil, walletError(err)
+	}
+
+	txs := make([]*pb.TransactionSummary, 0, len(results))
+	for _, r := range results {
+		amount, err := btcutil.NewAmount(r.Amount)
+		if err != nil {
+			return nil, walletError(err)
+		}
+		tx := &pb.TransactionSummary{
+			Txid:          r.TxID,
+			Vout:          r.Vout,
+			Address:       r.Address,
+			Category:      r.Category,
+			Amount:        int64(amount),
+			Confirmations: r.Confirmations,
+			BlockHash:     r.BlockHash,
+			Timestamp:     r.Time,
+		}
+		if r.Fee != nil {
+			fee, err := btcutil.NewAmount(*r.Fee)
+			if err != nil {
+				return nil, walletError(err)
+			}
+			tx.Fee = int64(fee)
+		}
+		txs = append(txs, tx)
+	}
+	return &pb.ListTransactionsResponse{Transactions: txs}, nil
+}
+
+// SendTransaction sends a transaction from a wallet of the tenant of the
+// request.
+func (s *walletDaemonServer) SendTransaction(ctx context.Context,
+	req *pb.SendTransactionRequest) (*pb.SendTransactionResponse, error) {
+
+	if len(req.Outputs) == 0 {
+		return nil, grpc.Errorf(codes.InvalidArgument,
+			"transaction has no outputs")
+	}
+	outputs := make([]walletd.Output, 0, len(req.Outputs))
+	for _, o := range req.Outputs {
+		if o.Amount <= 0 {
+			return nil, grpc.Errorf(codes.InvalidArgument,
+				"output amounts must be positive")
+		}
+		outputs = append(outputs, walletd.Output{
+			Address: o.Address,
+			Amount:  btcutil.Amount(o.Amount),
+		})
+	}
+
+	tenant := logctx.FromContext(ctx).Tenant
+	hash, err := s.walletd.SendOutputs(tenant, req.WalletUuid,
+		req.Passphrase, outputs, req.Account, req.RequiredConfirmations)
+	err = walletError(err)
+	err = audit(s.walletd.AuditLog(), ctx, req.WalletUuid, err)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.SendTransactionResponse{Txid: hash.String()}, nil
+}
+
+// maxSetLabels is the number of labels a SetLabels request may set.
+const maxSetLabels = 1000
+
+// marshalLabels converts wallet labels to their RPC messages.
+func marshalLabels(labels []walletd.Label) []*pb.Label {
+	msgs := make([]*pb.Label, 0, len(labels))
+	for _, l := range labels {
+		msgs = append(msgs, &pb.Label{
+			Type:  l.Type,
+			Ref:   l.Ref,
+			Label: l.Label,
+		})
+	}
+	return msgs
+}
+
+// SetLabels sets or removes labels of a wallet of the tenant of the request.
+func (s *walletDaemonServer) SetLabels(ctx context.Context,
+	req *pb.SetLabelsRequest) (*pb.SetLabelsResponse, error) {
+
+	if len(req.Labels) == 0 || len(req.Labels) > maxSetLabels {
+		return nil, grpc.Errorf(codes.InvalidArgument,
+			"between 1 and %d labels may be set", maxSetLabels)
+	}
+	labels := make([]walletd.Label, 0, len(req.Labels))
+	for _, l := range req.Labels {
+		labels = append(labels, walletd.Label{
+			Type:  l.Type,
+			Ref:   l.Ref,
+			Label: l.Label,
+		})
+	}
+	tenant := logctx.FromContext(ctx).Tenant
+	err := walletError(s.walletd.SetLabels(tenant, req.WalletUuid, labels))
+	err = audit(s.walletd.AuditLog(), ctx, req.WalletUuid, err)
+	if err != nil {
+		return nil, err
+	}
+	logctx.Log(logctx.WithWallet(ctx, req.WalletUuid), log).Infof(
+		"Set %d labels of wallet %s", len(labels), req.WalletUuid)
+	return &pb.SetLabelsResponse{}, nil
+}
+
+// GetLabels returns labels of a wallet of the tenant of the request.
+func (s *walletDaemonServer) GetLabels(ctx context.Context,
+	req *pb.GetLabelsRequest) (*pb.GetLabelsResponse, error) {
+
+	tenant := logctx.FromContext(ctx).Tenant
+	labels, err := s.walletd.Labels(tenant, req.WalletUuid, req.Type,
+		req.Refs)
+	if err != nil {
+		return nil, walletError(err)
+	}
+	return &pb.GetLabelsResponse{Labels: marshalLabels(labels)}, nil
+}
+
+// labelExportWriter sends the data written to it as ExportLabels responses.
+type labelExportWriter struct {
+	svr pb.WalletDaemonService_ExportLabelsServer
+}
+
+func (w labelExportWriter) Write(p []byte) (int, error) {
+	// The message is serialized by Send, so p is not retained.
+	if err := w.svr.Send(&pb.ExportLabelsResponse{Data: p}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// ExportLabels streams the labels of a wallet of the tenant of the request in
+// the BIP0329 format.
+func (s *walletDaemonServer) ExportLabels(req *pb.ExportLabelsRequest,
+	svr pb.WalletDaemonService_ExportLabelsServer) error {
+
+	ctx := svr.Context()
+	tenant := logctx.FromContext(ctx).Tenant
+	n, err := s.walletd.ExportLabels(tenant, req.WalletUuid,
+		labelExportWriter{svr})
+	err = walletError(err)
+	err = audit(s.walletd.AuditLog(), ctx, req.WalletUuid, err)
+	if err != nil {
+		return err
+	}
+	logctx.Log(logctx.WithWallet(ctx, req.WalletUuid), log).Infof(
+		"Exported %d labels of wallet %s", n, req.WalletUuid)
+	return nil
+}
+
+// labelImportReader reads the data of ImportLabels requests.  The wallet is
+// read from the first request.
+type labelImportReader struct {
+	svr   pb.WalletDaemonService_ImportLabelsServer
+	first *pb.ImportLabelsRequest
+	buf   []byte
+}
+
+func (r *labelImportReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.first != nil {
+			r.buf, r.first = r.first.Data, nil
+			continue
+		}
+		req, err := r.svr.Recv()
+		if err != nil {
+			return 0, err
+		}
+		r.buf = req.Data
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// ImportLabels sets the labels of a BIP0329 export streamed by the client on
+// a wallet of the tenant of the request.
+func (s *walletDaemonServer) ImportLabels(svr pb.WalletDaemonService_ImportLabelsServer) error {
+	ctx := svr.Context()
+	first, err := svr.Recv()
+	if err == io.EOF {
+		return grpc.Errorf(codes.InvalidArgument, "no labels sent")
+	}
+	if err != nil {
+		return err
+	}
+
+	tenant := logctx.FromContext(ctx).Tenant
+	result, err := s.walletd.ImportLabels(tenant, first.WalletUuid,
+		&labelImportReader{svr: svr, first: first})
+	err = walletError(err)
+	err = audit(s.walletd.AuditLog(), ctx, first.WalletUuid, err)
+	if err != nil {
+		return err
+	}
+	logctx.Log(logctx.WithWallet(ctx, first.WalletUuid), log).Infof(
+		"Imported %d labels of wallet %s, skipped %d records",
+		result.Imported, first.WalletUuid, result.Skipped)
+	return svr.SendAndClose(&pb.ImportLabelsResponse{
+		Imported: uint32(result.Imported),
+		Skipped:  uint32(result.Skipped),
+	})
+}
+
+// WalletEvents streams the events of the wallets of the tenant of the request,
+// or of a single wallet, until the client cancels the stream or the daemon
+// shuts down.
+func (s *walletDaemonServer) WalletEvents(req *pb.WalletEventsRequest,
+	svr pb.WalletDaemonService_WalletEventsServer) error {
+
+	tenant := logctx.FromContext(svr.Context()).Tenant
+	events, cancel, err := s.walletd.SubscribeEvents(tenant, req.WalletUuid)
+	if err != nil {
+		return walletError(err)
+	}
+	defer cancel()
+
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return grpc.Errorf(codes.Unavailable,
+					"server is shutting down")
+			}
+			err := svr.Send(&pb.WalletEventsResponse{
+				Kind:       e.Kind,
+				Timestamp:  e.Time.Unix(),
+				WalletUuid: e.WalletUUID,
+				Network:    e.Net,
+				Hash:       e.Hash,
+				Height:     e.Height,
+				Dropped:    e.Dropped,
+			})
+			if err != nil {
+				return err
+			}
+		case <-svr.Context().Done():
+			return nil
+		}
+	}
+}
+
+// StartAdminService creates an implementation of the AdminService and
+// registers it with the gRPC server.
+func StartAdminService(server *grpc.Server, certs CertificateManager,
+	logs LogManager, walletd *walletd.WalletDaemon) {
+
+	service := &adminServer{certs, logs, walletd, walletd.AuditLog()}
+	pb.RegisterAdminServiceServer(server, service)
+}
+
+// RegenerateCertificate replaces the TLS keypair of the RPC server with a new
+// self-signed keypair and returns the new certificate.
+func (s *adminServer) RegenerateCertificate(ctx context.Context,
+	req *pb.RegenerateCertificateRequest) (*pb.RegenerateCertificateResponse, error) {
+
+	cert, err := s.certs.Regenerate()
+	if err != nil {
+		err = grpc.Errorf(codes.Internal, "%s", err.Error())
+	}
+	err = audit(s.audit, ctx, "", err)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.RegenerateCertificateResponse{Certificate: cert}, nil
+}
+
+// GetLogLevels returns the log level of every subsystem.
+func (s *adminServer) GetLogLevels(ctx context.Context,
+	req *pb.GetLogLevelsRequest) (*pb.GetLogLevelsResponse, error) {
+
+	return &pb.GetLogLevelsResponse{Levels: s.logs.LogLevels()}, nil
+}
+
+// SetLogLevel changes the log level of a subsystem, or of every subsystem when
+// none is specified.
+func (s *adminServer) SetLogLevel(ctx context.Context,
+	req *pb.SetLogLevelRequest) (*pb.SetLogLevelResponse, error) {
+
+	err := s.logs.SetLogLevel(req.Subsystem, req.Level)
+	if err != nil {
+		err = grpc.Errorf(codes.InvalidArgument, "%s", err.Error())
+	}
+	err = audit(s.audit, ctx, "", err)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.SetLogLevelResponse{}, nil
+}
+
+// TailLogs streams the log lines matching the request until the client
+// cancels the stream or the daemon shuts down.
+func (s *adminServer) TailLogs(req *pb.TailLogsRequest,
+	svr pb.AdminService_TailLogsServer) error {
+
+	lines, cancel, err := s.logs.TailLogs(req.MinLevel, req.Subsystems)
+	if err != nil {
+		return grpc.Errorf(codes.InvalidArgument, "%s", err.Error())
+	}
+	defer cancel()
+
+	for {
+		select {
+		case l, ok := <-lines:
+			if !ok {
+				return grpc.Errorf(codes.Unavailable,
+					"server is shutting down")
+			}
+			err := svr.Send(&pb.TailLogsResponse{
+				Subsystem: l.Subsystem,
+				Level:     l.Level,
+				Line:      l.Line,
+				Dropped:   l.Dropped,
+			})
+			if err != nil {
+				return err
+			}
+		case <-svr.Context().Done():
+			return nil
+		}
+	}
+}
+
+// QueryAuditLog returns the most recent audit records matching the request.
+func (s *adminServer) QueryAuditLog(ctx context.Context,
+	req *pb.QueryAuditLogRequest) (*pb.QueryAuditLogResponse, error) {
+
+	filter := &walletd.AuditFilter{
+		Tenant:     req.Tenant,
+		Method:     req.Method,
+		WalletUUID: req.WalletUuid,
+	}
+	if req.Since != 0 {
+		filter.Since = time.Unix(req.Since, 0)
+	}
+	if req.Until != 0 {
+		filter.Until = time.Unix(req.Until, 0)
+	}
+	limit := int(req.Limit)
+	if limit == 0 {
+		limit = defaultAuditQueryLimit
+	}
+
+	records, err := s.audit.Query(filter, limit)
+	if err != nil {
+		return nil, grpc.Errorf(codes.Internal, "%s", err.Error())
+	}
+
+	resp := &pb.QueryAuditLogResponse{
+		Records: make([]*pb.AuditRecord, 0, len(records)),
+	}
+	for _, r := range records {
+		var timestamp int64
+		if t, err := time.Parse(time.RFC3339Nano, r.Time); err == nil {
+			timestamp = t.UnixNano()
+		}
+		resp.Records = append(resp.Records, &pb.AuditRecord{
+			Sequence:   r.Sequence,
+			Timestamp:  timestamp,
+			Tenant:     r.Tenant,
+			Method:     r.Method,
+			WalletUuid: r.WalletUUID,
+			RequestId:  r.RequestID,
+			Success:    r.Success,
+			Error:      r.Error,
+			PrevHash:   r.PrevHash,
+			Hash:       r.Hash,
+		})
+	}
+	return resp, nil
+}
+
+// SetTenantQuota replaces the quota of a tenant.
+func (s *adminServer) SetTenantQuota(ctx context.Context,
+	req *pb.SetTenantQuotaRequest) (*pb.SetTenantQuotaResponse, error) {
+
+	q := &walletd.Quota{}
+	if req.Quota != nil {
+		q.MaxWallets = req.Quota.MaxWallets
+		q.MaxDiskBytes = req.Quota.MaxDiskBytes
+		q.MaxOpenWallets = req.Quota.MaxOpenWallets
+	}
+	err := s.walletd.SetQuota(req.Tenant, q)
+	switch err {
+	case walletd.ErrNoTenant:
+		err = grpc.Errorf(codes.InvalidArgument, "%s", err.Error())
+	case nil:
+	default:
+		err = grpc.Errorf(codes.Internal, "%s", err.Error())
+	}
+	err = audit(s.audit, ctx, "", err)
+	if err != nil {
+		return nil, err
+	}
+	logctx.Log(ctx, log).Infof("Set quota of tenant %s to %d wallets, "+
+		"%d disk bytes and %d open wallets", req.Tenant, q.MaxWallets,
+		q.MaxDiskBytes, q.MaxOpenWallets)
+	return &pb.SetTenantQuotaResponse{}, nil
+}
+
+// GetTenantUsage reports the quota and usage of a tenant, or of every tenant
+// when none is specified.
+func (s *adminServer) GetTenantUsage(ctx context.Context,
+	req *pb.GetTenantUsageRequest) (*pb.GetTenantUsageResponse, error) {
+
+	tenants := []string{req.Tenant}
+	if req.Tenant == "" {
+		var err error
+		tenants, err = s.walletd.Tenants()
+		if err != nil {
+			return nil, grpc.Errorf(codes.Internal, "%s", err.Error())
+		}
+	}
+
+	resp := &pb.GetTenantUsageResponse{
+		Tenants: make([]*pb.TenantUsage, 0, len(tenants)),
+	}
+	for _, tenant := range tenants {
+		q, u, err := s.walletd.TenantUsage(tenant)
+		if err != nil {
+			return nil, grpc.Errorf(codes.Internal, "%s", err.Error())
+		}
+		resp.Tenants = append(resp.Tenants, &pb.TenantUsage{
+			Tenant: tenant,
+			Quota: &pb.TenantQuota{
+				MaxWallets:     q.MaxWallets,
+				MaxDiskBytes:   q.MaxDiskBytes,
+				MaxOpenWallets: q.MaxOpenWallets,
+			},
+			Wallets:     u.Wallets,
+			DiskBytes:   u.DiskBytes,
+			OpenWallets: u.OpenWallets,
+		})
+	}
+	return resp, nil
+}
+
+// backupStreamWriter sends the data written to it as BackupWallet responses.
+type backupStreamWriter struct {
+	svr pb.AdminService_BackupWalletServer
+}
+
+func (w backupStreamWriter) Write(p []byte) (int, error) {
+	// The message is serialized by Send, so p is not retained.
+	if err := w.svr.Send(&pb.BackupWalletResponse{Data: p}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// BackupWallet streams an encrypted backup of a wallet.
+func (s *adminServer) BackupWallet(req *pb.BackupWalletRequest,
+	svr pb.AdminService_BackupWalletServer) error {
+
+	ctx := svr.Context()
+	err := walletError(s.walletd.BackupWallet(req.WalletUuid,
+		backupStreamWriter{svr}))
+	err = audit(s.audit, ctx, req.WalletUuid, err)
+	if err != nil {
+		return err
+	}
+	logctx.Log(logctx.WithWallet(ctx, req.WalletUuid), log).Infof(
+		"Backed up wallet %s", req.WalletUuid)
+	return nil
+}
+
+// restoreStreamReader reads the data of RestoreWallet requests.  The options
+// of the restore are read from the first request.
+type restoreStreamReader struct {
+	svr   pb.AdminService_RestoreWalletServer
+	first *pb.RestoreWalletRequest
+	buf   []byte
+}
+
+func (r *restoreStreamReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.first != nil {
+			r.buf, r.first = r.first.Data, nil
+			continue
+		}
+		req, err := r.svr.Recv()
+		if err != nil {
+			return 0, err
+		}
+		r.buf = req.Data
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// RestoreWallet recreates a wallet from a backup streamed by the client.
+func (s *adminServer) RestoreWallet(svr pb.AdminService_RestoreWalletServer) error {
+	ctx := svr.Context()
+	first, err := svr.Recv()
+	if err == io.EOF {
+		return grpc.Errorf(codes.InvalidArgument, "no backup sent")
+	}
+	if err != nil {
+		return err
+	}
+	if first.Tenant == "" {
+		return grpc.Errorf(codes.InvalidArgument, "no tenant specified")
+	}
+
+	uuid, err := s.walletd.RestoreWallet(first.Tenant,
+		&restoreStreamReader{svr: svr, first: first}, first.NewUuid)
+	err = walletError(err)
+	err = audit(s.audit, ctx, uuid, err)
+	if err != nil {
+		return err
+	}
+	logctx.Log(logctx.WithWallet(ctx, uuid), log).Infof("Restored wallet %s",
+		uuid)
+	return svr.SendAndClose(&pb.RestoreWalletResponse{WalletUuid: uuid})
+}
+
+// ListBackups lists the scheduled backups of the daemon.
+func (s *adminServer) ListBackups(ctx context.Context,
+	req *pb.ListBackupsRequest) (*pb.ListBackupsResponse, error) {
+
+	backups, err := s.walletd.ListBackups()
+	if err != nil {
+		return nil, walletError(err)
+	}
+	resp := &pb.ListBackupsResponse{
+		Backups: make([]*pb.BackupInfo, 0, len(backups)),
+	}
+	for _, b := range backups {
+		resp.Backups = append(resp.Backups, &pb.BackupInfo{
+			Name:      b.Name,
+			Timestamp: b.Time.Unix(),
+			Files:     uint32(b.Files),
+			Size:      b.Size,
+		})
+	}
+	return resp, nil
+}
+
+// VerifyBackup checks the files of a backup against its manifest.
+func (s *adminServer) VerifyBackup(ctx context.Context,
+	req *pb.VerifyBackupRequest) (*pb.VerifyBackupResponse, error) {
+
+	problems, err := s.walletd.VerifyBackup(req.Name)
+	if err != nil {
+		return nil, walletError(err)
+	}
+	if len(problems) != 0 {
+		logctx.Log(ctx, log).Warnf("Backup %s failed verification: %s",
+			req.Name, strings.Join(problems, ", "))
+	}
+	return &pb.VerifyBackupResponse{Problems: problems}, nil
+}
+
+// walletCheckResponse converts the result of checking a wallet to its RPC
+// message.
+func walletCheckResponse(c *walletd.WalletCheck) *pb.WalletCheck {
+	return &pb.WalletCheck{
+		WalletUuid:     c.UUID,
+		Network:        c.Net,
+		Problems:       c.Problems,
+		Accounts:       uint32(c.Accounts),
+		Addresses:      uint32(c.Addresses),
+		UnspentOutputs: uint32(c.Unspent),
+		Balance:        c.Balance,
+	}
+}
+
+// CheckWallet checks a wallet, or the registry and every wallet when none is
+// specified.
+func (s *adminServer) CheckWallet(ctx context.Context,
+	req *pb.CheckWalletRequest) (*pb.CheckWalletResponse, error) {
+
+	if req.WalletUuid != "" {
+		c, err := s.walletd.CheckWallet(req.WalletUuid)
+		if err != nil {
+			return nil, walletError(err)
+		}
+		return &pb.CheckWalletResponse{
+			Wallets: []*pb.WalletCheck{walletCheckResponse(c)},
+		}, nil
+	}
+
+	report, err := s.walletd.CheckWallets()
+	if err != nil {
+		return nil, walletError(err)
+	}
+	resp := &pb.CheckWalletResponse{
+		Wallets:             make([]*pb.WalletCheck, 0, len(report.Wallets)),
+		UnregisteredWallets: report.Unregistered,
+		MissingWallets:      report.Missing,
+	}
+	for _, c := range report.Wallets {
+		resp.Wallets = append(resp.Wallets, walletCheckResponse(c))
+	}
+	if !report.OK() {
+		logctx.Log(ctx, log).Warnf("Wallet check found problems")
+	}
+	return resp, nil
+}
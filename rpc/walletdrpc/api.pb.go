@@ -17,6 +17,69 @@ It has these top-level messages:
 	NetworkResponse
 	CreateWalletRequest
 	CreateWalletResponse
+	CreateWalletsRequest
+	CreateWalletsResponse
+	OpenWalletRequest
+	OpenWalletResponse
+	SetWalletAutoloadRequest
+	SetWalletAutoloadResponse
+	WalletInfo
+	ListWalletsRequest
+	ListWalletsResponse
+	UpdateWalletMetadataRequest
+	UpdateWalletMetadataResponse
+	GetWalletInfoRequest
+	GetWalletInfoResponse
+	DeleteWalletRequest
+	DeleteWalletResponse
+	BalanceRequest
+	BalanceResponse
+	TransactionSummary
+	ListTransactionsRequest
+	ListTransactionsResponse
+	TransactionOutput
+	SendTransactionRequest
+	SendTransactionResponse
+	Label
+	SetLabelsRequest
+	SetLabelsResponse
+	GetLabelsRequest
+	GetLabelsResponse
+	ExportLabelsRequest
+	ExportLabelsResponse
+	ImportLabelsRequest
+	ImportLabelsResponse
+	WalletEventsRequest
+	WalletEventsResponse
+	RegenerateCertificateRequest
+	RegenerateCertificateResponse
+	GetLogLevelsRequest
+	GetLogLevelsResponse
+	SetLogLevelRequest
+	SetLogLevelResponse
+	TailLogsRequest
+	TailLogsResponse
+	AuditRecord
+	QueryAuditLogRequest
+	QueryAuditLogResponse
+	TenantQuota
+	TenantUsage
+	SetTenantQuotaRequest
+	SetTenantQuotaResponse
+	GetTenantUsageRequest
+	GetTenantUsageResponse
+	BackupWalletRequest
+	BackupWalletResponse
+	RestoreWalletRequest
+	RestoreWalletResponse
+	BackupInfo
+	ListBackupsRequest
+	ListBackupsResponse
+	VerifyBackupRequest
+	VerifyBackupResponse
+	WalletCheck
+	CheckWalletRequest
+	CheckWalletResponse
 */
 package walletdrpc
 
@@ -121,6 +184,9 @@ func (*PingResponse) ProtoMessage()               {}
 func (*PingResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
 
 type NetworkRequest struct {
+	// Optional wallet to query the network of.  The default network of
+	// the daemon is returned when empty.
+	WalletUuid string `protobuf:"bytes,1,opt,name=wallet_uuid,json=walletUuid" json:"wallet_uuid,omitempty"`
 }
 
 func (m *NetworkRequest) Reset()                    { *m = NetworkRequest{} }
@@ -128,8 +194,16 @@ func (m *NetworkRequest) String() string            { return proto.CompactTextSt
 func (*NetworkRequest) ProtoMessage()               {}
 func (*NetworkRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
 
+func (m *NetworkRequest) GetWalletUuid() string {
+	if m != nil {
+		return m.WalletUuid
+	}
+	return ""
+}
+
 type NetworkResponse struct {
-	ActiveNetwork uint32 `protobuf:"varint,1,opt,name=active_network,json=activeNetwork" json:"active_network,omitempty"`
+	ActiveNetwork uint32   `protobuf:"varint,1,opt,name=active_network,json=activeNetwork" json:"active_network,omitempty"`
+	Networks      []uint32 `protobuf:"varint,2,rep,packed,name=networks" json:"networks,omitempty"`
 }
 
 func (m *NetworkResponse) Reset()                    { *m = NetworkResponse{} }
@@ -137,287 +211,3417 @@ func (m *NetworkResponse) String() string            { return proto.CompactTextS
 func (*NetworkResponse) ProtoMessage()               {}
 func (*NetworkResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
 
-func (m *NetworkResponse) GetActiveNetwork() uint32 {
-	if m != nil {
-		return m.ActiveNetwork
+func (m *NetworkResponse) GetActiveNetwork() uint32 {
+	if m != nil {
+		return m.ActiveNetwork
+	}
+	return 0
+}
+
+func (m *NetworkResponse) GetNetworks() []uint32 {
+	if m != nil {
+		return m.Networks
+	}
+	return nil
+}
+
+type CreateWalletRequest struct {
+	Pass string `protobuf:"bytes,1,opt,name=pass" json:"pass,omitempty"`
+	// chaincfg name of the wallet network, such as "testnet3".  The default
+	// network of the daemon is used when empty.
+	Network string `protobuf:"bytes,2,opt,name=network" json:"network,omitempty"`
+}
+
+func (m *CreateWalletRequest) Reset()                    { *m = CreateWalletRequest{} }
+func (m *CreateWalletRequest) String() string            { return proto.CompactTextString(m) }
+func (*CreateWalletRequest) ProtoMessage()               {}
+func (*CreateWalletRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *CreateWalletRequest) GetPass() string {
+	if m != nil {
+		return m.Pass
+	}
+	return ""
+}
+
+func (m *CreateWalletRequest) GetNetwork() string {
+	if m != nil {
+		return m.Network
+	}
+	return ""
+}
+
+type CreateWalletResponse struct {
+	Uuid string `protobuf:"bytes,1,opt,name=uuid" json:"uuid,omitempty"`
+}
+
+func (m *CreateWalletResponse) Reset()                    { *m = CreateWalletResponse{} }
+func (m *CreateWalletResponse) String() string            { return proto.CompactTextString(m) }
+func (*CreateWalletResponse) ProtoMessage()               {}
+func (*CreateWalletResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+func (m *CreateWalletResponse) GetUuid() string {
+	if m != nil {
+		return m.Uuid
+	}
+	return ""
+}
+
+type CreateWalletsRequest struct {
+	// chaincfg name of the network of the wallets.  The default network of
+	// the daemon is used when empty.
+	Network string `protobuf:"bytes,1,opt,name=network" json:"network,omitempty"`
+	// Private passphrases of the wallets, one wallet being created for
+	// every passphrase.
+	Passphrases [][]byte `protobuf:"bytes,2,rep,name=passphrases,proto3" json:"passphrases,omitempty"`
+}
+
+func (m *CreateWalletsRequest) Reset()                    { *m = CreateWalletsRequest{} }
+func (m *CreateWalletsRequest) String() string            { return proto.CompactTextString(m) }
+func (*CreateWalletsRequest) ProtoMessage()               {}
+func (*CreateWalletsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+func (m *CreateWalletsRequest) GetNetwork() string {
+	if m != nil {
+		return m.Network
+	}
+	return ""
+}
+
+func (m *CreateWalletsRequest) GetPassphrases() [][]byte {
+	if m != nil {
+		return m.Passphrases
+	}
+	return nil
+}
+
+// A CreateWalletsResponse is streamed for every wallet of the request, in the
+// order they are finished.
+type CreateWalletsResponse struct {
+	// Position of the passphrase of the wallet in the request.
+	Index uint32 `protobuf:"varint,1,opt,name=index" json:"index,omitempty"`
+	// Empty when the wallet could not be created.
+	Uuid string `protobuf:"bytes,2,opt,name=uuid" json:"uuid,omitempty"`
+	// gRPC status code and description of the failure to create the
+	// wallet, zero and empty when it was created.
+	ErrorCode uint32 `protobuf:"varint,3,opt,name=error_code,json=errorCode" json:"error_code,omitempty"`
+	Error     string `protobuf:"bytes,4,opt,name=error" json:"error,omitempty"`
+}
+
+func (m *CreateWalletsResponse) Reset()                    { *m = CreateWalletsResponse{} }
+func (m *CreateWalletsResponse) String() string            { return proto.CompactTextString(m) }
+func (*CreateWalletsResponse) ProtoMessage()               {}
+func (*CreateWalletsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+func (m *CreateWalletsResponse) GetIndex() uint32 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *CreateWalletsResponse) GetUuid() string {
+	if m != nil {
+		return m.Uuid
+	}
+	return ""
+}
+
+func (m *CreateWalletsResponse) GetErrorCode() uint32 {
+	if m != nil {
+		return m.ErrorCode
+	}
+	return 0
+}
+
+func (m *CreateWalletsResponse) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type OpenWalletRequest struct {
+	WalletUuid string `protobuf:"bytes,1,opt,name=wallet_uuid,json=walletUuid" json:"wallet_uuid,omitempty"`
+}
+
+func (m *OpenWalletRequest) Reset()                    { *m = OpenWalletRequest{} }
+func (m *OpenWalletRequest) String() string            { return proto.CompactTextString(m) }
+func (*OpenWalletRequest) ProtoMessage()               {}
+func (*OpenWalletRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+
+func (m *OpenWalletRequest) GetWalletUuid() string {
+	if m != nil {
+		return m.WalletUuid
+	}
+	return ""
+}
+
+type OpenWalletResponse struct {
+}
+
+func (m *OpenWalletResponse) Reset()                    { *m = OpenWalletResponse{} }
+func (m *OpenWalletResponse) String() string            { return proto.CompactTextString(m) }
+func (*OpenWalletResponse) ProtoMessage()               {}
+func (*OpenWalletResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+
+type SetWalletAutoloadRequest struct {
+	WalletUuid string `protobuf:"bytes,1,opt,name=wallet_uuid,json=walletUuid" json:"wallet_uuid,omitempty"`
+	// Whether the wallet is opened when the daemon is started.
+	Autoload bool `protobuf:"varint,2,opt,name=autoload" json:"autoload,omitempty"`
+}
+
+func (m *SetWalletAutoloadRequest) Reset()                    { *m = SetWalletAutoloadRequest{} }
+func (m *SetWalletAutoloadRequest) String() string            { return proto.CompactTextString(m) }
+func (*SetWalletAutoloadRequest) ProtoMessage()               {}
+func (*SetWalletAutoloadRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+
+func (m *SetWalletAutoloadRequest) GetWalletUuid() string {
+	if m != nil {
+		return m.WalletUuid
+	}
+	return ""
+}
+
+func (m *SetWalletAutoloadRequest) GetAutoload() bool {
+	if m != nil {
+		return m.Autoload
+	}
+	return false
+}
+
+type SetWalletAutoloadResponse struct {
+}
+
+func (m *SetWalletAutoloadResponse) Reset()                    { *m = SetWalletAutoloadResponse{} }
+func (m *SetWalletAutoloadResponse) String() string            { return proto.CompactTextString(m) }
+func (*SetWalletAutoloadResponse) ProtoMessage()               {}
+func (*SetWalletAutoloadResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+
+type WalletInfo struct {
+	Uuid    string `protobuf:"bytes,1,opt,name=uuid" json:"uuid,omitempty"`
+	Network string `protobuf:"bytes,2,opt,name=network" json:"network,omitempty"`
+	Tenant  string `protobuf:"bytes,3,opt,name=tenant" json:"tenant,omitempty"`
+	// Unix times in seconds.
+	Created  int64 `protobuf:"varint,4,opt,name=created" json:"created,omitempty"`
+	LastUsed int64 `protobuf:"varint,5,opt,name=last_used,json=lastUsed" json:"last_used,omitempty"`
+	// Whether the wallet is opened when the daemon is started.
+	Autoload bool `protobuf:"varint,6,opt,name=autoload" json:"autoload,omitempty"`
+	// Whether the wallet is currently open.
+	Open bool `protobuf:"varint,7,opt,name=open" json:"open,omitempty"`
+	// Display name, sorted tags and key/value metadata of the wallet.
+	Name     string            `protobuf:"bytes,8,opt,name=name" json:"name,omitempty"`
+	Tags     []string          `protobuf:"bytes,9,rep,name=tags" json:"tags,omitempty"`
+	Metadata map[string]string `protobuf:"bytes,10,rep,name=metadata" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+}
+
+func (m *WalletInfo) Reset()                    { *m = WalletInfo{} }
+func (m *WalletInfo) String() string            { return proto.CompactTextString(m) }
+func (*WalletInfo) ProtoMessage()               {}
+func (*WalletInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+
+func (m *WalletInfo) GetUuid() string {
+	if m != nil {
+		return m.Uuid
+	}
+	return ""
+}
+
+func (m *WalletInfo) GetNetwork() string {
+	if m != nil {
+		return m.Network
+	}
+	return ""
+}
+
+func (m *WalletInfo) GetTenant() string {
+	if m != nil {
+		return m.Tenant
+	}
+	return ""
+}
+
+func (m *WalletInfo) GetCreated() int64 {
+	if m != nil {
+		return m.Created
+	}
+	return 0
+}
+
+func (m *WalletInfo) GetLastUsed() int64 {
+	if m != nil {
+		return m.LastUsed
+	}
+	return 0
+}
+
+func (m *WalletInfo) GetAutoload() bool {
+	if m != nil {
+		return m.Autoload
+	}
+	return false
+}
+
+func (m *WalletInfo) GetOpen() bool {
+	if m != nil {
+		return m.Open
+	}
+	return false
+}
+
+func (m *WalletInfo) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *WalletInfo) GetTags() []string {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+func (m *WalletInfo) GetMetadata() map[string]string {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
+type ListWalletsRequest struct {
+	// Only wallets with every one of these tags are listed.
+	Tags []string `protobuf:"bytes,1,rep,name=tags" json:"tags,omitempty"`
+	// Only wallets with every one of these metadata entries, with the same
+	// value, are listed.
+	Metadata map[string]string `protobuf:"bytes,2,rep,name=metadata" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+}
+
+func (m *ListWalletsRequest) Reset()                    { *m = ListWalletsRequest{} }
+func (m *ListWalletsRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListWalletsRequest) ProtoMessage()               {}
+func (*ListWalletsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+
+func (m *ListWalletsRequest) GetTags() []string {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+func (m *ListWalletsRequest) GetMetadata() map[string]string {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
+type ListWalletsResponse struct {
+	// Wallets of the tenant of the request, sorted by UUID.
+	Wallets []*WalletInfo `protobuf:"bytes,1,rep,name=wallets" json:"wallets,omitempty"`
+}
+
+func (m *ListWalletsResponse) Reset()                    { *m = ListWalletsResponse{} }
+func (m *ListWalletsResponse) String() string            { return proto.CompactTextString(m) }
+func (*ListWalletsResponse) ProtoMessage()               {}
+func (*ListWalletsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+
+func (m *ListWalletsResponse) GetWallets() []*WalletInfo {
+	if m != nil {
+		return m.Wallets
+	}
+	return nil
+}
+
+type UpdateWalletMetadataRequest struct {
+	WalletUuid string `protobuf:"bytes,1,opt,name=wallet_uuid,json=walletUuid" json:"wallet_uuid,omitempty"`
+	// The display name is replaced by name when set_name is true, and
+	// removed when name is also empty.
+	SetName bool   `protobuf:"varint,2,opt,name=set_name,json=setName" json:"set_name,omitempty"`
+	Name    string `protobuf:"bytes,3,opt,name=name" json:"name,omitempty"`
+	// Tags and metadata keys are removed before others are added.
+	AddTags        []string          `protobuf:"bytes,4,rep,name=add_tags,json=addTags" json:"add_tags,omitempty"`
+	RemoveTags     []string          `protobuf:"bytes,5,rep,name=remove_tags,json=removeTags" json:"remove_tags,omitempty"`
+	SetMetadata    map[string]string `protobuf:"bytes,6,rep,name=set_metadata,json=setMetadata" json:"set_metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	DeleteMetadata []string          `protobuf:"bytes,7,rep,name=delete_metadata,json=deleteMetadata" json:"delete_metadata,omitempty"`
+}
+
+func (m *UpdateWalletMetadataRequest) Reset()                    { *m = UpdateWalletMetadataRequest{} }
+func (m *UpdateWalletMetadataRequest) String() string            { return proto.CompactTextString(m) }
+func (*UpdateWalletMetadataRequest) ProtoMessage()               {}
+func (*UpdateWalletMetadataRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+
+func (m *UpdateWalletMetadataRequest) GetWalletUuid() string {
+	if m != nil {
+		return m.WalletUuid
+	}
+	return ""
+}
+
+func (m *UpdateWalletMetadataRequest) GetSetName() bool {
+	if m != nil {
+		return m.SetName
+	}
+	return false
+}
+
+func (m *UpdateWalletMetadataRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *UpdateWalletMetadataRequest) GetAddTags() []string {
+	if m != nil {
+		return m.AddTags
+	}
+	return nil
+}
+
+func (m *UpdateWalletMetadataRequest) GetRemoveTags() []string {
+	if m != nil {
+		return m.RemoveTags
+	}
+	return nil
+}
+
+func (m *UpdateWalletMetadataRequest) GetSetMetadata() map[string]string {
+	if m != nil {
+		return m.SetMetadata
+	}
+	return nil
+}
+
+func (m *UpdateWalletMetadataRequest) GetDeleteMetadata() []string {
+	if m != nil {
+		return m.DeleteMetadata
+	}
+	return nil
+}
+
+type UpdateWalletMetadataResponse struct {
+	// Wallet after the update.
+	Wallet *WalletInfo `protobuf:"bytes,1,opt,name=wallet" json:"wallet,omitempty"`
+}
+
+func (m *UpdateWalletMetadataResponse) Reset()                    { *m = UpdateWalletMetadataResponse{} }
+func (m *UpdateWalletMetadataResponse) String() string            { return proto.CompactTextString(m) }
+func (*UpdateWalletMetadataResponse) ProtoMessage()               {}
+func (*UpdateWalletMetadataResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
+
+func (m *UpdateWalletMetadataResponse) GetWallet() *WalletInfo {
+	if m != nil {
+		return m.Wallet
+	}
+	return nil
+}
+
+type GetWalletInfoRequest struct {
+	WalletUuid string `protobuf:"bytes,1,opt,name=wallet_uuid,json=walletUuid" json:"wallet_uuid,omitempty"`
+}
+
+func (m *GetWalletInfoRequest) Reset()                    { *m = GetWalletInfoRequest{} }
+func (m *GetWalletInfoRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetWalletInfoRequest) ProtoMessage()               {}
+func (*GetWalletInfoRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
+
+func (m *GetWalletInfoRequest) GetWalletUuid() string {
+	if m != nil {
+		return m.WalletUuid
+	}
+	return ""
+}
+
+type GetWalletInfoResponse struct {
+	Wallet *WalletInfo `protobuf:"bytes,1,opt,name=wallet" json:"wallet,omitempty"`
+}
+
+func (m *GetWalletInfoResponse) Reset()                    { *m = GetWalletInfoResponse{} }
+func (m *GetWalletInfoResponse) String() string            { return proto.CompactTextString(m) }
+func (*GetWalletInfoResponse) ProtoMessage()               {}
+func (*GetWalletInfoResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
+
+func (m *GetWalletInfoResponse) GetWallet() *WalletInfo {
+	if m != nil {
+		return m.Wallet
+	}
+	return nil
+}
+
+type DeleteWalletRequest struct {
+	WalletUuid string `protobuf:"bytes,1,opt,name=wallet_uuid,json=walletUuid" json:"wallet_uuid,omitempty"`
+}
+
+func (m *DeleteWalletRequest) Reset()                    { *m = DeleteWalletRequest{} }
+func (m *DeleteWalletRequest) String() string            { return proto.CompactTextString(m) }
+func (*DeleteWalletRequest) ProtoMessage()               {}
+func (*DeleteWalletRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
+
+func (m *DeleteWalletRequest) GetWalletUuid() string {
+	if m != nil {
+		return m.WalletUuid
+	}
+	return ""
+}
+
+type DeleteWalletResponse struct {
+}
+
+func (m *DeleteWalletResponse) Reset()                    { *m = DeleteWalletResponse{} }
+func (m *DeleteWalletResponse) String() string            { return proto.CompactTextString(m) }
+func (*DeleteWalletResponse) ProtoMessage()               {}
+func (*DeleteWalletResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
+
+type BalanceRequest struct {
+	WalletUuid string `protobuf:"bytes,1,opt,name=wallet_uuid,json=walletUuid" json:"wallet_uuid,omitempty"`
+	// Minimum number of confirmations of the counted outputs.
+	RequiredConfirmations int32 `protobuf:"varint,2,opt,name=required_confirmations,json=requiredConfirmations" json:"required_confirmations,omitempty"`
+}
+
+func (m *BalanceRequest) Reset()                    { *m = BalanceRequest{} }
+func (m *BalanceRequest) String() string            { return proto.CompactTextString(m) }
+func (*BalanceRequest) ProtoMessage()               {}
+func (*BalanceRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
+
+func (m *BalanceRequest) GetWalletUuid() string {
+	if m != nil {
+		return m.WalletUuid
+	}
+	return ""
+}
+
+func (m *BalanceRequest) GetRequiredConfirmations() int32 {
+	if m != nil {
+		return m.RequiredConfirmations
+	}
+	return 0
+}
+
+type BalanceResponse struct {
+	// Balance in satoshis.
+	Balance int64 `protobuf:"varint,1,opt,name=balance" json:"balance,omitempty"`
+}
+
+func (m *BalanceResponse) Reset()                    { *m = BalanceResponse{} }
+func (m *BalanceResponse) String() string            { return proto.CompactTextString(m) }
+func (*BalanceResponse) ProtoMessage()               {}
+func (*BalanceResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24} }
+
+func (m *BalanceResponse) GetBalance() int64 {
+	if m != nil {
+		return m.Balance
+	}
+	return 0
+}
+
+type TransactionSummary struct {
+	Txid    string `protobuf:"bytes,1,opt,name=txid" json:"txid,omitempty"`
+	Vout    uint32 `protobuf:"varint,2,opt,name=vout" json:"vout,omitempty"`
+	Address string `protobuf:"bytes,3,opt,name=address" json:"address,omitempty"`
+	// One of send, receive, generate or immature.
+	Category string `protobuf:"bytes,4,opt,name=category" json:"category,omitempty"`
+	// Amounts in satoshis.  Sent amounts and fees are negative.
+	Amount        int64  `protobuf:"varint,5,opt,name=amount" json:"amount,omitempty"`
+	Fee           int64  `protobuf:"varint,6,opt,name=fee" json:"fee,omitempty"`
+	Confirmations int64  `protobuf:"varint,7,opt,name=confirmations" json:"confirmations,omitempty"`
+	BlockHash     string `protobuf:"bytes,8,opt,name=block_hash,json=blockHash" json:"block_hash,omitempty"`
+	// Unix time in seconds.
+	Timestamp int64 `protobuf:"varint,9,opt,name=timestamp" json:"timestamp,omitempty"`
+}
+
+func (m *TransactionSummary) Reset()                    { *m = TransactionSummary{} }
+func (m *TransactionSummary) String() string            { return proto.CompactTextString(m) }
+func (*TransactionSummary) ProtoMessage()               {}
+func (*TransactionSummary) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{25} }
+
+func (m *TransactionSummary) GetTxid() string {
+	if m != nil {
+		return m.Txid
+	}
+	return ""
+}
+
+func (m *TransactionSummary) GetVout() uint32 {
+	if m != nil {
+		return m.Vout
+	}
+	return 0
+}
+
+func (m *TransactionSummary) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *TransactionSummary) GetCategory() string {
+	if m != nil {
+		return m.Category
+	}
+	return ""
+}
+
+func (m *TransactionSummary) GetAmount() int64 {
+	if m != nil {
+		return m.Amount
+	}
+	return 0
+}
+
+func (m *TransactionSummary) GetFee() int64 {
+	if m != nil {
+		return m.Fee
+	}
+	return 0
+}
+
+func (m *TransactionSummary) GetConfirmations() int64 {
+	if m != nil {
+		return m.Confirmations
+	}
+	return 0
+}
+
+func (m *TransactionSummary) GetBlockHash() string {
+	if m != nil {
+		return m.BlockHash
+	}
+	return ""
+}
+
+func (m *TransactionSummary) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+type ListTransactionsRequest struct {
+	WalletUuid string `protobuf:"bytes,1,opt,name=wallet_uuid,json=walletUuid" json:"wallet_uuid,omitempty"`
+	// Number of most recent transactions to skip.
+	From uint32 `protobuf:"varint,2,opt,name=from" json:"from,omitempty"`
+	// Maximum number of transactions to return.  Defaults to 10.
+	Count uint32 `protobuf:"varint,3,opt,name=count" json:"count,omitempty"`
+}
+
+func (m *ListTransactionsRequest) Reset()                    { *m = ListTransactionsRequest{} }
+func (m *ListTransactionsRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListTransactionsRequest) ProtoMessage()               {}
+func (*ListTransactionsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{26} }
+
+func (m *ListTransactionsRequest) GetWalletUuid() string {
+	if m != nil {
+		return m.WalletUuid
+	}
+	return ""
+}
+
+func (m *ListTransactionsRequest) GetFrom() uint32 {
+	if m != nil {
+		return m.From
+	}
+	return 0
+}
+
+func (m *ListTransactionsRequest) GetCount() uint32 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+type ListTransactionsResponse struct {
+	Transactions []*TransactionSummary `protobuf:"bytes,1,rep,name=transactions" json:"transactions,omitempty"`
+}
+
+func (m *ListTransactionsResponse) Reset()                    { *m = ListTransactionsResponse{} }
+func (m *ListTransactionsResponse) String() string            { return proto.CompactTextString(m) }
+func (*ListTransactionsResponse) ProtoMessage()               {}
+func (*ListTransactionsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{27} }
+
+func (m *ListTransactionsResponse) GetTransactions() []*TransactionSummary {
+	if m != nil {
+		return m.Transactions
+	}
+	return nil
+}
+
+type TransactionOutput struct {
+	Address string `protobuf:"bytes,1,opt,name=address" json:"address,omitempty"`
+	// Amount in satoshis.
+	Amount int64 `protobuf:"varint,2,opt,name=amount" json:"amount,omitempty"`
+}
+
+func (m *TransactionOutput) Reset()                    { *m = TransactionOutput{} }
+func (m *TransactionOutput) String() string            { return proto.CompactTextString(m) }
+func (*TransactionOutput) ProtoMessage()               {}
+func (*TransactionOutput) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{28} }
+
+func (m *TransactionOutput) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *TransactionOutput) GetAmount() int64 {
+	if m != nil {
+		return m.Amount
+	}
+	return 0
+}
+
+type SendTransactionRequest struct {
+	WalletUuid string `protobuf:"bytes,1,opt,name=wallet_uuid,json=walletUuid" json:"wallet_uuid,omitempty"`
+	// Private passphrase unlocking the wallet while the transaction is
+	// signed.
+	Passphrase []byte               `protobuf:"bytes,2,opt,name=passphrase,proto3" json:"passphrase,omitempty"`
+	Outputs    []*TransactionOutput `protobuf:"bytes,3,rep,name=outputs" json:"outputs,omitempty"`
+	Account    uint32               `protobuf:"varint,4,opt,name=account" json:"account,omitempty"`
+	// Minimum number of confirmations of the spent outputs.
+	RequiredConfirmations int32 `protobuf:"varint,5,opt,name=required_confirmations,json=requiredConfirmations" json:"required_confirmations,omitempty"`
+}
+
+func (m *SendTransactionRequest) Reset()                    { *m = SendTransactionRequest{} }
+func (m *SendTransactionRequest) String() string            { return proto.CompactTextString(m) }
+func (*SendTransactionRequest) ProtoMessage()               {}
+func (*SendTransactionRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{29} }
+
+func (m *SendTransactionRequest) GetWalletUuid() string {
+	if m != nil {
+		return m.WalletUuid
+	}
+	return ""
+}
+
+func (m *SendTransactionRequest) GetPassphrase() []byte {
+	if m != nil {
+		return m.Passphrase
+	}
+	return nil
+}
+
+func (m *SendTransactionRequest) GetOutputs() []*TransactionOutput {
+	if m != nil {
+		return m.Outputs
+	}
+	return nil
+}
+
+func (m *SendTransactionRequest) GetAccount() uint32 {
+	if m != nil {
+		return m.Account
+	}
+	return 0
+}
+
+func (m *SendTransactionRequest) GetRequiredConfirmations() int32 {
+	if m != nil {
+		return m.RequiredConfirmations
+	}
+	return 0
+}
+
+type SendTransactionResponse struct {
+	Txid string `protobuf:"bytes,1,opt,name=txid" json:"txid,omitempty"`
+}
+
+func (m *SendTransactionResponse) Reset()                    { *m = SendTransactionResponse{} }
+func (m *SendTransactionResponse) String() string            { return proto.CompactTextString(m) }
+func (*SendTransactionResponse) ProtoMessage()               {}
+func (*SendTransactionResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{30} }
+
+func (m *SendTransactionResponse) GetTxid() string {
+	if m != nil {
+		return m.Txid
+	}
+	return ""
+}
+
+// Label is a note attached to a transaction, address or output of a wallet.
+type Label struct {
+	// One of tx, addr or output, as in BIP0329.
+	Type string `protobuf:"bytes,1,opt,name=type" json:"type,omitempty"`
+	// Transaction hash, encoded address, or transaction hash and output
+	// index of an output, such as "<txid>:1".
+	Ref   string `protobuf:"bytes,2,opt,name=ref" json:"ref,omitempty"`
+	Label string `protobuf:"bytes,3,opt,name=label" json:"label,omitempty"`
+}
+
+func (m *Label) Reset()                    { *m = Label{} }
+func (m *Label) String() string            { return proto.CompactTextString(m) }
+func (*Label) ProtoMessage()               {}
+func (*Label) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{31} }
+
+func (m *Label) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *Label) GetRef() string {
+	if m != nil {
+		return m.Ref
+	}
+	return ""
+}
+
+func (m *Label) GetLabel() string {
+	if m != nil {
+		return m.Label
+	}
+	return ""
+}
+
+type SetLabelsRequest struct {
+	WalletUuid string `protobuf:"bytes,1,opt,name=wallet_uuid,json=walletUuid" json:"wallet_uuid,omitempty"`
+	// Labels replacing the existing labels of the same refs.  Labels with
+	// an empty label are removed.
+	Labels []*Label `protobuf:"bytes,2,rep,name=labels" json:"labels,omitempty"`
+}
+
+func (m *SetLabelsRequest) Reset()                    { *m = SetLabelsRequest{} }
+func (m *SetLabelsRequest) String() string            { return proto.CompactTextString(m) }
+func (*SetLabelsRequest) ProtoMessage()               {}
+func (*SetLabelsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{32} }
+
+func (m *SetLabelsRequest) GetWalletUuid() string {
+	if m != nil {
+		return m.WalletUuid
+	}
+	return ""
+}
+
+func (m *SetLabelsRequest) GetLabels() []*Label {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+type SetLabelsResponse struct {
+}
+
+func (m *SetLabelsResponse) Reset()                    { *m = SetLabelsResponse{} }
+func (m *SetLabelsResponse) String() string            { return proto.CompactTextString(m) }
+func (*SetLabelsResponse) ProtoMessage()               {}
+func (*SetLabelsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{33} }
+
+type GetLabelsRequest struct {
+	WalletUuid string `protobuf:"bytes,1,opt,name=wallet_uuid,json=walletUuid" json:"wallet_uuid,omitempty"`
+	// Only labels of this type are returned when not empty.
+	Type string `protobuf:"bytes,2,opt,name=type" json:"type,omitempty"`
+	// Only labels of these refs are returned when not empty, which
+	// requires the type.
+	Refs []string `protobuf:"bytes,3,rep,name=refs" json:"refs,omitempty"`
+}
+
+func (m *GetLabelsRequest) Reset()                    { *m = GetLabelsRequest{} }
+func (m *GetLabelsRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetLabelsRequest) ProtoMessage()               {}
+func (*GetLabelsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34} }
+
+func (m *GetLabelsRequest) GetWalletUuid() string {
+	if m != nil {
+		return m.WalletUuid
+	}
+	return ""
+}
+
+func (m *GetLabelsRequest) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *GetLabelsRequest) GetRefs() []string {
+	if m != nil {
+		return m.Refs
+	}
+	return nil
+}
+
+type GetLabelsResponse struct {
+	Labels []*Label `protobuf:"bytes,1,rep,name=labels" json:"labels,omitempty"`
+}
+
+func (m *GetLabelsResponse) Reset()                    { *m = GetLabelsResponse{} }
+func (m *GetLabelsResponse) String() string            { return proto.CompactTextString(m) }
+func (*GetLabelsResponse) ProtoMessage()               {}
+func (*GetLabelsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{35} }
+
+func (m *GetLabelsResponse) GetLabels() []*Label {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+type ExportLabelsRequest struct {
+	WalletUuid string `protobuf:"bytes,1,opt,name=wallet_uuid,json=walletUuid" json:"wallet_uuid,omitempty"`
+}
+
+func (m *ExportLabelsRequest) Reset()                    { *m = ExportLabelsRequest{} }
+func (m *ExportLabelsRequest) String() string            { return proto.CompactTextString(m) }
+func (*ExportLabelsRequest) ProtoMessage()               {}
+func (*ExportLabelsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{36} }
+
+func (m *ExportLabelsRequest) GetWalletUuid() string {
+	if m != nil {
+		return m.WalletUuid
+	}
+	return ""
+}
+
+type ExportLabelsResponse struct {
+	// Next part of the BIP0329 export.  The export is the concatenation of
+	// the data of every response.
+	Data []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (m *ExportLabelsResponse) Reset()                    { *m = ExportLabelsResponse{} }
+func (m *ExportLabelsResponse) String() string            { return proto.CompactTextString(m) }
+func (*ExportLabelsResponse) ProtoMessage()               {}
+func (*ExportLabelsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{37} }
+
+func (m *ExportLabelsResponse) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+type ImportLabelsRequest struct {
+	// Only read from the first request.
+	WalletUuid string `protobuf:"bytes,1,opt,name=wallet_uuid,json=walletUuid" json:"wallet_uuid,omitempty"`
+	// Next part of a BIP0329 export.
+	Data []byte `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (m *ImportLabelsRequest) Reset()                    { *m = ImportLabelsRequest{} }
+func (m *ImportLabelsRequest) String() string            { return proto.CompactTextString(m) }
+func (*ImportLabelsRequest) ProtoMessage()               {}
+func (*ImportLabelsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{38} }
+
+func (m *ImportLabelsRequest) GetWalletUuid() string {
+	if m != nil {
+		return m.WalletUuid
+	}
+	return ""
+}
+
+func (m *ImportLabelsRequest) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+type ImportLabelsResponse struct {
+	Imported uint32 `protobuf:"varint,1,opt,name=imported" json:"imported,omitempty"`
+	// Number of records of unsupported types or with invalid refs or
+	// labels, which were not imported.
+	Skipped uint32 `protobuf:"varint,2,opt,name=skipped" json:"skipped,omitempty"`
+}
+
+func (m *ImportLabelsResponse) Reset()                    { *m = ImportLabelsResponse{} }
+func (m *ImportLabelsResponse) String() string            { return proto.CompactTextString(m) }
+func (*ImportLabelsResponse) ProtoMessage()               {}
+func (*ImportLabelsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{39} }
+
+func (m *ImportLabelsResponse) GetImported() uint32 {
+	if m != nil {
+		return m.Imported
+	}
+	return 0
+}
+
+func (m *ImportLabelsResponse) GetSkipped() uint32 {
+	if m != nil {
+		return m.Skipped
+	}
+	return 0
+}
+
+type WalletEventsRequest struct {
+	// Only stream the events of this wallet and the chain events of its
+	// network.  The events of every wallet of the tenant are streamed when
+	// empty.
+	WalletUuid string `protobuf:"bytes,1,opt,name=wallet_uuid,json=walletUuid" json:"wallet_uuid,omitempty"`
+}
+
+func (m *WalletEventsRequest) Reset()                    { *m = WalletEventsRequest{} }
+func (m *WalletEventsRequest) String() string            { return proto.CompactTextString(m) }
+func (*WalletEventsRequest) ProtoMessage()               {}
+func (*WalletEventsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{40} }
+
+func (m *WalletEventsRequest) GetWalletUuid() string {
+	if m != nil {
+		return m.WalletUuid
+	}
+	return ""
+}
+
+type WalletEventsResponse struct {
+	// One of created, opened, closed, deleted, transaction_sent,
+	// block_connected or block_disconnected.
+	Kind string `protobuf:"bytes,1,opt,name=kind" json:"kind,omitempty"`
+	// Unix time in seconds.
+	Timestamp int64 `protobuf:"varint,2,opt,name=timestamp" json:"timestamp,omitempty"`
+	// Empty for chain events.
+	WalletUuid string `protobuf:"bytes,3,opt,name=wallet_uuid,json=walletUuid" json:"wallet_uuid,omitempty"`
+	Network    string `protobuf:"bytes,4,opt,name=network" json:"network,omitempty"`
+	// Hash of the sent transaction, or of the block of chain events.
+	Hash   string `protobuf:"bytes,5,opt,name=hash" json:"hash,omitempty"`
+	Height int32  `protobuf:"varint,6,opt,name=height" json:"height,omitempty"`
+	// Number of events dropped before this one because the client did not
+	// keep up.
+	Dropped uint64 `protobuf:"varint,7,opt,name=dropped" json:"dropped,omitempty"`
+}
+
+func (m *WalletEventsResponse) Reset()                    { *m = WalletEventsResponse{} }
+func (m *WalletEventsResponse) String() string            { return proto.CompactTextString(m) }
+func (*WalletEventsResponse) ProtoMessage()               {}
+func (*WalletEventsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{41} }
+
+func (m *WalletEventsResponse) GetKind() string {
+	if m != nil {
+		return m.Kind
+	}
+	return ""
+}
+
+func (m *WalletEventsResponse) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *WalletEventsResponse) GetWalletUuid() string {
+	if m != nil {
+		return m.WalletUuid
+	}
+	return ""
+}
+
+func (m *WalletEventsResponse) GetNetwork() string {
+	if m != nil {
+		return m.Network
+	}
+	return ""
+}
+
+func (m *WalletEventsResponse) GetHash() string {
+	if m != nil {
+		return m.Hash
+	}
+	return ""
+}
+
+func (m *WalletEventsResponse) GetHeight() int32 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *WalletEventsResponse) GetDropped() uint64 {
+	if m != nil {
+		return m.Dropped
+	}
+	return 0
+}
+
+type RegenerateCertificateRequest struct {
+}
+
+func (m *RegenerateCertificateRequest) Reset()                    { *m = RegenerateCertificateRequest{} }
+func (m *RegenerateCertificateRequest) String() string            { return proto.CompactTextString(m) }
+func (*RegenerateCertificateRequest) ProtoMessage()               {}
+func (*RegenerateCertificateRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{42} }
+
+type RegenerateCertificateResponse struct {
+	// PEM encoded self-signed certificate now presented by the RPC server.
+	Certificate []byte `protobuf:"bytes,1,opt,name=certificate,proto3" json:"certificate,omitempty"`
+}
+
+func (m *RegenerateCertificateResponse) Reset()                    { *m = RegenerateCertificateResponse{} }
+func (m *RegenerateCertificateResponse) String() string            { return proto.CompactTextString(m) }
+func (*RegenerateCertificateResponse) ProtoMessage()               {}
+func (*RegenerateCertificateResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{43} }
+
+func (m *RegenerateCertificateResponse) GetCertificate() []byte {
+	if m != nil {
+		return m.Certificate
+	}
+	return nil
+}
+
+type GetLogLevelsRequest struct {
+}
+
+func (m *GetLogLevelsRequest) Reset()                    { *m = GetLogLevelsRequest{} }
+func (m *GetLogLevelsRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetLogLevelsRequest) ProtoMessage()               {}
+func (*GetLogLevelsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{44} }
+
+type GetLogLevelsResponse struct {
+	// Log level of every subsystem, keyed by subsystem identifier.
+	Levels map[string]string `protobuf:"bytes,1,rep,name=levels" json:"levels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+}
+
+func (m *GetLogLevelsResponse) Reset()                    { *m = GetLogLevelsResponse{} }
+func (m *GetLogLevelsResponse) String() string            { return proto.CompactTextString(m) }
+func (*GetLogLevelsResponse) ProtoMessage()               {}
+func (*GetLogLevelsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{45} }
+
+func (m *GetLogLevelsResponse) GetLevels() map[string]string {
+	if m != nil {
+		return m.Levels
+	}
+	return nil
+}
+
+type SetLogLevelRequest struct {
+	// Subsystem identifier, such as "WLTD".  Every subsystem is changed
+	// when empty.
+	Subsystem string `protobuf:"bytes,1,opt,name=subsystem" json:"subsystem,omitempty"`
+	// One of trace, debug, info, warn, error or critical.
+	Level string `protobuf:"bytes,2,opt,name=level" json:"level,omitempty"`
+}
+
+func (m *SetLogLevelRequest) Reset()                    { *m = SetLogLevelRequest{} }
+func (m *SetLogLevelRequest) String() string            { return proto.CompactTextString(m) }
+func (*SetLogLevelRequest) ProtoMessage()               {}
+func (*SetLogLevelRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{46} }
+
+func (m *SetLogLevelRequest) GetSubsystem() string {
+	if m != nil {
+		return m.Subsystem
+	}
+	return ""
+}
+
+func (m *SetLogLevelRequest) GetLevel() string {
+	if m != nil {
+		return m.Level
+	}
+	return ""
+}
+
+type SetLogLevelResponse struct {
+}
+
+func (m *SetLogLevelResponse) Reset()                    { *m = SetLogLevelResponse{} }
+func (m *SetLogLevelResponse) String() string            { return proto.CompactTextString(m) }
+func (*SetLogLevelResponse) ProtoMessage()               {}
+func (*SetLogLevelResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{47} }
+
+type TailLogsRequest struct {
+	// Lowest level of the streamed lines.  Lines of every level are
+	// streamed when empty.
+	MinLevel string `protobuf:"bytes,1,opt,name=min_level,json=minLevel" json:"min_level,omitempty"`
+	// Subsystems whose lines are streamed.  Lines of every subsystem are
+	// streamed when empty.
+	Subsystems []string `protobuf:"bytes,2,rep,name=subsystems" json:"subsystems,omitempty"`
+}
+
+func (m *TailLogsRequest) Reset()                    { *m = TailLogsRequest{} }
+func (m *TailLogsRequest) String() string            { return proto.CompactTextString(m) }
+func (*TailLogsRequest) ProtoMessage()               {}
+func (*TailLogsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{48} }
+
+func (m *TailLogsRequest) GetMinLevel() string {
+	if m != nil {
+		return m.MinLevel
+	}
+	return ""
+}
+
+func (m *TailLogsRequest) GetSubsystems() []string {
+	if m != nil {
+		return m.Subsystems
+	}
+	return nil
+}
+
+type TailLogsResponse struct {
+	Subsystem string `protobuf:"bytes,1,opt,name=subsystem" json:"subsystem,omitempty"`
+	Level     string `protobuf:"bytes,2,opt,name=level" json:"level,omitempty"`
+	// Formatted log line, as written to the log file.
+	Line string `protobuf:"bytes,3,opt,name=line" json:"line,omitempty"`
+	// Number of lines dropped before this one because the client did not
+	// keep up.
+	Dropped uint64 `protobuf:"varint,4,opt,name=dropped" json:"dropped,omitempty"`
+}
+
+func (m *TailLogsResponse) Reset()                    { *m = TailLogsResponse{} }
+func (m *TailLogsResponse) String() string            { return proto.CompactTextString(m) }
+func (*TailLogsResponse) ProtoMessage()               {}
+func (*TailLogsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{49} }
+
+func (m *TailLogsResponse) GetSubsystem() string {
+	if m != nil {
+		return m.Subsystem
+	}
+	return ""
+}
+
+func (m *TailLogsResponse) GetLevel() string {
+	if m != nil {
+		return m.Level
+	}
+	return ""
+}
+
+func (m *TailLogsResponse) GetLine() string {
+	if m != nil {
+		return m.Line
+	}
+	return ""
+}
+
+func (m *TailLogsResponse) GetDropped() uint64 {
+	if m != nil {
+		return m.Dropped
+	}
+	return 0
+}
+
+type AuditRecord struct {
+	Sequence uint64 `protobuf:"varint,1,opt,name=sequence" json:"sequence,omitempty"`
+	// Unix time in nanoseconds.
+	Timestamp int64  `protobuf:"varint,2,opt,name=timestamp" json:"timestamp,omitempty"`
+	Tenant    string `protobuf:"bytes,3,opt,name=tenant" json:"tenant,omitempty"`
+	// Full gRPC method name of the operation.
+	Method     string `protobuf:"bytes,4,opt,name=method" json:"method,omitempty"`
+	WalletUuid string `protobuf:"bytes,5,opt,name=wallet_uuid,json=walletUuid" json:"wallet_uuid,omitempty"`
+	RequestId  string `protobuf:"bytes,6,opt,name=request_id,json=requestId" json:"request_id,omitempty"`
+	Success    bool   `protobuf:"varint,7,opt,name=success" json:"success,omitempty"`
+	Error      string `protobuf:"bytes,8,opt,name=error" json:"error,omitempty"`
+	// Hex encoded hashes chaining the record to the previous one.
+	PrevHash string `protobuf:"bytes,9,opt,name=prev_hash,json=prevHash" json:"prev_hash,omitempty"`
+	Hash     string `protobuf:"bytes,10,opt,name=hash" json:"hash,omitempty"`
+}
+
+func (m *AuditRecord) Reset()                    { *m = AuditRecord{} }
+func (m *AuditRecord) String() string            { return proto.CompactTextString(m) }
+func (*AuditRecord) ProtoMessage()               {}
+func (*AuditRecord) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{50} }
+
+func (m *AuditRecord) GetSequence() uint64 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
+func (m *AuditRecord) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *AuditRecord) GetTenant() string {
+	if m != nil {
+		return m.Tenant
+	}
+	return ""
+}
+
+func (m *AuditRecord) GetMethod() string {
+	if m != nil {
+		return m.Method
+	}
+	return ""
+}
+
+func (m *AuditRecord) GetWalletUuid() string {
+	if m != nil {
+		return m.WalletUuid
+	}
+	return ""
+}
+
+func (m *AuditRecord) GetRequestId() string {
+	if m != nil {
+		return m.RequestId
+	}
+	return ""
+}
+
+func (m *AuditRecord) GetSuccess() bool {
+	if m != nil {
+		return m.Success
+	}
+	return false
+}
+
+func (m *AuditRecord) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *AuditRecord) GetPrevHash() string {
+	if m != nil {
+		return m.PrevHash
+	}
+	return ""
+}
+
+func (m *AuditRecord) GetHash() string {
+	if m != nil {
+		return m.Hash
+	}
+	return ""
+}
+
+type QueryAuditLogRequest struct {
+	// Filters.  Empty filters match every record.
+	Tenant     string `protobuf:"bytes,1,opt,name=tenant" json:"tenant,omitempty"`
+	Method     string `protobuf:"bytes,2,opt,name=method" json:"method,omitempty"`
+	WalletUuid string `protobuf:"bytes,3,opt,name=wallet_uuid,json=walletUuid" json:"wallet_uuid,omitempty"`
+	// Unix times in seconds bounding the records, or zero.
+	Since int64 `protobuf:"varint,4,opt,name=since" json:"since,omitempty"`
+	Until int64 `protobuf:"varint,5,opt,name=until" json:"until,omitempty"`
+	// Maximum number of records to return, counting from the most recent
+	// matching record.  Defaults to 1000.
+	Limit uint32 `protobuf:"varint,6,opt,name=limit" json:"limit,omitempty"`
+}
+
+func (m *QueryAuditLogRequest) Reset()                    { *m = QueryAuditLogRequest{} }
+func (m *QueryAuditLogRequest) String() string            { return proto.CompactTextString(m) }
+func (*QueryAuditLogRequest) ProtoMessage()               {}
+func (*QueryAuditLogRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{51} }
+
+func (m *QueryAuditLogRequest) GetTenant() string {
+	if m != nil {
+		return m.Tenant
+	}
+	return ""
+}
+
+func (m *QueryAuditLogRequest) GetMethod() string {
+	if m != nil {
+		return m.Method
+	}
+	return ""
+}
+
+func (m *QueryAuditLogRequest) GetWalletUuid() string {
+	if m != nil {
+		return m.WalletUuid
+	}
+	return ""
+}
+
+func (m *QueryAuditLogRequest) GetSince() int64 {
+	if m != nil {
+		return m.Since
+	}
+	return 0
+}
+
+func (m *QueryAuditLogRequest) GetUntil() int64 {
+	if m != nil {
+		return m.Until
+	}
+	return 0
+}
+
+func (m *QueryAuditLogRequest) GetLimit() uint32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type QueryAuditLogResponse struct {
+	// Matching records, oldest first.
+	Records []*AuditRecord `protobuf:"bytes,1,rep,name=records" json:"records,omitempty"`
+}
+
+func (m *QueryAuditLogResponse) Reset()                    { *m = QueryAuditLogResponse{} }
+func (m *QueryAuditLogResponse) String() string            { return proto.CompactTextString(m) }
+func (*QueryAuditLogResponse) ProtoMessage()               {}
+func (*QueryAuditLogResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{52} }
+
+func (m *QueryAuditLogResponse) GetRecords() []*AuditRecord {
+	if m != nil {
+		return m.Records
+	}
+	return nil
+}
+
+// Limits of a tenant.  Zero limits are not enforced.
+type TenantQuota struct {
+	MaxWallets uint64 `protobuf:"varint,1,opt,name=max_wallets,json=maxWallets" json:"max_wallets,omitempty"`
+	// Total size of the wallet files above which no more wallets may be
+	// created.
+	MaxDiskBytes   uint64 `protobuf:"varint,2,opt,name=max_disk_bytes,json=maxDiskBytes" json:"max_disk_bytes,omitempty"`
+	MaxOpenWallets uint64 `protobuf:"varint,3,opt,name=max_open_wallets,json=maxOpenWallets" json:"max_open_wallets,omitempty"`
+}
+
+func (m *TenantQuota) Reset()                    { *m = TenantQuota{} }
+func (m *TenantQuota) String() string            { return proto.CompactTextString(m) }
+func (*TenantQuota) ProtoMessage()               {}
+func (*TenantQuota) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{53} }
+
+func (m *TenantQuota) GetMaxWallets() uint64 {
+	if m != nil {
+		return m.MaxWallets
+	}
+	return 0
+}
+
+func (m *TenantQuota) GetMaxDiskBytes() uint64 {
+	if m != nil {
+		return m.MaxDiskBytes
+	}
+	return 0
+}
+
+func (m *TenantQuota) GetMaxOpenWallets() uint64 {
+	if m != nil {
+		return m.MaxOpenWallets
+	}
+	return 0
+}
+
+type TenantUsage struct {
+	Tenant      string       `protobuf:"bytes,1,opt,name=tenant" json:"tenant,omitempty"`
+	Quota       *TenantQuota `protobuf:"bytes,2,opt,name=quota" json:"quota,omitempty"`
+	Wallets     uint64       `protobuf:"varint,3,opt,name=wallets" json:"wallets,omitempty"`
+	DiskBytes   uint64       `protobuf:"varint,4,opt,name=disk_bytes,json=diskBytes" json:"disk_bytes,omitempty"`
+	OpenWallets uint64       `protobuf:"varint,5,opt,name=open_wallets,json=openWallets" json:"open_wallets,omitempty"`
+}
+
+func (m *TenantUsage) Reset()                    { *m = TenantUsage{} }
+func (m *TenantUsage) String() string            { return proto.CompactTextString(m) }
+func (*TenantUsage) ProtoMessage()               {}
+func (*TenantUsage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{54} }
+
+func (m *TenantUsage) GetTenant() string {
+	if m != nil {
+		return m.Tenant
+	}
+	return ""
+}
+
+func (m *TenantUsage) GetQuota() *TenantQuota {
+	if m != nil {
+		return m.Quota
+	}
+	return nil
+}
+
+func (m *TenantUsage) GetWallets() uint64 {
+	if m != nil {
+		return m.Wallets
+	}
+	return 0
+}
+
+func (m *TenantUsage) GetDiskBytes() uint64 {
+	if m != nil {
+		return m.DiskBytes
+	}
+	return 0
+}
+
+func (m *TenantUsage) GetOpenWallets() uint64 {
+	if m != nil {
+		return m.OpenWallets
+	}
+	return 0
+}
+
+type SetTenantQuotaRequest struct {
+	Tenant string `protobuf:"bytes,1,opt,name=tenant" json:"tenant,omitempty"`
+	// Quota replacing the current one.  A quota without limits removes
+	// it.
+	Quota *TenantQuota `protobuf:"bytes,2,opt,name=quota" json:"quota,omitempty"`
+}
+
+func (m *SetTenantQuotaRequest) Reset()                    { *m = SetTenantQuotaRequest{} }
+func (m *SetTenantQuotaRequest) String() string            { return proto.CompactTextString(m) }
+func (*SetTenantQuotaRequest) ProtoMessage()               {}
+func (*SetTenantQuotaRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{55} }
+
+func (m *SetTenantQuotaRequest) GetTenant() string {
+	if m != nil {
+		return m.Tenant
+	}
+	return ""
+}
+
+func (m *SetTenantQuotaRequest) GetQuota() *TenantQuota {
+	if m != nil {
+		return m.Quota
+	}
+	return nil
+}
+
+type SetTenantQuotaResponse struct {
+}
+
+func (m *SetTenantQuotaResponse) Reset()                    { *m = SetTenantQuotaResponse{} }
+func (m *SetTenantQuotaResponse) String() string            { return proto.CompactTextString(m) }
+func (*SetTenantQuotaResponse) ProtoMessage()               {}
+func (*SetTenantQuotaResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{56} }
+
+type GetTenantUsageRequest struct {
+	// Tenant to report.  Every tenant owning wallets or with a quota is
+	// reported when empty.
+	Tenant string `protobuf:"bytes,1,opt,name=tenant" json:"tenant,omitempty"`
+}
+
+func (m *GetTenantUsageRequest) Reset()                    { *m = GetTenantUsageRequest{} }
+func (m *GetTenantUsageRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetTenantUsageRequest) ProtoMessage()               {}
+func (*GetTenantUsageRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{57} }
+
+func (m *GetTenantUsageRequest) GetTenant() string {
+	if m != nil {
+		return m.Tenant
+	}
+	return ""
+}
+
+type GetTenantUsageResponse struct {
+	Tenants []*TenantUsage `protobuf:"bytes,1,rep,name=tenants" json:"tenants,omitempty"`
+}
+
+func (m *GetTenantUsageResponse) Reset()                    { *m = GetTenantUsageResponse{} }
+func (m *GetTenantUsageResponse) String() string            { return proto.CompactTextString(m) }
+func (*GetTenantUsageResponse) ProtoMessage()               {}
+func (*GetTenantUsageResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{58} }
+
+func (m *GetTenantUsageResponse) GetTenants() []*TenantUsage {
+	if m != nil {
+		return m.Tenants
+	}
+	return nil
+}
+
+type BackupWalletRequest struct {
+	WalletUuid string `protobuf:"bytes,1,opt,name=wallet_uuid,json=walletUuid" json:"wallet_uuid,omitempty"`
+}
+
+func (m *BackupWalletRequest) Reset()                    { *m = BackupWalletRequest{} }
+func (m *BackupWalletRequest) String() string            { return proto.CompactTextString(m) }
+func (*BackupWalletRequest) ProtoMessage()               {}
+func (*BackupWalletRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{59} }
+
+func (m *BackupWalletRequest) GetWalletUuid() string {
+	if m != nil {
+		return m.WalletUuid
+	}
+	return ""
+}
+
+type BackupWalletResponse struct {
+	// Next part of the encrypted backup.  The backup is the concatenation
+	// of the data of every response.
+	Data []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (m *BackupWalletResponse) Reset()                    { *m = BackupWalletResponse{} }
+func (m *BackupWalletResponse) String() string            { return proto.CompactTextString(m) }
+func (*BackupWalletResponse) ProtoMessage()               {}
+func (*BackupWalletResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{60} }
+
+func (m *BackupWalletResponse) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+type RestoreWalletRequest struct {
+	// Next part of the encrypted backup, as returned by BackupWallet.
+	Data []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	// Restore the wallet under a new UUID instead of its original one.
+	// Only read from the first request.
+	NewUuid bool `protobuf:"varint,2,opt,name=new_uuid,json=newUuid" json:"new_uuid,omitempty"`
+	// Tenant the wallet is restored to, whatever the tenant it was backed
+	// up from.  Required, and only read from the first request.
+	Tenant string `protobuf:"bytes,3,opt,name=tenant" json:"tenant,omitempty"`
+}
+
+func (m *RestoreWalletRequest) Reset()                    { *m = RestoreWalletRequest{} }
+func (m *RestoreWalletRequest) String() string            { return proto.CompactTextString(m) }
+func (*RestoreWalletRequest) ProtoMessage()               {}
+func (*RestoreWalletRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{61} }
+
+func (m *RestoreWalletRequest) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *RestoreWalletRequest) GetNewUuid() bool {
+	if m != nil {
+		return m.NewUuid
+	}
+	return false
+}
+
+func (m *RestoreWalletRequest) GetTenant() string {
+	if m != nil {
+		return m.Tenant
+	}
+	return ""
+}
+
+type RestoreWalletResponse struct {
+	WalletUuid string `protobuf:"bytes,1,opt,name=wallet_uuid,json=walletUuid" json:"wallet_uuid,omitempty"`
+}
+
+func (m *RestoreWalletResponse) Reset()                    { *m = RestoreWalletResponse{} }
+func (m *RestoreWalletResponse) String() string            { return proto.CompactTextString(m) }
+func (*RestoreWalletResponse) ProtoMessage()               {}
+func (*RestoreWalletResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{62} }
+
+func (m *RestoreWalletResponse) GetWalletUuid() string {
+	if m != nil {
+		return m.WalletUuid
+	}
+	return ""
+}
+
+type BackupInfo struct {
+	// Name of the backup directory within the backup directory.
+	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	// Unix time in seconds.
+	Timestamp int64  `protobuf:"varint,2,opt,name=timestamp" json:"timestamp,omitempty"`
+	Files     uint32 `protobuf:"varint,3,opt,name=files" json:"files,omitempty"`
+	Size      uint64 `protobuf:"varint,4,opt,name=size" json:"size,omitempty"`
+}
+
+func (m *BackupInfo) Reset()                    { *m = BackupInfo{} }
+func (m *BackupInfo) String() string            { return proto.CompactTextString(m) }
+func (*BackupInfo) ProtoMessage()               {}
+func (*BackupInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{63} }
+
+func (m *BackupInfo) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *BackupInfo) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *BackupInfo) GetFiles() uint32 {
+	if m != nil {
+		return m.Files
+	}
+	return 0
+}
+
+func (m *BackupInfo) GetSize() uint64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+type ListBackupsRequest struct {
+}
+
+func (m *ListBackupsRequest) Reset()                    { *m = ListBackupsRequest{} }
+func (m *ListBackupsRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListBackupsRequest) ProtoMessage()               {}
+func (*ListBackupsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{64} }
+
+type ListBackupsResponse struct {
+	// Backups in the backup directory, oldest first.
+	Backups []*BackupInfo `protobuf:"bytes,1,rep,name=backups" json:"backups,omitempty"`
+}
+
+func (m *ListBackupsResponse) Reset()                    { *m = ListBackupsResponse{} }
+func (m *ListBackupsResponse) String() string            { return proto.CompactTextString(m) }
+func (*ListBackupsResponse) ProtoMessage()               {}
+func (*ListBackupsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{65} }
+
+func (m *ListBackupsResponse) GetBackups() []*BackupInfo {
+	if m != nil {
+		return m.Backups
+	}
+	return nil
+}
+
+type VerifyBackupRequest struct {
+	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+}
+
+func (m *VerifyBackupRequest) Reset()                    { *m = VerifyBackupRequest{} }
+func (m *VerifyBackupRequest) String() string            { return proto.CompactTextString(m) }
+func (*VerifyBackupRequest) ProtoMessage()               {}
+func (*VerifyBackupRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{66} }
+
+func (m *VerifyBackupRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type VerifyBackupResponse struct {
+	// Missing, modified and unlisted files of the backup.  Empty when the
+	// backup matches its manifest.
+	Problems []string `protobuf:"bytes,1,rep,name=problems" json:"problems,omitempty"`
+}
+
+func (m *VerifyBackupResponse) Reset()                    { *m = VerifyBackupResponse{} }
+func (m *VerifyBackupResponse) String() string            { return proto.CompactTextString(m) }
+func (*VerifyBackupResponse) ProtoMessage()               {}
+func (*VerifyBackupResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{67} }
+
+func (m *VerifyBackupResponse) GetProblems() []string {
+	if m != nil {
+		return m.Problems
+	}
+	return nil
+}
+
+type WalletCheck struct {
+	WalletUuid string `protobuf:"bytes,1,opt,name=wallet_uuid,json=walletUuid" json:"wallet_uuid,omitempty"`
+	Network    string `protobuf:"bytes,2,opt,name=network" json:"network,omitempty"`
+	// Inconsistencies found in the wallet.  Empty when the wallet is
+	// intact.
+	Problems       []string `protobuf:"bytes,3,rep,name=problems" json:"problems,omitempty"`
+	Accounts       uint32   `protobuf:"varint,4,opt,name=accounts" json:"accounts,omitempty"`
+	Addresses      uint32   `protobuf:"varint,5,opt,name=addresses" json:"addresses,omitempty"`
+	UnspentOutputs uint32   `protobuf:"varint,6,opt,name=unspent_outputs,json=unspentOutputs" json:"unspent_outputs,omitempty"`
+	// Balance including unconfirmed outputs, in satoshis.
+	Balance int64 `protobuf:"varint,7,opt,name=balance" json:"balance,omitempty"`
+}
+
+func (m *WalletCheck) Reset()                    { *m = WalletCheck{} }
+func (m *WalletCheck) String() string            { return proto.CompactTextString(m) }
+func (*WalletCheck) ProtoMessage()               {}
+func (*WalletCheck) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{68} }
+
+func (m *WalletCheck) GetWalletUuid() string {
+	if m != nil {
+		return m.WalletUuid
+	}
+	return ""
+}
+
+func (m *WalletCheck) GetNetwork() string {
+	if m != nil {
+		return m.Network
+	}
+	return ""
+}
+
+func (m *WalletCheck) GetProblems() []string {
+	if m != nil {
+		return m.Problems
+	}
+	return nil
+}
+
+func (m *WalletCheck) GetAccounts() uint32 {
+	if m != nil {
+		return m.Accounts
+	}
+	return 0
+}
+
+func (m *WalletCheck) GetAddresses() uint32 {
+	if m != nil {
+		return m.Addresses
+	}
+	return 0
+}
+
+func (m *WalletCheck) GetUnspentOutputs() uint32 {
+	if m != nil {
+		return m.UnspentOutputs
+	}
+	return 0
+}
+
+func (m *WalletCheck) GetBalance() int64 {
+	if m != nil {
+		return m.Balance
+	}
+	return 0
+}
+
+type CheckWalletRequest struct {
+	// Wallet to check.  The registry is cross-checked against the wallets
+	// directory and every wallet is checked when empty.
+	WalletUuid string `protobuf:"bytes,1,opt,name=wallet_uuid,json=walletUuid" json:"wallet_uuid,omitempty"`
+}
+
+func (m *CheckWalletRequest) Reset()                    { *m = CheckWalletRequest{} }
+func (m *CheckWalletRequest) String() string            { return proto.CompactTextString(m) }
+func (*CheckWalletRequest) ProtoMessage()               {}
+func (*CheckWalletRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{69} }
+
+func (m *CheckWalletRequest) GetWalletUuid() string {
+	if m != nil {
+		return m.WalletUuid
+	}
+	return ""
+}
+
+type CheckWalletResponse struct {
+	Wallets []*WalletCheck `protobuf:"bytes,1,rep,name=wallets" json:"wallets,omitempty"`
+	// Wallet directories without a registry record.
+	UnregisteredWallets []string `protobuf:"bytes,2,rep,name=unregistered_wallets,json=unregisteredWallets" json:"unregistered_wallets,omitempty"`
+	// Registry records without a wallet directory.
+	MissingWallets []string `protobuf:"bytes,3,rep,name=missing_wallets,json=missingWallets" json:"missing_wallets,omitempty"`
+}
+
+func (m *CheckWalletResponse) Reset()                    { *m = CheckWalletResponse{} }
+func (m *CheckWalletResponse) String() string            { return proto.CompactTextString(m) }
+func (*CheckWalletResponse) ProtoMessage()               {}
+func (*CheckWalletResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{70} }
+
+func (m *CheckWalletResponse) GetWallets() []*WalletCheck {
+	if m != nil {
+		return m.Wallets
+	}
+	return nil
+}
+
+func (m *CheckWalletResponse) GetUnregisteredWallets() []string {
+	if m != nil {
+		return m.UnregisteredWallets
+	}
+	return nil
+}
+
+func (m *CheckWalletResponse) GetMissingWallets() []string {
+	if m != nil {
+		return m.MissingWallets
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*VersionRequest)(nil), "walletdrpc.VersionRequest")
+	proto.RegisterType((*VersionResponse)(nil), "walletdrpc.VersionResponse")
+	proto.RegisterType((*PingRequest)(nil), "walletdrpc.PingRequest")
+	proto.RegisterType((*PingResponse)(nil), "walletdrpc.PingResponse")
+	proto.RegisterType((*NetworkRequest)(nil), "walletdrpc.NetworkRequest")
+	proto.RegisterType((*NetworkResponse)(nil), "walletdrpc.NetworkResponse")
+	proto.RegisterType((*CreateWalletRequest)(nil), "walletdrpc.CreateWalletRequest")
+	proto.RegisterType((*CreateWalletResponse)(nil), "walletdrpc.CreateWalletResponse")
+	proto.RegisterType((*CreateWalletsRequest)(nil), "walletdrpc.CreateWalletsRequest")
+	proto.RegisterType((*CreateWalletsResponse)(nil), "walletdrpc.CreateWalletsResponse")
+	proto.RegisterType((*OpenWalletRequest)(nil), "walletdrpc.OpenWalletRequest")
+	proto.RegisterType((*OpenWalletResponse)(nil), "walletdrpc.OpenWalletResponse")
+	proto.RegisterType((*SetWalletAutoloadRequest)(nil), "walletdrpc.SetWalletAutoloadRequest")
+	proto.RegisterType((*SetWalletAutoloadResponse)(nil), "walletdrpc.SetWalletAutoloadResponse")
+	proto.RegisterType((*WalletInfo)(nil), "walletdrpc.WalletInfo")
+	proto.RegisterType((*ListWalletsRequest)(nil), "walletdrpc.ListWalletsRequest")
+	proto.RegisterType((*ListWalletsResponse)(nil), "walletdrpc.ListWalletsResponse")
+	proto.RegisterType((*UpdateWalletMetadataRequest)(nil), "walletdrpc.UpdateWalletMetadataRequest")
+	proto.RegisterType((*UpdateWalletMetadataResponse)(nil), "walletdrpc.UpdateWalletMetadataResponse")
+	proto.RegisterType((*GetWalletInfoRequest)(nil), "walletdrpc.GetWalletInfoRequest")
+	proto.RegisterType((*GetWalletInfoResponse)(nil), "walletdrpc.GetWalletInfoResponse")
+	proto.RegisterType((*DeleteWalletRequest)(nil), "walletdrpc.DeleteWalletRequest")
+	proto.RegisterType((*DeleteWalletResponse)(nil), "walletdrpc.DeleteWalletResponse")
+	proto.RegisterType((*BalanceRequest)(nil), "walletdrpc.BalanceRequest")
+	proto.RegisterType((*BalanceResponse)(nil), "walletdrpc.BalanceResponse")
+	proto.RegisterType((*TransactionSummary)(nil), "walletdrpc.TransactionSummary")
+	proto.RegisterType((*ListTransactionsRequest)(nil), "walletdrpc.ListTransactionsRequest")
+	proto.RegisterType((*ListTransactionsResponse)(nil), "walletdrpc.ListTransactionsResponse")
+	proto.RegisterType((*TransactionOutput)(nil), "walletdrpc.TransactionOutput")
+	proto.RegisterType((*SendTransactionRequest)(nil), "walletdrpc.SendTransactionRequest")
+	proto.RegisterType((*SendTransactionResponse)(nil), "walletdrpc.SendTransactionResponse")
+	proto.RegisterType((*Label)(nil), "walletdrpc.Label")
+	proto.RegisterType((*SetLabelsRequest)(nil), "walletdrpc.SetLabelsRequest")
+	proto.RegisterType((*SetLabelsResponse)(nil), "walletdrpc.SetLabelsResponse")
+	proto.RegisterType((*GetLabelsRequest)(nil), "walletdrpc.GetLabelsRequest")
+	proto.RegisterType((*GetLabelsResponse)(nil), "walletdrpc.GetLabelsResponse")
+	proto.RegisterType((*ExportLabelsRequest)(nil), "walletdrpc.ExportLabelsRequest")
+	proto.RegisterType((*ExportLabelsResponse)(nil), "walletdrpc.ExportLabelsResponse")
+	proto.RegisterType((*ImportLabelsRequest)(nil), "walletdrpc.ImportLabelsRequest")
+	proto.RegisterType((*ImportLabelsResponse)(nil), "walletdrpc.ImportLabelsResponse")
+	proto.RegisterType((*WalletEventsRequest)(nil), "walletdrpc.WalletEventsRequest")
+	proto.RegisterType((*WalletEventsResponse)(nil), "walletdrpc.WalletEventsResponse")
+	proto.RegisterType((*RegenerateCertificateRequest)(nil), "walletdrpc.RegenerateCertificateRequest")
+	proto.RegisterType((*RegenerateCertificateResponse)(nil), "walletdrpc.RegenerateCertificateResponse")
+	proto.RegisterType((*GetLogLevelsRequest)(nil), "walletdrpc.GetLogLevelsRequest")
+	proto.RegisterType((*GetLogLevelsResponse)(nil), "walletdrpc.GetLogLevelsResponse")
+	proto.RegisterType((*SetLogLevelRequest)(nil), "walletdrpc.SetLogLevelRequest")
+	proto.RegisterType((*SetLogLevelResponse)(nil), "walletdrpc.SetLogLevelResponse")
+	proto.RegisterType((*TailLogsRequest)(nil), "walletdrpc.TailLogsRequest")
+	proto.RegisterType((*TailLogsResponse)(nil), "walletdrpc.TailLogsResponse")
+	proto.RegisterType((*AuditRecord)(nil), "walletdrpc.AuditRecord")
+	proto.RegisterType((*QueryAuditLogRequest)(nil), "walletdrpc.QueryAuditLogRequest")
+	proto.RegisterType((*QueryAuditLogResponse)(nil), "walletdrpc.QueryAuditLogResponse")
+	proto.RegisterType((*TenantQuota)(nil), "walletdrpc.TenantQuota")
+	proto.RegisterType((*TenantUsage)(nil), "walletdrpc.TenantUsage")
+	proto.RegisterType((*SetTenantQuotaRequest)(nil), "walletdrpc.SetTenantQuotaRequest")
+	proto.RegisterType((*SetTenantQuotaResponse)(nil), "walletdrpc.SetTenantQuotaResponse")
+	proto.RegisterType((*GetTenantUsageRequest)(nil), "walletdrpc.GetTenantUsageRequest")
+	proto.RegisterType((*GetTenantUsageResponse)(nil), "walletdrpc.GetTenantUsageResponse")
+	proto.RegisterType((*BackupWalletRequest)(nil), "walletdrpc.BackupWalletRequest")
+	proto.RegisterType((*BackupWalletResponse)(nil), "walletdrpc.BackupWalletResponse")
+	proto.RegisterType((*RestoreWalletRequest)(nil), "walletdrpc.RestoreWalletRequest")
+	proto.RegisterType((*RestoreWalletResponse)(nil), "walletdrpc.RestoreWalletResponse")
+	proto.RegisterType((*BackupInfo)(nil), "walletdrpc.BackupInfo")
+	proto.RegisterType((*ListBackupsRequest)(nil), "walletdrpc.ListBackupsRequest")
+	proto.RegisterType((*ListBackupsResponse)(nil), "walletdrpc.ListBackupsResponse")
+	proto.RegisterType((*VerifyBackupRequest)(nil), "walletdrpc.VerifyBackupRequest")
+	proto.RegisterType((*VerifyBackupResponse)(nil), "walletdrpc.VerifyBackupResponse")
+	proto.RegisterType((*WalletCheck)(nil), "walletdrpc.WalletCheck")
+	proto.RegisterType((*CheckWalletRequest)(nil), "walletdrpc.CheckWalletRequest")
+	proto.RegisterType((*CheckWalletResponse)(nil), "walletdrpc.CheckWalletResponse")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion3
+
+// Client API for VersionService service
+
+type VersionServiceClient interface {
+	Version(ctx context.Context, in *VersionRequest, opts ...grpc.CallOption) (*VersionResponse, error)
+}
+
+type versionServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewVersionServiceClient(cc *grpc.ClientConn) VersionServiceClient {
+	return &versionServiceClient{cc}
+}
+
+func (c *versionServiceClient) Version(ctx context.Context, in *VersionRequest, opts ...grpc.CallOption) (*VersionResponse, error) {
+	out := new(VersionResponse)
+	err := grpc.Invoke(ctx, "/walletdrpc.VersionService/Version", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for VersionService service
+
+type VersionServiceServer interface {
+	Version(context.Context, *VersionRequest) (*VersionResponse, error)
+}
+
+func RegisterVersionServiceServer(s *grpc.Server, srv VersionServiceServer) {
+	s.RegisterService(&_VersionService_serviceDesc, srv)
+}
+
+func _VersionService_Version_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VersionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VersionServiceServer).Version(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walletdrpc.VersionService/Version",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VersionServiceServer).Version(ctx, req.(*VersionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _VersionService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "walletdrpc.VersionService",
+	HandlerType: (*VersionServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Version",
+			Handler:    _VersionService_Version_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api.proto",
+}
+
+// Client API for WalletDaemonService service
+
+type WalletDaemonServiceClient interface {
+	// Queries
+	Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingResponse, error)
+	Network(ctx context.Context, in *NetworkRequest, opts ...grpc.CallOption) (*NetworkResponse, error)
+	// Wallet
+	CreateWallet(ctx context.Context, in *CreateWalletRequest, opts ...grpc.CallOption) (*CreateWalletResponse, error)
+	CreateWallets(ctx context.Context, in *CreateWalletsRequest, opts ...grpc.CallOption) (WalletDaemonService_CreateWalletsClient, error)
+	OpenWallet(ctx context.Context, in *OpenWalletRequest, opts ...grpc.CallOption) (*OpenWalletResponse, error)
+	SetWalletAutoload(ctx context.Context, in *SetWalletAutoloadRequest, opts ...grpc.CallOption) (*SetWalletAutoloadResponse, error)
+	ListWallets(ctx context.Context, in *ListWalletsRequest, opts ...grpc.CallOption) (*ListWalletsResponse, error)
+	GetWalletInfo(ctx context.Context, in *GetWalletInfoRequest, opts ...grpc.CallOption) (*GetWalletInfoResponse, error)
+	UpdateWalletMetadata(ctx context.Context, in *UpdateWalletMetadataRequest, opts ...grpc.CallOption) (*UpdateWalletMetadataResponse, error)
+	DeleteWallet(ctx context.Context, in *DeleteWalletRequest, opts ...grpc.CallOption) (*DeleteWalletResponse, error)
+	// Transactions
+	Balance(ctx context.Context, in *BalanceRequest, opts ...grpc.CallOption) (*BalanceResponse, error)
+	ListTransactions(ctx context.Context, in *ListTransactionsRequest, opts ...grpc.CallOption) (*ListTransactionsResponse, error)
+	SendTransaction(ctx context.Context, in *SendTransactionRequest, opts ...grpc.CallOption) (*SendTransactionResponse, error)
+	// Labels
+	SetLabels(ctx context.Context, in *SetLabelsRequest, opts ...grpc.CallOption) (*SetLabelsResponse, error)
+	GetLabels(ctx context.Context, in *GetLabelsRequest, opts ...grpc.CallOption) (*GetLabelsResponse, error)
+	ExportLabels(ctx context.Context, in *ExportLabelsRequest, opts ...grpc.CallOption) (WalletDaemonService_ExportLabelsClient, error)
+	ImportLabels(ctx context.Context, opts ...grpc.CallOption) (WalletDaemonService_ImportLabelsClient, error)
+	// Events
+	WalletEvents(ctx context.Context, in *WalletEventsRequest, opts ...grpc.CallOption) (WalletDaemonService_WalletEventsClient, error)
+}
+
+type walletDaemonServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewWalletDaemonServiceClient(cc *grpc.ClientConn) WalletDaemonServiceClient {
+	return &walletDaemonServiceClient{cc}
+}
+
+func (c *walletDaemonServiceClient) Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingResponse, error) {
+	out := new(PingResponse)
+	err := grpc.Invoke(ctx, "/walletdrpc.WalletDaemonService/Ping", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletDaemonServiceClient) Network(ctx context.Context, in *NetworkRequest, opts ...grpc.CallOption) (*NetworkResponse, error) {
+	out := new(NetworkResponse)
+	err := grpc.Invoke(ctx, "/walletdrpc.WalletDaemonService/Network", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletDaemonServiceClient) CreateWallet(ctx context.Context, in *CreateWalletRequest, opts ...grpc.CallOption) (*CreateWalletResponse, error) {
+	out := new(CreateWalletResponse)
+	err := grpc.Invoke(ctx, "/walletdrpc.WalletDaemonService/CreateWallet", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletDaemonServiceClient) CreateWallets(ctx context.Context, in *CreateWalletsRequest, opts ...grpc.CallOption) (WalletDaemonService_CreateWalletsClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_WalletDaemonService_serviceDesc.Streams[0], c.cc, "/walletdrpc.WalletDaemonService/CreateWallets", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &walletDaemonServiceCreateWalletsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type WalletDaemonService_CreateWalletsClient interface {
+	Recv() (*CreateWalletsResponse, error)
+	grpc.ClientStream
+}
+
+type walletDaemonServiceCreateWalletsClient struct {
+	grpc.ClientStream
+}
+
+func (x *walletDaemonServiceCreateWalletsClient) Recv() (*CreateWalletsResponse, error) {
+	m := new(CreateWalletsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *walletDaemonServiceClient) OpenWallet(ctx context.Context, in *OpenWalletRequest, opts ...grpc.CallOption) (*OpenWalletResponse, error) {
+	out := new(OpenWalletResponse)
+	err := grpc.Invoke(ctx, "/walletdrpc.WalletDaemonService/OpenWallet", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletDaemonServiceClient) SetWalletAutoload(ctx context.Context, in *SetWalletAutoloadRequest, opts ...grpc.CallOption) (*SetWalletAutoloadResponse, error) {
+	out := new(SetWalletAutoloadResponse)
+	err := grpc.Invoke(ctx, "/walletdrpc.WalletDaemonService/SetWalletAutoload", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletDaemonServiceClient) ListWallets(ctx context.Context, in *ListWalletsRequest, opts ...grpc.CallOption) (*ListWalletsResponse, error) {
+	out := new(ListWalletsResponse)
+	err := grpc.Invoke(ctx, "/walletdrpc.WalletDaemonService/ListWallets", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletDaemonServiceClient) GetWalletInfo(ctx context.Context, in *GetWalletInfoRequest, opts ...grpc.CallOption) (*GetWalletInfoResponse, error) {
+	out := new(GetWalletInfoResponse)
+	err := grpc.Invoke(ctx, "/walletdrpc.WalletDaemonService/GetWalletInfo", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletDaemonServiceClient) UpdateWalletMetadata(ctx context.Context, in *UpdateWalletMetadataRequest, opts ...grpc.CallOption) (*UpdateWalletMetadataResponse, error) {
+	out := new(UpdateWalletMetadataResponse)
+	err := grpc.Invoke(ctx, "/walletdrpc.WalletDaemonService/UpdateWalletMetadata", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletDaemonServiceClient) DeleteWallet(ctx context.Context, in *DeleteWalletRequest, opts ...grpc.CallOption) (*DeleteWalletResponse, error) {
+	out := new(DeleteWalletResponse)
+	err := grpc.Invoke(ctx, "/walletdrpc.WalletDaemonService/DeleteWallet", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletDaemonServiceClient) Balance(ctx context.Context, in *BalanceRequest, opts ...grpc.CallOption) (*BalanceResponse, error) {
+	out := new(BalanceResponse)
+	err := grpc.Invoke(ctx, "/walletdrpc.WalletDaemonService/Balance", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletDaemonServiceClient) ListTransactions(ctx context.Context, in *ListTransactionsRequest, opts ...grpc.CallOption) (*ListTransactionsResponse, error) {
+	out := new(ListTransactionsResponse)
+	err := grpc.Invoke(ctx, "/walletdrpc.WalletDaemonService/ListTransactions", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletDaemonServiceClient) SendTransaction(ctx context.Context, in *SendTransactionRequest, opts ...grpc.CallOption) (*SendTransactionResponse, error) {
+	out := new(SendTransactionResponse)
+	err := grpc.Invoke(ctx, "/walletdrpc.WalletDaemonService/SendTransaction", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletDaemonServiceClient) SetLabels(ctx context.Context, in *SetLabelsRequest, opts ...grpc.CallOption) (*SetLabelsResponse, error) {
+	out := new(SetLabelsResponse)
+	err := grpc.Invoke(ctx, "/walletdrpc.WalletDaemonService/SetLabels", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletDaemonServiceClient) GetLabels(ctx context.Context, in *GetLabelsRequest, opts ...grpc.CallOption) (*GetLabelsResponse, error) {
+	out := new(GetLabelsResponse)
+	err := grpc.Invoke(ctx, "/walletdrpc.WalletDaemonService/GetLabels", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletDaemonServiceClient) ExportLabels(ctx context.Context, in *ExportLabelsRequest, opts ...grpc.CallOption) (WalletDaemonService_ExportLabelsClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_WalletDaemonService_serviceDesc.Streams[1], c.cc, "/walletdrpc.WalletDaemonService/ExportLabels", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &walletDaemonServiceExportLabelsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type WalletDaemonService_ExportLabelsClient interface {
+	Recv() (*ExportLabelsResponse, error)
+	grpc.ClientStream
+}
+
+type walletDaemonServiceExportLabelsClient struct {
+	grpc.ClientStream
+}
+
+func (x *walletDaemonServiceExportLabelsClient) Recv() (*ExportLabelsResponse, error) {
+	m := new(ExportLabelsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *walletDaemonServiceClient) ImportLabels(ctx context.Context, opts ...grpc.CallOption) (WalletDaemonService_ImportLabelsClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_WalletDaemonService_serviceDesc.Streams[2], c.cc, "/walletdrpc.WalletDaemonService/ImportLabels", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &walletDaemonServiceImportLabelsClient{stream}
+	return x, nil
+}
+
+type WalletDaemonService_ImportLabelsClient interface {
+	Send(*ImportLabelsRequest) error
+	CloseAndRecv() (*ImportLabelsResponse, error)
+	grpc.ClientStream
+}
+
+type walletDaemonServiceImportLabelsClient struct {
+	grpc.ClientStream
+}
+
+func (x *walletDaemonServiceImportLabelsClient) Send(m *ImportLabelsRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *walletDaemonServiceImportLabelsClient) CloseAndRecv() (*ImportLabelsResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(ImportLabelsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *walletDaemonServiceClient) WalletEvents(ctx context.Context, in *WalletEventsRequest, opts ...grpc.CallOption) (WalletDaemonService_WalletEventsClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_WalletDaemonService_serviceDesc.Streams[3], c.cc, "/walletdrpc.WalletDaemonService/WalletEvents", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &walletDaemonServiceWalletEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type WalletDaemonService_WalletEventsClient interface {
+	Recv() (*WalletEventsResponse, error)
+	grpc.ClientStream
+}
+
+type walletDaemonServiceWalletEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *walletDaemonServiceWalletEventsClient) Recv() (*WalletEventsResponse, error) {
+	m := new(WalletEventsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Server API for WalletDaemonService service
+
+type WalletDaemonServiceServer interface {
+	// Queries
+	Ping(context.Context, *PingRequest) (*PingResponse, error)
+	Network(context.Context, *NetworkRequest) (*NetworkResponse, error)
+	// Wallet
+	CreateWallet(context.Context, *CreateWalletRequest) (*CreateWalletResponse, error)
+	CreateWallets(*CreateWalletsRequest, WalletDaemonService_CreateWalletsServer) error
+	OpenWallet(context.Context, *OpenWalletRequest) (*OpenWalletResponse, error)
+	SetWalletAutoload(context.Context, *SetWalletAutoloadRequest) (*SetWalletAutoloadResponse, error)
+	ListWallets(context.Context, *ListWalletsRequest) (*ListWalletsResponse, error)
+	GetWalletInfo(context.Context, *GetWalletInfoRequest) (*GetWalletInfoResponse, error)
+	UpdateWalletMetadata(context.Context, *UpdateWalletMetadataRequest) (*UpdateWalletMetadataResponse, error)
+	DeleteWallet(context.Context, *DeleteWalletRequest) (*DeleteWalletResponse, error)
+	// Transactions
+	Balance(context.Context, *BalanceRequest) (*BalanceResponse, error)
+	ListTransactions(context.Context, *ListTransactionsRequest) (*ListTransactionsResponse, error)
+	SendTransaction(context.Context, *SendTransactionRequest) (*SendTransactionResponse, error)
+	// Labels
+	SetLabels(context.Context, *SetLabelsRequest) (*SetLabelsResponse, error)
+	GetLabels(context.Context, *GetLabelsRequest) (*GetLabelsResponse, error)
+	ExportLabels(*ExportLabelsRequest, WalletDaemonService_ExportLabelsServer) error
+	ImportLabels(WalletDaemonService_ImportLabelsServer) error
+	// Events
+	WalletEvents(*WalletEventsRequest, WalletDaemonService_WalletEventsServer) error
+}
+
+func RegisterWalletDaemonServiceServer(s *grpc.Server, srv WalletDaemonServiceServer) {
+	s.RegisterService(&_WalletDaemonService_serviceDesc, srv)
+}
+
+func _WalletDaemonService_Ping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletDaemonServiceServer).Ping(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walletdrpc.WalletDaemonService/Ping",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletDaemonServiceServer).Ping(ctx, req.(*PingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletDaemonService_Network_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NetworkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletDaemonServiceServer).Network(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walletdrpc.WalletDaemonService/Network",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletDaemonServiceServer).Network(ctx, req.(*NetworkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletDaemonService_CreateWallet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateWalletRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletDaemonServiceServer).CreateWallet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walletdrpc.WalletDaemonService/CreateWallet",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletDaemonServiceServer).CreateWallet(ctx, req.(*CreateWalletRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletDaemonService_CreateWallets_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(CreateWalletsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(WalletDaemonServiceServer).CreateWallets(m, &walletDaemonServiceCreateWalletsServer{stream})
+}
+
+type WalletDaemonService_CreateWalletsServer interface {
+	Send(*CreateWalletsResponse) error
+	grpc.ServerStream
+}
+
+type walletDaemonServiceCreateWalletsServer struct {
+	grpc.ServerStream
+}
+
+func (x *walletDaemonServiceCreateWalletsServer) Send(m *CreateWalletsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _WalletDaemonService_OpenWallet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OpenWalletRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletDaemonServiceServer).OpenWallet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walletdrpc.WalletDaemonService/OpenWallet",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletDaemonServiceServer).OpenWallet(ctx, req.(*OpenWalletRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletDaemonService_SetWalletAutoload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetWalletAutoloadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletDaemonServiceServer).SetWalletAutoload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walletdrpc.WalletDaemonService/SetWalletAutoload",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletDaemonServiceServer).SetWalletAutoload(ctx, req.(*SetWalletAutoloadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletDaemonService_ListWallets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWalletsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletDaemonServiceServer).ListWallets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walletdrpc.WalletDaemonService/ListWallets",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletDaemonServiceServer).ListWallets(ctx, req.(*ListWalletsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletDaemonService_GetWalletInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetWalletInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletDaemonServiceServer).GetWalletInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walletdrpc.WalletDaemonService/GetWalletInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletDaemonServiceServer).GetWalletInfo(ctx, req.(*GetWalletInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletDaemonService_UpdateWalletMetadata_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateWalletMetadataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletDaemonServiceServer).UpdateWalletMetadata(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walletdrpc.WalletDaemonService/UpdateWalletMetadata",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletDaemonServiceServer).UpdateWalletMetadata(ctx, req.(*UpdateWalletMetadataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletDaemonService_DeleteWallet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteWalletRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletDaemonServiceServer).DeleteWallet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walletdrpc.WalletDaemonService/DeleteWallet",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletDaemonServiceServer).DeleteWallet(ctx, req.(*DeleteWalletRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletDaemonService_Balance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BalanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletDaemonServiceServer).Balance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walletdrpc.WalletDaemonService/Balance",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletDaemonServiceServer).Balance(ctx, req.(*BalanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletDaemonService_ListTransactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTransactionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletDaemonServiceServer).ListTransactions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walletdrpc.WalletDaemonService/ListTransactions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletDaemonServiceServer).ListTransactions(ctx, req.(*ListTransactionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletDaemonService_SendTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletDaemonServiceServer).SendTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walletdrpc.WalletDaemonService/SendTransaction",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletDaemonServiceServer).SendTransaction(ctx, req.(*SendTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletDaemonService_SetLabels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetLabelsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletDaemonServiceServer).SetLabels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walletdrpc.WalletDaemonService/SetLabels",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletDaemonServiceServer).SetLabels(ctx, req.(*SetLabelsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletDaemonService_GetLabels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLabelsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletDaemonServiceServer).GetLabels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walletdrpc.WalletDaemonService/GetLabels",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletDaemonServiceServer).GetLabels(ctx, req.(*GetLabelsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletDaemonService_ExportLabels_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportLabelsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(WalletDaemonServiceServer).ExportLabels(m, &walletDaemonServiceExportLabelsServer{stream})
+}
+
+type WalletDaemonService_ExportLabelsServer interface {
+	Send(*ExportLabelsResponse) error
+	grpc.ServerStream
+}
+
+type walletDaemonServiceExportLabelsServer struct {
+	grpc.ServerStream
+}
+
+func (x *walletDaemonServiceExportLabelsServer) Send(m *ExportLabelsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _WalletDaemonService_ImportLabels_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(WalletDaemonServiceServer).ImportLabels(&walletDaemonServiceImportLabelsServer{stream})
+}
+
+type WalletDaemonService_ImportLabelsServer interface {
+	SendAndClose(*ImportLabelsResponse) error
+	Recv() (*ImportLabelsRequest, error)
+	grpc.ServerStream
+}
+
+type walletDaemonServiceImportLabelsServer struct {
+	grpc.ServerStream
+}
+
+func (x *walletDaemonServiceImportLabelsServer) SendAndClose(m *ImportLabelsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *walletDaemonServiceImportLabelsServer) Recv() (*ImportLabelsRequest, error) {
+	m := new(ImportLabelsRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _WalletDaemonService_WalletEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WalletEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(WalletDaemonServiceServer).WalletEvents(m, &walletDaemonServiceWalletEventsServer{stream})
+}
+
+type WalletDaemonService_WalletEventsServer interface {
+	Send(*WalletEventsResponse) error
+	grpc.ServerStream
+}
+
+type walletDaemonServiceWalletEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *walletDaemonServiceWalletEventsServer) Send(m *WalletEventsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _WalletDaemonService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "walletdrpc.WalletDaemonService",
+	HandlerType: (*WalletDaemonServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Ping",
+			Handler:    _WalletDaemonService_Ping_Handler,
+		},
+		{
+			MethodName: "Network",
+			Handler:    _WalletDaemonService_Network_Handler,
+		},
+		{
+			MethodName: "CreateWallet",
+			Handler:    _WalletDaemonService_CreateWallet_Handler,
+		},
+		{
+			MethodName: "OpenWallet",
+			Handler:    _WalletDaemonService_OpenWallet_Handler,
+		},
+		{
+			MethodName: "SetWalletAutoload",
+			Handler:    _WalletDaemonService_SetWalletAutoload_Handler,
+		},
+		{
+			MethodName: "ListWallets",
+			Handler:    _WalletDaemonService_ListWallets_Handler,
+		},
+		{
+			MethodName: "GetWalletInfo",
+			Handler:    _WalletDaemonService_GetWalletInfo_Handler,
+		},
+		{
+			MethodName: "UpdateWalletMetadata",
+			Handler:    _WalletDaemonService_UpdateWalletMetadata_Handler,
+		},
+		{
+			MethodName: "DeleteWallet",
+			Handler:    _WalletDaemonService_DeleteWallet_Handler,
+		},
+		{
+			MethodName: "Balance",
+			Handler:    _WalletDaemonService_Balance_Handler,
+		},
+		{
+			MethodName: "ListTransactions",
+			Handler:    _WalletDaemonService_ListTransactions_Handler,
+		},
+		{
+			MethodName: "SendTransaction",
+			Handler:    _WalletDaemonService_SendTransaction_Handler,
+		},
+		{
+			MethodName: "SetLabels",
+			Handler:    _WalletDaemonService_SetLabels_Handler,
+		},
+		{
+			MethodName: "GetLabels",
+			Handler:    _WalletDaemonService_GetLabels_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "CreateWallets",
+			Handler:       _WalletDaemonService_CreateWallets_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ExportLabels",
+			Handler:       _WalletDaemonService_ExportLabels_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ImportLabels",
+			Handler:       _WalletDaemonService_ImportLabels_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "WalletEvents",
+			Handler:       _WalletDaemonService_WalletEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "api.proto",
+}
+
+// Client API for AdminService service
+
+type AdminServiceClient interface {
+	// Replaces the RPC server TLS keypair with a new self-signed keypair.
+	// Existing connections keep using the previous keypair.
+	RegenerateCertificate(ctx context.Context, in *RegenerateCertificateRequest, opts ...grpc.CallOption) (*RegenerateCertificateResponse, error)
+	// Logging
+	GetLogLevels(ctx context.Context, in *GetLogLevelsRequest, opts ...grpc.CallOption) (*GetLogLevelsResponse, error)
+	SetLogLevel(ctx context.Context, in *SetLogLevelRequest, opts ...grpc.CallOption) (*SetLogLevelResponse, error)
+	TailLogs(ctx context.Context, in *TailLogsRequest, opts ...grpc.CallOption) (AdminService_TailLogsClient, error)
+	// Audit
+	QueryAuditLog(ctx context.Context, in *QueryAuditLogRequest, opts ...grpc.CallOption) (*QueryAuditLogResponse, error)
+	// Quotas
+	SetTenantQuota(ctx context.Context, in *SetTenantQuotaRequest, opts ...grpc.CallOption) (*SetTenantQuotaResponse, error)
+	GetTenantUsage(ctx context.Context, in *GetTenantUsageRequest, opts ...grpc.CallOption) (*GetTenantUsageResponse, error)
+	// Backups are encrypted to the backup key of the daemon and can only
+	// be restored by a daemon with the same key.
+	BackupWallet(ctx context.Context, in *BackupWalletRequest, opts ...grpc.CallOption) (AdminService_BackupWalletClient, error)
+	RestoreWallet(ctx context.Context, opts ...grpc.CallOption) (AdminService_RestoreWalletClient, error)
+	// Scheduled backups of the registry and every wallet.
+	ListBackups(ctx context.Context, in *ListBackupsRequest, opts ...grpc.CallOption) (*ListBackupsResponse, error)
+	VerifyBackup(ctx context.Context, in *VerifyBackupRequest, opts ...grpc.CallOption) (*VerifyBackupResponse, error)
+	// Integrity checks.  Wallets are only read.
+	CheckWallet(ctx context.Context, in *CheckWalletRequest, opts ...grpc.CallOption) (*CheckWalletResponse, error)
+}
+
+type adminServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewAdminServiceClient(cc *grpc.ClientConn) AdminServiceClient {
+	return &adminServiceClient{cc}
+}
+
+func (c *adminServiceClient) RegenerateCertificate(ctx context.Context, in *RegenerateCertificateRequest, opts ...grpc.CallOption) (*RegenerateCertificateResponse, error) {
+	out := new(RegenerateCertificateResponse)
+	err := grpc.Invoke(ctx, "/walletdrpc.AdminService/RegenerateCertificate", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) GetLogLevels(ctx context.Context, in *GetLogLevelsRequest, opts ...grpc.CallOption) (*GetLogLevelsResponse, error) {
+	out := new(GetLogLevelsResponse)
+	err := grpc.Invoke(ctx, "/walletdrpc.AdminService/GetLogLevels", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) SetLogLevel(ctx context.Context, in *SetLogLevelRequest, opts ...grpc.CallOption) (*SetLogLevelResponse, error) {
+	out := new(SetLogLevelResponse)
+	err := grpc.Invoke(ctx, "/walletdrpc.AdminService/SetLogLevel", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) TailLogs(ctx context.Context, in *TailLogsRequest, opts ...grpc.CallOption) (AdminService_TailLogsClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_AdminService_serviceDesc.Streams[0], c.cc, "/walletdrpc.AdminService/TailLogs", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &adminServiceTailLogsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type AdminService_TailLogsClient interface {
+	Recv() (*TailLogsResponse, error)
+	grpc.ClientStream
+}
+
+type adminServiceTailLogsClient struct {
+	grpc.ClientStream
+}
+
+func (x *adminServiceTailLogsClient) Recv() (*TailLogsResponse, error) {
+	m := new(TailLogsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *adminServiceClient) QueryAuditLog(ctx context.Context, in *QueryAuditLogRequest, opts ...grpc.CallOption) (*QueryAuditLogResponse, error) {
+	out := new(QueryAuditLogResponse)
+	err := grpc.Invoke(ctx, "/walletdrpc.AdminService/QueryAuditLog", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
 	}
-	return 0
+	return out, nil
 }
 
-type CreateWalletRequest struct {
-	Pass string `protobuf:"bytes,1,opt,name=pass" json:"pass,omitempty"`
+func (c *adminServiceClient) SetTenantQuota(ctx context.Context, in *SetTenantQuotaRequest, opts ...grpc.CallOption) (*SetTenantQuotaResponse, error) {
+	out := new(SetTenantQuotaResponse)
+	err := grpc.Invoke(ctx, "/walletdrpc.AdminService/SetTenantQuota", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (m *CreateWalletRequest) Reset()                    { *m = CreateWalletRequest{} }
-func (m *CreateWalletRequest) String() string            { return proto.CompactTextString(m) }
-func (*CreateWalletRequest) ProtoMessage()               {}
-func (*CreateWalletRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+func (c *adminServiceClient) GetTenantUsage(ctx context.Context, in *GetTenantUsageRequest, opts ...grpc.CallOption) (*GetTenantUsageResponse, error) {
+	out := new(GetTenantUsageResponse)
+	err := grpc.Invoke(ctx, "/walletdrpc.AdminService/GetTenantUsage", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
 
-func (m *CreateWalletRequest) GetPass() string {
-	if m != nil {
-		return m.Pass
+func (c *adminServiceClient) BackupWallet(ctx context.Context, in *BackupWalletRequest, opts ...grpc.CallOption) (AdminService_BackupWalletClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_AdminService_serviceDesc.Streams[1], c.cc, "/walletdrpc.AdminService/BackupWallet", opts...)
+	if err != nil {
+		return nil, err
 	}
-	return ""
+	x := &adminServiceBackupWalletClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
 }
 
-type CreateWalletResponse struct {
-	Uuid string `protobuf:"bytes,1,opt,name=uuid" json:"uuid,omitempty"`
+type AdminService_BackupWalletClient interface {
+	Recv() (*BackupWalletResponse, error)
+	grpc.ClientStream
 }
 
-func (m *CreateWalletResponse) Reset()                    { *m = CreateWalletResponse{} }
-func (m *CreateWalletResponse) String() string            { return proto.CompactTextString(m) }
-func (*CreateWalletResponse) ProtoMessage()               {}
-func (*CreateWalletResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+type adminServiceBackupWalletClient struct {
+	grpc.ClientStream
+}
 
-func (m *CreateWalletResponse) GetUuid() string {
-	if m != nil {
-		return m.Uuid
+func (x *adminServiceBackupWalletClient) Recv() (*BackupWalletResponse, error) {
+	m := new(BackupWalletResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
 	}
-	return ""
+	return m, nil
 }
 
-func init() {
-	proto.RegisterType((*VersionRequest)(nil), "walletdrpc.VersionRequest")
-	proto.RegisterType((*VersionResponse)(nil), "walletdrpc.VersionResponse")
-	proto.RegisterType((*PingRequest)(nil), "walletdrpc.PingRequest")
-	proto.RegisterType((*PingResponse)(nil), "walletdrpc.PingResponse")
-	proto.RegisterType((*NetworkRequest)(nil), "walletdrpc.NetworkRequest")
-	proto.RegisterType((*NetworkResponse)(nil), "walletdrpc.NetworkResponse")
-	proto.RegisterType((*CreateWalletRequest)(nil), "walletdrpc.CreateWalletRequest")
-	proto.RegisterType((*CreateWalletResponse)(nil), "walletdrpc.CreateWalletResponse")
+func (c *adminServiceClient) RestoreWallet(ctx context.Context, opts ...grpc.CallOption) (AdminService_RestoreWalletClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_AdminService_serviceDesc.Streams[2], c.cc, "/walletdrpc.AdminService/RestoreWallet", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &adminServiceRestoreWalletClient{stream}
+	return x, nil
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
+type AdminService_RestoreWalletClient interface {
+	Send(*RestoreWalletRequest) error
+	CloseAndRecv() (*RestoreWalletResponse, error)
+	grpc.ClientStream
+}
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion3
+type adminServiceRestoreWalletClient struct {
+	grpc.ClientStream
+}
 
-// Client API for VersionService service
+func (x *adminServiceRestoreWalletClient) Send(m *RestoreWalletRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
 
-type VersionServiceClient interface {
-	Version(ctx context.Context, in *VersionRequest, opts ...grpc.CallOption) (*VersionResponse, error)
+func (x *adminServiceRestoreWalletClient) CloseAndRecv() (*RestoreWalletResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(RestoreWalletResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
 }
 
-type versionServiceClient struct {
-	cc *grpc.ClientConn
+func (c *adminServiceClient) ListBackups(ctx context.Context, in *ListBackupsRequest, opts ...grpc.CallOption) (*ListBackupsResponse, error) {
+	out := new(ListBackupsResponse)
+	err := grpc.Invoke(ctx, "/walletdrpc.AdminService/ListBackups", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func NewVersionServiceClient(cc *grpc.ClientConn) VersionServiceClient {
-	return &versionServiceClient{cc}
+func (c *adminServiceClient) VerifyBackup(ctx context.Context, in *VerifyBackupRequest, opts ...grpc.CallOption) (*VerifyBackupResponse, error) {
+	out := new(VerifyBackupResponse)
+	err := grpc.Invoke(ctx, "/walletdrpc.AdminService/VerifyBackup", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (c *versionServiceClient) Version(ctx context.Context, in *VersionRequest, opts ...grpc.CallOption) (*VersionResponse, error) {
-	out := new(VersionResponse)
-	err := grpc.Invoke(ctx, "/walletdrpc.VersionService/Version", in, out, c.cc, opts...)
+func (c *adminServiceClient) CheckWallet(ctx context.Context, in *CheckWalletRequest, opts ...grpc.CallOption) (*CheckWalletResponse, error) {
+	out := new(CheckWalletResponse)
+	err := grpc.Invoke(ctx, "/walletdrpc.AdminService/CheckWallet", in, out, c.cc, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-// Server API for VersionService service
+// Server API for AdminService service
 
-type VersionServiceServer interface {
-	Version(context.Context, *VersionRequest) (*VersionResponse, error)
+type AdminServiceServer interface {
+	// Replaces the RPC server TLS keypair with a new self-signed keypair.
+	// Existing connections keep using the previous keypair.
+	RegenerateCertificate(context.Context, *RegenerateCertificateRequest) (*RegenerateCertificateResponse, error)
+	// Logging
+	GetLogLevels(context.Context, *GetLogLevelsRequest) (*GetLogLevelsResponse, error)
+	SetLogLevel(context.Context, *SetLogLevelRequest) (*SetLogLevelResponse, error)
+	TailLogs(*TailLogsRequest, AdminService_TailLogsServer) error
+	// Audit
+	QueryAuditLog(context.Context, *QueryAuditLogRequest) (*QueryAuditLogResponse, error)
+	// Quotas
+	SetTenantQuota(context.Context, *SetTenantQuotaRequest) (*SetTenantQuotaResponse, error)
+	GetTenantUsage(context.Context, *GetTenantUsageRequest) (*GetTenantUsageResponse, error)
+	// Backups are encrypted to the backup key of the daemon and can only
+	// be restored by a daemon with the same key.
+	BackupWallet(*BackupWalletRequest, AdminService_BackupWalletServer) error
+	RestoreWallet(AdminService_RestoreWalletServer) error
+	// Scheduled backups of the registry and every wallet.
+	ListBackups(context.Context, *ListBackupsRequest) (*ListBackupsResponse, error)
+	VerifyBackup(context.Context, *VerifyBackupRequest) (*VerifyBackupResponse, error)
+	// Integrity checks.  Wallets are only read.
+	CheckWallet(context.Context, *CheckWalletRequest) (*CheckWalletResponse, error)
 }
 
-func RegisterVersionServiceServer(s *grpc.Server, srv VersionServiceServer) {
-	s.RegisterService(&_VersionService_serviceDesc, srv)
+func RegisterAdminServiceServer(s *grpc.Server, srv AdminServiceServer) {
+	s.RegisterService(&_AdminService_serviceDesc, srv)
 }
 
-func _VersionService_Version_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(VersionRequest)
+func _AdminService_RegenerateCertificate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegenerateCertificateRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(VersionServiceServer).Version(ctx, in)
+		return srv.(AdminServiceServer).RegenerateCertificate(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/walletdrpc.VersionService/Version",
+		FullMethod: "/walletdrpc.AdminService/RegenerateCertificate",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(VersionServiceServer).Version(ctx, req.(*VersionRequest))
+		return srv.(AdminServiceServer).RegenerateCertificate(ctx, req.(*RegenerateCertificateRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-var _VersionService_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "walletdrpc.VersionService",
-	HandlerType: (*VersionServiceServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "Version",
-			Handler:    _VersionService_Version_Handler,
-		},
-	},
-	Streams:  []grpc.StreamDesc{},
-	Metadata: "api.proto",
+func _AdminService_GetLogLevels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLogLevelsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).GetLogLevels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walletdrpc.AdminService/GetLogLevels",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).GetLogLevels(ctx, req.(*GetLogLevelsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-// Client API for WalletDaemonService service
+func _AdminService_SetLogLevel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetLogLevelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).SetLogLevel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walletdrpc.AdminService/SetLogLevel",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).SetLogLevel(ctx, req.(*SetLogLevelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
 
-type WalletDaemonServiceClient interface {
-	// Queries
-	Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingResponse, error)
-	Network(ctx context.Context, in *NetworkRequest, opts ...grpc.CallOption) (*NetworkResponse, error)
-	// Wallet
-	CreateWallet(ctx context.Context, in *CreateWalletRequest, opts ...grpc.CallOption) (*CreateWalletResponse, error)
+func _AdminService_TailLogs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TailLogsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AdminServiceServer).TailLogs(m, &adminServiceTailLogsServer{stream})
 }
 
-type walletDaemonServiceClient struct {
-	cc *grpc.ClientConn
+type AdminService_TailLogsServer interface {
+	Send(*TailLogsResponse) error
+	grpc.ServerStream
 }
 
-func NewWalletDaemonServiceClient(cc *grpc.ClientConn) WalletDaemonServiceClient {
-	return &walletDaemonServiceClient{cc}
+type adminServiceTailLogsServer struct {
+	grpc.ServerStream
 }
 
-func (c *walletDaemonServiceClient) Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingResponse, error) {
-	out := new(PingResponse)
-	err := grpc.Invoke(ctx, "/walletdrpc.WalletDaemonService/Ping", in, out, c.cc, opts...)
-	if err != nil {
+func (x *adminServiceTailLogsServer) Send(m *TailLogsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _AdminService_QueryAuditLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryAuditLogRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(AdminServiceServer).QueryAuditLog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walletdrpc.AdminService/QueryAuditLog",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).QueryAuditLog(ctx, req.(*QueryAuditLogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *walletDaemonServiceClient) Network(ctx context.Context, in *NetworkRequest, opts ...grpc.CallOption) (*NetworkResponse, error) {
-	out := new(NetworkResponse)
-	err := grpc.Invoke(ctx, "/walletdrpc.WalletDaemonService/Network", in, out, c.cc, opts...)
-	if err != nil {
+func _AdminService_SetTenantQuota_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetTenantQuotaRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(AdminServiceServer).SetTenantQuota(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walletdrpc.AdminService/SetTenantQuota",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).SetTenantQuota(ctx, req.(*SetTenantQuotaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *walletDaemonServiceClient) CreateWallet(ctx context.Context, in *CreateWalletRequest, opts ...grpc.CallOption) (*CreateWalletResponse, error) {
-	out := new(CreateWalletResponse)
-	err := grpc.Invoke(ctx, "/walletdrpc.WalletDaemonService/CreateWallet", in, out, c.cc, opts...)
-	if err != nil {
+func _AdminService_GetTenantUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTenantUsageRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(AdminServiceServer).GetTenantUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/walletdrpc.AdminService/GetTenantUsage",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).GetTenantUsage(ctx, req.(*GetTenantUsageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-// Server API for WalletDaemonService service
+func _AdminService_BackupWallet_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(BackupWalletRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AdminServiceServer).BackupWallet(m, &adminServiceBackupWalletServer{stream})
+}
 
-type WalletDaemonServiceServer interface {
-	// Queries
-	Ping(context.Context, *PingRequest) (*PingResponse, error)
-	Network(context.Context, *NetworkRequest) (*NetworkResponse, error)
-	// Wallet
-	CreateWallet(context.Context, *CreateWalletRequest) (*CreateWalletResponse, error)
+type AdminService_BackupWalletServer interface {
+	Send(*BackupWalletResponse) error
+	grpc.ServerStream
 }
 
-func RegisterWalletDaemonServiceServer(s *grpc.Server, srv WalletDaemonServiceServer) {
-	s.RegisterService(&_WalletDaemonService_serviceDesc, srv)
+type adminServiceBackupWalletServer struct {
+	grpc.ServerStream
 }
 
-func _WalletDaemonService_Ping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(PingRequest)
+func (x *adminServiceBackupWalletServer) Send(m *BackupWalletResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _AdminService_RestoreWallet_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AdminServiceServer).RestoreWallet(&adminServiceRestoreWalletServer{stream})
+}
+
+type AdminService_RestoreWalletServer interface {
+	SendAndClose(*RestoreWalletResponse) error
+	Recv() (*RestoreWalletRequest, error)
+	grpc.ServerStream
+}
+
+type adminServiceRestoreWalletServer struct {
+	grpc.ServerStream
+}
+
+func (x *adminServiceRestoreWalletServer) SendAndClose(m *RestoreWalletResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *adminServiceRestoreWalletServer) Recv() (*RestoreWalletRequest, error) {
+	m := new(RestoreWalletRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _AdminService_ListBackups_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBackupsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(WalletDaemonServiceServer).Ping(ctx, in)
+		return srv.(AdminServiceServer).ListBackups(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/walletdrpc.WalletDaemonService/Ping",
+		FullMethod: "/walletdrpc.AdminService/ListBackups",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(WalletDaemonServiceServer).Ping(ctx, req.(*PingRequest))
+		return srv.(AdminServiceServer).ListBackups(ctx, req.(*ListBackupsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _WalletDaemonService_Network_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(NetworkRequest)
+func _AdminService_VerifyBackup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyBackupRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(WalletDaemonServiceServer).Network(ctx, in)
+		return srv.(AdminServiceServer).VerifyBackup(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/walletdrpc.WalletDaemonService/Network",
+		FullMethod: "/walletdrpc.AdminService/VerifyBackup",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(WalletDaemonServiceServer).Network(ctx, req.(*NetworkRequest))
+		return srv.(AdminServiceServer).VerifyBackup(ctx, req.(*VerifyBackupRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _WalletDaemonService_CreateWallet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(CreateWalletRequest)
+func _AdminService_CheckWallet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckWalletRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(WalletDaemonServiceServer).CreateWallet(ctx, in)
+		return srv.(AdminServiceServer).CheckWallet(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/walletdrpc.WalletDaemonService/CreateWallet",
+		FullMethod: "/walletdrpc.AdminService/CheckWallet",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(WalletDaemonServiceServer).CreateWallet(ctx, req.(*CreateWalletRequest))
+		return srv.(AdminServiceServer).CheckWallet(ctx, req.(*CheckWalletRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-var _WalletDaemonService_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "walletdrpc.WalletDaemonService",
-	HandlerType: (*WalletDaemonServiceServer)(nil),
+var _AdminService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "walletdrpc.AdminService",
+	HandlerType: (*AdminServiceServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
-			MethodName: "Ping",
-			Handler:    _WalletDaemonService_Ping_Handler,
+			MethodName: "RegenerateCertificate",
+			Handler:    _AdminService_RegenerateCertificate_Handler,
 		},
 		{
-			MethodName: "Network",
-			Handler:    _WalletDaemonService_Network_Handler,
+			MethodName: "GetLogLevels",
+			Handler:    _AdminService_GetLogLevels_Handler,
 		},
 		{
-			MethodName: "CreateWallet",
-			Handler:    _WalletDaemonService_CreateWallet_Handler,
+			MethodName: "SetLogLevel",
+			Handler:    _AdminService_SetLogLevel_Handler,
+		},
+		{
+			MethodName: "QueryAuditLog",
+			Handler:    _AdminService_QueryAuditLog_Handler,
+		},
+		{
+			MethodName: "SetTenantQuota",
+			Handler:    _AdminService_SetTenantQuota_Handler,
+		},
+		{
+			MethodName: "GetTenantUsage",
+			Handler:    _AdminService_GetTenantUsage_Handler,
+		},
+		{
+			MethodName: "ListBackups",
+			Handler:    _AdminService_ListBackups_Handler,
+		},
+		{
+			MethodName: "VerifyBackup",
+			Handler:    _AdminService_VerifyBackup_Handler,
+		},
+		{
+			MethodName: "CheckWallet",
+			Handler:    _AdminService_CheckWallet_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "TailLogs",
+			Handler:       _AdminService_TailLogs_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "BackupWallet",
+			Handler:       _AdminService_BackupWallet_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "RestoreWallet",
+			Handler:       _AdminService_RestoreWallet_Handler,
+			ClientStreams: true,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "api.proto",
 }
 
 func init() { proto.RegisterFile("api.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 368 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x74, 0x92, 0xd1, 0x4e, 0xfa, 0x30,
-	0x14, 0xc6, 0xb3, 0xff, 0x1f, 0x30, 0x1c, 0x19, 0x98, 0x42, 0xe2, 0x32, 0x13, 0x25, 0x4b, 0x4c,
-	0xd4, 0x0b, 0x2e, 0xf0, 0x46, 0x6f, 0xd5, 0x5b, 0x8d, 0x0e, 0xa3, 0x97, 0xa4, 0x6c, 0x27, 0x5a,
-	0x1d, 0x5b, 0x6d, 0x3b, 0x78, 0x3e, 0x9f, 0xc6, 0xd7, 0x70, 0x6b, 0x0b, 0x6c, 0x8a, 0x77, 0x3d,
-	0xbf, 0xf3, 0xad, 0xe7, 0x3b, 0x5f, 0x07, 0x6d, 0xca, 0xd9, 0x88, 0x8b, 0x4c, 0x65, 0x04, 0x96,
-	0x34, 0x49, 0x50, 0xc5, 0x82, 0x47, 0xc1, 0x1e, 0x74, 0x9f, 0x50, 0x48, 0x96, 0xa5, 0x21, 0x7e,
-	0xe4, 0x28, 0x55, 0xf0, 0xe9, 0x40, 0x6f, 0x8d, 0x24, 0xcf, 0x52, 0x89, 0xe4, 0x18, 0xba, 0x0b,
-	0x83, 0xa6, 0x52, 0x09, 0x96, 0xbe, 0x78, 0xce, 0xd0, 0x39, 0x69, 0x87, 0xae, 0xa5, 0x13, 0x0d,
-	0xc9, 0x00, 0x9a, 0x73, 0xfa, 0x96, 0x09, 0xef, 0x5f, 0xd1, 0x75, 0x43, 0x53, 0x68, 0xca, 0xd2,
-	0x82, 0xfe, 0xb7, 0xb4, 0x2c, 0x4a, 0xca, 0xa9, 0x8a, 0x5e, 0xbd, 0x86, 0xa1, 0xba, 0x20, 0x87,
-	0x00, 0x5c, 0xa0, 0xc0, 0x04, 0xa9, 0x44, 0xaf, 0xa9, 0x87, 0x54, 0x48, 0x69, 0x64, 0x96, 0xb3,
-	0x24, 0x9e, 0xce, 0x51, 0xd1, 0x98, 0x2a, 0xea, 0xb5, 0x8c, 0x11, 0x4d, 0x6f, 0x2d, 0x0c, 0x5c,
-	0xd8, 0xbd, 0x2f, 0x0c, 0xad, 0x56, 0xea, 0x42, 0xc7, 0x94, 0x66, 0x9d, 0x72, 0xe9, 0x3b, 0x54,
-	0xcb, 0x4c, 0xbc, 0xaf, 0x14, 0x17, 0xd0, 0x5b, 0x93, 0xcd, 0xce, 0x34, 0x52, 0x6c, 0x81, 0xd3,
-	0xd4, 0x74, 0xf4, 0xce, 0x6e, 0xe8, 0x1a, 0x6a, 0xe5, 0xc1, 0x29, 0xf4, 0xaf, 0x05, 0x52, 0x85,
-	0xcf, 0x3a, 0x54, 0x7b, 0x21, 0x21, 0xd0, 0xe0, 0x54, 0x4a, 0x9b, 0x93, 0x3e, 0x07, 0x67, 0x30,
-	0xa8, 0x4b, 0xed, 0xa4, 0x42, 0x9b, 0xe7, 0x2c, 0x5e, 0x69, 0xcb, 0xf3, 0xf8, 0x71, 0xfd, 0x2e,
-	0x13, 0x14, 0x0b, 0x16, 0x21, 0xb9, 0x82, 0x1d, 0x4b, 0x88, 0x3f, 0xda, 0xbc, 0xe0, 0xa8, 0xfe,
-	0x7c, 0xfe, 0xc1, 0xd6, 0x9e, 0x99, 0x34, 0xfe, 0x72, 0xa0, 0x6f, 0x86, 0xdf, 0x50, 0x9c, 0x6f,
-	0xee, 0xbe, 0x84, 0x46, 0x19, 0x10, 0xd9, 0xaf, 0x7e, 0x5c, 0x49, 0xd0, 0xf7, 0x7e, 0x37, 0xac,
-	0xf9, 0xc2, 0x96, 0x8d, 0xa2, 0x6e, 0xab, 0x1e, 0x70, 0xdd, 0xd6, 0xcf, 0xa8, 0x1f, 0xa0, 0x53,
-	0x0d, 0x86, 0x1c, 0x55, 0xc5, 0x5b, 0xd2, 0xf5, 0x87, 0x7f, 0x0b, 0xcc, 0x95, 0xb3, 0x96, 0xfe,
-	0xd5, 0xcf, 0xbf, 0x03, 0x00, 0x00, 0xff, 0xff, 0x24, 0x20, 0x50, 0xcd, 0xf7, 0x02, 0x00, 0x00,
+	// 2700 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xb4, 0x1a, 0x5d, 0x73, 0xdb, 0xc6,
+	0x71, 0xf8, 0x25, 0x92, 0x4b, 0x51, 0x92, 0x4f, 0x94, 0xcc, 0x40, 0xb2, 0x45, 0x21, 0xce, 0x44,
+	0x49, 0x1b, 0x35, 0x56, 0x9b, 0xc4, 0x69, 0x67, 0x32, 0xb5, 0x65, 0x8f, 0xac, 0x44, 0xb5, 0x6b,
+	0x48, 0xb6, 0x67, 0x9a, 0x89, 0x59, 0x88, 0x38, 0x49, 0xa8, 0x88, 0x0f, 0xe3, 0x0e, 0xb2, 0xd4,
+	0x87, 0xfe, 0x86, 0xbe, 0xb5, 0x3f, 0xa0, 0x2f, 0x7d, 0x6a, 0xff, 0x42, 0x1f, 0xfb, 0x2f, 0xda,
+	0x69, 0xdf, 0xfb, 0x13, 0xda, 0xb9, 0x0f, 0x00, 0x77, 0x20, 0x40, 0x4a, 0x99, 0xe9, 0xdb, 0xed,
+	0xde, 0xde, 0xde, 0xee, 0xde, 0xee, 0x62, 0x77, 0x49, 0x68, 0xdb, 0xa1, 0xbb, 0x1d, 0x46, 0x01,
+	0x0d, 0x10, 0xbc, 0xb3, 0xc7, 0x63, 0x4c, 0x9d, 0x28, 0x1c, 0x99, 0x4b, 0xb0, 0xf0, 0x0a, 0x47,
+	0xc4, 0x0d, 0x7c, 0x0b, 0xbf, 0x8d, 0x31, 0xa1, 0xe6, 0xdf, 0x2a, 0xb0, 0x98, 0xa2, 0x48, 0x18,
+	0xf8, 0x04, 0xa3, 0x0f, 0x60, 0xe1, 0x42, 0xa0, 0x86, 0x84, 0x46, 0xae, 0x7f, 0xda, 0xaf, 0x0c,
+	0x2a, 0x5b, 0x6d, 0xab, 0x2b, 0xb1, 0x87, 0x1c, 0x89, 0x7a, 0xd0, 0xf0, 0xec, 0xdf, 0x04, 0x51,
+	0xbf, 0x3a, 0xa8, 0x6c, 0x75, 0x2d, 0x01, 0x70, 0xac, 0xeb, 0x07, 0x51, 0xbf, 0x26, 0xb1, 0xae,
+	0x2f, 0xb0, 0xa1, 0x4d, 0x47, 0x67, 0xfd, 0xba, 0xc0, 0x72, 0x00, 0xdd, 0x05, 0x08, 0x23, 0x1c,
+	0xe1, 0x31, 0xb6, 0x09, 0xee, 0x37, 0xf8, 0x25, 0x0a, 0x86, 0x09, 0x72, 0x1c, 0xbb, 0x63, 0x67,
+	0xe8, 0x61, 0x6a, 0x3b, 0x36, 0xb5, 0xfb, 0x73, 0x42, 0x10, 0x8e, 0xfd, 0x85, 0x44, 0x9a, 0x5d,
+	0xe8, 0xfc, 0xd2, 0xf5, 0x4f, 0x13, 0x95, 0x16, 0x60, 0x5e, 0x80, 0x42, 0x1d, 0xf3, 0x3e, 0x2c,
+	0x3c, 0xc3, 0xf4, 0x5d, 0x10, 0x9d, 0x4b, 0x0a, 0xb4, 0x01, 0x1d, 0x61, 0x94, 0x61, 0x1c, 0xbb,
+	0x8e, 0xd4, 0x4e, 0xda, 0xe9, 0x65, 0xec, 0x3a, 0xe6, 0x11, 0x2c, 0xa6, 0x47, 0x32, 0xa3, 0xd8,
+	0x23, 0xea, 0x5e, 0xe0, 0xa1, 0x2f, 0x76, 0xf8, 0xb1, 0xae, 0xd5, 0x15, 0x58, 0x49, 0x8e, 0x0c,
+	0x68, 0xc9, 0x7d, 0xd2, 0xaf, 0x0e, 0x6a, 0x5b, 0x5d, 0x2b, 0x85, 0xcd, 0x5d, 0x58, 0xde, 0x8d,
+	0xb0, 0x4d, 0xf1, 0x6b, 0x7e, 0x53, 0x22, 0x0d, 0x82, 0x7a, 0x68, 0x13, 0x22, 0xc5, 0xe0, 0x6b,
+	0xd4, 0x87, 0x66, 0x72, 0x4d, 0x95, 0xa3, 0x13, 0xd0, 0xfc, 0x18, 0x7a, 0x3a, 0x13, 0x29, 0x1f,
+	0x82, 0xba, 0xa2, 0x0c, 0x5f, 0x9b, 0x96, 0x4e, 0x4b, 0x92, 0x1b, 0x15, 0xee, 0x15, 0x8d, 0x3b,
+	0x1a, 0x40, 0x87, 0xdd, 0x1f, 0x9e, 0x45, 0x36, 0xc1, 0x42, 0x83, 0x79, 0x4b, 0x45, 0x99, 0x17,
+	0xb0, 0x92, 0xe3, 0x29, 0x05, 0xe8, 0x41, 0xc3, 0xf5, 0x1d, 0x7c, 0x29, 0xed, 0x22, 0x80, 0x54,
+	0xac, 0x6a, 0x26, 0x16, 0xba, 0x03, 0x80, 0xa3, 0x28, 0x88, 0x86, 0xa3, 0xc0, 0xc1, 0xd2, 0x4f,
+	0xda, 0x1c, 0xb3, 0x1b, 0x38, 0x9c, 0x11, 0x07, 0xb8, 0xaf, 0xb4, 0x2d, 0x01, 0x98, 0x3f, 0x81,
+	0x5b, 0xcf, 0x43, 0xec, 0xeb, 0xa6, 0x9b, 0xf9, 0x90, 0x3d, 0x40, 0xea, 0x29, 0xe9, 0x11, 0xaf,
+	0xa1, 0x7f, 0x88, 0xa9, 0x40, 0x3e, 0x8c, 0x69, 0x30, 0x0e, 0x6c, 0xe7, 0xba, 0x2c, 0xd9, 0x0b,
+	0xdb, 0xf2, 0x0c, 0xd7, 0xaa, 0x65, 0xa5, 0xb0, 0xb9, 0x06, 0xef, 0x15, 0x30, 0x96, 0xb7, 0xfe,
+	0xa3, 0x0a, 0x20, 0xb6, 0xf6, 0xfd, 0x93, 0xa0, 0xe8, 0xc1, 0xca, 0x9f, 0x1d, 0xad, 0xc2, 0x1c,
+	0xc5, 0xbe, 0xed, 0x53, 0x6e, 0xaf, 0xb6, 0x25, 0x21, 0x76, 0x62, 0xc4, 0x9f, 0xc3, 0xe1, 0xe6,
+	0xaa, 0x59, 0x09, 0x88, 0xd6, 0xa0, 0x3d, 0xb6, 0x09, 0x1d, 0xc6, 0x04, 0x3b, 0x3c, 0xb6, 0x6a,
+	0x56, 0x8b, 0x21, 0x5e, 0x12, 0xac, 0x2b, 0x31, 0xa7, 0x2b, 0xc1, 0x04, 0x0b, 0x42, 0xec, 0xf7,
+	0x9b, 0x1c, 0xcf, 0xd7, 0x0c, 0xe7, 0xdb, 0x1e, 0xee, 0xb7, 0x84, 0xb0, 0x6c, 0xcd, 0x70, 0xd4,
+	0x3e, 0x25, 0xfd, 0xf6, 0xa0, 0xc6, 0x70, 0x6c, 0x8d, 0x7e, 0x0e, 0xad, 0x34, 0x56, 0x61, 0x50,
+	0xdb, 0xea, 0xec, 0xdc, 0xdb, 0xce, 0xf2, 0xcf, 0x76, 0xa6, 0xfe, 0x76, 0x12, 0xbd, 0x4f, 0x7c,
+	0x1a, 0x5d, 0x59, 0xe9, 0x29, 0xe3, 0x67, 0xd0, 0xd5, 0xb6, 0xd0, 0x12, 0xd4, 0xce, 0xf1, 0x95,
+	0x34, 0x13, 0x5b, 0x32, 0x07, 0xb9, 0xb0, 0xc7, 0x31, 0x96, 0x36, 0x12, 0xc0, 0x4f, 0xab, 0x0f,
+	0x2a, 0xe6, 0x5f, 0x2a, 0x80, 0x0e, 0x5c, 0x42, 0x73, 0xfe, 0x9e, 0x48, 0x5a, 0x51, 0x24, 0x7d,
+	0xaa, 0x48, 0x5a, 0xe5, 0x92, 0xfe, 0x50, 0x95, 0x74, 0x92, 0xcb, 0xff, 0x47, 0xe2, 0x3d, 0x58,
+	0xd6, 0xae, 0x92, 0xc1, 0xf4, 0x29, 0x34, 0x85, 0x30, 0x42, 0xe8, 0xce, 0xce, 0x6a, 0xb1, 0x19,
+	0xad, 0x84, 0xcc, 0xfc, 0x4f, 0x15, 0xd6, 0x5e, 0x86, 0x4e, 0x1a, 0x98, 0x89, 0x48, 0xd7, 0xf6,
+	0xeb, 0xf7, 0xa0, 0x45, 0x30, 0x1d, 0xf2, 0x67, 0x16, 0x7e, 0xdd, 0x24, 0x98, 0x3e, 0x93, 0x2f,
+	0xcd, 0xd1, 0x35, 0xe5, 0xf5, 0xdf, 0x83, 0x96, 0xed, 0x38, 0x43, 0x6e, 0xd7, 0x3a, 0xb7, 0x6b,
+	0xd3, 0x76, 0x9c, 0x23, 0x66, 0xda, 0x0d, 0xe8, 0x44, 0xd8, 0x0b, 0x2e, 0xb0, 0xd8, 0x6d, 0xf0,
+	0x5d, 0x10, 0x28, 0x4e, 0xf0, 0x2d, 0xcc, 0xb3, 0xab, 0x94, 0xac, 0xce, 0x54, 0x7c, 0xa0, 0xaa,
+	0x38, 0x45, 0x95, 0xed, 0x43, 0x4c, 0x35, 0x83, 0x5b, 0x1d, 0x92, 0x61, 0xd0, 0x87, 0xb0, 0xe8,
+	0xe0, 0x31, 0xa6, 0x38, 0xe3, 0xdf, 0xe4, 0x12, 0x2c, 0x08, 0x74, 0x42, 0x68, 0x7c, 0x05, 0x4b,
+	0x79, 0x4e, 0x37, 0x7a, 0xba, 0x67, 0xb0, 0x5e, 0x2c, 0xa5, 0x7c, 0xc3, 0x6d, 0x98, 0x13, 0x0a,
+	0x71, 0x76, 0xe5, 0x4f, 0x28, 0xa9, 0xcc, 0x2f, 0xa0, 0xb7, 0x87, 0xa9, 0xb2, 0x71, 0xdd, 0x24,
+	0xb7, 0x07, 0x2b, 0xb9, 0x83, 0xdf, 0x53, 0x82, 0xcf, 0x61, 0xf9, 0x31, 0xb7, 0xd1, 0x0d, 0xb3,
+	0xec, 0x2a, 0xf4, 0xf4, 0x73, 0x32, 0xe3, 0x9d, 0xc1, 0xc2, 0x23, 0x7b, 0x6c, 0xfb, 0x23, 0x7c,
+	0x6d, 0x2f, 0xfc, 0x0c, 0x56, 0x23, 0xfc, 0x36, 0x76, 0x23, 0xec, 0x0c, 0x47, 0x81, 0x7f, 0xe2,
+	0x46, 0x9e, 0x4d, 0xdd, 0xc0, 0x27, 0xdc, 0xfe, 0x0d, 0x6b, 0x25, 0xd9, 0xdd, 0x55, 0x37, 0xcd,
+	0x1f, 0xc0, 0x62, 0x7a, 0x93, 0x54, 0xbe, 0x0f, 0xcd, 0x63, 0x81, 0xe2, 0xd7, 0xd4, 0xac, 0x04,
+	0x34, 0xff, 0x5b, 0x01, 0x74, 0x14, 0xd9, 0x3e, 0x61, 0x9f, 0xee, 0xc0, 0x3f, 0x8c, 0x3d, 0xcf,
+	0x8e, 0xae, 0x78, 0x96, 0xb8, 0xcc, 0x12, 0x32, 0x5b, 0x33, 0xdc, 0x45, 0x10, 0x53, 0x59, 0xe2,
+	0xf0, 0x35, 0x63, 0x6c, 0x3b, 0x4e, 0x84, 0x09, 0x91, 0x01, 0x91, 0x80, 0x2c, 0xab, 0x8e, 0x6c,
+	0x8a, 0x4f, 0x83, 0xe8, 0x4a, 0x7e, 0xbc, 0x52, 0x98, 0x25, 0x70, 0xdb, 0x0b, 0x62, 0x9f, 0xca,
+	0x5c, 0x2c, 0x21, 0xe6, 0x71, 0x27, 0x18, 0xf3, 0x24, 0x5c, 0xb3, 0xd8, 0x12, 0xdd, 0x83, 0xae,
+	0xae, 0x79, 0x93, 0xef, 0xe9, 0x48, 0xf6, 0x11, 0x3d, 0x1e, 0x07, 0xa3, 0xf3, 0xe1, 0x99, 0x4d,
+	0xce, 0x64, 0x5e, 0x6e, 0x73, 0xcc, 0x53, 0x9b, 0x9c, 0xa1, 0x75, 0x68, 0x53, 0xd7, 0xc3, 0x84,
+	0xda, 0x5e, 0xd8, 0x6f, 0x73, 0x06, 0x19, 0xc2, 0x74, 0xe0, 0x36, 0xcb, 0x3a, 0x8a, 0x11, 0xc8,
+	0xb5, 0x5f, 0x08, 0x41, 0xfd, 0x24, 0x0a, 0xbc, 0xc4, 0x24, 0x6c, 0xcd, 0x82, 0x64, 0xc4, 0x75,
+	0x93, 0x45, 0x1f, 0x07, 0xcc, 0x37, 0xd0, 0x9f, 0xbc, 0x45, 0xbe, 0xce, 0x23, 0x98, 0xa7, 0x0a,
+	0x5e, 0x66, 0xb9, 0xbb, 0xaa, 0x83, 0x4e, 0x3e, 0x91, 0xa5, 0x9d, 0x31, 0x9f, 0xc0, 0x2d, 0x85,
+	0xe6, 0x79, 0x4c, 0x43, 0xfd, 0x75, 0x2a, 0xfa, 0xeb, 0x64, 0x2f, 0x50, 0x55, 0x5f, 0xc0, 0xfc,
+	0x57, 0x05, 0x56, 0x0f, 0xb1, 0xef, 0x28, 0xbc, 0xae, 0x6d, 0x0c, 0x56, 0xc1, 0xa6, 0xc5, 0x11,
+	0xe7, 0x3b, 0x6f, 0x29, 0x18, 0xf4, 0x05, 0x34, 0x03, 0x2e, 0x17, 0xf3, 0x15, 0xa6, 0xe1, 0x9d,
+	0x12, 0x0d, 0x85, 0xf4, 0x56, 0x42, 0xcd, 0xd5, 0x18, 0x09, 0x9b, 0x8a, 0x92, 0x39, 0x01, 0xa7,
+	0x44, 0x48, 0x63, 0x5a, 0x84, 0x7c, 0x02, 0xb7, 0x27, 0x94, 0xcc, 0x4a, 0xc7, 0xbc, 0xe3, 0x9b,
+	0xbb, 0xd0, 0x38, 0xb0, 0x8f, 0xf1, 0x98, 0x6f, 0x5e, 0x85, 0x38, 0xdd, 0xbc, 0x0a, 0x31, 0xf3,
+	0xd9, 0x08, 0x9f, 0xc8, 0x8c, 0xc8, 0x96, 0xcc, 0x01, 0xc6, 0x8c, 0x5c, 0x46, 0x84, 0x00, 0xcc,
+	0x37, 0x3c, 0xc3, 0x72, 0x3e, 0xd7, 0xf7, 0xaf, 0x8f, 0x60, 0x8e, 0x9f, 0x26, 0xf2, 0xb3, 0x7c,
+	0x4b, 0xfb, 0x2c, 0xb3, 0x1d, 0x4b, 0x12, 0x98, 0xcb, 0x70, 0x4b, 0xe1, 0x2f, 0x93, 0xce, 0xb7,
+	0xb0, 0xb4, 0x77, 0xe3, 0x4b, 0x13, 0x2d, 0xab, 0x8a, 0x96, 0x08, 0xea, 0x11, 0x3e, 0x11, 0x0f,
+	0xd7, 0xb6, 0xf8, 0xda, 0xfc, 0x0a, 0x6e, 0xed, 0xe5, 0x6f, 0x54, 0x24, 0xae, 0xcc, 0x92, 0xf8,
+	0x73, 0x58, 0x7e, 0x72, 0x19, 0x06, 0xd1, 0x0d, 0xe5, 0x63, 0x55, 0xbf, 0x7e, 0x2e, 0x7b, 0x3a,
+	0xfe, 0x85, 0xab, 0x70, 0xcf, 0xe3, 0x6b, 0xf3, 0x6b, 0x58, 0xde, 0xf7, 0x6e, 0x7e, 0x47, 0xca,
+	0xab, 0xaa, 0xf0, 0x3a, 0x80, 0xde, 0xbe, 0x57, 0x70, 0xaf, 0x01, 0x2d, 0x97, 0xe3, 0xb1, 0x23,
+	0xeb, 0xfd, 0x14, 0x66, 0xae, 0x4b, 0xce, 0xdd, 0x30, 0xc4, 0x8e, 0xcc, 0x11, 0x09, 0xc8, 0xb4,
+	0x17, 0x5f, 0x88, 0x27, 0x17, 0xd8, 0xa7, 0xd7, 0xd7, 0xfe, 0xef, 0x15, 0xe8, 0xe9, 0x07, 0x33,
+	0xf5, 0xcf, 0x5d, 0x3f, 0xf5, 0x5c, 0xb6, 0xd6, 0x33, 0x5f, 0x35, 0x97, 0xf9, 0xf2, 0x77, 0xd5,
+	0x26, 0xac, 0xa0, 0x94, 0xe0, 0x75, 0xbd, 0x04, 0x47, 0x50, 0xe7, 0xb9, 0x56, 0xf4, 0xa9, 0x7c,
+	0xcd, 0x72, 0xca, 0x19, 0x76, 0x4f, 0xcf, 0x28, 0x4f, 0xe0, 0x0d, 0x4b, 0x42, 0x8c, 0x8b, 0x13,
+	0x05, 0xdc, 0x06, 0x2c, 0x7b, 0xd7, 0xad, 0x04, 0x34, 0xef, 0xc2, 0xba, 0x85, 0x4f, 0xb1, 0x8f,
+	0x23, 0x9b, 0xe2, 0x5d, 0x1c, 0x51, 0xf7, 0xc4, 0x65, 0x1f, 0x89, 0xa4, 0x7b, 0x7d, 0x08, 0x77,
+	0x4a, 0xf6, 0xa5, 0xce, 0x03, 0xe8, 0x8c, 0x32, 0xb4, 0x7c, 0x79, 0x15, 0x65, 0xae, 0xc0, 0x32,
+	0x73, 0xd2, 0xe0, 0xf4, 0x00, 0x5f, 0x64, 0x0e, 0x60, 0xfe, 0xa1, 0x02, 0x3d, 0x1d, 0x2f, 0x39,
+	0x3e, 0x86, 0xb9, 0x31, 0xbe, 0xc8, 0xfc, 0x57, 0x2b, 0x84, 0x8b, 0x4e, 0x6c, 0x0b, 0x50, 0x14,
+	0x5f, 0xf2, 0xac, 0xf1, 0x25, 0x74, 0x14, 0xf4, 0x8d, 0x2a, 0xa9, 0xa7, 0x80, 0x0e, 0xb3, 0x6b,
+	0x12, 0xb7, 0x58, 0x87, 0x36, 0x89, 0x8f, 0xc9, 0x15, 0xa1, 0xd8, 0x93, 0x7c, 0x32, 0x04, 0xe3,
+	0xc6, 0x2f, 0x4e, 0xb8, 0x71, 0x80, 0xa9, 0xae, 0x71, 0x92, 0x39, 0xe1, 0x19, 0x2c, 0x1e, 0xd9,
+	0xee, 0xf8, 0x20, 0x38, 0x4d, 0x9d, 0x6e, 0x0d, 0xda, 0x9e, 0xeb, 0x0f, 0x05, 0x0f, 0xc1, 0xbd,
+	0xe5, 0xb9, 0x3e, 0x3f, 0xc7, 0xd2, 0x7a, 0x7a, 0x93, 0xc8, 0x43, 0x6d, 0x4b, 0xc1, 0x98, 0x14,
+	0x96, 0x32, 0x7e, 0xd2, 0x8a, 0xdf, 0x43, 0x5c, 0xe6, 0x52, 0x63, 0xd7, 0x4f, 0x0b, 0x6b, 0xb6,
+	0x56, 0x5d, 0xa7, 0xae, 0xbb, 0xce, 0x1f, 0xab, 0xd0, 0x79, 0x18, 0x3b, 0x2e, 0xb5, 0xf0, 0x28,
+	0x88, 0x78, 0x13, 0x47, 0x98, 0x36, 0x49, 0x89, 0x53, 0xb7, 0x52, 0x78, 0x46, 0x14, 0x94, 0x75,
+	0x93, 0xab, 0x30, 0xe7, 0x61, 0x7a, 0x16, 0x38, 0xd2, 0xf7, 0x25, 0x94, 0x8f, 0x9a, 0xc6, 0x44,
+	0xd4, 0xdc, 0x01, 0x88, 0x84, 0x61, 0x87, 0xae, 0x23, 0xa7, 0x34, 0x6d, 0x89, 0xd9, 0x17, 0x29,
+	0x21, 0x1e, 0x8d, 0xd8, 0x47, 0xb9, 0x29, 0x5b, 0x0b, 0x01, 0x66, 0xcd, 0x7e, 0x4b, 0x69, 0xf6,
+	0xd9, 0xe3, 0x84, 0x11, 0xbe, 0x10, 0xb5, 0x4d, 0x5b, 0x3c, 0x0e, 0x43, 0xf0, 0xd2, 0x26, 0x89,
+	0x43, 0xc8, 0xe2, 0xd0, 0xfc, 0x73, 0x05, 0x7a, 0x2f, 0x62, 0x1c, 0x5d, 0x71, 0xfb, 0x1c, 0x04,
+	0xc9, 0x30, 0x48, 0xd1, 0xb4, 0x52, 0xa2, 0x69, 0x75, 0x9a, 0xa6, 0x93, 0xf9, 0xa1, 0x07, 0x0d,
+	0xe2, 0x32, 0x8b, 0x8b, 0x76, 0x5b, 0x00, 0x0c, 0x1b, 0xfb, 0xd4, 0x1d, 0xcb, 0xe2, 0x4e, 0x00,
+	0x0c, 0x3b, 0x76, 0x3d, 0x57, 0x24, 0x87, 0xae, 0x25, 0x00, 0xf3, 0x6b, 0x58, 0xc9, 0x89, 0x2a,
+	0x3d, 0xe8, 0x3e, 0x34, 0x23, 0xfe, 0xb2, 0x49, 0x20, 0xde, 0x56, 0x03, 0x51, 0x79, 0x79, 0x2b,
+	0xa1, 0x33, 0x7f, 0x07, 0x9d, 0x23, 0xae, 0xd0, 0x8b, 0x38, 0xa0, 0x36, 0x93, 0xde, 0xb3, 0x2f,
+	0x87, 0x59, 0xeb, 0xc8, 0x9c, 0x02, 0x3c, 0xfb, 0x52, 0xf6, 0x97, 0xe8, 0x1e, 0x2c, 0x30, 0x02,
+	0xc7, 0x25, 0xe7, 0xc3, 0xe3, 0x2b, 0x8a, 0x45, 0x59, 0x5d, 0xb7, 0xe6, 0x3d, 0xfb, 0xf2, 0xb1,
+	0x4b, 0xce, 0x1f, 0x31, 0x1c, 0xda, 0x82, 0x25, 0x46, 0xc5, 0x3a, 0xff, 0x94, 0x57, 0x8d, 0xd3,
+	0xb1, 0xd3, 0xd9, 0x40, 0x85, 0x98, 0x7f, 0xad, 0x24, 0x02, 0xbc, 0x24, 0xf6, 0x29, 0x2e, 0x35,
+	0xf7, 0x27, 0xd0, 0x78, 0xcb, 0x24, 0xe4, 0xd7, 0xe5, 0x14, 0x53, 0x14, 0xb0, 0x04, 0x15, 0xf3,
+	0x17, 0xfd, 0xde, 0x04, 0x64, 0x8e, 0xa6, 0x08, 0x2f, 0x02, 0xa4, 0xed, 0xa4, 0x92, 0x6f, 0xc2,
+	0xbc, 0x26, 0x75, 0x83, 0x13, 0x74, 0x02, 0x45, 0xe4, 0x37, 0xb0, 0x72, 0x88, 0xa9, 0x7a, 0xe9,
+	0x0c, 0x57, 0xb9, 0x99, 0xec, 0x66, 0x1f, 0x56, 0xf3, 0xfc, 0x65, 0x16, 0xfa, 0x11, 0xef, 0xd3,
+	0x14, 0x73, 0xcd, 0xb8, 0xd9, 0xfc, 0x06, 0x56, 0xf3, 0x07, 0x32, 0x57, 0x11, 0x34, 0x85, 0xae,
+	0xa2, 0x9e, 0x48, 0xe8, 0xd8, 0xc7, 0xf7, 0x91, 0x3d, 0x3a, 0x8f, 0xc3, 0x1b, 0x36, 0x77, 0x1f,
+	0x43, 0x4f, 0x3f, 0x37, 0xa5, 0xf4, 0xf8, 0x0e, 0x7a, 0x16, 0x26, 0x34, 0x88, 0x26, 0x47, 0x9c,
+	0x79, 0x5a, 0x36, 0x40, 0xf0, 0xf1, 0xbb, 0x61, 0x3a, 0x1d, 0x6c, 0xb1, 0x2f, 0xed, 0x3b, 0x1e,
+	0x63, 0x25, 0xe9, 0xc9, 0x7c, 0x00, 0x2b, 0x39, 0xf6, 0x52, 0x96, 0x99, 0x4a, 0x9c, 0x01, 0x08,
+	0x25, 0x92, 0xd1, 0x1b, 0x9f, 0x67, 0x54, 0x94, 0x79, 0xc6, 0xf4, 0x84, 0xd9, 0x83, 0xc6, 0x89,
+	0x3b, 0xc6, 0x24, 0x69, 0x70, 0x38, 0xc0, 0xf8, 0x10, 0xf7, 0xb7, 0x58, 0xba, 0x21, 0x5f, 0xb3,
+	0x89, 0x23, 0x6b, 0x7a, 0xc4, 0x6d, 0xe9, 0xb7, 0x57, 0x8e, 0x79, 0x52, 0x6c, 0x36, 0xe6, 0x39,
+	0x16, 0xa8, 0xa2, 0x31, 0x4f, 0x26, 0xb1, 0x95, 0x90, 0x99, 0x1f, 0xc1, 0xf2, 0x2b, 0x1c, 0xb9,
+	0x27, 0x57, 0x62, 0x53, 0x31, 0x70, 0x5e, 0x23, 0x73, 0x07, 0x7a, 0x3a, 0x69, 0x56, 0xbb, 0x85,
+	0x51, 0x70, 0x3c, 0xc6, 0x9e, 0xb8, 0xb5, 0x6d, 0xa5, 0xb0, 0xf9, 0xcf, 0x0a, 0x74, 0x84, 0x6d,
+	0x77, 0xcf, 0xf0, 0xe8, 0x7c, 0x76, 0xd1, 0x58, 0x3e, 0xb1, 0x54, 0xaf, 0xa9, 0xe9, 0xd7, 0xb0,
+	0x3d, 0xd9, 0xce, 0x10, 0xd9, 0xde, 0xa4, 0x30, 0x7b, 0x08, 0xd9, 0xb1, 0x61, 0x11, 0xbf, 0x5d,
+	0x2b, 0x43, 0xb0, 0xe9, 0x4e, 0xec, 0x93, 0x10, 0xfb, 0x74, 0x98, 0x34, 0x56, 0x22, 0xb9, 0x2e,
+	0x48, 0xf4, 0xf3, 0xac, 0x81, 0x4a, 0xda, 0xff, 0xa6, 0xde, 0xfe, 0x7f, 0x06, 0x88, 0x2b, 0x77,
+	0xc3, 0x38, 0xf8, 0x53, 0x05, 0x96, 0xb5, 0x73, 0x59, 0x28, 0xea, 0xa3, 0xba, 0xdb, 0x93, 0x53,
+	0x16, 0x7e, 0x2e, 0x4b, 0x62, 0xf7, 0xa1, 0x17, 0xfb, 0x11, 0x3e, 0x75, 0x09, 0xc5, 0xac, 0x8d,
+	0x4b, 0xce, 0x8b, 0x42, 0x63, 0x59, 0xdd, 0x4b, 0x12, 0xf7, 0x87, 0xb0, 0xe8, 0xb9, 0x84, 0xb8,
+	0xfe, 0xa9, 0x92, 0x91, 0xf9, 0x54, 0x4b, 0xa2, 0x25, 0xe1, 0xce, 0x51, 0xfa, 0x13, 0xcf, 0x21,
+	0x8e, 0x2e, 0xdc, 0x11, 0x6b, 0xb5, 0x9b, 0x12, 0x83, 0x0c, 0x55, 0x34, 0xfd, 0x97, 0x20, 0x63,
+	0xad, 0x70, 0x4f, 0x28, 0xb9, 0xf3, 0xef, 0x4e, 0x52, 0xba, 0x3f, 0xb6, 0xb1, 0x97, 0xf1, 0xfe,
+	0x12, 0xea, 0xec, 0xb7, 0x16, 0xa4, 0xe9, 0xac, 0xfc, 0x18, 0x63, 0xf4, 0x27, 0x37, 0xd2, 0x09,
+	0x40, 0x33, 0xfd, 0xd1, 0x44, 0x25, 0xd2, 0x7f, 0xab, 0x31, 0xd6, 0x0a, 0xf7, 0x24, 0x8f, 0x17,
+	0x30, 0xaf, 0xfe, 0x18, 0x81, 0x36, 0x54, 0xe2, 0x82, 0xdf, 0x5a, 0x8c, 0x41, 0x39, 0x81, 0x64,
+	0xf9, 0x0a, 0xba, 0x2a, 0x9e, 0xa0, 0xd2, 0x23, 0x49, 0x70, 0x1b, 0x9b, 0x53, 0x28, 0x04, 0xd7,
+	0x4f, 0x2b, 0xe8, 0x1b, 0x80, 0xec, 0xc3, 0x89, 0xb4, 0x31, 0xc0, 0xc4, 0xef, 0x1a, 0xc6, 0xdd,
+	0xb2, 0x6d, 0x29, 0xe4, 0xaf, 0x79, 0xe3, 0xab, 0xff, 0xce, 0x80, 0xb4, 0x49, 0x7b, 0xd9, 0xef,
+	0x1b, 0xc6, 0x07, 0x33, 0xa8, 0xe4, 0x0d, 0xcf, 0xa0, 0xa3, 0xcc, 0xa5, 0xd1, 0xdd, 0xe9, 0xb3,
+	0x71, 0x63, 0xa3, 0x74, 0x5f, 0xf2, 0x3b, 0x82, 0xae, 0x36, 0xa3, 0xd4, 0xcd, 0x5a, 0x34, 0xf7,
+	0x34, 0x36, 0xa7, 0x50, 0x48, 0xae, 0x2e, 0xf4, 0x8a, 0x46, 0xb0, 0xe8, 0xc3, 0x6b, 0x8e, 0x92,
+	0x8d, 0xad, 0xd9, 0x84, 0x99, 0xab, 0xa9, 0x33, 0x4e, 0xdd, 0xd5, 0x0a, 0xa6, 0xa6, 0xc6, 0xa0,
+	0x9c, 0x20, 0x8b, 0x00, 0x39, 0xb4, 0xd4, 0x23, 0x40, 0x9f, 0x99, 0x1a, 0x6b, 0x85, 0x7b, 0x92,
+	0xc7, 0x77, 0xb0, 0x94, 0x9f, 0xb1, 0xa1, 0xf7, 0xf3, 0x8f, 0x51, 0x30, 0xe7, 0x33, 0xee, 0x4d,
+	0x27, 0x92, 0xec, 0x7f, 0x05, 0x8b, 0xb9, 0xa9, 0x11, 0x32, 0x75, 0x07, 0x2a, 0x9a, 0x9b, 0x19,
+	0xef, 0x4f, 0xa5, 0x91, 0xbc, 0x9f, 0x42, 0x3b, 0x9d, 0xde, 0xa0, 0x75, 0xfd, 0x84, 0x3e, 0xbb,
+	0x30, 0xee, 0x94, 0xec, 0x66, 0x9c, 0xf6, 0x8a, 0x39, 0xed, 0x4d, 0xe5, 0x34, 0x39, 0xca, 0x39,
+	0x84, 0x79, 0x75, 0xce, 0xa2, 0xbf, 0x72, 0xc1, 0xe4, 0xc6, 0x18, 0x94, 0x13, 0xa4, 0xa1, 0x7f,
+	0x08, 0xf3, 0xfb, 0x5e, 0x19, 0xd3, 0x7d, 0x6f, 0x06, 0xd3, 0xa2, 0xf9, 0xcb, 0x16, 0x67, 0xaa,
+	0x8e, 0x44, 0x74, 0xa6, 0x05, 0x53, 0x16, 0x63, 0x50, 0x4e, 0x90, 0x48, 0xba, 0xf3, 0xfb, 0x16,
+	0xcc, 0x3f, 0x74, 0x3c, 0x37, 0xcd, 0xef, 0x63, 0x58, 0x29, 0x9c, 0x46, 0x20, 0x2d, 0x70, 0xa6,
+	0x0d, 0x34, 0x8c, 0x8f, 0xae, 0x41, 0x99, 0xc5, 0x98, 0x3a, 0x6e, 0xd0, 0x75, 0x2a, 0x18, 0x69,
+	0x18, 0x83, 0x59, 0x93, 0x0a, 0x96, 0xc7, 0x94, 0x81, 0x80, 0x9e, 0xc7, 0x26, 0x67, 0x0e, 0xc6,
+	0x46, 0xe9, 0xbe, 0xe4, 0xb7, 0x07, 0xad, 0xa4, 0xf3, 0x47, 0x5a, 0x60, 0xe6, 0xe6, 0x0b, 0xc6,
+	0x7a, 0xf1, 0x66, 0xea, 0x14, 0x47, 0xd0, 0xd5, 0xba, 0x40, 0x3d, 0x21, 0x16, 0xf5, 0xb2, 0xc6,
+	0xe6, 0x14, 0x0a, 0x29, 0xde, 0x6b, 0x58, 0xd0, 0x9b, 0x0f, 0xb4, 0x99, 0xd3, 0x68, 0xb2, 0xf1,
+	0x31, 0xcc, 0x69, 0x24, 0x19, 0x63, 0xbd, 0x15, 0x41, 0xf9, 0xf4, 0x3c, 0xd9, 0xd7, 0x18, 0xe6,
+	0x34, 0x92, 0x2c, 0xe2, 0xd4, 0xf6, 0x42, 0x7f, 0xf3, 0x82, 0x86, 0xc5, 0x18, 0x94, 0x13, 0xa4,
+	0xc6, 0x7d, 0x05, 0x5d, 0xad, 0x51, 0xd0, 0x8d, 0x5b, 0xd4, 0xa2, 0x18, 0x9b, 0x53, 0x28, 0xd2,
+	0xa0, 0x93, 0x5f, 0x45, 0x71, 0x6b, 0xc1, 0x57, 0x51, 0xaf, 0xfa, 0x8d, 0x8d, 0xd2, 0xfd, 0xcc,
+	0xe1, 0xd5, 0x12, 0x5d, 0x57, 0xbe, 0xa0, 0xce, 0x37, 0x06, 0xe5, 0x04, 0x99, 0xc3, 0x2b, 0x55,
+	0xaa, 0x2e, 0xe2, 0x64, 0xd9, 0x6b, 0x6c, 0x94, 0xee, 0x0b, 0x7e, 0xc7, 0x73, 0xfc, 0x5f, 0x44,
+	0x3f, 0xfe, 0xdf, 0x00, 0xb7, 0xa0, 0x43, 0x6c, 0x52, 0x24, 0x00, 0x00,
 }
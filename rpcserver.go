@@ -7,110 +7,35 @@ package main
 import (
 	"crypto/tls"
 	"errors"
-	"fmt"
-	"io/ioutil"
 	"net"
-	"os"
-	"path/filepath"
 	"runtime"
 	"strings"
+	"sync/atomic"
 	"time"
 
-	"github.com/btcsuite/btcutil"
+	"github.com/google/uuid"
+	"github.com/tuxcanfly/wltd/logctx"
 	"github.com/tuxcanfly/wltd/rpc/rpcserver"
 	"github.com/tuxcanfly/wltd/walletd"
+	"golang.org/x/net/context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
 )
 
-// openRPCKeyPair creates or loads the RPC TLS keypair specified by the
-// application config.  This function respects the cfg.OneTimeTLSKey setting.
-func openRPCKeyPair() (tls.Certificate, error) {
-	// Check for existence of the TLS key file.  If one time TLS keys are
-	// enabled but a key already exists, this function should error since
-	// it's possible that a persistent certificate was copied to a remote
-	// machine.  Otherwise, generate a new keypair when the key is missing.
-	// When generating new persistent keys, overwriting an existing cert is
-	// acceptable if the previous execution used a one time TLS key.
-	// Otherwise, both the cert and key should be read from disk.  If the
-	// cert is missing, the read error will occur in LoadX509KeyPair.
-	_, e := os.Stat(cfg.RPCKey)
-	keyExists := !os.IsNotExist(e)
-	switch {
-	case cfg.OneTimeTLSKey && keyExists:
-		err := fmt.Errorf("one time TLS keys are enabled, but TLS key "+
-			"`%s` already exists", cfg.RPCKey)
-		return tls.Certificate{}, err
-	case cfg.OneTimeTLSKey:
-		return generateRPCKeyPair(false)
-	case !keyExists:
-		return generateRPCKeyPair(true)
-	default:
-		return tls.LoadX509KeyPair(cfg.RPCCert, cfg.RPCKey)
-	}
-}
+// rpcDraining is set to 1 once the RPC server begins shutting down.  While it
+// is set, new requests are refused with codes.Unavailable so that in-flight
+// requests can be drained.
+var rpcDraining int32
 
-// generateRPCKeyPair generates a new RPC TLS keypair and writes the cert and
-// possibly also the key in PEM format to the paths specified by the config.  If
-// successful, the new keypair is returned.
-func generateRPCKeyPair(writeKey bool) (tls.Certificate, error) {
-	log.Infof("Generating TLS certificates...")
-
-	// Create directories for cert and key files if they do not yet exist.
-	certDir, _ := filepath.Split(cfg.RPCCert)
-	keyDir, _ := filepath.Split(cfg.RPCKey)
-	err := os.MkdirAll(certDir, 0700)
-	if err != nil {
-		return tls.Certificate{}, err
-	}
-	err = os.MkdirAll(keyDir, 0700)
-	if err != nil {
-		return tls.Certificate{}, err
-	}
+// errRPCDraining is returned to requests which arrive during shutdown.
+var errRPCDraining = grpc.Errorf(codes.Unavailable, "server is shutting down")
 
-	// Generate cert pair.
-	org := "wltd autogenerated cert"
-	validUntil := time.Now().Add(time.Hour * 24 * 365 * 10)
-	cert, key, err := btcutil.NewTLSCertPair(org, validUntil, nil)
-	if err != nil {
-		return tls.Certificate{}, err
-	}
-	keyPair, err := tls.X509KeyPair(cert, key)
-	if err != nil {
-		return tls.Certificate{}, err
-	}
+func startRPCServer(walletDaemon *walletd.WalletDaemon, certs *certManager,
+	auth *authenticator) (*grpc.Server, error) {
 
-	// Write cert and (potentially) the key files.
-	err = ioutil.WriteFile(cfg.RPCCert, cert, 0600)
-	if err != nil {
-		return tls.Certificate{}, err
-	}
-	if writeKey {
-		err = ioutil.WriteFile(cfg.RPCKey, key, 0600)
-		if err != nil {
-			rmErr := os.Remove(cfg.RPCCert)
-			if rmErr != nil {
-				log.Warnf("Cannot remove written certificates: %v",
-					rmErr)
-			}
-			return tls.Certificate{}, err
-		}
-	}
-
-	log.Info("Done generating TLS certificates")
-	return keyPair, nil
-}
-
-func startRPCServer(walletDaemon *walletd.WalletDaemon) (*grpc.Server, error) {
-	var (
-		server  *grpc.Server
-		keyPair tls.Certificate
-		err     error
-	)
-	keyPair, err = openRPCKeyPair()
-	if err != nil {
-		return nil, err
-	}
+	var server *grpc.Server
 
 	if len(cfg.RPCListeners) != 0 {
 		listeners := makeListeners(cfg.RPCListeners, net.Listen)
@@ -118,10 +43,28 @@ func startRPCServer(walletDaemon *walletd.WalletDaemon) (*grpc.Server, error) {
 			err := errors.New("failed to create listeners for RPC server")
 			return nil, err
 		}
-		creds := credentials.NewServerTLSFromCert(&keyPair)
-		server = grpc.NewServer(grpc.Creds(creds))
+		creds := credentials.NewTLS(&tls.Config{
+			GetCertificate: certs.GetCertificate,
+		})
+		limiter := newRateLimiter(cfg.rateLimits)
+		expensiveOps := newConcurrencyLimiter(cfg.MaxExpensiveOps)
+		server = grpc.NewServer(grpc.Creds(creds),
+			grpc.UnaryInterceptor(chainUnaryInterceptors(
+				drainUnaryInterceptor,
+				auth.unaryInterceptor,
+				contextUnaryInterceptor,
+				limiter.unaryInterceptor,
+				expensiveOps.unaryInterceptor)),
+			grpc.StreamInterceptor(chainStreamInterceptors(
+				drainStreamInterceptor,
+				auth.streamInterceptor,
+				contextStreamInterceptor,
+				limiter.streamInterceptor,
+				expensiveOps.streamInterceptor)))
 		rpcserver.StartVersionService(server)
-		rpcserver.StartWalletDaemonService(server, walletDaemon, activeNet)
+		rpcserver.StartWalletDaemonService(server, walletDaemon)
+		rpcserver.StartAdminService(server, certs, logTails,
+			walletDaemon)
 		for _, lis := range listeners {
 			lis := lis
 			go func() {
@@ -142,6 +85,172 @@ func startRPCServer(walletDaemon *walletd.WalletDaemon) (*grpc.Server, error) {
 	return server, nil
 }
 
+// drainUnaryInterceptor refuses unary requests once the server is draining.
+func drainUnaryInterceptor(ctx context.Context, req interface{},
+	info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+
+	if atomic.LoadInt32(&rpcDraining) != 0 {
+		return nil, errRPCDraining
+	}
+	return handler(ctx, req)
+}
+
+// drainStreamInterceptor refuses streaming requests once the server is
+// draining.
+func drainStreamInterceptor(srv interface{}, ss grpc.ServerStream,
+	info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+	if atomic.LoadInt32(&rpcDraining) != 0 {
+		return errRPCDraining
+	}
+	return handler(srv, ss)
+}
+
+// chainUnaryInterceptors returns an interceptor which runs the passed
+// interceptors in order before the handler.
+func chainUnaryInterceptors(interceptors ...grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{},
+		info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, next := interceptors[i], handler
+			handler = func(ctx context.Context, req interface{}) (interface{}, error) {
+				return interceptor(ctx, req, info, next)
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// chainStreamInterceptors returns an interceptor which runs the passed
+// interceptors in order before the handler.
+func chainStreamInterceptors(interceptors ...grpc.StreamServerInterceptor) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream,
+		info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, next := interceptors[i], handler
+			handler = func(srv interface{}, ss grpc.ServerStream) error {
+				return interceptor(srv, ss, info, next)
+			}
+		}
+		return handler(srv, ss)
+	}
+}
+
+// requestIDHeader is the metadata key of the request ID of a request.  The
+// request ID is generated when the client does not provide one, and is
+// returned in the response header.
+const (
+	requestIDHeader = "request-id"
+
+	// maxRequestIDLen is the longest request ID accepted from clients.
+	maxRequestIDLen = 64
+)
+
+// requestFields returns the logging context of a request to method.  The
+// tenant, or the admin, is the one authenticated by authenticator.
+func requestFields(ctx context.Context, method string) logctx.Fields {
+	f := logctx.Fields{Method: method}
+	if md, ok := metadata.FromContext(ctx); ok {
+		if ids := md[requestIDHeader]; len(ids) != 0 &&
+			len(ids[0]) <= maxRequestIDLen {
+			f.RequestID = ids[0]
+		}
+	}
+	if c, ok := credentialFromContext(ctx); ok {
+		switch c.role {
+		case roleTenant:
+			f.Tenant = c.name
+		case roleAdmin:
+			f.Admin = c.name
+		}
+	}
+	if f.RequestID == "" {
+		f.RequestID = uuid.New().String()
+	}
+	return f
+}
+
+// contextUnaryInterceptor attaches the logging context of a request to its
+// context, and traces the request message with its secret fields masked.
+func contextUnaryInterceptor(ctx context.Context, req interface{},
+	info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+
+	f := requestFields(ctx, info.FullMethod)
+	ctx = logctx.NewContext(ctx, f)
+	grpc.SetHeader(ctx, metadata.Pairs(requestIDHeader, f.RequestID))
+	logctx.Log(ctx, grpcLog).Tracef("Request %s: %v", info.FullMethod,
+		logctx.MaskSecrets(req))
+
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	logctx.Log(ctx, grpcLog).Debugf("Handled %s in %v (%v)",
+		info.FullMethod, time.Since(start), grpc.Code(err))
+	return resp, err
+}
+
+// contextServerStream attaches a logging context to a server stream and
+// traces every received message with its secret fields masked.
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *contextServerStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		logctx.Log(s.ctx, grpcLog).Tracef("Received %v",
+			logctx.MaskSecrets(m))
+	}
+	return err
+}
+
+// contextStreamInterceptor is the streaming equivalent of
+// contextUnaryInterceptor.
+func contextStreamInterceptor(srv interface{}, ss grpc.ServerStream,
+	info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+	f := requestFields(ss.Context(), info.FullMethod)
+	ss.SetHeader(metadata.Pairs(requestIDHeader, f.RequestID))
+	stream := &contextServerStream{
+		ServerStream: ss,
+		ctx:          logctx.NewContext(ss.Context(), f),
+	}
+
+	start := time.Now()
+	err := handler(srv, stream)
+	logctx.Log(stream.ctx, grpcLog).Debugf("Handled %s in %v (%v)",
+		info.FullMethod, time.Since(start), grpc.Code(err))
+	return err
+}
+
+// stopRPCServer refuses new requests and waits for in-flight requests to
+// finish before stopping the server.  Requests which are still running after
+// timeout are cancelled by forcibly stopping the server.
+func stopRPCServer(server *grpc.Server, timeout time.Duration) {
+	atomic.StoreInt32(&rpcDraining, 1)
+
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(timeout):
+		log.Warnf("RPC requests did not finish within %v, forcing "+
+			"shutdown", timeout)
+		server.Stop()
+		<-stopped
+	}
+}
+
 type listenFunc func(net string, laddr string) (net.Listener, error)
 
 // makeListeners splits the normalized listen addresses into IPv4 and IPv6
@@ -22,10 +22,21 @@ var interruptHandlersDone = make(chan struct{})
 
 var simulateInterruptChannel = make(chan struct{}, 1)
 
+// reloadChannel is used to receive signals requesting a configuration reload.
+var reloadChannel chan os.Signal
+
+// addReloadHandlerChannel is used to add a handler to the list of handlers to
+// be invoked when a configuration reload is requested.
+var addReloadHandlerChannel = make(chan func())
+
 // signals defines the signals that are handled to do a clean shutdown.
 // Conditional compilation is used to also include SIGTERM on Unix.
 var signals = []os.Signal{os.Interrupt}
 
+// reloadSignals defines the signals that are handled to reload the
+// configuration.  Conditional compilation is used to include SIGHUP on Unix.
+var reloadSignals []os.Signal
+
 // simulateInterrupt requests invoking the clean termination process by an
 // internal component instead of a SIGINT.
 func simulateInterrupt() {
@@ -37,11 +48,17 @@ func simulateInterrupt() {
 
 // mainInterruptHandler listens for SIGINT (Ctrl+C) signals on the
 // interruptChannel and invokes the registered interruptCallbacks accordingly.
-// It also listens for callback registration.  It must be run as a goroutine.
+// Reload signals received on the reloadChannel invoke the registered
+// reloadCallbacks.  It also listens for callback registration.  It must be
+// run as a goroutine.
 func mainInterruptHandler() {
 	// interruptCallbacks is a list of callbacks to invoke when a
 	// SIGINT (Ctrl+C) is received.
 	var interruptCallbacks []func()
+
+	// reloadCallbacks is a list of callbacks to invoke when a reload
+	// signal is received.
+	var reloadCallbacks []func()
 	invokeCallbacks := func() {
 		// run handlers in LIFO order.
 		for i := range interruptCallbacks {
@@ -62,22 +79,49 @@ func mainInterruptHandler() {
 			invokeCallbacks()
 			return
 
+		case sig := <-reloadChannel:
+			log.Infof("Received signal (%s).  Reloading "+
+				"configuration...", sig)
+			for _, callback := range reloadCallbacks {
+				callback()
+			}
+
 		case handler := <-addHandlerChannel:
 			interruptCallbacks = append(interruptCallbacks, handler)
+		case handler := <-addReloadHandlerChannel:
+			reloadCallbacks = append(reloadCallbacks, handler)
 		}
 	}
 }
 
+// startInterruptHandler creates the signal channels and starts the main
+// interrupt handler which invokes all other callbacks and exits if not
+// already done.
+func startInterruptHandler() {
+	if interruptChannel != nil {
+		return
+	}
+
+	interruptChannel = make(chan os.Signal, 1)
+	signal.Notify(interruptChannel, signals...)
+	reloadChannel = make(chan os.Signal, 1)
+	if len(reloadSignals) != 0 {
+		signal.Notify(reloadChannel, reloadSignals...)
+	}
+	go mainInterruptHandler()
+}
+
 // addInterruptHandler adds a handler to call when a SIGINT (Ctrl+C) is
 // received.
 func addInterruptHandler(handler func()) {
-	// Create the channel and start the main interrupt handler which invokes
-	// all other callbacks and exits if not already done.
-	if interruptChannel == nil {
-		interruptChannel = make(chan os.Signal, 1)
-		signal.Notify(interruptChannel, signals...)
-		go mainInterruptHandler()
-	}
-
+	startInterruptHandler()
 	addHandlerChannel <- handler
 }
+
+// addReloadHandler adds a handler to call when a configuration reload is
+// requested.  Reload handlers run on the same goroutine as interrupt handlers,
+// so they never run concurrently with shutdown.
+func addReloadHandler(handler func()) {
+	startInterruptHandler()
+	addReloadHandlerChannel <- handler
+}
@@ -5,9 +5,6 @@
 package main
 
 import (
-	"net"
-	"net/http"
-	_ "net/http/pprof"
 	"os"
 	"runtime"
 
@@ -51,31 +48,106 @@ func walletdMain() error {
 	log.Infof("Version %s", version())
 
 	if cfg.Profile != "" {
-		go func() {
-			listenAddr := net.JoinHostPort("", cfg.Profile)
-			log.Infof("Profile server listening on %s", listenAddr)
-			profileRedirect := http.RedirectHandler("/debug/pprof",
-				http.StatusSeeOther)
-			http.Handle("/", profileRedirect)
-			log.Errorf("%v", http.ListenAndServe(listenAddr, nil))
-		}()
+		if err := startProfileServer(cfg.Profile); err != nil {
+			log.Errorf("Unable to start profile server: %v", err)
+		}
+	}
+	// The check only reads the data directory, and must not open its
+	// databases for writing, since a daemon may be running.
+	if cfg.Check {
+		return runCheck()
 	}
-	walletDaemon := walletd.NewWalletDaemon(cfg.AppDataDir, activeNet)
-	rpcs, err := startRPCServer(walletDaemon)
+
+	backupKey, err := loadBackupKey(cfg.BackupKey)
+	if err != nil {
+		log.Errorf("Unable to load backup key: %v", err)
+		return err
+	}
+	chainClients, err := newChainClients()
+	if err != nil {
+		log.Errorf("Unable to create btcd RPC clients: %v", err)
+		return err
+	}
+	walletDaemon, err := walletd.NewWalletDaemon(&walletd.Config{
+		DataDir:         cfg.AppDataDir,
+		DBDriver:        cfg.DBDriver,
+		ChainParams:     activeNet,
+		ExtraNets:       extraNets,
+		IdleTimeout:     cfg.WalletIdleTimeout,
+		BackupKey:       backupKey,
+		BackupDir:       cfg.BackupDir,
+		BackupSchedule:  cfg.backupSchedule,
+		BackupRetention: cfg.BackupRetention,
+		NoInitialLoad:   cfg.NoInitialLoad,
+		CreateWorkers:   cfg.CreateWorkers,
+		PubPassphrase:   []byte(cfg.WalletPass),
+		ChainClients:    chainClients,
+	})
+	if err != nil {
+		log.Errorf("Unable to create wallet daemon: %v", err)
+		return err
+	}
+	if cfg.MigrateDryRun {
+		return runMigrateDryRun(walletDaemon)
+	}
+	startChainClients(chainClients)
+	if err := walletDaemon.Start(); err != nil {
+		log.Errorf("Unable to start wallet daemon: %v", err)
+		walletDaemon.Close()
+		return err
+	}
+	addInterruptHandler(func() {
+		log.Warn("Stopping wallet daemon...")
+		walletDaemon.Stop()
+		walletDaemon.WaitForShutdown()
+		walletDaemon.UnloadWallets()
+		if err := walletDaemon.Close(); err != nil {
+			log.Errorf("Unable to close wallet registry and audit log: %v",
+				err)
+		}
+		log.Info("Wallet daemon shutdown")
+	})
+
+	certs, err := newCertManager(cfg.RPCCert, cfg.RPCKey, cfg.OneTimeTLSKey,
+		tlsExtraHosts(cfg), cfg.CertValidity)
+	if err != nil {
+		log.Errorf("Unable to open RPC TLS keypair: %v", err)
+		return err
+	}
+	certs.Start()
+	addInterruptHandler(certs.Stop)
+
+	auth, err := newAuthenticator(cfg.AuthFile)
+	if err != nil {
+		log.Errorf("Unable to read RPC credentials: %v", err)
+		return err
+	}
+	rpcs, err := startRPCServer(walletDaemon, certs, auth)
 	if err != nil {
 		log.Errorf("Unable to create RPC server: %v", err)
 		return err
 	}
 	if rpcs != nil {
+		// Interrupt handlers run in LIFO order, so the RPC server is
+		// drained before the wallet daemon is stopped.
 		addInterruptHandler(func() {
-			// TODO: Does this need to wait for the grpc server to
-			// finish up any requests?
 			log.Warn("Stopping RPC server...")
-			rpcs.Stop()
+			// Log tails and event streams never finish on their
+			// own, so end them rather than waiting for them to
+			// drain.
+			logTails.close()
+			walletDaemon.CloseEvents()
+			stopRPCServer(rpcs, cfg.ShutdownTimeout)
 			log.Info("RPC server shutdown")
 		})
 	}
 
+	// Apply configuration changes when a reload is requested (SIGHUP on
+	// Unix).
+	addReloadHandler(func() {
+		reloadConfig(walletDaemon, certs, auth)
+	})
+
 	<-interruptHandlersDone
 	log.Info("Shutdown complete")
 	return nil
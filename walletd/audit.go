@@ -0,0 +1,318 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package walletd
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// AuditLogName is the name of the audit log within the daemon data directory.
+const AuditLogName = "audit.log"
+
+// auditTimeFormat is the format of audit record timestamps.
+const auditTimeFormat = time.RFC3339Nano
+
+// genesisHash is the previous hash of the first audit record.
+var genesisHash = hex.EncodeToString(make([]byte, sha256.Size))
+
+// AuditRecord is a security relevant operation recorded in the audit log.
+//
+// Records are stored one JSON object per line.  Each record includes the hash
+// of the previous record, and its own hash is the SHA-256 of its JSON encoding
+// without the hash.  Modifying, inserting or removing any record except the
+// last ones therefore breaks the chain.  Removing records from the end can
+// only be detected by comparing the hash of the last record with a copy kept
+// elsewhere.
+type AuditRecord struct {
+	Sequence   uint64 `json:"seq"`
+	Time       string `json:"time"`
+	Tenant     string `json:"tenant,omitempty"`
+	Admin      string `json:"admin,omitempty"`
+	Method     string `json:"method"`
+	WalletUUID string `json:"wallet_uuid,omitempty"`
+	RequestID  string `json:"request_id,omitempty"`
+	Success    bool   `json:"success"`
+	Error      string `json:"error,omitempty"`
+	PrevHash   string `json:"prev"`
+	Hash       string `json:"hash,omitempty"`
+}
+
+// hash returns the hash of the record, computed without its Hash field.
+func (r *AuditRecord) hash() (string, error) {
+	c := *r
+	c.Hash = ""
+	b, err := json.Marshal(&c)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:]), nil
+}
+
+// AuditFilter selects audit records.  Empty fields match every record.
+type AuditFilter struct {
+	Tenant     string
+	Method     string
+	WalletUUID string
+	Since      time.Time
+	Until      time.Time
+}
+
+// match returns whether the record is selected by the filter.
+func (f *AuditFilter) match(r *AuditRecord) bool {
+	if f.Tenant != "" && r.Tenant != f.Tenant {
+		return false
+	}
+	if f.Method != "" && r.Method != f.Method {
+		return false
+	}
+	if f.WalletUUID != "" && r.WalletUUID != f.WalletUUID {
+		return false
+	}
+	if f.Since.IsZero() && f.Until.IsZero() {
+		return true
+	}
+	t, err := time.Parse(auditTimeFormat, r.Time)
+	if err != nil {
+		return false
+	}
+	if !f.Since.IsZero() && t.Before(f.Since) {
+		return false
+	}
+	return f.Until.IsZero() || t.Before(f.Until)
+}
+
+// IncompleteRecordError describes a last audit record which was only partially
+// written, such as by a crash during Append.
+type IncompleteRecordError struct {
+	Line int
+
+	// Offset is the size of the complete records preceding the
+	// incomplete one.
+	Offset int64
+}
+
+// Error implements the error interface.
+func (e *IncompleteRecordError) Error() string {
+	return fmt.Sprintf("line %d: incomplete record", e.Line)
+}
+
+// AuditLog is an append-only, hash-chained log of security relevant
+// operations.
+type AuditLog struct {
+	mu       sync.Mutex
+	path     string
+	file     *os.File
+	size     int64
+	sequence uint64
+	lastHash string
+}
+
+// OpenAuditLog opens the audit log at path for appending, creating it if it
+// does not exist.  The chain is continued from the last record.  An incomplete
+// last record is removed with a warning, since it was never acknowledged.
+func OpenAuditLog(path string) (*AuditLog, error) {
+	l := &AuditLog{path: path, lastHash: genesisHash}
+
+	// Find the last record to continue the chain from.
+	err := forEachAuditRecord(path, func(r *AuditRecord, line int) error {
+		l.sequence = r.Sequence
+		l.lastHash = r.Hash
+		return nil
+	})
+	if e, ok := err.(*IncompleteRecordError); ok {
+		log.Warnf("Removing incomplete record at %s:%d", path, e.Line)
+		err = os.Truncate(path, e.Offset)
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	l.file, err = os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND,
+		0600)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := l.file.Stat()
+	if err != nil {
+		l.file.Close()
+		return nil, err
+	}
+	l.size = fi.Size()
+	return l, nil
+}
+
+// Append chains the record to the log and writes it to disk.  The sequence
+// number, time and hashes of the record are set by Append.
+func (l *AuditLog) Append(r *AuditRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return fmt.Errorf("audit log is closed")
+	}
+
+	rec := *r
+	rec.Sequence = l.sequence + 1
+	rec.Time = time.Now().UTC().Format(auditTimeFormat)
+	rec.PrevHash = l.lastHash
+	hash, err := rec.hash()
+	if err != nil {
+		return err
+	}
+	rec.Hash = hash
+
+	b, err := json.Marshal(&rec)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	_, err = l.file.Write(b)
+	if err == nil {
+		err = l.file.Sync()
+	}
+	if err != nil {
+		// Remove what was written of the record, so that the next
+		// one follows the last complete record.
+		l.file.Truncate(l.size)
+		return err
+	}
+
+	l.size += int64(len(b))
+	l.sequence = rec.Sequence
+	l.lastHash = rec.Hash
+	return nil
+}
+
+// Query returns the last limit records selected by the filter, oldest first.
+// Every selected record is returned when limit is zero.  Records appended
+// while the log is read are not returned.
+func (l *AuditLog) Query(filter *AuditFilter, limit int) ([]*AuditRecord, error) {
+	// Only the records complete when the query starts are read, so that
+	// appends may continue while the log is read.
+	l.mu.Lock()
+	size := l.size
+	l.mu.Unlock()
+
+	f, err := os.Open(l.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []*AuditRecord
+	err = readAuditRecords(io.LimitReader(f, size), func(r *AuditRecord, line int) error {
+		if !filter.match(r) {
+			return nil
+		}
+		records = append(records, r)
+		if limit > 0 && len(records) > limit {
+			records = records[1:]
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// Close closes the audit log.
+func (l *AuditLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// VerifyAuditLog checks the hash chain of the audit log at path.  It returns
+// the number of valid records and the hash of the last one.  An error
+// describing the first invalid record is returned if the chain is broken.
+func VerifyAuditLog(path string) (int, string, error) {
+	n := 0
+	prevHash := genesisHash
+	var prevSequence uint64
+	err := forEachAuditRecord(path, func(r *AuditRecord, line int) error {
+		if r.PrevHash != prevHash {
+			return fmt.Errorf("line %d: record %d does not follow "+
+				"the previous record", line, r.Sequence)
+		}
+		if r.Sequence != prevSequence+1 {
+			return fmt.Errorf("line %d: record %d follows record "+
+				"%d", line, r.Sequence, prevSequence)
+		}
+		hash, err := r.hash()
+		if err != nil {
+			return fmt.Errorf("line %d: %v", line, err)
+		}
+		if r.Hash != hash {
+			return fmt.Errorf("line %d: record %d has been "+
+				"modified", line, r.Sequence)
+		}
+		n++
+		prevHash = r.Hash
+		prevSequence = r.Sequence
+		return nil
+	})
+	return n, prevHash, err
+}
+
+// forEachAuditRecord calls fn with every record of the audit log at path and
+// its line number.  Iteration stops at the first error returned by fn.
+func forEachAuditRecord(path string, fn func(*AuditRecord, int) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return readAuditRecords(f, fn)
+}
+
+// readAuditRecords calls fn with every record read from rd and its line
+// number.  An IncompleteRecordError is returned when the last record is only
+// partially written.
+func readAuditRecords(rd io.Reader, fn func(*AuditRecord, int) error) error {
+	r := bufio.NewReader(rd)
+	var offset int64
+	for line := 1; ; line++ {
+		b, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			// A partially written last record is left by a crash
+			// during Append.
+			if len(bytes.TrimSpace(b)) != 0 {
+				return &IncompleteRecordError{line, offset}
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		offset += int64(len(b))
+		var rec AuditRecord
+		if err := json.Unmarshal(b, &rec); err != nil {
+			return fmt.Errorf("line %d: %v", line, err)
+		}
+		if err := fn(&rec, line); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,506 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package walletd
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/btcsuite/btcwallet/walletdb"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/nacl/box"
+	"golang.org/x/crypto/nacl/secretbox"
+)
+
+// walletDbName is the name of the wallet database within the network
+// directory of a wallet.
+const walletDbName = "wallet.db"
+
+const (
+	// backupMagic starts every wallet backup.
+	backupMagic = "WLTDBAK1"
+
+	// backupChunkSize is the maximum size of the plaintext sealed in a
+	// single chunk of a backup.
+	backupChunkSize = 64 * 1024
+
+	// backupVersion is the version of the backup header.
+	backupVersion = 1
+)
+
+var (
+	// ErrNoBackupKey is returned when backing up or restoring a wallet
+	// without a configured backup key.
+	ErrNoBackupKey = errors.New("no backup key configured")
+
+	// ErrWalletExists is returned when restoring a wallet under the UUID
+	// of a registered wallet.
+	ErrWalletExists = errors.New("wallet already exists")
+
+	// ErrInvalidBackup is returned when restoring a backup which was not
+	// encrypted to the backup key, was modified or is truncated.
+	ErrInvalidBackup = errors.New("invalid wallet backup")
+)
+
+// backupHeader holds the registry metadata of a backed up wallet.  It precedes
+// the wallet database in the plaintext of a backup.
+type backupHeader struct {
+	Version int    `json:"version"`
+	UUID    string `json:"uuid"`
+	Net     string `json:"net"`
+	Tenant  string `json:"tenant,omitempty"`
+	Created int64  `json:"created"`
+
+	// Descriptive metadata of the wallet, missing from backups written
+	// before it was recorded.
+	Name     string            `json:"name,omitempty"`
+	Tags     []string          `json:"tags,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// Labels of the wallet as BIP0329 records, missing from backups
+	// written before they were recorded.
+	Labels []bip329Record `json:"labels,omitempty"`
+}
+
+// A backup is encrypted to the public key of the backup key.  It starts with
+// backupMagic and the public key of an ephemeral key pair, followed by chunks
+// of at most backupChunkSize bytes of plaintext, each sealed with the shared
+// key of the ephemeral and backup keys.  Every chunk is prefixed by its sealed
+// length as a big endian uint32.  The nonce of a chunk holds its index and
+// whether it is the last one, so that reordered, removed and truncated chunks
+// are detected.
+
+// chunkNonce returns the nonce of the chunk at index.
+func chunkNonce(index uint64, last bool) *[24]byte {
+	var nonce [24]byte
+	binary.BigEndian.PutUint64(nonce[:8], index)
+	if last {
+		nonce[8] = 1
+	}
+	return &nonce
+}
+
+// backupWriter encrypts a backup to a public key.
+type backupWriter struct {
+	w      io.Writer
+	key    [32]byte
+	buf    []byte
+	index  uint64
+	header bool
+}
+
+// newBackupWriter returns a writer encrypting to the public key.  Close must be
+// called to write the last chunk.
+func newBackupWriter(w io.Writer, publicKey *[32]byte) (*backupWriter, error) {
+	ephemeralPub, ephemeralPriv, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	bw := &backupWriter{w: w, buf: make([]byte, 0, backupChunkSize)}
+	box.Precompute(&bw.key, publicKey, ephemeralPriv)
+
+	if _, err := io.WriteString(w, backupMagic); err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(ephemeralPub[:]); err != nil {
+		return nil, err
+	}
+	return bw, nil
+}
+
+// Write implements the io.Writer interface.
+func (bw *backupWriter) Write(p []byte) (int, error) {
+	n := 0
+	for len(p) != 0 {
+		if len(bw.buf) == backupChunkSize {
+			if err := bw.seal(false); err != nil {
+				return n, err
+			}
+		}
+		c := copy(bw.buf[len(bw.buf):backupChunkSize], p)
+		bw.buf = bw.buf[:len(bw.buf)+c]
+		p = p[c:]
+		n += c
+	}
+	return n, nil
+}
+
+// seal writes the buffered plaintext as a chunk.
+func (bw *backupWriter) seal(last bool) error {
+	sealed := secretbox.Seal(nil, bw.buf, chunkNonce(bw.index, last), &bw.key)
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(sealed)))
+	if _, err := bw.w.Write(length[:]); err != nil {
+		return err
+	}
+	if _, err := bw.w.Write(sealed); err != nil {
+		return err
+	}
+	bw.buf = bw.buf[:0]
+	bw.index++
+	return nil
+}
+
+// Close writes the last chunk.  It does not close the underlying writer.
+func (bw *backupWriter) Close() error {
+	return bw.seal(true)
+}
+
+// backupReader decrypts a backup with a private key.
+type backupReader struct {
+	r     io.Reader
+	key   [32]byte
+	buf   []byte
+	index uint64
+	last  bool
+}
+
+// newBackupReader returns a reader decrypting the backup read from r with the
+// private key.
+func newBackupReader(r io.Reader, privateKey *[32]byte) (*backupReader, error) {
+	var header [len(backupMagic) + 32]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, ErrInvalidBackup
+	}
+	if string(header[:len(backupMagic)]) != backupMagic {
+		return nil, ErrInvalidBackup
+	}
+	var ephemeralPub [32]byte
+	copy(ephemeralPub[:], header[len(backupMagic):])
+	br := &backupReader{r: r}
+	box.Precompute(&br.key, &ephemeralPub, privateKey)
+	return br, nil
+}
+
+// Read implements the io.Reader interface.  ErrInvalidBackup is returned when
+// a chunk cannot be decrypted or the backup ends before its last chunk.
+func (br *backupReader) Read(p []byte) (int, error) {
+	for len(br.buf) == 0 {
+		if br.last {
+			return 0, io.EOF
+		}
+		if err := br.open(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, br.buf)
+	br.buf = br.buf[n:]
+	return n, nil
+}
+
+// open reads and decrypts the next chunk.
+func (br *backupReader) open() error {
+	var length [4]byte
+	if _, err := io.ReadFull(br.r, length[:]); err != nil {
+		return ErrInvalidBackup
+	}
+	n := binary.BigEndian.Uint32(length[:])
+	if n < secretbox.Overhead || n > backupChunkSize+secretbox.Overhead {
+		return ErrInvalidBackup
+	}
+	sealed := make([]byte, n)
+	if _, err := io.ReadFull(br.r, sealed); err != nil {
+		return ErrInvalidBackup
+	}
+
+	// The chunk is the last one when it opens with the last nonce.
+	for _, last := range []bool{false, true} {
+		plain, ok := secretbox.Open(nil, sealed,
+			chunkNonce(br.index, last), &br.key)
+		if ok {
+			br.buf = plain
+			br.index++
+			br.last = last
+			return nil
+		}
+	}
+	return ErrInvalidBackup
+}
+
+// backupPublicKey returns the public key of the backup key of the daemon.
+func (w *WalletDaemon) backupPublicKey() (*[32]byte, error) {
+	if w.backupKey == nil {
+		return nil, ErrNoBackupKey
+	}
+	var pub [32]byte
+	curve25519.ScalarBaseMult(&pub, w.backupKey)
+	return &pub, nil
+}
+
+// walletDB returns the database of a registered wallet for reading.  The
+// database of an open wallet is shared with the wallet, which is kept open
+// until the returned function is called.  Otherwise the database is opened,
+// and opening or deleting the wallet is blocked until the returned function
+// closes it.  Callers should release the database as soon as possible.
+func (w *WalletDaemon) walletDB(rec *walletRecord) (walletdb.DB, func(), error) {
+	if db, release, ok := w.openWalletDB(rec.ID); ok {
+		return db, release, nil
+	}
+
+	unlock := w.lockWallet(rec.ID)
+	// The wallet may have been opened in the meantime.
+	if db, release, ok := w.openWalletDB(rec.ID); ok {
+		unlock()
+		return db, release, nil
+	}
+
+	chainParams, err := ParamsForNet(rec.Net)
+	if err != nil {
+		unlock()
+		return nil, nil, err
+	}
+	db, err := w.storage.openDB(rec.ID, chainParams)
+	if err != nil {
+		unlock()
+		return nil, nil, err
+	}
+	return db, func() {
+		db.Close()
+		unlock()
+	}, nil
+}
+
+// openWalletDB returns the database of a wallet if it is open, and prevents
+// the wallet from being closed until the returned function is called.
+func (w *WalletDaemon) openWalletDB(id string) (walletdb.DB, func(), bool) {
+	w.walletsMu.Lock()
+	defer w.walletsMu.Unlock()
+
+	lw, ok := w.wallets[id]
+	if !ok {
+		return nil, nil, false
+	}
+	lw.refs++
+	return lw.db, func() {
+		w.walletsMu.Lock()
+		lw.refs--
+		w.walletsMu.Unlock()
+	}, true
+}
+
+// BackupWallet writes an encrypted backup of a wallet, its registry record and
+// its labels to out.  The wallet database is copied in a read transaction, so the backup
+// is consistent even while the wallet is in use.  It is copied to a temporary
+// file in the data directory before being written to out, so that a slow
+// reader does not keep the wallet locked.  The backup can only be restored by
+// a daemon with the same backup key.
+func (w *WalletDaemon) BackupWallet(id string, out io.Writer) error {
+	if !w.storage.persistent() {
+		return ErrNotPersistent
+	}
+	pub, err := w.backupPublicKey()
+	if err != nil {
+		return err
+	}
+	rec, err := w.registry.wallet(id)
+	if err != nil {
+		return err
+	}
+
+	done, err := w.beginOperation()
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	tmp, err := ioutil.TempFile(w.dbDir, "backup-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+	db, release, err := w.walletDB(rec)
+	if err != nil {
+		return err
+	}
+	err = db.Copy(tmp)
+	release()
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	labels, err := w.registry.labels(id, labelTypes, nil)
+	if err != nil {
+		return err
+	}
+	records := make([]bip329Record, 0, len(labels))
+	for _, l := range labels {
+		records = append(records, bip329Record{
+			Type:  l.Type,
+			Ref:   l.Ref,
+			Label: l.Label,
+		})
+	}
+
+	bw, err := newBackupWriter(out, pub)
+	if err != nil {
+		return err
+	}
+	header, err := json.Marshal(&backupHeader{
+		Version:  backupVersion,
+		UUID:     rec.ID,
+		Net:      rec.Net,
+		Tenant:   rec.Tenant,
+		Created:  rec.Created.Unix(),
+		Name:     rec.Name,
+		Tags:     rec.Tags,
+		Metadata: rec.Metadata,
+		Labels:   records,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := bw.Write(append(header, '\n')); err != nil {
+		return err
+	}
+	if _, err := io.Copy(bw, tmp); err != nil {
+		return err
+	}
+	return bw.Close()
+}
+
+// RestoreWallet recreates a wallet, its registry record and its labels from a
+// backup written by BackupWallet for the tenant, and returns its UUID.  The tenant
+// recorded in the backup is ignored.  The wallet is restored under its
+// original UUID, unless newUUID is set.  ErrWalletExists is returned if the
+// UUID is already registered, and a QuotaExceededError if the tenant may not
+// create another wallet.  The restored wallet is not opened.
+func (w *WalletDaemon) RestoreWallet(tenant string, in io.Reader, newUUID bool) (string, error) {
+	if w.backupKey == nil {
+		return "", ErrNoBackupKey
+	}
+	if !w.storage.persistent() {
+		return "", ErrNotPersistent
+	}
+	br, err := newBackupReader(in, w.backupKey)
+	if err != nil {
+		return "", err
+	}
+	r := bufio.NewReader(br)
+	line, err := r.ReadBytes('\n')
+	if err != nil {
+		if err == io.EOF {
+			err = ErrInvalidBackup
+		}
+		return "", err
+	}
+	var header backupHeader
+	if err := json.Unmarshal(line, &header); err != nil {
+		return "", ErrInvalidBackup
+	}
+	if header.Version != backupVersion {
+		return "", fmt.Errorf("unsupported wallet backup version %d",
+			header.Version)
+	}
+	chainParams, ok := w.nets[header.Net]
+	if !ok {
+		return "", ErrUnknownNetwork
+	}
+	labels := make([]Label, 0, len(header.Labels))
+	for _, l := range header.Labels {
+		ref, err := normalizeLabelRef(l.Type, l.Ref, chainParams)
+		if err != nil || l.Label == "" {
+			return "", ErrInvalidBackup
+		}
+		labels = append(labels, Label{l.Type, ref, l.Label})
+	}
+	id := header.UUID
+	if newUUID {
+		id = uuid.New().String()
+	} else if _, err := uuid.Parse(id); err != nil {
+		return "", ErrInvalidBackup
+	}
+
+	done, err := w.beginOperation()
+	if err != nil {
+		return "", err
+	}
+	defer done()
+
+	release, err := w.reserveQuota(tenant, true, false)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	// Holding the wallet lock keeps a concurrent restore of the same UUID
+	// from passing the existence checks before the wallet is registered.
+	defer w.lockWallet(id)()
+
+	if _, err := w.registry.wallet(id); err != ErrWalletNotFound {
+		if err == nil {
+			err = ErrWalletExists
+		}
+		return "", err
+	}
+	if w.storage.walletExists(id, chainParams) {
+		return "", ErrWalletExists
+	}
+	dbPath := w.storage.dbPath(id, chainParams)
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0700); err != nil {
+		return "", err
+	}
+
+	err = writeFileSync(dbPath, r)
+	if err == nil {
+		err = w.registry.putWallet(&walletRecord{
+			ID:       id,
+			Net:      header.Net,
+			Tenant:   tenant,
+			Created:  time.Unix(header.Created, 0),
+			LastUsed: time.Now(),
+			Name:     header.Name,
+			Tags:     header.Tags,
+			Metadata: header.Metadata,
+		})
+		if err == nil && len(labels) != 0 {
+			err = w.registry.putLabels(id, labels)
+			if err != nil {
+				w.registry.deleteWallet(id)
+			}
+		}
+	}
+	if err != nil {
+		w.storage.removeWallet(id, chainParams)
+		return "", err
+	}
+	w.updateWalletUsage(tenant, id, true)
+	return id, nil
+}
+
+// writeFileSync writes the contents of r to a new file and syncs it to disk.
+// The file is written under a temporary name and renamed once complete.
+func writeFileSync(name string, r io.Reader) error {
+	tmp := name + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, r)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, name)
+}
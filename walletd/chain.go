@@ -0,0 +1,254 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package walletd
+
+import (
+	"sync"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcwallet/chain"
+	"github.com/btcsuite/btcwallet/walletdb"
+)
+
+// walletChainClient is the chain backend of a single open wallet.  The
+// backend of a network is shared by every open wallet of that network, so
+// the wallet reads the notifications dispatched to it by the daemon instead
+// of those of the backend, and cannot start or stop the backend, which is
+// owned by the daemon.
+type walletChainClient struct {
+	chain.Interface
+	lw      *loadedWallet
+	rescans *rescanTracker
+}
+
+// Notifications returns the notifications dispatched to the wallet.
+func (c *walletChainClient) Notifications() <-chan interface{} {
+	return c.lw.ntfns
+}
+
+// Start does nothing, since the shared backend is started by its owner.
+func (c *walletChainClient) Start() error {
+	return nil
+}
+
+// Stop does nothing, since the shared backend is stopped by its owner.  The
+// notifications of the wallet end when it is closed.
+func (c *walletChainClient) Stop() {}
+
+// WaitForShutdown does nothing, since the shared backend is stopped by its
+// owner.
+func (c *walletChainClient) WaitForShutdown() {}
+
+// Rescan rescans the chain for the wallet with the shared backend.  The
+// rescan notifications of the backend do not tell which rescan they belong
+// to, so the rescans of a network are run one at a time and recorded, and
+// their notifications are only dispatched to the wallet which started them.
+func (c *walletChainClient) Rescan(startHash *chainhash.Hash,
+	addrs []btcutil.Address, outPoints []*wire.OutPoint) error {
+
+	c.rescans.run <- struct{}{}
+	defer func() { <-c.rescans.run }()
+
+	c.rescans.start(c.lw)
+	err := c.Interface.Rescan(startHash, addrs, outPoints)
+	if err != nil {
+		// A failed rescan is not finished by a notification.
+		c.rescans.cancel(c.lw)
+	}
+	return err
+}
+
+// rescanTracker records the wallets rescanning with the chain backend of a
+// network, in the order their rescans were started.
+type rescanTracker struct {
+	// run is held while a rescan is running.
+	run chan struct{}
+
+	// wallets are the wallets whose rescans have been started and whose
+	// RescanFinished notification has not been dispatched yet.  The
+	// first wallet is the one the rescan notifications being dispatched
+	// belong to.
+	wallets []*loadedWallet
+	mu      sync.Mutex
+}
+
+// newRescanTracker creates the rescan tracker of a network.
+func newRescanTracker() *rescanTracker {
+	return &rescanTracker{run: make(chan struct{}, 1)}
+}
+
+// start records a rescan of the wallet.
+func (t *rescanTracker) start(lw *loadedWallet) {
+	t.mu.Lock()
+	t.wallets = append(t.wallets, lw)
+	t.mu.Unlock()
+}
+
+// cancel removes the last rescan of the wallet, which failed.
+func (t *rescanTracker) cancel(lw *loadedWallet) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i := len(t.wallets) - 1; i >= 0; i-- {
+		if t.wallets[i] == lw {
+			t.wallets = append(t.wallets[:i], t.wallets[i+1:]...)
+			return
+		}
+	}
+}
+
+// current returns the wallet whose rescan is being notified, or nil if no
+// rescan is running.
+func (t *rescanTracker) current() *loadedWallet {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.wallets) == 0 {
+		return nil
+	}
+	return t.wallets[0]
+}
+
+// finish removes and returns the wallet whose rescan is being notified, or
+// nil if no rescan is running.
+func (t *rescanTracker) finish() *loadedWallet {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.wallets) == 0 {
+		return nil
+	}
+	lw := t.wallets[0]
+	t.wallets[0] = nil
+	t.wallets = t.wallets[1:]
+	return lw
+}
+
+// synchronize attaches the chain backend of the network of the wallet, if the
+// daemon has one, so that the wallet syncs with the chain and consumes the
+// notifications dispatched to it.
+func (w *WalletDaemon) synchronize(lw *loadedWallet) {
+	client, ok := w.chainClients[lw.chainParams.Name]
+	if !ok {
+		return
+	}
+	lw.wallet.SynchronizeRPC(&walletChainClient{
+		Interface: client,
+		lw:        lw,
+		rescans:   w.rescans[lw.chainParams.Name],
+	})
+}
+
+// startNotifications starts queueing the chain notifications dispatched to
+// the wallet.
+func (lw *loadedWallet) startNotifications() {
+	lw.ntfns = make(chan interface{})
+	lw.ntfnQueue = make(chan interface{})
+	lw.ntfnQuit = make(chan struct{})
+	lw.ntfnDone = make(chan struct{})
+	go lw.queueNotifications()
+}
+
+// queueNotifications queues the notifications dispatched to the wallet until
+// the wallet consumes them, in order, so that dispatching never waits for the
+// wallet.  It closes the notification channel of the wallet once
+// notifications are stopped.  It must be run as a goroutine.
+func (lw *loadedWallet) queueNotifications() {
+	defer close(lw.ntfnDone)
+	defer close(lw.ntfns)
+
+	var queue []interface{}
+	var next interface{}
+	var out chan interface{}
+	for {
+		select {
+		case n := <-lw.ntfnQueue:
+			if out == nil {
+				next, out = n, lw.ntfns
+				continue
+			}
+			queue = append(queue, n)
+		case out <- next:
+			if len(queue) == 0 {
+				next, out = nil, nil
+				continue
+			}
+			next = queue[0]
+			queue[0] = nil
+			queue = queue[1:]
+		case <-lw.ntfnQuit:
+			return
+		}
+	}
+}
+
+// notify queues the chain notification n for the wallet.  Notifications sent
+// after the wallet is closed are dropped.
+func (lw *loadedWallet) notify(n interface{}) {
+	select {
+	case lw.ntfnQueue <- n:
+	case <-lw.ntfnQuit:
+	}
+}
+
+// stopNotifications stops queueing chain notifications for the wallet and
+// closes its notification channel, which ends the notification handler of
+// the wallet.  Queued notifications are dropped.
+func (lw *loadedWallet) stopNotifications() {
+	close(lw.ntfnQuit)
+	<-lw.ntfnDone
+}
+
+// indexOutpoints records the unspent outputs of the wallet, so that the
+// transactions spending them are dispatched to it.
+func (lw *loadedWallet) indexOutpoints() error {
+	lw.outpoints = make(map[wire.OutPoint]struct{})
+	return walletdb.View(lw.db, func(dbtx walletdb.ReadTx) error {
+		ns := dbtx.ReadBucket(wtxmgrNamespaceKey)
+		credits, err := lw.wallet.TxStore.UnspentOutputs(ns)
+		if err != nil {
+			return err
+		}
+		for _, c := range credits {
+			lw.outpoints[c.OutPoint] = struct{}{}
+		}
+		return nil
+	})
+}
+
+// isRelevant returns whether the transaction of n pays an address of the
+// wallet or spends an output indexed for it, and indexes the outputs paying
+// the wallet.  The backend of a network reports the transactions relevant to
+// any of its wallets, and the others must not record them.  Spent outputs are
+// kept in the index, since the spending transaction may be disconnected and
+// the output spent again, until the wallet is closed.
+func (lw *loadedWallet) isRelevant(n chain.RelevantTx) bool {
+	rec := n.TxRecord
+	relevant := false
+	for i, out := range rec.MsgTx.TxOut {
+		_, addrs, _, err := txscript.ExtractPkScriptAddrs(out.PkScript,
+			lw.chainParams)
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			if ok, err := lw.wallet.HaveAddress(addr); err == nil && ok {
+				op := wire.OutPoint{Hash: rec.Hash, Index: uint32(i)}
+				lw.outpoints[op] = struct{}{}
+				relevant = true
+				break
+			}
+		}
+	}
+	if relevant {
+		return true
+	}
+	for _, in := range rec.MsgTx.TxIn {
+		if _, ok := lw.outpoints[in.PreviousOutPoint]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,345 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package walletd
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcwallet/waddrmgr"
+	"github.com/btcsuite/btcwallet/wallet"
+	"github.com/btcsuite/btcwallet/walletdb"
+	"github.com/btcsuite/btcwallet/wtxmgr"
+)
+
+// Top level buckets of a wallet database, as created by the wallet package.
+var (
+	waddrmgrNamespaceKey = []byte("waddrmgr")
+	wtxmgrNamespaceKey   = []byte("wtxmgr")
+)
+
+// WalletCheck is the result of checking a wallet.
+type WalletCheck struct {
+	UUID string `json:"uuid"`
+	Net  string `json:"net"`
+
+	// Problems describes every inconsistency found.  The wallet is intact
+	// when it is empty.
+	Problems []string `json:"problems"`
+
+	Accounts  int   `json:"accounts"`
+	Addresses int   `json:"addresses"`
+	Unspent   int   `json:"unspent"`
+	Balance   int64 `json:"balance"`
+}
+
+// CheckReport is the result of checking the registry and every wallet.
+type CheckReport struct {
+	Wallets []*WalletCheck `json:"wallets"`
+
+	// Unregistered lists the wallet directories without a registry
+	// record, and Missing the registry records without a wallet
+	// directory.
+	Unregistered []string `json:"unregistered"`
+	Missing      []string `json:"missing"`
+}
+
+// OK returns whether no problem was found.
+func (r *CheckReport) OK() bool {
+	if len(r.Unregistered) != 0 || len(r.Missing) != 0 {
+		return false
+	}
+	for _, c := range r.Wallets {
+		if len(c.Problems) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckConfig describes the data directory checked by Check.
+type CheckConfig struct {
+	// DataDir is the data directory of the daemon.
+	DataDir string
+
+	// DBDriver is the walletdb driver of the registry and wallets.  The
+	// default driver is used when it is empty.
+	DBDriver string
+
+	// PubPassphrase is the public passphrase of the wallets.
+	// wallet.InsecurePubPassphrase is used when it is nil.
+	PubPassphrase []byte
+
+	// LockTimeout is how long opening a database waits for another
+	// process, such as a running daemon, to close it.  ErrDBLocked is
+	// returned, or reported as a problem of the wallet, once it expires.
+	LockTimeout time.Duration
+}
+
+// Check checks the registry and every wallet of a data directory as
+// CheckWallets does, without starting a daemon.  The databases are opened for
+// reading only, so nothing is created or modified, and data directories whose
+// registry has not been migrated yet are checked with the layout they were
+// written with.  ErrNewerSchema is returned if the registry was written by a
+// newer version of the daemon.
+func Check(cfg *CheckConfig) (*CheckReport, error) {
+	driver := cfg.DBDriver
+	if driver == "" {
+		driver = DefaultDBDriver
+	}
+	pubPassphrase := cfg.PubPassphrase
+	if pubPassphrase == nil {
+		pubPassphrase = []byte(wallet.InsecurePubPassphrase)
+	}
+	store := &storage{dataDir: cfg.DataDir, driver: driver}
+
+	db, err := store.openReadOnly(store.registryPath(), cfg.LockTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open registry: %v", err)
+	}
+	defer db.Close()
+	reg := &registry{db: db}
+	version, err := reg.schemaVersion()
+	if err != nil {
+		return nil, err
+	}
+	if version > latestSchemaVersion() {
+		return nil, ErrNewerSchema
+	}
+	store.unsharded = version < shardedLayoutVersion
+
+	c := &checker{
+		storage:       store,
+		registry:      reg,
+		pubPassphrase: pubPassphrase,
+		walletDB: func(rec *walletRecord) (walletdb.DB, func(), error) {
+			chainParams, err := ParamsForNet(rec.Net)
+			if err != nil {
+				return nil, nil, err
+			}
+			db, err := store.openReadOnlyDB(rec.ID, chainParams,
+				cfg.LockTimeout)
+			if err != nil {
+				return nil, nil, err
+			}
+			return db, func() { db.Close() }, nil
+		},
+	}
+	return c.checkWallets()
+}
+
+// CheckWallets cross-checks the registry against the wallets directory and
+// checks every registered wallet with a directory.  ErrMigrationPending is
+// returned if the wallets directory has not been migrated to the current
+// layout yet.
+func (w *WalletDaemon) CheckWallets() (*CheckReport, error) {
+	version, err := w.registry.schemaVersion()
+	if err != nil {
+		return nil, err
+	}
+	if version < shardedLayoutVersion {
+		return nil, ErrMigrationPending
+	}
+	return w.checker().checkWallets()
+}
+
+// CheckWallet checks the database of a registered wallet.  Problems found in
+// the wallet are reported in the result rather than as an error.
+func (w *WalletDaemon) CheckWallet(id string) (*WalletCheck, error) {
+	rec, err := w.registry.wallet(id)
+	if err != nil {
+		return nil, err
+	}
+	return w.checker().checkWallet(rec), nil
+}
+
+// checker checks the registry and wallets of a data directory.
+type checker struct {
+	storage       *storage
+	registry      *registry
+	pubPassphrase []byte
+
+	// walletDB returns the database of a registered wallet for reading,
+	// and a function releasing it.
+	walletDB func(rec *walletRecord) (walletdb.DB, func(), error)
+}
+
+// checker returns a checker reading the databases of the daemon, which are
+// shared with its open wallets.
+func (w *WalletDaemon) checker() *checker {
+	return &checker{
+		storage:       w.storage,
+		registry:      w.registry,
+		pubPassphrase: w.pubPassphrase,
+		walletDB:      w.walletDB,
+	}
+}
+
+// checkWallets cross-checks the registry against the wallets directory and
+// checks every registered wallet with a directory.
+func (ch *checker) checkWallets() (*CheckReport, error) {
+	report := &CheckReport{
+		Wallets:      []*WalletCheck{},
+		Unregistered: []string{},
+		Missing:      []string{},
+	}
+
+	var recs []*walletRecord
+	registered := make(map[string]struct{})
+	err := ch.registry.forEachWallet(func(rec *walletRecord) error {
+		recs = append(recs, rec)
+		registered[rec.ID] = struct{}{}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := ch.storage.walletIDs()
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		if _, ok := registered[id]; !ok {
+			report.Unregistered = append(report.Unregistered, id)
+		}
+	}
+
+	for _, rec := range recs {
+		chainParams, err := ParamsForNet(rec.Net)
+		if err == nil && !ch.storage.walletExists(rec.ID, chainParams) {
+			report.Missing = append(report.Missing, rec.ID)
+			continue
+		}
+		report.Wallets = append(report.Wallets, ch.checkWallet(rec))
+	}
+	sort.Strings(report.Missing)
+	return report, nil
+}
+
+// checkWallet checks the buckets, address manager and transaction store of a
+// wallet.  The database is only read.
+func (ch *checker) checkWallet(rec *walletRecord) *WalletCheck {
+	c := &WalletCheck{UUID: rec.ID, Net: rec.Net, Problems: []string{}}
+	problem := func(format string, args ...interface{}) {
+		c.Problems = append(c.Problems, fmt.Sprintf(format, args...))
+	}
+
+	chainParams, err := ParamsForNet(rec.Net)
+	if err != nil {
+		problem("registry: %v", err)
+		return c
+	}
+	db, release, err := ch.walletDB(rec)
+	if err != nil {
+		problem("cannot open database: %v", err)
+		return c
+	}
+	defer release()
+
+	err = walletdb.View(db, func(tx walletdb.ReadTx) error {
+		addrmgrNs := tx.ReadBucket(waddrmgrNamespaceKey)
+		if addrmgrNs == nil {
+			problem("missing %s bucket", waddrmgrNamespaceKey)
+		}
+		txmgrNs := tx.ReadBucket(wtxmgrNamespaceKey)
+		if txmgrNs == nil {
+			problem("missing %s bucket", wtxmgrNamespaceKey)
+		}
+		if addrmgrNs == nil || txmgrNs == nil {
+			return nil
+		}
+
+		mgr, err := waddrmgr.Open(addrmgrNs, ch.pubPassphrase,
+			chainParams)
+		if err != nil {
+			problem("address manager: %v", err)
+			return nil
+		}
+		defer mgr.Close()
+		checkAddressManager(c, mgr, addrmgrNs, problem)
+
+		store, err := wtxmgr.Open(txmgrNs, chainParams)
+		if err != nil {
+			problem("transaction store: %v", err)
+			return nil
+		}
+		checkTxStore(c, store, txmgrNs, mgr.SyncedTo().Height,
+			int32(chainParams.CoinbaseMaturity), problem)
+		return nil
+	})
+	if err != nil {
+		problem("%v", err)
+	}
+	return c
+}
+
+// checkAddressManager checks that every account has properties and every
+// active address can be looked up.
+func checkAddressManager(c *WalletCheck, mgr *waddrmgr.Manager,
+	ns walletdb.ReadBucket, problem func(string, ...interface{})) {
+
+	err := mgr.ForEachAccount(ns, func(account uint32) error {
+		c.Accounts++
+		if _, err := mgr.AccountProperties(ns, account); err != nil {
+			problem("account %d: %v", account, err)
+		}
+		return nil
+	})
+	if err != nil {
+		problem("cannot iterate accounts: %v", err)
+	}
+
+	err = mgr.ForEachActiveAddress(ns, func(addr btcutil.Address) error {
+		c.Addresses++
+		if _, err := mgr.Address(ns, addr); err != nil {
+			problem("address %s: %v", addr, err)
+		}
+		return nil
+	})
+	if err != nil {
+		problem("cannot iterate addresses: %v", err)
+	}
+}
+
+// checkTxStore checks that the balance of the transaction store matches the
+// sum of its unspent outputs, leaving out immature coinbase outputs as the
+// balance does.
+func checkTxStore(c *WalletCheck, store *wtxmgr.Store, ns walletdb.ReadBucket,
+	syncHeight, coinbaseMaturity int32, problem func(string, ...interface{})) {
+
+	credits, err := store.UnspentOutputs(ns)
+	if err != nil {
+		problem("cannot read unspent outputs: %v", err)
+		return
+	}
+	var sum btcutil.Amount
+	for _, credit := range credits {
+		if credit.Amount < 0 {
+			problem("output %v has a negative amount", credit.OutPoint)
+		}
+		// The balance excludes coinbase outputs which have not
+		// matured yet.
+		if credit.FromCoinBase && credit.Height != -1 &&
+			syncHeight-credit.Height+1 < coinbaseMaturity {
+			continue
+		}
+		sum += credit.Amount
+	}
+	c.Unspent = len(credits)
+
+	balance, err := store.Balance(ns, 0, syncHeight)
+	if err != nil {
+		problem("cannot compute balance: %v", err)
+		return
+	}
+	c.Balance = int64(balance)
+	if balance != sum {
+		problem("balance %v does not match the unspent outputs "+
+			"total %v", balance, sum)
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package walletd
+
+import (
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcwallet/chain"
+)
+
+// eventBufferSize is the number of events which may be queued for a
+// subscriber.  Events published while the queue is full are dropped rather
+// than blocking the daemon.
+const eventBufferSize = 64
+
+// Kinds of the events published by the daemon.
+const (
+	EventWalletCreated     = "created"
+	EventWalletOpened      = "opened"
+	EventWalletClosed      = "closed"
+	EventWalletDeleted     = "deleted"
+	EventTransactionSent   = "transaction_sent"
+	EventBlockConnected    = "block_connected"
+	EventBlockDisconnected = "block_disconnected"
+)
+
+// Event is a change of a wallet, or of the chain of a network, published to
+// the subscribers of the tenant of the wallet.  Chain events have no wallet
+// and tenant, and are published to every subscriber of their network.
+type Event struct {
+	Kind       string
+	Time       time.Time
+	Tenant     string
+	WalletUUID string
+	Net        string
+
+	// Hash is the hash of the sent transaction or of the block of chain
+	// events, and Height is the height of the block.
+	Hash   string
+	Height int32
+
+	// Dropped is the number of events dropped before this one because the
+	// subscriber did not keep up.
+	Dropped uint64
+}
+
+// eventSubscription receives the events of the wallets of a tenant, or of a
+// single wallet when walletUUID is set.
+type eventSubscription struct {
+	tenant     string
+	walletUUID string
+	net        string
+	events     chan Event
+
+	// dropped is the number of events dropped since the last queued
+	// event.  It is protected by the eventPublisher mutex.
+	dropped uint64
+}
+
+// matches returns whether the subscription receives the event e.
+func (sub *eventSubscription) matches(e *Event) bool {
+	if e.WalletUUID == "" {
+		return sub.net == "" || sub.net == e.Net
+	}
+	if sub.walletUUID != "" {
+		return sub.walletUUID == e.WalletUUID
+	}
+	return sub.tenant == e.Tenant
+}
+
+// eventPublisher publishes the events of the daemon to its subscribers.
+type eventPublisher struct {
+	mu     sync.Mutex
+	subs   map[*eventSubscription]struct{}
+	closed bool
+}
+
+func newEventPublisher() *eventPublisher {
+	return &eventPublisher{subs: make(map[*eventSubscription]struct{})}
+}
+
+// publish queues the event for every matching subscriber.
+func (p *eventPublisher) publish(e Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	for sub := range p.subs {
+		if !sub.matches(&e) {
+			continue
+		}
+		e.Dropped = sub.dropped
+		select {
+		case sub.events <- e:
+			sub.dropped = 0
+		default:
+			sub.dropped++
+		}
+	}
+}
+
+// subscribe adds the subscription.  The returned function removes it and
+// closes its channel.
+func (p *eventPublisher) subscribe(sub *eventSubscription) func() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		close(sub.events)
+		return func() {}
+	}
+	p.subs[sub] = struct{}{}
+
+	return func() {
+		p.mu.Lock()
+		if _, ok := p.subs[sub]; ok {
+			delete(p.subs, sub)
+			close(sub.events)
+		}
+		p.mu.Unlock()
+	}
+}
+
+// close ends every subscription and refuses new ones.
+func (p *eventPublisher) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.closed = true
+	for sub := range p.subs {
+		delete(p.subs, sub)
+		close(sub.events)
+	}
+}
+
+// SubscribeEvents subscribes to the events of the wallets of the tenant, or of
+// a single wallet of the tenant when id is not empty, along with the chain
+// events of their networks.  ErrWalletNotFound is returned if the wallet is
+// not registered to the tenant.  The returned channel is closed when cancel
+// is called or the event streams are closed.
+func (w *WalletDaemon) SubscribeEvents(tenant, id string) (events <-chan Event,
+	cancel func(), err error) {
+
+	sub := &eventSubscription{
+		tenant: tenant,
+		events: make(chan Event, eventBufferSize),
+	}
+	if id != "" {
+		rec, err := w.tenantWallet(tenant, id)
+		if err != nil {
+			return nil, nil, err
+		}
+		sub.walletUUID = id
+		sub.net = rec.Net
+	}
+	return sub.events, w.events.subscribe(sub), nil
+}
+
+// CloseEvents ends every event subscription and refuses new ones.  It is used
+// on shutdown so that event streams do not hold up draining the RPC server.
+func (w *WalletDaemon) CloseEvents() {
+	w.events.close()
+}
+
+// publishWalletEvent publishes an event of a wallet of the tenant.
+func (w *WalletDaemon) publishWalletEvent(kind, tenant, id, net string) {
+	w.events.publish(Event{
+		Kind:       kind,
+		Tenant:     tenant,
+		WalletUUID: id,
+		Net:        net,
+	})
+}
+
+// publishChainEvent publishes the chain notification n of network net if it
+// is a block notification.  Other notifications are only meant for wallets.
+func (w *WalletDaemon) publishChainEvent(net string, n interface{}) {
+	e := Event{Net: net}
+	switch n := n.(type) {
+	case chain.BlockConnected:
+		e.Kind = EventBlockConnected
+		e.Hash = n.Hash.String()
+		e.Height = n.Height
+	case chain.BlockDisconnected:
+		e.Kind = EventBlockDisconnected
+		e.Hash = n.Hash.String()
+		e.Height = n.Height
+	default:
+		return
+	}
+	w.events.publish(e)
+}
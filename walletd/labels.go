@@ -0,0 +1,415 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package walletd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcwallet/walletdb"
+)
+
+// Types of the labels of a wallet, named as in BIP0329.  The ref of a
+// transaction label is its hash, the one of an address label is the encoded
+// address, and the one of an output label is the hash of its transaction and
+// its index separated by a colon.
+const (
+	LabelTx      = "tx"
+	LabelAddress = "addr"
+	LabelOutput  = "output"
+)
+
+// labelTypes are the supported label types, in the order labels are listed.
+var labelTypes = []string{LabelTx, LabelAddress, LabelOutput}
+
+// MaxLabelLen is the maximum number of characters of a label, as recommended
+// by BIP0329.
+const MaxLabelLen = 255
+
+// MaxImportLabels is the maximum number of labels of an import.
+const MaxImportLabels = 100000
+
+// maxImportLineLen is the maximum length of a line of an import.  Lines are
+// longer than labels since records may have fields which are not imported.
+const maxImportLineLen = 64 * 1024
+
+// labelsBucketName is the name of the nested bucket of a wallet bucket
+// holding its labels.  It holds a nested bucket per label type, mapping refs
+// to labels.
+var labelsBucketName = []byte("labels")
+
+// Label is a note attached to a transaction, address or output of a wallet.
+type Label struct {
+	Type  string
+	Ref   string
+	Label string
+}
+
+// InvalidLabelError is returned for labels which cannot be set, such as those
+// of an unsupported type or with a ref which is invalid for the network of the
+// wallet, and for imports which cannot be read.
+type InvalidLabelError struct {
+	Reason string
+}
+
+func (e *InvalidLabelError) Error() string {
+	return "invalid label: " + e.Reason
+}
+
+// LabelImport is the outcome of an import of labels.
+type LabelImport struct {
+	// Imported is the number of labels set by the import.
+	Imported int
+
+	// Skipped is the number of records which were not imported, such as
+	// those of unsupported types, with an empty or too long label, or
+	// with a ref which is invalid for the network of the wallet.
+	Skipped int
+}
+
+// bip329Record is a line of a BIP0329 export.  Only the type, ref and label
+// are imported.
+type bip329Record struct {
+	Type      string `json:"type"`
+	Ref       string `json:"ref"`
+	Label     string `json:"label,omitempty"`
+	Origin    string `json:"origin,omitempty"`
+	Spendable *bool  `json:"spendable,omitempty"`
+}
+
+// normalizeLabelRef returns the canonical form of the ref of a label of type
+// typ, so that a ref is stored once however it is written.  An
+// InvalidLabelError is returned for unsupported types and invalid refs.
+func normalizeLabelRef(typ, ref string, params *chaincfg.Params) (string, error) {
+	switch typ {
+	case LabelTx:
+		return normalizeTxRef(ref)
+	case LabelAddress:
+		addr, err := btcutil.DecodeAddress(ref, params)
+		if err != nil || !addr.IsForNet(params) {
+			return "", &InvalidLabelError{fmt.Sprintf("invalid %s "+
+				"address %s", params.Name, ref)}
+		}
+		return addr.EncodeAddress(), nil
+	case LabelOutput:
+		i := strings.LastIndexByte(ref, ':')
+		if i < 0 {
+			return "", &InvalidLabelError{"output ref " + ref +
+				" is not of the form txid:index"}
+		}
+		hash, err := normalizeTxRef(ref[:i])
+		if err != nil {
+			return "", err
+		}
+		index, err := strconv.ParseUint(ref[i+1:], 10, 32)
+		if err != nil {
+			return "", &InvalidLabelError{"invalid output index " +
+				ref[i+1:]}
+		}
+		return hash + ":" + strconv.FormatUint(index, 10), nil
+	}
+	return "", checkLabelType(typ)
+}
+
+// checkLabelType returns an InvalidLabelError unless typ is a supported label
+// type.
+func checkLabelType(typ string) error {
+	for _, t := range labelTypes {
+		if typ == t {
+			return nil
+		}
+	}
+	return &InvalidLabelError{"unsupported label type " + typ}
+}
+
+// normalizeTxRef returns the canonical form of a transaction hash.
+func normalizeTxRef(ref string) (string, error) {
+	hash, err := chainhash.NewHashFromStr(ref)
+	if err != nil || len(ref) != chainhash.MaxHashStringSize {
+		return "", &InvalidLabelError{"invalid transaction hash " + ref}
+	}
+	return hash.String(), nil
+}
+
+// walletParams returns the registry record of a wallet of the tenant and the
+// parameters of its network.
+func (w *WalletDaemon) walletParams(tenant, id string) (*walletRecord,
+	*chaincfg.Params, error) {
+
+	rec, err := w.tenantWallet(tenant, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	params, ok := w.nets[rec.Net]
+	if !ok {
+		return nil, nil, ErrUnknownNetwork
+	}
+	return rec, params, nil
+}
+
+// SetLabels sets the labels of a wallet of the tenant, replacing the existing
+// labels of the same refs.  Labels with an empty label are removed.  Either
+// every label is set, or none is and an InvalidLabelError is returned for the
+// first invalid label.  ErrWalletNotFound is returned if the wallet is not
+// registered to the tenant.
+func (w *WalletDaemon) SetLabels(tenant, id string, labels []Label) error {
+	_, params, err := w.walletParams(tenant, id)
+	if err != nil {
+		return err
+	}
+	normalized := make([]Label, 0, len(labels))
+	for _, l := range labels {
+		ref, err := normalizeLabelRef(l.Type, l.Ref, params)
+		if err != nil {
+			return err
+		}
+		if utf8.RuneCountInString(l.Label) > MaxLabelLen ||
+			!utf8.ValidString(l.Label) {
+			return &InvalidLabelError{fmt.Sprintf("labels must be "+
+				"valid UTF-8 of at most %d characters",
+				MaxLabelLen)}
+		}
+		normalized = append(normalized, Label{l.Type, ref, l.Label})
+	}
+	return w.putLabels(tenant, id, normalized)
+}
+
+// Labels returns the labels of a wallet of the tenant, sorted by type and ref.
+// Only the labels of type typ are returned when it is not empty, and only
+// those of the passed refs, in their order, when there are any.  Refs without
+// a label are omitted.  ErrWalletNotFound is returned if the wallet is not
+// registered to the tenant.
+func (w *WalletDaemon) Labels(tenant, id, typ string, refs []string) ([]Label, error) {
+	_, params, err := w.walletParams(tenant, id)
+	if err != nil {
+		return nil, err
+	}
+	if typ == "" && len(refs) != 0 {
+		return nil, &InvalidLabelError{"refs may only be given with " +
+			"a label type"}
+	}
+	types := labelTypes
+	if typ != "" {
+		if err := checkLabelType(typ); err != nil {
+			return nil, err
+		}
+		types = []string{typ}
+	}
+	normalized := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		ref, err := normalizeLabelRef(typ, ref, params)
+		if err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, ref)
+	}
+	return w.registry.labels(id, types, normalized)
+}
+
+// ExportLabels writes every label of a wallet of the tenant to out in the
+// BIP0329 format, one JSON record per line, and returns the number of labels
+// written.  ErrWalletNotFound is returned if the wallet is not registered to
+// the tenant.
+func (w *WalletDaemon) ExportLabels(tenant, id string, out io.Writer) (int, error) {
+	labels, err := w.Labels(tenant, id, "", nil)
+	if err != nil {
+		return 0, err
+	}
+	bw := bufio.NewWriter(out)
+	enc := json.NewEncoder(bw)
+	for _, l := range labels {
+		err := enc.Encode(&bip329Record{
+			Type:  l.Type,
+			Ref:   l.Ref,
+			Label: l.Label,
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(labels), bw.Flush()
+}
+
+// ImportLabels sets the labels read from in, in the BIP0329 format, on a
+// wallet of the tenant.  Imported labels replace the existing labels of the
+// same refs, and other existing labels are kept.  Records which cannot be
+// imported, such as those of other types than the supported ones, are
+// skipped, as BIP0329 recommends.  Nothing is imported if the import cannot
+// be read, in which case an InvalidLabelError is returned for malformed
+// records.  ErrWalletNotFound is returned if the wallet is not registered to
+// the tenant.
+func (w *WalletDaemon) ImportLabels(tenant, id string, in io.Reader) (*LabelImport, error) {
+	_, params, err := w.walletParams(tenant, id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := new(LabelImport)
+	var labels []Label
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 4096), maxImportLineLen)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var rec bip329Record
+		if err := json.Unmarshal([]byte(text), &rec); err != nil {
+			return nil, &InvalidLabelError{fmt.Sprintf("line %d "+
+				"is not a BIP0329 record", line)}
+		}
+		ref, err := normalizeLabelRef(rec.Type, rec.Ref, params)
+		if err != nil || rec.Label == "" ||
+			utf8.RuneCountInString(rec.Label) > MaxLabelLen ||
+			!utf8.ValidString(rec.Label) {
+			result.Skipped++
+			continue
+		}
+		if len(labels) == MaxImportLabels {
+			return nil, &InvalidLabelError{fmt.Sprintf("an import "+
+				"may have at most %d labels", MaxImportLabels)}
+		}
+		labels = append(labels, Label{rec.Type, ref, rec.Label})
+	}
+	if err := scanner.Err(); err != nil {
+		if err == bufio.ErrTooLong {
+			err = &InvalidLabelError{fmt.Sprintf("import lines "+
+				"may be at most %d bytes", maxImportLineLen)}
+		}
+		return nil, err
+	}
+
+	if err := w.putLabels(tenant, id, labels); err != nil {
+		return nil, err
+	}
+	result.Imported = len(labels)
+	return result, nil
+}
+
+// putLabels sets the labels of a wallet of the tenant and counts them in the
+// usage of the tenant.  A QuotaExceededError is returned, and no label is set,
+// if the labels would exceed the disk quota of the tenant.
+func (w *WalletDaemon) putLabels(tenant, id string, labels []Label) error {
+	if err := w.checkLabelQuota(tenant, labels); err != nil {
+		return err
+	}
+	if err := w.registry.putLabels(id, labels); err != nil {
+		return err
+	}
+	w.updateWalletUsage(tenant, id, false)
+	return nil
+}
+
+// labelSize returns the number of registry bytes used by a label.
+func labelSize(l *Label) uint64 {
+	return uint64(len(l.Ref) + len(l.Label))
+}
+
+// putLabels sets the labels of a wallet in a single transaction, removing
+// those with an empty label.  ErrWalletNotFound is returned if the wallet is
+// not registered.
+func (r *registry) putLabels(id string, labels []Label) error {
+	return walletdb.Update(r.db, func(tx walletdb.ReadWriteTx) error {
+		wallets := tx.ReadWriteBucket(walletsBucketName)
+		b := wallets.NestedReadWriteBucket([]byte(id))
+		if b == nil {
+			return ErrWalletNotFound
+		}
+		b, err := b.CreateBucketIfNotExists(labelsBucketName)
+		if err != nil {
+			return err
+		}
+		for _, l := range labels {
+			tb, err := b.CreateBucketIfNotExists([]byte(l.Type))
+			if err != nil {
+				return err
+			}
+			if l.Label == "" {
+				err = tb.Delete([]byte(l.Ref))
+			} else {
+				err = tb.Put([]byte(l.Ref), []byte(l.Label))
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// labelsSize returns the number of registry bytes used by the labels of a
+// wallet.  A wallet which is not registered has none.
+func (r *registry) labelsSize(id string) (uint64, error) {
+	var size uint64
+	err := walletdb.View(r.db, func(tx walletdb.ReadTx) error {
+		b := tx.ReadBucket(walletsBucketName).NestedReadBucket([]byte(id))
+		if b == nil {
+			return nil
+		}
+		b = b.NestedReadBucket(labelsBucketName)
+		if b == nil {
+			return nil
+		}
+		for _, typ := range labelTypes {
+			tb := b.NestedReadBucket([]byte(typ))
+			if tb == nil {
+				continue
+			}
+			tb.ForEach(func(k, v []byte) error {
+				size += uint64(len(k) + len(v))
+				return nil
+			})
+		}
+		return nil
+	})
+	return size, err
+}
+
+// labels returns the labels of a wallet of the passed types, sorted by type
+// and ref, or only those of the passed refs when there are any.
+// ErrWalletNotFound is returned if the wallet is not registered.
+func (r *registry) labels(id string, types, refs []string) ([]Label, error) {
+	var labels []Label
+	err := walletdb.View(r.db, func(tx walletdb.ReadTx) error {
+		b := tx.ReadBucket(walletsBucketName).NestedReadBucket([]byte(id))
+		if b == nil {
+			return ErrWalletNotFound
+		}
+		b = b.NestedReadBucket(labelsBucketName)
+		if b == nil {
+			return nil
+		}
+		for _, typ := range types {
+			tb := b.NestedReadBucket([]byte(typ))
+			if tb == nil {
+				continue
+			}
+			if len(refs) == 0 {
+				tb.ForEach(func(k, v []byte) error {
+					labels = append(labels,
+						Label{typ, string(k), string(v)})
+					return nil
+				})
+				continue
+			}
+			for _, ref := range refs {
+				if v := tb.Get([]byte(ref)); v != nil {
+					labels = append(labels,
+						Label{typ, ref, string(v)})
+				}
+			}
+		}
+		return nil
+	})
+	return labels, err
+}
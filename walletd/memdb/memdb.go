@@ -0,0 +1,461 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package memdb implements an in-memory walletdb driver.
+
+It is intended for tests, which can run the wallet daemon without writing
+wallet databases to disk.  Databases are identified by the path they are
+created with and live until the process exits or they are removed with Remove,
+so a database may be closed and opened again like a file backed one.
+
+Usage:
+
+	import _ "github.com/tuxcanfly/wltd/walletd/memdb"
+
+	db, err := walletdb.Create(memdb.DbType, path)
+
+Read transactions run concurrently, while a read-write transaction excludes
+every other transaction.  The changes of a read-write transaction are made to a
+copy of the database which replaces it on commit.
+*/
+package memdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/btcsuite/btcwallet/walletdb"
+)
+
+// DbType is the walletdb driver name of in-memory databases.
+const DbType = "memdb"
+
+// ErrCopyNotSupported is returned by Copy, since in-memory databases have no
+// on-disk format.
+var ErrCopyNotSupported = errors.New("memdb: copying a database is not " +
+	"supported")
+
+// store holds the contents of a database across opens.
+type store struct {
+	mu   sync.RWMutex
+	root *bucket
+}
+
+var (
+	storesMu sync.Mutex
+	stores   = make(map[string]*store)
+)
+
+// Exists returns whether a database was created at path.
+func Exists(path string) bool {
+	storesMu.Lock()
+	_, ok := stores[path]
+	storesMu.Unlock()
+	return ok
+}
+
+// Remove deletes the database created at path.  Handles of the database which
+// are still open keep working on its last contents.
+func Remove(path string) {
+	storesMu.Lock()
+	delete(stores, path)
+	storesMu.Unlock()
+}
+
+// parseArgs returns the path passed to the Create and Open functions of the
+// driver.
+func parseArgs(funcName string, args ...interface{}) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("invalid arguments to %s.%s -- "+
+			"expected database path", DbType, funcName)
+	}
+	path, ok := args[0].(string)
+	if !ok {
+		return "", fmt.Errorf("first argument to %s.%s is invalid -- "+
+			"expected database path string", DbType, funcName)
+	}
+	return path, nil
+}
+
+func createDB(args ...interface{}) (walletdb.DB, error) {
+	path, err := parseArgs("Create", args...)
+	if err != nil {
+		return nil, err
+	}
+	storesMu.Lock()
+	defer storesMu.Unlock()
+	if _, ok := stores[path]; ok {
+		return nil, walletdb.ErrDbExists
+	}
+	s := &store{root: newBucket()}
+	stores[path] = s
+	return &db{store: s}, nil
+}
+
+func openDB(args ...interface{}) (walletdb.DB, error) {
+	path, err := parseArgs("Open", args...)
+	if err != nil {
+		return nil, err
+	}
+	storesMu.Lock()
+	defer storesMu.Unlock()
+	s, ok := stores[path]
+	if !ok {
+		return nil, walletdb.ErrDbDoesNotExist
+	}
+	return &db{store: s}, nil
+}
+
+func init() {
+	driver := walletdb.Driver{
+		DbType: DbType,
+		Create: createDB,
+		Open:   openDB,
+	}
+	if err := walletdb.RegisterDriver(driver); err != nil {
+		panic(fmt.Sprintf("Failed to regiser database driver '%s': %v",
+			DbType, err))
+	}
+}
+
+// db is an open handle of an in-memory database.  It implements the
+// walletdb.DB interface.
+type db struct {
+	store *store
+
+	mu     sync.Mutex
+	closed bool
+}
+
+func (d *db) isClosed() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.closed
+}
+
+func (d *db) BeginReadTx() (walletdb.ReadTx, error) {
+	if d.isClosed() {
+		return nil, walletdb.ErrDbNotOpen
+	}
+	d.store.mu.RLock()
+	return &transaction{store: d.store, root: d.store.root}, nil
+}
+
+func (d *db) BeginReadWriteTx() (walletdb.ReadWriteTx, error) {
+	if d.isClosed() {
+		return nil, walletdb.ErrDbNotOpen
+	}
+	d.store.mu.Lock()
+	return &transaction{store: d.store, root: d.store.root.clone(),
+		writable: true}, nil
+}
+
+func (d *db) Copy(w io.Writer) error {
+	return ErrCopyNotSupported
+}
+
+func (d *db) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return walletdb.ErrDbNotOpen
+	}
+	d.closed = true
+	return nil
+}
+
+// transaction implements the walletdb.ReadTx and walletdb.ReadWriteTx
+// interfaces.
+type transaction struct {
+	store    *store
+	root     *bucket
+	writable bool
+	closed   bool
+}
+
+func (tx *transaction) ReadBucket(key []byte) walletdb.ReadBucket {
+	return tx.ReadWriteBucket(key)
+}
+
+func (tx *transaction) ReadWriteBucket(key []byte) walletdb.ReadWriteBucket {
+	b := tx.root.nested(key)
+	if b == nil {
+		return nil
+	}
+	return &bucketHandle{tx: tx, b: b}
+}
+
+func (tx *transaction) CreateTopLevelBucket(key []byte) (walletdb.ReadWriteBucket, error) {
+	root := &bucketHandle{tx: tx, b: tx.root}
+	return root.CreateBucketIfNotExists(key)
+}
+
+func (tx *transaction) DeleteTopLevelBucket(key []byte) error {
+	root := &bucketHandle{tx: tx, b: tx.root}
+	return root.DeleteNestedBucket(key)
+}
+
+func (tx *transaction) Commit() error {
+	if tx.closed {
+		return walletdb.ErrTxClosed
+	}
+	if !tx.writable {
+		return walletdb.ErrTxNotWritable
+	}
+	tx.store.root = tx.root
+	tx.closed = true
+	tx.store.mu.Unlock()
+	return nil
+}
+
+func (tx *transaction) Rollback() error {
+	if tx.closed {
+		return walletdb.ErrTxClosed
+	}
+	tx.closed = true
+	if tx.writable {
+		tx.store.mu.Unlock()
+	} else {
+		tx.store.mu.RUnlock()
+	}
+	return nil
+}
+
+// item is either a value or a nested bucket.
+type item struct {
+	value  []byte
+	bucket *bucket
+}
+
+// bucket holds the items of a bucket keyed by key.
+type bucket struct {
+	items map[string]*item
+}
+
+func newBucket() *bucket {
+	return &bucket{items: make(map[string]*item)}
+}
+
+// clone returns a deep copy of the bucket.  Values are immutable once put, so
+// they are shared.
+func (b *bucket) clone() *bucket {
+	c := &bucket{items: make(map[string]*item, len(b.items))}
+	for k, it := range b.items {
+		if it.bucket != nil {
+			c.items[k] = &item{bucket: it.bucket.clone()}
+		} else {
+			c.items[k] = it
+		}
+	}
+	return c
+}
+
+// nested returns the nested bucket at key, or nil.
+func (b *bucket) nested(key []byte) *bucket {
+	it, ok := b.items[string(key)]
+	if !ok {
+		return nil
+	}
+	return it.bucket
+}
+
+// sortedKeys returns the keys of the bucket in byte order.
+func (b *bucket) sortedKeys() []string {
+	keys := make([]string, 0, len(b.items))
+	for k := range b.items {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// bucketHandle is a bucket accessed in a transaction.  It implements the
+// walletdb.ReadBucket and walletdb.ReadWriteBucket interfaces.
+type bucketHandle struct {
+	tx *transaction
+	b  *bucket
+}
+
+func (h *bucketHandle) NestedReadBucket(key []byte) walletdb.ReadBucket {
+	return h.NestedReadWriteBucket(key)
+}
+
+func (h *bucketHandle) NestedReadWriteBucket(key []byte) walletdb.ReadWriteBucket {
+	b := h.b.nested(key)
+	if b == nil {
+		return nil
+	}
+	return &bucketHandle{tx: h.tx, b: b}
+}
+
+func (h *bucketHandle) ForEach(fn func(k, v []byte) error) error {
+	for _, k := range h.b.sortedKeys() {
+		if err := fn([]byte(k), h.b.items[k].value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *bucketHandle) Get(key []byte) []byte {
+	it, ok := h.b.items[string(key)]
+	if !ok {
+		return nil
+	}
+	return it.value
+}
+
+// checkWritable returns an error unless the bucket may be modified.
+func (h *bucketHandle) checkWritable() error {
+	if h.tx.closed {
+		return walletdb.ErrTxClosed
+	}
+	if !h.tx.writable {
+		return walletdb.ErrTxNotWritable
+	}
+	return nil
+}
+
+func (h *bucketHandle) CreateBucket(key []byte) (walletdb.ReadWriteBucket, error) {
+	if err := h.checkWritable(); err != nil {
+		return nil, err
+	}
+	if len(key) == 0 {
+		return nil, walletdb.ErrBucketNameRequired
+	}
+	if it, ok := h.b.items[string(key)]; ok {
+		if it.bucket != nil {
+			return nil, walletdb.ErrBucketExists
+		}
+		return nil, walletdb.ErrIncompatibleValue
+	}
+	b := newBucket()
+	h.b.items[string(key)] = &item{bucket: b}
+	return &bucketHandle{tx: h.tx, b: b}, nil
+}
+
+func (h *bucketHandle) CreateBucketIfNotExists(key []byte) (walletdb.ReadWriteBucket, error) {
+	if b := h.b.nested(key); b != nil {
+		return &bucketHandle{tx: h.tx, b: b}, nil
+	}
+	return h.CreateBucket(key)
+}
+
+func (h *bucketHandle) DeleteNestedBucket(key []byte) error {
+	if err := h.checkWritable(); err != nil {
+		return err
+	}
+	it, ok := h.b.items[string(key)]
+	if !ok {
+		return walletdb.ErrBucketNotFound
+	}
+	if it.bucket == nil {
+		return walletdb.ErrIncompatibleValue
+	}
+	delete(h.b.items, string(key))
+	return nil
+}
+
+func (h *bucketHandle) Put(key, value []byte) error {
+	if err := h.checkWritable(); err != nil {
+		return err
+	}
+	if len(key) == 0 {
+		return walletdb.ErrKeyRequired
+	}
+	if it, ok := h.b.items[string(key)]; ok && it.bucket != nil {
+		return walletdb.ErrIncompatibleValue
+	}
+	// The value must not be nil, which marks nested buckets.
+	v := make([]byte, len(value))
+	copy(v, value)
+	h.b.items[string(key)] = &item{value: v}
+	return nil
+}
+
+func (h *bucketHandle) Delete(key []byte) error {
+	if err := h.checkWritable(); err != nil {
+		return err
+	}
+	if it, ok := h.b.items[string(key)]; ok && it.bucket != nil {
+		return walletdb.ErrIncompatibleValue
+	}
+	delete(h.b.items, string(key))
+	return nil
+}
+
+func (h *bucketHandle) ReadCursor() walletdb.ReadCursor {
+	return h.ReadWriteCursor()
+}
+
+func (h *bucketHandle) ReadWriteCursor() walletdb.ReadWriteCursor {
+	return &cursor{h: h, keys: h.b.sortedKeys(), pos: -1}
+}
+
+// cursor iterates over the keys of a bucket at the time the cursor was
+// created.  It implements the walletdb.ReadCursor and walletdb.ReadWriteCursor
+// interfaces.
+type cursor struct {
+	h    *bucketHandle
+	keys []string
+	pos  int
+}
+
+// current returns the key and value at the cursor position.  Keys deleted
+// since the cursor was created are skipped in the direction dir.
+func (c *cursor) current(dir int) ([]byte, []byte) {
+	for c.pos >= 0 && c.pos < len(c.keys) {
+		if it, ok := c.h.b.items[c.keys[c.pos]]; ok {
+			return []byte(c.keys[c.pos]), it.value
+		}
+		c.pos += dir
+	}
+	return nil, nil
+}
+
+func (c *cursor) First() ([]byte, []byte) {
+	c.pos = 0
+	return c.current(1)
+}
+
+func (c *cursor) Last() ([]byte, []byte) {
+	c.pos = len(c.keys) - 1
+	return c.current(-1)
+}
+
+func (c *cursor) Next() ([]byte, []byte) {
+	if c.pos < len(c.keys) {
+		c.pos++
+	}
+	return c.current(1)
+}
+
+func (c *cursor) Prev() ([]byte, []byte) {
+	if c.pos >= 0 {
+		c.pos--
+	}
+	return c.current(-1)
+}
+
+func (c *cursor) Seek(seek []byte) ([]byte, []byte) {
+	c.pos = sort.Search(len(c.keys), func(i int) bool {
+		return bytes.Compare([]byte(c.keys[i]), seek) >= 0
+	})
+	return c.current(1)
+}
+
+func (c *cursor) Delete() error {
+	if err := c.h.checkWritable(); err != nil {
+		return err
+	}
+	if c.pos < 0 || c.pos >= len(c.keys) {
+		return walletdb.ErrInvalid
+	}
+	return c.h.Delete([]byte(c.keys[c.pos]))
+}
@@ -0,0 +1,267 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package walletd
+
+import (
+	"fmt"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/btcsuite/btcwallet/walletdb"
+)
+
+// Limits of the descriptive metadata of a wallet.  They keep registry records
+// small, since every record is read when listing wallets.
+const (
+	MaxWalletNameLen    = 128
+	MaxWalletTags       = 32
+	MaxWalletTagLen     = 64
+	MaxMetadataEntries  = 32
+	MaxMetadataKeyLen   = 64
+	MaxMetadataValueLen = 1024
+)
+
+var (
+	// nameKey is the key of the display name in the per-wallet buckets.
+	nameKey = []byte("name")
+
+	// tagsBucketName and metadataBucketName are the names of the nested
+	// buckets of a wallet bucket holding its tags, as keys with empty
+	// values, and its metadata entries.
+	tagsBucketName     = []byte("tags")
+	metadataBucketName = []byte("metadata")
+)
+
+// InvalidMetadataError is returned when updating the metadata of a wallet
+// would make it invalid, such as exceeding one of its limits.
+type InvalidMetadataError struct {
+	Reason string
+}
+
+func (e *InvalidMetadataError) Error() string {
+	return "invalid wallet metadata: " + e.Reason
+}
+
+// MetadataUpdate describes a change of the descriptive metadata of a wallet.
+// Tags and metadata keys are removed before others are added, so an update
+// may replace them.
+type MetadataUpdate struct {
+	// Name replaces the display name of the wallet when it is not nil.
+	// The name is removed when set to the empty string.
+	Name *string
+
+	AddTags    []string
+	RemoveTags []string
+
+	// SetMetadata adds or replaces metadata entries, and DeleteMetadata
+	// removes the entries of its keys.
+	SetMetadata    map[string]string
+	DeleteMetadata []string
+}
+
+// WalletFilter selects the wallets returned by ListWallets.  A wallet matches
+// when it has every tag of the filter and a metadata entry with the same value
+// for every entry of the filter.  The empty filter matches every wallet.
+type WalletFilter struct {
+	Tags     []string
+	Metadata map[string]string
+}
+
+// matches returns whether the wallet of the record is selected by the filter.
+func (f *WalletFilter) matches(rec *walletRecord) bool {
+	if f == nil {
+		return true
+	}
+	for _, tag := range f.Tags {
+		i := sort.SearchStrings(rec.Tags, tag)
+		if i == len(rec.Tags) || rec.Tags[i] != tag {
+			return false
+		}
+	}
+	for k, v := range f.Metadata {
+		if value, ok := rec.Metadata[k]; !ok || value != v {
+			return false
+		}
+	}
+	return true
+}
+
+// apply changes the metadata of the record as described by the update.
+func (u *MetadataUpdate) apply(rec *walletRecord) error {
+	if u.Name != nil {
+		rec.Name = *u.Name
+	}
+
+	tags := make(map[string]struct{}, len(rec.Tags))
+	for _, tag := range rec.Tags {
+		tags[tag] = struct{}{}
+	}
+	for _, tag := range u.RemoveTags {
+		delete(tags, tag)
+	}
+	for _, tag := range u.AddTags {
+		tags[tag] = struct{}{}
+	}
+	rec.Tags = rec.Tags[:0]
+	for tag := range tags {
+		rec.Tags = append(rec.Tags, tag)
+	}
+	sort.Strings(rec.Tags)
+
+	if rec.Metadata == nil {
+		rec.Metadata = make(map[string]string)
+	}
+	for _, k := range u.DeleteMetadata {
+		delete(rec.Metadata, k)
+	}
+	for k, v := range u.SetMetadata {
+		rec.Metadata[k] = v
+	}
+	return checkMetadata(rec)
+}
+
+// checkMetadata returns an InvalidMetadataError if the metadata of the record
+// exceeds its limits or is not valid UTF-8.
+func checkMetadata(rec *walletRecord) error {
+	invalid := func(format string, args ...interface{}) error {
+		return &InvalidMetadataError{Reason: fmt.Sprintf(format, args...)}
+	}
+
+	if len(rec.Name) > MaxWalletNameLen || !utf8.ValidString(rec.Name) {
+		return invalid("name must be valid UTF-8 of at most %d bytes",
+			MaxWalletNameLen)
+	}
+	if len(rec.Tags) > MaxWalletTags {
+		return invalid("a wallet may have at most %d tags",
+			MaxWalletTags)
+	}
+	for _, tag := range rec.Tags {
+		if tag == "" || len(tag) > MaxWalletTagLen ||
+			!utf8.ValidString(tag) {
+			return invalid("tags must be valid UTF-8 of 1 to %d "+
+				"bytes", MaxWalletTagLen)
+		}
+	}
+	if len(rec.Metadata) > MaxMetadataEntries {
+		return invalid("a wallet may have at most %d metadata entries",
+			MaxMetadataEntries)
+	}
+	for k, v := range rec.Metadata {
+		if k == "" || len(k) > MaxMetadataKeyLen ||
+			!utf8.ValidString(k) {
+			return invalid("metadata keys must be valid UTF-8 of 1 "+
+				"to %d bytes", MaxMetadataKeyLen)
+		}
+		if len(v) > MaxMetadataValueLen || !utf8.ValidString(v) {
+			return invalid("metadata values must be valid UTF-8 of "+
+				"at most %d bytes", MaxMetadataValueLen)
+		}
+	}
+	return nil
+}
+
+// UpdateWalletMetadata changes the display name, tags and metadata of a wallet
+// of the tenant and returns its new description.  ErrWalletNotFound is
+// returned if the wallet is not registered to the tenant, and an
+// InvalidMetadataError if the update is invalid, in which case the wallet is
+// left unchanged.
+func (w *WalletDaemon) UpdateWalletMetadata(tenant, id string,
+	update *MetadataUpdate) (*WalletInfo, error) {
+
+	if _, err := w.tenantWallet(tenant, id); err != nil {
+		return nil, err
+	}
+	rec, err := w.registry.updateMetadata(id, update.apply)
+	if err != nil {
+		return nil, err
+	}
+	return w.walletInfo(rec), nil
+}
+
+// updateMetadata changes the metadata of the registry record of a wallet with
+// fn and returns the updated record.  Nothing is changed if fn returns an
+// error.  ErrWalletNotFound is returned if the wallet is not registered.
+func (r *registry) updateMetadata(id string,
+	fn func(*walletRecord) error) (*walletRecord, error) {
+
+	var rec *walletRecord
+	err := walletdb.Update(r.db, func(tx walletdb.ReadWriteTx) error {
+		wallets := tx.ReadWriteBucket(walletsBucketName)
+		b := wallets.NestedReadWriteBucket([]byte(id))
+		if b == nil {
+			return ErrWalletNotFound
+		}
+		rec = readWalletRecord(id, b)
+		if err := fn(rec); err != nil {
+			return err
+		}
+		return putMetadata(b, rec)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// putMetadata records the display name, tags and metadata of the wallet record
+// held by bucket b, replacing the previous ones.  Empty values are not stored.
+func putMetadata(b walletdb.ReadWriteBucket, rec *walletRecord) error {
+	if rec.Name == "" {
+		if err := b.Delete(nameKey); err != nil {
+			return err
+		}
+	} else if err := b.Put(nameKey, []byte(rec.Name)); err != nil {
+		return err
+	}
+
+	for _, name := range [][]byte{tagsBucketName, metadataBucketName} {
+		err := b.DeleteNestedBucket(name)
+		if err != nil && err != walletdb.ErrBucketNotFound {
+			return err
+		}
+	}
+	if len(rec.Tags) != 0 {
+		tags, err := b.CreateBucket(tagsBucketName)
+		if err != nil {
+			return err
+		}
+		for _, tag := range rec.Tags {
+			if err := tags.Put([]byte(tag), []byte{}); err != nil {
+				return err
+			}
+		}
+	}
+	if len(rec.Metadata) != 0 {
+		metadata, err := b.CreateBucket(metadataBucketName)
+		if err != nil {
+			return err
+		}
+		for k, v := range rec.Metadata {
+			if err := metadata.Put([]byte(k), []byte(v)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// readMetadata deserializes the display name, tags and metadata of the wallet
+// record held by bucket b into rec.  Tags are sorted, as they are stored.
+func readMetadata(b walletdb.ReadBucket, rec *walletRecord) {
+	rec.Name = string(b.Get(nameKey))
+	if tags := b.NestedReadBucket(tagsBucketName); tags != nil {
+		tags.ForEach(func(k, v []byte) error {
+			rec.Tags = append(rec.Tags, string(k))
+			return nil
+		})
+	}
+	if metadata := b.NestedReadBucket(metadataBucketName); metadata != nil {
+		rec.Metadata = make(map[string]string)
+		metadata.ForEach(func(k, v []byte) error {
+			rec.Metadata[string(k)] = string(v)
+			return nil
+		})
+	}
+}
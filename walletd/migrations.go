@@ -0,0 +1,179 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package walletd
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/btcsuite/btcwallet/walletdb"
+)
+
+// ErrNewerSchema is returned when opening a registry written by a newer
+// version of the daemon.
+var ErrNewerSchema = errors.New("registry schema is newer than supported " +
+	"by this version")
+
+// ErrMigrationPending is returned by operations which require the registry and
+// wallets to be migrated, which is done when the daemon is started.
+var ErrMigrationPending = errors.New("registry must be migrated by starting " +
+	"the daemon first")
+
+// shardedLayoutVersion is the schema version from which wallets are stored in
+// shard directories.
+const shardedLayoutVersion = 3
+
+var (
+	// metaBucketName is the name of the top level bucket holding the
+	// registry metadata.
+	metaBucketName = []byte("meta")
+
+	// schemaVersionKey is the key of the schema version in the metadata
+	// bucket.
+	schemaVersionKey = []byte("version")
+)
+
+// migration upgrades the registry from the previous schema version to
+// version.  Migrations are run in their own transaction, which also records
+// the new version, so a failed migration leaves the registry at the previous
+// version.
+type migration struct {
+	version     uint32
+	description string
+	migrate     func(w *WalletDaemon, tx walletdb.ReadWriteTx) error
+}
+
+// migrations are the registry migrations in ascending version order.  The
+// first schema version, which only has the wallets bucket, is 1.  New
+// migrations must be appended, and must also be reflected in createRegistry.
+var migrations = []migration{
+	{
+		version:     2,
+		description: "add the quotas bucket and wallet tenants",
+		migrate: func(w *WalletDaemon, tx walletdb.ReadWriteTx) error {
+			// Wallets without a tenant belong to the empty tenant,
+			// so only the bucket is needed.  It may already exist
+			// in registries created before versioning.
+			if tx.ReadWriteBucket(quotasBucketName) != nil {
+				return nil
+			}
+			_, err := tx.CreateTopLevelBucket(quotasBucketName)
+			return err
+		},
+	},
+	{
+		version:     shardedLayoutVersion,
+		description: "move wallet directories into shard directories",
+		migrate: func(w *WalletDaemon, tx walletdb.ReadWriteTx) error {
+			return w.storage.shardWalletDirs()
+		},
+	},
+}
+
+// latestSchemaVersion returns the schema version of the registry after every
+// migration.
+func latestSchemaVersion() uint32 {
+	return migrations[len(migrations)-1].version
+}
+
+// schemaVersion returns the schema version of the registry.  Registries
+// created before versioning are at version 1.
+func (r *registry) schemaVersion() (uint32, error) {
+	version := uint32(1)
+	err := walletdb.View(r.db, func(tx walletdb.ReadTx) error {
+		meta := tx.ReadBucket(metaBucketName)
+		if meta == nil {
+			return nil
+		}
+		v := meta.Get(schemaVersionKey)
+		if len(v) != 4 {
+			return fmt.Errorf("invalid registry schema version")
+		}
+		version = binary.LittleEndian.Uint32(v)
+		return nil
+	})
+	return version, err
+}
+
+// putSchemaVersion records the schema version of the registry, creating the
+// metadata bucket if needed.
+func putSchemaVersion(tx walletdb.ReadWriteTx, version uint32) error {
+	meta := tx.ReadWriteBucket(metaBucketName)
+	if meta == nil {
+		var err error
+		meta, err = tx.CreateTopLevelBucket(metaBucketName)
+		if err != nil {
+			return err
+		}
+	}
+	var v [4]byte
+	binary.LittleEndian.PutUint32(v[:], version)
+	return meta.Put(schemaVersionKey, v[:])
+}
+
+// MigrationStep describes a migration which has not been applied yet.
+type MigrationStep struct {
+	Version     uint32
+	Description string
+}
+
+// PendingMigrations returns the current schema version of the registry and
+// the migrations which will be applied when the daemon is started.
+func (w *WalletDaemon) PendingMigrations() (uint32, []MigrationStep, error) {
+	version, err := w.registry.schemaVersion()
+	if err != nil {
+		return 0, nil, err
+	}
+	var steps []MigrationStep
+	for _, m := range migrations {
+		if m.version > version {
+			steps = append(steps, MigrationStep{m.version,
+				m.description})
+		}
+	}
+	return version, steps, nil
+}
+
+// migrate applies the pending migrations to the registry.  The registry is
+// backed up next to itself before the first migration is applied.
+func (w *WalletDaemon) migrate() error {
+	version, steps, err := w.PendingMigrations()
+	if err != nil {
+		return err
+	}
+	if len(steps) == 0 {
+		return nil
+	}
+
+	backup := filepath.Join(w.dbDir, fmt.Sprintf("%s.v%d-%s.bak",
+		registryDbName, version, time.Now().UTC().Format(backupNameFormat)))
+	log.Infof("Backing up registry to %s before migrating it", backup)
+	_, _, err = copyFileSync(backup, w.registry.db.Copy)
+	if err != nil {
+		return fmt.Errorf("cannot back up registry: %v", err)
+	}
+
+	for _, m := range migrations {
+		if m.version <= version {
+			continue
+		}
+		log.Infof("Migrating registry to version %d: %s", m.version,
+			m.description)
+		err := walletdb.Update(w.registry.db, func(tx walletdb.ReadWriteTx) error {
+			if err := m.migrate(w, tx); err != nil {
+				return err
+			}
+			return putSchemaVersion(tx, m.version)
+		})
+		if err != nil {
+			return fmt.Errorf("cannot migrate registry to version "+
+				"%d: %v", m.version, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package walletd
+
+import (
+	"errors"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// ErrUnknownNetwork is returned when a network is not served by the daemon.
+var ErrUnknownNetwork = errors.New("network is not served by this daemon")
+
+// knownNets holds the parameters of every network wallets may be created for.
+var knownNets = []*chaincfg.Params{
+	&chaincfg.MainNetParams,
+	&chaincfg.TestNet3Params,
+	&chaincfg.RegressionNetParams,
+	&chaincfg.SimNetParams,
+}
+
+// ParamsForNet returns the chain parameters of the network with the passed
+// chaincfg name, such as "mainnet" or "testnet3".
+func ParamsForNet(name string) (*chaincfg.Params, error) {
+	for _, params := range knownNets {
+		if params.Name == name {
+			return params, nil
+		}
+	}
+	return nil, ErrUnknownNetwork
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package walletd
+
+import (
+	"runtime"
+	"sync"
+)
+
+// CreateResult is the outcome of creating one of the wallets of a
+// CreateWallets call.  Index is the position of the private passphrase of the
+// wallet in the call, UUID is set when the wallet was created and Err when it
+// could not be.
+type CreateResult struct {
+	Index int
+	UUID  string
+	Err   error
+}
+
+// createWorkers returns the number of wallets the daemon creates concurrently
+// for CreateWallets calls.
+func createWorkers(n int) int {
+	if n <= 0 {
+		n = runtime.NumCPU()
+	}
+	return n
+}
+
+// createPool hands out the slots of the workers creating the wallets of
+// CreateWallets calls.  Waiting tenants take turns as slots are released, so
+// a tenant creating many wallets, or making many calls, does not hold back
+// the wallets of other tenants.
+type createPool struct {
+	mu      sync.Mutex
+	workers int
+	free    int
+
+	// waiters holds the waiting slot requests of every tenant, and
+	// tenants the tenants with waiting requests in the order they are
+	// served.
+	waiters map[string][]chan struct{}
+	tenants []string
+}
+
+// newCreatePool creates a pool of n worker slots.
+func newCreatePool(n int) *createPool {
+	return &createPool{
+		workers: n,
+		free:    n,
+		waiters: make(map[string][]chan struct{}),
+	}
+}
+
+// acquire waits for a worker slot for the tenant.  The returned function
+// releases the slot.
+func (p *createPool) acquire(tenant string) func() {
+	p.mu.Lock()
+	if p.free != 0 {
+		p.free--
+		p.mu.Unlock()
+		return p.release
+	}
+	ready := make(chan struct{})
+	if _, ok := p.waiters[tenant]; !ok {
+		p.tenants = append(p.tenants, tenant)
+	}
+	p.waiters[tenant] = append(p.waiters[tenant], ready)
+	p.mu.Unlock()
+
+	<-ready
+	return p.release
+}
+
+// release hands a released slot to the next waiting tenant, which then goes
+// to the back of the line, or frees it when nobody is waiting.
+func (p *createPool) release() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.tenants) == 0 {
+		p.free++
+		return
+	}
+	tenant := p.tenants[0]
+	p.tenants = p.tenants[1:]
+	waiters := p.waiters[tenant]
+	close(waiters[0])
+	if len(waiters) == 1 {
+		delete(p.waiters, tenant)
+	} else {
+		p.waiters[tenant] = waiters[1:]
+		p.tenants = append(p.tenants, tenant)
+	}
+}
+
+// CreateWallets creates a wallet of the tenant on the named network for every
+// private passphrase, as CreateWallet does, except that every wallet is closed
+// once registered rather than left open until it is idle, so that bulk
+// provisioning does not load every wallet.  Wallets are created concurrently
+// by a worker pool shared by every CreateWallets call of the daemon, so that
+// bulk provisioning cannot use more than the configured number of workers.
+// Tenants creating wallets at once take turns using the workers.
+//
+// The result of every wallet is passed to report, which is never called
+// concurrently, in the order the wallets are finished.  Failing to create a
+// wallet does not stop the others, but an error returned by report does: the
+// wallets which were not started are skipped and the error is returned once
+// the running ones are finished.  ErrUnknownNetwork is returned before
+// creating any wallet if the network is not served by the daemon.
+func (w *WalletDaemon) CreateWallets(tenant, net string, privPassphrases [][]byte,
+	report func(*CreateResult) error) error {
+
+	if _, ok := w.nets[net]; net != "" && !ok {
+		return ErrUnknownNetwork
+	}
+
+	workers := w.createPool.workers
+	if workers > len(privPassphrases) {
+		workers = len(privPassphrases)
+	}
+	jobs := make(chan int)
+	results := make(chan *CreateResult)
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				release := w.createPool.acquire(tenant)
+				id, err := w.createWallet(tenant, net,
+					privPassphrases[i], nil, false)
+				release()
+				results <- &CreateResult{Index: i, UUID: id, Err: err}
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for i := range privPassphrases {
+			select {
+			case jobs <- i:
+			case <-stop:
+				return
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var reportErr error
+	created := 0
+	for r := range results {
+		if r.Err == nil {
+			created++
+		}
+		if reportErr != nil {
+			continue
+		}
+		if reportErr = report(r); reportErr != nil {
+			close(stop)
+		}
+	}
+	log.Infof("Created %d of %d wallets for tenant %q", created,
+		len(privPassphrases), tenant)
+	return reportErr
+}
@@ -0,0 +1,365 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package walletd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/tuxcanfly/wltd/logctx"
+)
+
+// ErrNoTenant is returned when setting the quota of the empty tenant.  Wallets
+// created without a tenant are not subject to quotas.
+var ErrNoTenant = errors.New("quotas require a tenant")
+
+// Quota limits the wallets of a tenant.  Zero limits are not enforced.
+type Quota struct {
+	// MaxWallets is the number of wallets the tenant may create.
+	MaxWallets uint64
+
+	// MaxDiskBytes is the total size of the wallet files and labels of
+	// the tenant above which no more wallets may be created.  Labels may
+	// not be set beyond it either, while existing wallets may still grow
+	// beyond it.
+	MaxDiskBytes uint64
+
+	// MaxOpenWallets is the number of wallets of the tenant which may be
+	// open at once.
+	MaxOpenWallets uint64
+}
+
+// Usage is the resource usage of a tenant counted against its quota.
+type Usage struct {
+	Wallets     uint64
+	DiskBytes   uint64
+	OpenWallets uint64
+}
+
+// QuotaExceededError is returned when an operation would exceed the quota of
+// the tenant.
+type QuotaExceededError struct {
+	Tenant string
+
+	// Limit names the exceeded limit, one of "wallets", "disk" or "open
+	// wallets".
+	Limit string
+	Max   uint64
+}
+
+// Error implements the error interface.
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("quota of %d %s of tenant %s exceeded", e.Max,
+		e.Limit, e.Tenant)
+}
+
+// quotaReservation counts the wallets of a tenant which are being created, and
+// those being opened or created to be kept open, which are not yet recorded in
+// the registry or the open wallets.
+type quotaReservation struct {
+	creating uint64
+	opening  uint64
+}
+
+// SetQuota sets the quota of a tenant.  Setting a quota without any limit
+// removes it.  Existing wallets exceeding a lowered quota are left untouched.
+func (w *WalletDaemon) SetQuota(tenant string, q *Quota) error {
+	if tenant == "" {
+		return ErrNoTenant
+	}
+	return w.registry.putQuota(tenant, q)
+}
+
+// TenantUsage returns the quota and current usage of a tenant.  The size of
+// the files of open wallets is the one measured when they were created or last
+// closed.
+func (w *WalletDaemon) TenantUsage(tenant string) (*Quota, *Usage, error) {
+	q, err := w.registry.quota(tenant)
+	if err != nil {
+		return nil, nil, err
+	}
+	w.quotaMu.Lock()
+	defer w.quotaMu.Unlock()
+	u, err := w.usage(tenant)
+	if err != nil {
+		return nil, nil, err
+	}
+	return q, u, nil
+}
+
+// Tenants returns every tenant owning wallets or with a quota.  Wallets
+// created without a tenant are reported under the empty tenant.
+func (w *WalletDaemon) Tenants() ([]string, error) {
+	seen := make(map[string]struct{})
+	var tenants []string
+	add := func(tenant string) {
+		if _, ok := seen[tenant]; !ok {
+			seen[tenant] = struct{}{}
+			tenants = append(tenants, tenant)
+		}
+	}
+	err := w.registry.forEachWallet(func(rec *walletRecord) error {
+		add(rec.Tenant)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = w.registry.forEachQuota(func(tenant string, q *Quota) error {
+		add(tenant)
+		return nil
+	})
+	return tenants, err
+}
+
+// usage returns the usage of a tenant from the usage counters, and the open
+// wallets of the tenant.  It must be called with quotaMu held.
+func (w *WalletDaemon) usage(tenant string) (*Usage, error) {
+	if err := w.loadUsage(); err != nil {
+		return nil, err
+	}
+	u := new(Usage)
+	if c, ok := w.usages[tenant]; ok {
+		*u = *c
+	}
+
+	w.walletsMu.Lock()
+	for _, lw := range w.wallets {
+		if lw.tenant == tenant {
+			u.OpenWallets++
+		}
+	}
+	w.walletsMu.Unlock()
+	return u, nil
+}
+
+// loadUsage initializes the usage counters from the registry and the wallet
+// files when they are first used.  The counters are then kept up to date as
+// wallets are registered, closed and removed, so that the registry and wallet
+// directories are only walked once.  It must be called with quotaMu held.
+func (w *WalletDaemon) loadUsage() error {
+	if w.usages != nil {
+		return nil
+	}
+	// The wallets are measured once read, since their labels are read
+	// in their own transaction.
+	var recs []*walletRecord
+	err := w.registry.forEachWallet(func(rec *walletRecord) error {
+		recs = append(recs, rec)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	usages := make(map[string]*Usage)
+	sizes := make(map[string]uint64)
+	for _, rec := range recs {
+		size, err := w.walletSize(rec.ID)
+		if err != nil {
+			return err
+		}
+		u, ok := usages[rec.Tenant]
+		if !ok {
+			u = new(Usage)
+			usages[rec.Tenant] = u
+		}
+		u.Wallets++
+		u.DiskBytes += size
+		sizes[rec.ID] = size
+	}
+	w.usages, w.walletSizes = usages, sizes
+	return nil
+}
+
+// walletSize returns the disk usage of a wallet, which is the size of its
+// files and of its labels in the registry.
+func (w *WalletDaemon) walletSize(id string) (uint64, error) {
+	size, err := dirSize(w.storage.walletDir(id))
+	if err != nil {
+		return 0, err
+	}
+	labels, err := w.registry.labelsSize(id)
+	if err != nil {
+		return 0, err
+	}
+	return size + labels, nil
+}
+
+// updateWalletUsage measures the size of the files and labels of a wallet of
+// the tenant again.  A newly registered wallet is also counted in the usage of
+// the tenant when registered is set, while a wallet which is not counted, such
+// as one removed in the meantime, is otherwise ignored.
+func (w *WalletDaemon) updateWalletUsage(tenant, id string, registered bool) {
+	size, err := w.walletSize(id)
+	if err != nil {
+		// A new wallet is still counted, with its files measured
+		// once it is closed.
+		logctx.WalletLog(log, id).Warnf("Cannot measure the size of "+
+			"wallet %s: %v", id, err)
+		if !registered {
+			return
+		}
+	}
+
+	w.quotaMu.Lock()
+	defer w.quotaMu.Unlock()
+	if w.usages == nil {
+		// The wallet is counted when the counters are loaded.
+		return
+	}
+	old, counted := w.walletSizes[id]
+	if !counted && !registered {
+		return
+	}
+	u, ok := w.usages[tenant]
+	if !ok {
+		u = new(Usage)
+		w.usages[tenant] = u
+	}
+	if !counted {
+		u.Wallets++
+	}
+	u.DiskBytes = u.DiskBytes - old + size
+	w.walletSizes[id] = size
+}
+
+// removeWalletUsage removes a wallet of the tenant which is no longer
+// registered from its usage.
+func (w *WalletDaemon) removeWalletUsage(tenant, id string) {
+	w.quotaMu.Lock()
+	defer w.quotaMu.Unlock()
+
+	size, ok := w.walletSizes[id]
+	if !ok {
+		return
+	}
+	u := w.usages[tenant]
+	u.Wallets--
+	u.DiskBytes -= size
+	if *u == (Usage{}) {
+		delete(w.usages, tenant)
+	}
+	delete(w.walletSizes, id)
+}
+
+// checkLabelQuota returns a QuotaExceededError if setting the labels would
+// exceed the disk quota of the tenant.  The size of the labels is counted in
+// full even when they replace existing labels, and removing labels is always
+// allowed.
+func (w *WalletDaemon) checkLabelQuota(tenant string, labels []Label) error {
+	var size uint64
+	for i := range labels {
+		if labels[i].Label != "" {
+			size += labelSize(&labels[i])
+		}
+	}
+	if size == 0 {
+		return nil
+	}
+	q, err := w.registry.quota(tenant)
+	if err != nil {
+		return err
+	}
+	if q.MaxDiskBytes == 0 {
+		return nil
+	}
+
+	w.quotaMu.Lock()
+	defer w.quotaMu.Unlock()
+	u, err := w.usage(tenant)
+	if err != nil {
+		return err
+	}
+	if u.DiskBytes+size > q.MaxDiskBytes {
+		return &QuotaExceededError{tenant, "disk bytes", q.MaxDiskBytes}
+	}
+	return nil
+}
+
+// reserveQuota checks that the tenant may create a wallet, when creating is
+// set, and keep a wallet open, when open is set, and reserves them until the
+// returned function is called.  The function must be called once the wallet
+// has been recorded in the registry and the open wallets, or has failed to be.
+func (w *WalletDaemon) reserveQuota(tenant string, creating, open bool) (func(), error) {
+	q, err := w.registry.quota(tenant)
+	if err != nil {
+		return nil, err
+	}
+	if *q == (Quota{}) {
+		return func() {}, nil
+	}
+
+	// Usage is counted and reserved under quotaMu, so that concurrent
+	// operations of the tenant see each other's reservations.
+	w.quotaMu.Lock()
+	defer w.quotaMu.Unlock()
+
+	u, err := w.usage(tenant)
+	if err != nil {
+		return nil, err
+	}
+	r := w.reserved[tenant]
+	if r == nil {
+		r = new(quotaReservation)
+	}
+	if creating {
+		if q.MaxWallets != 0 && u.Wallets+r.creating >= q.MaxWallets {
+			return nil, &QuotaExceededError{tenant, "wallets",
+				q.MaxWallets}
+		}
+		if q.MaxDiskBytes != 0 && u.DiskBytes >= q.MaxDiskBytes {
+			return nil, &QuotaExceededError{tenant, "disk bytes",
+				q.MaxDiskBytes}
+		}
+	}
+	if open && q.MaxOpenWallets != 0 &&
+		u.OpenWallets+r.opening >= q.MaxOpenWallets {
+		return nil, &QuotaExceededError{tenant, "open wallets",
+			q.MaxOpenWallets}
+	}
+
+	if creating {
+		r.creating++
+	}
+	if open {
+		r.opening++
+	}
+	w.reserved[tenant] = r
+
+	return func() {
+		w.quotaMu.Lock()
+		if creating {
+			r.creating--
+		}
+		if open {
+			r.opening--
+		}
+		if *r == (quotaReservation{}) {
+			delete(w.reserved, tenant)
+		}
+		w.quotaMu.Unlock()
+	}, nil
+}
+
+// dirSize returns the total size of the regular files below a directory.  A
+// missing directory has a size of zero.
+func dirSize(dir string) (uint64, error) {
+	var size uint64
+	err := filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if fi.Mode().IsRegular() {
+			size += uint64(fi.Size())
+		}
+		return nil
+	})
+	return size, err
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package walletd
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/btcsuite/btcwallet/walletdb"
+)
+
+// ErrDBLocked is returned when a database cannot be opened for reading
+// because another process, such as a running daemon, holds it open.
+var ErrDBLocked = errors.New("database is in use by another process")
+
+// openReadOnlyDB opens the bdb database at path for reading only, waiting at
+// most timeout for a process writing to it to close it.  Unlike the bdb
+// walletdb driver, it neither creates the database nor takes an exclusive
+// lock, so databases can be read while nothing may write to them.
+// walletdb.ErrDbDoesNotExist is returned if there is no database at path.
+func openReadOnlyDB(path string, timeout time.Duration) (walletdb.DB, error) {
+	if !fileExists(path) {
+		return nil, walletdb.ErrDbDoesNotExist
+	}
+	db, err := bolt.Open(path, 0600, &bolt.Options{
+		Timeout:  timeout,
+		ReadOnly: true,
+	})
+	if err == bolt.ErrTimeout {
+		return nil, ErrDBLocked
+	}
+	if err != nil {
+		return nil, err
+	}
+	return (*readOnlyDB)(db), nil
+}
+
+// readOnlyDB is a walletdb.DB which only begins read transactions.
+type readOnlyDB bolt.DB
+
+func (db *readOnlyDB) BeginReadTx() (walletdb.ReadTx, error) {
+	tx, err := (*bolt.DB)(db).Begin(false)
+	if err != nil {
+		return nil, err
+	}
+	return (*readOnlyTx)(tx), nil
+}
+
+func (db *readOnlyDB) BeginReadWriteTx() (walletdb.ReadWriteTx, error) {
+	return nil, walletdb.ErrTxNotWritable
+}
+
+func (db *readOnlyDB) Copy(w io.Writer) error {
+	return (*bolt.DB)(db).View(func(tx *bolt.Tx) error {
+		_, err := tx.WriteTo(w)
+		return err
+	})
+}
+
+func (db *readOnlyDB) Close() error {
+	return (*bolt.DB)(db).Close()
+}
+
+// readOnlyTx is a read transaction of a readOnlyDB.
+type readOnlyTx bolt.Tx
+
+func (tx *readOnlyTx) ReadBucket(key []byte) walletdb.ReadBucket {
+	b := (*bolt.Tx)(tx).Bucket(key)
+	if b == nil {
+		return nil
+	}
+	return (*readOnlyBucket)(b)
+}
+
+func (tx *readOnlyTx) Rollback() error {
+	return (*bolt.Tx)(tx).Rollback()
+}
+
+// readOnlyBucket is a bucket read by a readOnlyTx.
+type readOnlyBucket bolt.Bucket
+
+func (b *readOnlyBucket) NestedReadBucket(key []byte) walletdb.ReadBucket {
+	nested := (*bolt.Bucket)(b).Bucket(key)
+	if nested == nil {
+		return nil
+	}
+	return (*readOnlyBucket)(nested)
+}
+
+func (b *readOnlyBucket) ForEach(fn func(k, v []byte) error) error {
+	return (*bolt.Bucket)(b).ForEach(fn)
+}
+
+func (b *readOnlyBucket) Get(key []byte) []byte {
+	return (*bolt.Bucket)(b).Get(key)
+}
+
+func (b *readOnlyBucket) ReadCursor() walletdb.ReadCursor {
+	return (*bolt.Bucket)(b).Cursor()
+}
@@ -0,0 +1,355 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package walletd
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/btcsuite/btcwallet/walletdb"
+)
+
+// registryDbName is the name of the registry database within the daemon data
+// directory.
+const registryDbName = "walletd.db"
+
+// ErrWalletNotFound is returned when a wallet UUID is not recorded in the
+// registry.
+var ErrWalletNotFound = errors.New("wallet not found")
+
+var (
+	// walletsBucketName is the name of the top level bucket holding a
+	// nested bucket per wallet, keyed by wallet UUID.
+	walletsBucketName = []byte("wallets")
+
+	// quotasBucketName is the name of the top level bucket holding a
+	// nested bucket per tenant with a quota, keyed by tenant.
+	quotasBucketName = []byte("quotas")
+
+	// Keys of the per-wallet buckets.
+	netKey      = []byte("net")
+	tenantKey   = []byte("tenant")
+	createdKey  = []byte("created")
+	lastUsedKey = []byte("lastused")
+	autoloadKey = []byte("autoload")
+
+	// Keys of the per-tenant quota buckets.
+	maxWalletsKey = []byte("maxwallets")
+	maxDiskKey    = []byte("maxdisk")
+	maxOpenKey    = []byte("maxopen")
+)
+
+// walletRecord describes a wallet managed by the daemon.
+type walletRecord struct {
+	ID       string
+	Net      string
+	Tenant   string
+	Created  time.Time
+	LastUsed time.Time
+
+	// Autoload is set for wallets which are opened when the daemon is
+	// started.
+	Autoload bool
+
+	// Name, Tags and Metadata describe the wallet to people.  Tags are
+	// sorted.
+	Name     string
+	Tags     []string
+	Metadata map[string]string
+}
+
+// registry records every wallet created by the daemon in the walletd.db
+// database.
+type registry struct {
+	db walletdb.DB
+}
+
+// openRegistry opens the registry database of the storage, creating it at the
+// latest schema version when it does not exist yet.  Existing registries are
+// left at their schema version until they are migrated, and ErrNewerSchema is
+// returned if the schema is newer than this version of the daemon supports.
+func openRegistry(s *storage) (*registry, error) {
+	db, err := s.openRegistryDB()
+	if err == walletdb.ErrDbDoesNotExist {
+		return createRegistry(s)
+	}
+	if err != nil {
+		return nil, err
+	}
+	r := &registry{db: db}
+	version, err := r.schemaVersion()
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	if version > latestSchemaVersion() {
+		db.Close()
+		return nil, ErrNewerSchema
+	}
+	return r, nil
+}
+
+// createRegistry creates the registry database of the storage with the top
+// level buckets of the latest schema version.
+func createRegistry(s *storage) (*registry, error) {
+	db, err := s.createRegistryDB()
+	if err != nil {
+		return nil, err
+	}
+
+	err = walletdb.Update(db, func(tx walletdb.ReadWriteTx) error {
+		buckets := [][]byte{walletsBucketName, quotasBucketName,
+			metaBucketName}
+		for _, name := range buckets {
+			if _, err := tx.CreateTopLevelBucket(name); err != nil {
+				return err
+			}
+		}
+		return putSchemaVersion(tx, latestSchemaVersion())
+	})
+	if err != nil {
+		db.Close()
+		s.removeRegistryDB()
+		return nil, fmt.Errorf("cannot initialize registry: %v", err)
+	}
+
+	return &registry{db: db}, nil
+}
+
+// close closes the registry database.
+func (r *registry) close() error {
+	return r.db.Close()
+}
+
+// putWallet creates or updates the registry record for a wallet.
+func (r *registry) putWallet(rec *walletRecord) error {
+	return walletdb.Update(r.db, func(tx walletdb.ReadWriteTx) error {
+		wallets := tx.ReadWriteBucket(walletsBucketName)
+		b, err := wallets.CreateBucketIfNotExists([]byte(rec.ID))
+		if err != nil {
+			return err
+		}
+		if err := b.Put(netKey, []byte(rec.Net)); err != nil {
+			return err
+		}
+		if rec.Tenant != "" {
+			if err := b.Put(tenantKey, []byte(rec.Tenant)); err != nil {
+				return err
+			}
+		}
+		if err := b.Put(createdKey, uint64Bytes(uint64(rec.Created.Unix()))); err != nil {
+			return err
+		}
+		if err := putAutoload(b, rec.Autoload); err != nil {
+			return err
+		}
+		if err := putMetadata(b, rec); err != nil {
+			return err
+		}
+		return b.Put(lastUsedKey, uint64Bytes(uint64(rec.LastUsed.Unix())))
+	})
+}
+
+// setAutoload changes whether a wallet is opened when the daemon is started.
+// ErrWalletNotFound is returned if the wallet is not registered.
+func (r *registry) setAutoload(id string, autoload bool) error {
+	return walletdb.Update(r.db, func(tx walletdb.ReadWriteTx) error {
+		wallets := tx.ReadWriteBucket(walletsBucketName)
+		b := wallets.NestedReadWriteBucket([]byte(id))
+		if b == nil {
+			return ErrWalletNotFound
+		}
+		return putAutoload(b, autoload)
+	})
+}
+
+// putAutoload records the autoload flag of the wallet record held by bucket
+// b.  The flag is only stored when set.
+func putAutoload(b walletdb.ReadWriteBucket, autoload bool) error {
+	if !autoload {
+		return b.Delete(autoloadKey)
+	}
+	return b.Put(autoloadKey, []byte{1})
+}
+
+// setLastUsed updates the last used time of the wallets in the passed map,
+// keyed by wallet UUID.  Wallets missing from the registry are skipped.
+func (r *registry) setLastUsed(times map[string]time.Time) error {
+	return walletdb.Update(r.db, func(tx walletdb.ReadWriteTx) error {
+		wallets := tx.ReadWriteBucket(walletsBucketName)
+		for id, t := range times {
+			b := wallets.NestedReadWriteBucket([]byte(id))
+			if b == nil {
+				continue
+			}
+			err := b.Put(lastUsedKey, uint64Bytes(uint64(t.Unix())))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// deleteWallet removes the registry record of a wallet.
+func (r *registry) deleteWallet(id string) error {
+	return walletdb.Update(r.db, func(tx walletdb.ReadWriteTx) error {
+		wallets := tx.ReadWriteBucket(walletsBucketName)
+		err := wallets.DeleteNestedBucket([]byte(id))
+		if err == walletdb.ErrBucketNotFound {
+			return ErrWalletNotFound
+		}
+		return err
+	})
+}
+
+// wallet returns the registry record of a wallet.  ErrWalletNotFound is
+// returned if the wallet is not registered.
+func (r *registry) wallet(id string) (*walletRecord, error) {
+	var rec *walletRecord
+	err := walletdb.View(r.db, func(tx walletdb.ReadTx) error {
+		b := tx.ReadBucket(walletsBucketName).NestedReadBucket([]byte(id))
+		if b == nil {
+			return ErrWalletNotFound
+		}
+		rec = readWalletRecord(id, b)
+		return nil
+	})
+	return rec, err
+}
+
+// forEachWallet calls fn with the record of every registered wallet.
+// Iteration stops at the first error returned by fn.
+func (r *registry) forEachWallet(fn func(*walletRecord) error) error {
+	return walletdb.View(r.db, func(tx walletdb.ReadTx) error {
+		wallets := tx.ReadBucket(walletsBucketName)
+		return wallets.ForEach(func(k, v []byte) error {
+			// Only nested buckets describe wallets.
+			if v != nil {
+				return nil
+			}
+			b := wallets.NestedReadBucket(k)
+			return fn(readWalletRecord(string(k), b))
+		})
+	})
+}
+
+// readWalletRecord deserializes the wallet record held by bucket b.
+func readWalletRecord(id string, b walletdb.ReadBucket) *walletRecord {
+	rec := &walletRecord{
+		ID:       id,
+		Net:      string(b.Get(netKey)),
+		Tenant:   string(b.Get(tenantKey)),
+		Created:  bytesTime(b.Get(createdKey)),
+		LastUsed: bytesTime(b.Get(lastUsedKey)),
+		Autoload: b.Get(autoloadKey) != nil,
+	}
+	readMetadata(b, rec)
+	return rec
+}
+
+// putQuota sets the quota of a tenant.  A quota without any limit is removed.
+func (r *registry) putQuota(tenant string, q *Quota) error {
+	return walletdb.Update(r.db, func(tx walletdb.ReadWriteTx) error {
+		quotas := tx.ReadWriteBucket(quotasBucketName)
+		if *q == (Quota{}) {
+			err := quotas.DeleteNestedBucket([]byte(tenant))
+			if err == walletdb.ErrBucketNotFound {
+				return nil
+			}
+			return err
+		}
+		b, err := quotas.CreateBucketIfNotExists([]byte(tenant))
+		if err != nil {
+			return err
+		}
+		if err := b.Put(maxWalletsKey, uint64Bytes(q.MaxWallets)); err != nil {
+			return err
+		}
+		if err := b.Put(maxDiskKey, uint64Bytes(q.MaxDiskBytes)); err != nil {
+			return err
+		}
+		return b.Put(maxOpenKey, uint64Bytes(q.MaxOpenWallets))
+	})
+}
+
+// quota returns the quota of a tenant.  A quota without any limit is returned
+// for tenants without a quota.
+func (r *registry) quota(tenant string) (*Quota, error) {
+	q := new(Quota)
+	if tenant == "" {
+		return q, nil
+	}
+	err := walletdb.View(r.db, func(tx walletdb.ReadTx) error {
+		b := tx.ReadBucket(quotasBucketName).NestedReadBucket([]byte(tenant))
+		if b != nil {
+			*q = readQuota(b)
+		}
+		return nil
+	})
+	return q, err
+}
+
+// forEachQuota calls fn with every tenant with a quota and its quota.
+// Iteration stops at the first error returned by fn.
+func (r *registry) forEachQuota(fn func(string, *Quota) error) error {
+	return walletdb.View(r.db, func(tx walletdb.ReadTx) error {
+		quotas := tx.ReadBucket(quotasBucketName)
+		return quotas.ForEach(func(k, v []byte) error {
+			if v != nil {
+				return nil
+			}
+			q := readQuota(quotas.NestedReadBucket(k))
+			return fn(string(k), &q)
+		})
+	})
+}
+
+// readQuota deserializes the quota held by bucket b.
+func readQuota(b walletdb.ReadBucket) Quota {
+	return Quota{
+		MaxWallets:     bytesUint64(b.Get(maxWalletsKey)),
+		MaxDiskBytes:   bytesUint64(b.Get(maxDiskKey)),
+		MaxOpenWallets: bytesUint64(b.Get(maxOpenKey)),
+	}
+}
+
+// uint64Bytes returns the little endian serialization of v.
+func uint64Bytes(v uint64) []byte {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], v)
+	return buf[:]
+}
+
+// bytesUint64 deserializes a value written with uint64Bytes.  Zero is
+// returned for missing values.
+func bytesUint64(b []byte) uint64 {
+	if len(b) != 8 {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(b)
+}
+
+// bytesTime deserializes a unix timestamp written with uint64Bytes.  The zero
+// time is returned for missing values.
+func bytesTime(b []byte) time.Time {
+	if len(b) != 8 {
+		return time.Time{}
+	}
+	return time.Unix(int64(binary.LittleEndian.Uint64(b)), 0)
+}
+
+// fileExists reports whether the named file or directory exists.
+func fileExists(name string) bool {
+	if _, err := os.Stat(name); err != nil {
+		if os.IsNotExist(err) {
+			return false
+		}
+	}
+	return true
+}
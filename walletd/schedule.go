@@ -0,0 +1,195 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package walletd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// scheduleMacros are the shorthands accepted by ParseSchedule.
+var scheduleMacros = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+}
+
+// scheduleFields are the bounds of the fields of a schedule, in order.
+var scheduleFields = []struct {
+	name     string
+	min, max int
+}{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 6},
+}
+
+// Schedule is a cron-like schedule of times in the local time zone.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+
+	// domStar and dowStar record whether the day of month and day of week
+	// fields are unrestricted.  When both are restricted, a day matches if
+	// either field does, as in cron.
+	domStar, dowStar bool
+}
+
+// ParseSchedule parses a schedule in the five field crontab format "minute
+// hour day-of-month month day-of-week".  Every field is *, a value, a range
+// a-b, optionally with a /step, or a comma separated list of those.  Sunday is
+// day 0 of the week.  The @hourly, @daily, @weekly and @monthly shorthands are
+// also accepted.
+func ParseSchedule(spec string) (*Schedule, error) {
+	expanded := spec
+	if macro, ok := scheduleMacros[spec]; ok {
+		expanded = macro
+	}
+	fields := strings.Fields(expanded)
+	if len(fields) != len(scheduleFields) {
+		return nil, fmt.Errorf("schedule '%s' does not have %d fields",
+			spec, len(scheduleFields))
+	}
+
+	var sets [5]uint64
+	for i, f := range fields {
+		set, err := parseScheduleField(f, scheduleFields[i].min,
+			scheduleFields[i].max)
+		if err != nil {
+			return nil, fmt.Errorf("schedule '%s' has an invalid %s: "+
+				"%v", spec, scheduleFields[i].name, err)
+		}
+		sets[i] = set
+	}
+	return &Schedule{
+		minute:  sets[0],
+		hour:    sets[1],
+		dom:     sets[2],
+		month:   sets[3],
+		dow:     sets[4],
+		domStar: fields[2] == "*",
+		dowStar: fields[4] == "*",
+	}, nil
+}
+
+// parseScheduleField returns the set of values matched by a field as a bit
+// mask.
+func parseScheduleField(field string, min, max int) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, step := part, 1
+		if slash := strings.Index(part, "/"); slash != -1 {
+			rng = part[:slash]
+			var err error
+			step, err = strconv.Atoi(part[slash+1:])
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step in '%s'", part)
+			}
+		}
+
+		lo, hi := min, max
+		if rng != "*" {
+			bounds := strings.SplitN(rng, "-", 2)
+			var err error
+			lo, err = strconv.Atoi(bounds[0])
+			if err != nil {
+				return 0, fmt.Errorf("invalid value in '%s'", part)
+			}
+			hi = lo
+			if len(bounds) == 2 {
+				hi, err = strconv.Atoi(bounds[1])
+				if err != nil {
+					return 0, fmt.Errorf("invalid value in '%s'",
+						part)
+				}
+			} else if step != 1 {
+				// A single value with a step runs to the end of
+				// the field, as in cron.
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("'%s' is out of the range %d-%d",
+				part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
+
+// dayMatches returns whether the day of t is scheduled.
+func (s *Schedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	switch {
+	case s.domStar && s.dowStar:
+		return true
+	case s.domStar:
+		return dow
+	case s.dowStar:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+// Next returns the first scheduled time after t, or the zero time if the
+// schedule never matches, such as on February 30th.  Times skipped when
+// daylight saving time starts are not scheduled, and times repeated when it
+// ends are only scheduled once.
+func (s *Schedule) Next(t time.Time) time.Time {
+	after := wallClock(t)
+	t = t.Truncate(time.Minute).Add(time.Minute)
+
+	// Every schedule which matches at all does so within a few years.
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = advance(t, time.Date(t.Year(), t.Month()+1, 1, 0, 0,
+				0, 0, t.Location()))
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = advance(t, time.Date(t.Year(), t.Month(), t.Day()+1,
+				0, 0, 0, 0, t.Location()))
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = advance(t, time.Date(t.Year(), t.Month(), t.Day(),
+				t.Hour()+1, 0, 0, 0, t.Location()))
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 || !wallClock(t).After(after) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// advance returns next, the start of a later month, day or hour than t, unless
+// it is not after t.  time.Date normalizes the local times skipped when
+// daylight saving time starts to earlier times, in which case the start of the
+// hour following t is returned instead, so that Next always moves forward.
+func advance(t, next time.Time) time.Time {
+	if next.After(t) {
+		return next
+	}
+	return t.Add(time.Duration(60-t.Minute()) * time.Minute)
+}
+
+// wallClock returns the date and time of day of t, without its time zone, as
+// a time which can be compared with others.
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(),
+		t.Second(), t.Nanosecond(), time.UTC)
+}
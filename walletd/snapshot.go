@@ -0,0 +1,421 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package walletd
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcwallet/walletdb"
+	"github.com/tuxcanfly/wltd/logctx"
+)
+
+const (
+	// backupNameFormat is the format of the names of daemon backups.  It
+	// is the UTC time of the backup, and sorts in chronological order.
+	backupNameFormat = "2006-01-02T15-04-05Z"
+
+	// backupTempPrefix prefixes the names of backups which are being
+	// written.  They are renamed once complete.
+	backupTempPrefix = ".inprogress-"
+
+	// backupManifestName is the name of the manifest of a backup.  It
+	// holds the SHA-256 of every other file of the backup in the format
+	// of sha256sum, so it may also be checked with sha256sum -c.
+	backupManifestName = "MANIFEST.sha256"
+)
+
+var (
+	// ErrNoBackupDir is returned by backup operations when no backup
+	// directory is configured.
+	ErrNoBackupDir = errors.New("no backup directory configured")
+
+	// ErrBackupNotFound is returned when verifying a backup which does
+	// not exist.
+	ErrBackupNotFound = errors.New("backup not found")
+)
+
+// BackupInfo describes a backup of the daemon in the backup directory.
+type BackupInfo struct {
+	Name  string
+	Time  time.Time
+	Files int
+	Size  uint64
+}
+
+// backupScheduler creates backups of the daemon according to the backup
+// schedule and removes the oldest backups beyond the retention.  It must be
+// run as a goroutine.
+func (w *WalletDaemon) backupScheduler(quit <-chan struct{}) {
+	defer w.wg.Done()
+
+	for {
+		next := w.backupSchedule.Next(time.Now())
+		if next.IsZero() {
+			log.Warnf("Backup schedule never matches, scheduled " +
+				"backups are disabled")
+			return
+		}
+		timer := time.NewTimer(next.Sub(time.Now()))
+		select {
+		case <-timer.C:
+			info, err := w.BackupDaemon()
+			if err != nil {
+				log.Errorf("Scheduled backup failed: %v", err)
+				continue
+			}
+			log.Infof("Created backup %s of %d files", info.Name,
+				info.Files)
+			if err := w.pruneBackups(); err != nil {
+				log.Errorf("Cannot remove old backups: %v", err)
+			}
+		case <-quit:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// BackupDaemon creates a backup of the registry database and of every wallet
+// in a new directory of the backup directory named after the current time.
+// The databases are copied in read transactions, so the backup is consistent
+// even while wallets are in use.
+func (w *WalletDaemon) BackupDaemon() (*BackupInfo, error) {
+	if w.backupDir == "" {
+		return nil, ErrNoBackupDir
+	}
+	if !w.storage.persistent() {
+		return nil, ErrNotPersistent
+	}
+
+	now := time.Now().UTC()
+	info := &BackupInfo{Name: now.Format(backupNameFormat), Time: now}
+	dir := filepath.Join(w.backupDir, info.Name)
+	tmpDir := filepath.Join(w.backupDir, backupTempPrefix+info.Name)
+	if err := os.MkdirAll(tmpDir, 0700); err != nil {
+		return nil, err
+	}
+
+	manifest, err := w.writeBackupFiles(tmpDir, info)
+	if err == nil {
+		err = writeFileSync(filepath.Join(tmpDir, backupManifestName),
+			strings.NewReader(manifest))
+	}
+	if err == nil {
+		err = os.Rename(tmpDir, dir)
+	}
+	if err != nil {
+		os.RemoveAll(tmpDir)
+		return nil, err
+	}
+	return info, nil
+}
+
+// writeBackupFiles copies the registry and every wallet to dir and returns the
+// manifest of the copied files.
+func (w *WalletDaemon) writeBackupFiles(dir string, info *BackupInfo) (string, error) {
+	var manifest []string
+	add := func(name string, copy func(io.Writer) error) error {
+		hash, size, err := copyFileSync(filepath.Join(dir, name), copy)
+		if err != nil {
+			return err
+		}
+		// Manifest paths use forward slashes on every platform.
+		manifest = append(manifest, hash+"  "+filepath.ToSlash(name))
+		info.Files++
+		info.Size += size
+		return nil
+	}
+
+	// The registry is copied first, so wallets created during the backup
+	// are at worst copied without being registered.
+	if err := add(registryDbName, w.registry.db.Copy); err != nil {
+		return "", err
+	}
+
+	var recs []*walletRecord
+	err := w.registry.forEachWallet(func(rec *walletRecord) error {
+		recs = append(recs, rec)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	for _, rec := range recs {
+		chainParams, err := ParamsForNet(rec.Net)
+		if err != nil {
+			return "", err
+		}
+		db, release, err := w.walletDB(rec)
+		if err == walletdb.ErrDbDoesNotExist {
+			// The wallet was removed since the registry was read.
+			logctx.WalletLog(log, rec.ID).Warnf("Skipping backup "+
+				"of missing wallet %s", rec.ID)
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("cannot open wallet %s: %v", rec.ID,
+				err)
+		}
+		name := relDBPath(rec.ID, chainParams)
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0700); err != nil {
+			release()
+			return "", err
+		}
+		err = add(name, db.Copy)
+		release()
+		if err != nil {
+			return "", fmt.Errorf("cannot copy wallet %s: %v", rec.ID,
+				err)
+		}
+	}
+
+	return strings.Join(manifest, "\n") + "\n", nil
+}
+
+// copyFileSync creates the named file with the contents written by copy and
+// syncs it to disk.  It returns the hex encoded SHA-256 and the size of the
+// contents.
+func copyFileSync(name string, copy func(io.Writer) error) (string, uint64, error) {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return "", 0, err
+	}
+	h := sha256.New()
+	cw := &countingWriter{w: io.MultiWriter(f, h)}
+	err = copy(cw)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), cw.n, nil
+}
+
+// countingWriter counts the bytes written to the underlying writer.
+type countingWriter struct {
+	w io.Writer
+	n uint64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += uint64(n)
+	return n, err
+}
+
+// ListBackups returns every complete backup in the backup directory, oldest
+// first.
+func (w *WalletDaemon) ListBackups() ([]*BackupInfo, error) {
+	if w.backupDir == "" {
+		return nil, ErrNoBackupDir
+	}
+	names, err := w.backupNames()
+	if err != nil {
+		return nil, err
+	}
+
+	backups := make([]*BackupInfo, 0, len(names))
+	for _, name := range names {
+		t, _ := time.Parse(backupNameFormat, name)
+		info := &BackupInfo{Name: name, Time: t}
+		backups = append(backups, info)
+
+		// Backups with an unreadable manifest are listed without
+		// files and reported by VerifyBackup.
+		entries, err := readManifest(filepath.Join(w.backupDir, name))
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			fi, err := os.Stat(filepath.Join(w.backupDir, name,
+				filepath.FromSlash(e.name)))
+			if err == nil {
+				info.Size += uint64(fi.Size())
+			}
+		}
+		info.Files = len(entries)
+	}
+	return backups, nil
+}
+
+// backupNames returns the names of the complete backups in the backup
+// directory, oldest first.
+func (w *WalletDaemon) backupNames() ([]string, error) {
+	d, err := os.Open(w.backupDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	entries, err := d.Readdirnames(-1)
+	d.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, name := range entries {
+		if _, err := time.Parse(backupNameFormat, name); err != nil {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// VerifyBackup checks every file of a backup against its manifest.  It returns
+// a description of every missing, modified or unlisted file, and no problems
+// when the backup is intact.
+func (w *WalletDaemon) VerifyBackup(name string) ([]string, error) {
+	if w.backupDir == "" {
+		return nil, ErrNoBackupDir
+	}
+	if _, err := time.Parse(backupNameFormat, name); err != nil {
+		return nil, ErrBackupNotFound
+	}
+	dir := filepath.Join(w.backupDir, name)
+	if !fileExists(dir) {
+		return nil, ErrBackupNotFound
+	}
+	entries, err := readManifest(dir)
+	if err != nil {
+		return []string{err.Error()}, nil
+	}
+
+	var problems []string
+	listed := make(map[string]struct{}, len(entries))
+	for _, e := range entries {
+		listed[e.name] = struct{}{}
+		hash, err := hashFile(filepath.Join(dir, filepath.FromSlash(e.name)))
+		switch {
+		case os.IsNotExist(err):
+			problems = append(problems, e.name+": missing")
+		case err != nil:
+			problems = append(problems, fmt.Sprintf("%s: %v", e.name,
+				err))
+		case hash != e.hash:
+			problems = append(problems, e.name+": checksum mismatch")
+		}
+	}
+
+	err = filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !fi.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if _, ok := listed[rel]; !ok && rel != backupManifestName {
+			problems = append(problems, rel+": not in manifest")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return problems, nil
+}
+
+// manifestEntry is a file listed in the manifest of a backup.
+type manifestEntry struct {
+	hash string
+	name string
+}
+
+// readManifest reads the manifest of the backup in dir.
+func readManifest(dir string) ([]manifestEntry, error) {
+	f, err := os.Open(filepath.Join(dir, backupManifestName))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []manifestEntry
+	s := bufio.NewScanner(f)
+	for line := 1; s.Scan(); line++ {
+		fields := strings.SplitN(s.Text(), "  ", 2)
+		if len(fields) != 2 || len(fields[0]) != sha256.Size*2 {
+			return nil, fmt.Errorf("%s: line %d is invalid",
+				backupManifestName, line)
+		}
+		entries = append(entries, manifestEntry{fields[0], fields[1]})
+	}
+	return entries, s.Err()
+}
+
+// hashFile returns the hex encoded SHA-256 of the named file.
+func hashFile(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// pruneBackups removes the oldest backups beyond the backup retention, along
+// with backups left incomplete by a crash.
+func (w *WalletDaemon) pruneBackups() error {
+	d, err := os.Open(w.backupDir)
+	if err != nil {
+		return err
+	}
+	entries, err := d.Readdirnames(-1)
+	d.Close()
+	if err != nil {
+		return err
+	}
+	for _, name := range entries {
+		if strings.HasPrefix(name, backupTempPrefix) {
+			if err := os.RemoveAll(filepath.Join(w.backupDir, name)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if w.backupRetention <= 0 {
+		return nil
+	}
+	names, err := w.backupNames()
+	if err != nil {
+		return err
+	}
+	if len(names) <= w.backupRetention {
+		return nil
+	}
+	for _, name := range names[:len(names)-w.backupRetention] {
+		log.Infof("Removing backup %s", name)
+		if err := os.RemoveAll(filepath.Join(w.backupDir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
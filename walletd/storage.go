@@ -0,0 +1,265 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package walletd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcwallet/walletdb"
+	_ "github.com/btcsuite/btcwallet/walletdb/bdb"
+	"github.com/tuxcanfly/wltd/walletd/memdb"
+)
+
+const (
+	// DefaultDBDriver is the walletdb driver used when none is
+	// configured.
+	DefaultDBDriver = "bdb"
+
+	// walletsDirName is the name of the directory of the data directory
+	// holding the wallets.
+	walletsDirName = "wallets"
+
+	// shardLen is the number of leading UUID characters naming the shard
+	// directory of a wallet, so that no directory holds more than a few
+	// thousand wallets.
+	shardLen = 2
+)
+
+// ErrNotPersistent is returned by operations on the files of wallets, such as
+// backups, when the databases are not stored in files.
+var ErrNotPersistent = errors.New("operation requires a file backed " +
+	"database driver")
+
+// DBDrivers returns the names of the walletdb drivers which may be configured.
+func DBDrivers() []string {
+	return []string{"bdb", memdb.DbType}
+}
+
+// storage locates the databases of the daemon and opens them with the
+// configured walletdb driver.  Wallets are stored in the directory
+// wallets/<shard>/<uuid>/<net> of the data directory, where the shard is the
+// start of the UUID.
+type storage struct {
+	dataDir string
+	driver  string
+
+	// unsharded is set when the wallets may still be stored directly in
+	// the wallets directory, as they were before shard directories were
+	// introduced.  Only checks read such data directories, since the
+	// daemon moves the wallets on startup.
+	unsharded bool
+}
+
+// persistent returns whether the databases are stored in files.
+func (s *storage) persistent() bool {
+	return s.driver != memdb.DbType
+}
+
+// shardName returns the name of the shard directory of a wallet.
+func shardName(id string) string {
+	if len(id) < shardLen {
+		return strings.ToLower(id)
+	}
+	return strings.ToLower(id[:shardLen])
+}
+
+// walletsDir returns the directory holding every wallet.
+func (s *storage) walletsDir() string {
+	return filepath.Join(s.dataDir, walletsDirName)
+}
+
+// walletDir returns the directory holding every file of a wallet.
+func (s *storage) walletDir(id string) string {
+	if s.unsharded {
+		dir := filepath.Join(s.walletsDir(), id)
+		if fileExists(dir) {
+			return dir
+		}
+	}
+	return filepath.Join(s.walletsDir(), shardName(id), id)
+}
+
+// relDBPath returns the path of the database of a wallet relative to the data
+// directory.
+func relDBPath(id string, chainParams *chaincfg.Params) string {
+	return filepath.Join(networkDir(filepath.Join(walletsDirName,
+		shardName(id), id), chainParams), walletDbName)
+}
+
+// dbPath returns the path of the database of a wallet.
+func (s *storage) dbPath(id string, chainParams *chaincfg.Params) string {
+	return filepath.Join(networkDir(s.walletDir(id), chainParams),
+		walletDbName)
+}
+
+// createDB creates the database of a new wallet.
+func (s *storage) createDB(id string, chainParams *chaincfg.Params) (walletdb.DB, error) {
+	path := s.dbPath(id, chainParams)
+	if s.persistent() {
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return nil, err
+		}
+	}
+	return walletdb.Create(s.driver, path)
+}
+
+// openDB opens the database of a wallet.  walletdb.ErrDbDoesNotExist is
+// returned if the wallet has no database.
+func (s *storage) openDB(id string, chainParams *chaincfg.Params) (walletdb.DB, error) {
+	return walletdb.Open(s.driver, s.dbPath(id, chainParams))
+}
+
+// openReadOnlyDB opens the database of a wallet for reading only, as
+// openReadOnly does.
+func (s *storage) openReadOnlyDB(id string, chainParams *chaincfg.Params,
+	timeout time.Duration) (walletdb.DB, error) {
+
+	return s.openReadOnly(s.dbPath(id, chainParams), timeout)
+}
+
+// openReadOnly opens the database at path for reading only, waiting at most
+// timeout for another process to close it.  Databases which are not stored in
+// files are opened with the driver, since no other process can use them.
+func (s *storage) openReadOnly(path string, timeout time.Duration) (walletdb.DB, error) {
+	if !s.persistent() {
+		return walletdb.Open(s.driver, path)
+	}
+	return openReadOnlyDB(path, timeout)
+}
+
+// walletExists returns whether the wallet has been stored.
+func (s *storage) walletExists(id string, chainParams *chaincfg.Params) bool {
+	if !s.persistent() {
+		return memdb.Exists(s.dbPath(id, chainParams))
+	}
+	return fileExists(s.walletDir(id))
+}
+
+// removeWallet removes every file of a wallet.  The wallet must not be open.
+func (s *storage) removeWallet(id string, chainParams *chaincfg.Params) error {
+	if !s.persistent() {
+		memdb.Remove(s.dbPath(id, chainParams))
+		return nil
+	}
+	return os.RemoveAll(s.walletDir(id))
+}
+
+// walletIDs returns the UUIDs of the wallets found in the wallets directory.
+// Databases which are not stored in files cannot be listed, so none are
+// returned for them.
+func (s *storage) walletIDs() ([]string, error) {
+	if !s.persistent() {
+		return nil, nil
+	}
+	shards, err := readDirNames(s.walletsDir())
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, shard := range shards {
+		// Shard names are shorter than any wallet UUID.
+		if s.unsharded && len(shard) > shardLen {
+			ids = append(ids, shard)
+			continue
+		}
+		names, err := readDirNames(filepath.Join(s.walletsDir(), shard))
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, names...)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// shardWalletDirs moves the wallet directories found directly in the wallets
+// directory, where they were stored before shard directories were introduced,
+// into their shard directories.  It may be run again after being interrupted.
+func (s *storage) shardWalletDirs() error {
+	if !s.persistent() {
+		return nil
+	}
+	names, err := readDirNames(s.walletsDir())
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		// Shard names are shorter than any wallet UUID.
+		if len(name) <= shardLen {
+			continue
+		}
+		dir := s.walletDir(name)
+		if fileExists(dir) {
+			return fmt.Errorf("cannot move wallet %s: %s already "+
+				"exists", name, dir)
+		}
+		if err := os.MkdirAll(filepath.Dir(dir), 0700); err != nil {
+			return err
+		}
+		err := os.Rename(filepath.Join(s.walletsDir(), name), dir)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readDirNames returns the names of the directories in dir.  A missing
+// directory has none.
+func readDirNames(dir string) ([]string, error) {
+	d, err := os.Open(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	fis, err := d.Readdir(-1)
+	d.Close()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, fi := range fis {
+		if fi.IsDir() {
+			names = append(names, fi.Name())
+		}
+	}
+	return names, nil
+}
+
+// openRegistryDB opens the registry database, returning
+// walletdb.ErrDbDoesNotExist if it has not been created.
+func (s *storage) openRegistryDB() (walletdb.DB, error) {
+	return walletdb.Open(s.driver, s.registryPath())
+}
+
+// registryPath returns the path of the registry database.
+func (s *storage) registryPath() string {
+	return filepath.Join(s.dataDir, registryDbName)
+}
+
+// createRegistryDB creates the registry database.
+func (s *storage) createRegistryDB() (walletdb.DB, error) {
+	return walletdb.Create(s.driver, filepath.Join(s.dataDir, registryDbName))
+}
+
+// removeRegistryDB removes the registry database after it failed to be
+// initialized.
+func (s *storage) removeRegistryDB() {
+	path := filepath.Join(s.dataDir, registryDbName)
+	if !s.persistent() {
+		memdb.Remove(path)
+		return
+	}
+	os.Remove(path)
+}
@@ -5,33 +5,275 @@
 package walletd
 
 import (
+	"errors"
+	"fmt"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcwallet/chain"
+	"github.com/btcsuite/btcwallet/waddrmgr"
 	"github.com/btcsuite/btcwallet/wallet"
-	_ "github.com/btcsuite/btcwallet/walletdb/bdb"
+	"github.com/btcsuite/btcwallet/walletdb"
 	"github.com/google/uuid"
+	"github.com/tuxcanfly/wltd/logctx"
 )
 
-type WalletDaemon struct {
-	dbDir       string
+// ErrShuttingDown is returned by operations which are attempted after the
+// wallet daemon has been asked to stop.
+var ErrShuttingDown = errors.New("wallet daemon is shutting down")
+
+// errNoPrivatePassphrase is returned to the wallet package when opening a
+// wallet requires prompting for a passphrase, which the daemon cannot do.
+var errNoPrivatePassphrase = errors.New("wallet requires a private " +
+	"passphrase to be opened")
+
+// openCallbacks are the callbacks passed when opening wallets.  Wallets are
+// only upgraded when opened if the upgrade does not need any secret.
+var openCallbacks = &waddrmgr.OpenCallbacks{
+	ObtainSeed: func() ([]byte, error) {
+		return nil, errNoPrivatePassphrase
+	},
+	ObtainPrivatePass: func() ([]byte, error) {
+		return nil, errNoPrivatePassphrase
+	},
+}
+
+// Config holds the settings used to create a WalletDaemon.
+type Config struct {
+	// DataDir is the directory holding the registry database and the
+	// wallets directory.
+	DataDir string
+
+	// DBDriver is the walletdb driver of the registry and wallet
+	// databases.  DefaultDBDriver is used when it is empty.
+	DBDriver string
+
+	// ChainParams are the parameters of the default network, which is
+	// used for wallets created without specifying a network.
+	ChainParams *chaincfg.Params
+
+	// ExtraNets are the parameters of the networks served in addition to
+	// the default network.
+	ExtraNets []*chaincfg.Params
+
+	// IdleTimeout is the duration after which an open wallet which has not
+	// been used is closed.  Zero disables closing idle wallets.
+	IdleTimeout time.Duration
+
+	// ChainClients are the optional chain backends, keyed by network name,
+	// whose notifications are dispatched to the open wallets of that
+	// network.  Open wallets sync with the backend of their network, which
+	// must be started before the daemon and stopped after it.
+	ChainClients map[string]chain.Interface
+
+	// BackupKey is the private key wallet backups are encrypted to and
+	// restored with.  Backups are refused when it is nil.
+	BackupKey *[32]byte
+
+	// BackupDir is the directory holding backups of the daemon.  Daemon
+	// backups are disabled when it is empty.
+	BackupDir string
+
+	// BackupSchedule is the schedule of daemon backups, which are only
+	// created on demand when it is nil.
+	BackupSchedule *Schedule
+
+	// BackupRetention is the number of daemon backups kept by scheduled
+	// backups.  Zero keeps every backup.
+	BackupRetention int
+
+	// NoInitialLoad disables opening the wallets marked for autoloading
+	// when the daemon is started.
+	NoInitialLoad bool
+
+	// CreateWorkers is the number of wallets created concurrently by
+	// CreateWallets, and of autoload wallets opened concurrently on
+	// startup.  The number of CPUs is used when it is zero.
+	CreateWorkers int
+
+	// PubPassphrase is the public passphrase wallets are created and
+	// opened with.  wallet.InsecurePubPassphrase is used when it is nil.
+	PubPassphrase []byte
+}
+
+// loadedWallet is a wallet opened by the daemon.
+type loadedWallet struct {
+	wallet *wallet.Wallet
+	db     walletdb.DB
+
+	// chainParams are the parameters of the network of the wallet.
 	chainParams *chaincfg.Params
-	wg          sync.WaitGroup
+
+	// tenant owns the wallet.
+	tenant string
+
+	// ntfns receives the chain notifications dispatched to the wallet,
+	// which are consumed by the wallet once it is synchronized with the
+	// chain backend of its network.  The dispatcher sends them to
+	// ntfnQueue, from where they are queued until the wallet consumes
+	// them.  ntfnQuit stops the queueing, which closes ntfns and then
+	// ntfnDone.
+	ntfns     chan interface{}
+	ntfnQueue chan interface{}
+	ntfnQuit  chan struct{}
+	ntfnDone  chan struct{}
+
+	// outpoints indexes the outputs paying the wallet, so that the
+	// transactions spending them are dispatched to it.  It is only used
+	// by the chain notification dispatcher of the network once the wallet
+	// is open.
+	outpoints map[wire.OutPoint]struct{}
+
+	lastUsed time.Time
+
+	// refs is the number of operations, such as backups, using the wallet
+	// database.  The wallet is not closed while it is in use.
+	refs int
+
+	// sendMu serializes sending transactions, which unlock the wallet.
+	sendMu sync.Mutex
+}
+
+type WalletDaemon struct {
+	dbDir        string
+	storage      *storage
+	chainParams  *chaincfg.Params
+	nets         map[string]*chaincfg.Params
+	chainClients map[string]chain.Interface
+	rescans      map[string]*rescanTracker
+	registry     *registry
+	audit        *AuditLog
+	backupKey    *[32]byte
+
+	backupDir       string
+	backupSchedule  *Schedule
+	backupRetention int
+
+	noInitialLoad bool
+
+	// missingWallets holds the registered wallets whose files were missing
+	// in the last registry maintenance pass.  It is only used by the
+	// registry maintainer.
+	missingWallets map[string]struct{}
+
+	// pubPassphrase is the public passphrase of every wallet.
+	pubPassphrase []byte
+
+	// createPool bounds the number of wallets created concurrently by
+	// CreateWallets calls.
+	createPool *createPool
+
+	// events publishes the changes of wallets to subscribers.
+	events *eventPublisher
+
+	wg sync.WaitGroup
+
+	// wallets holds every wallet opened by the daemon, keyed by wallet
+	// UUID.  idleTimeout is protected by the same mutex since it may be
+	// changed while the daemon is running.
+	wallets     map[string]*loadedWallet
+	idleTimeout time.Duration
+	walletsMu   sync.Mutex
+
+	// reserved counts the wallets of every tenant which are being created
+	// or opened, so that concurrent operations cannot exceed its quota.
+	reserved map[string]*quotaReservation
+	quotaMu  sync.Mutex
+
+	// usages counts the registered wallets of every tenant and the size
+	// of their files, which is the size last measured of every wallet in
+	// walletSizes.  Both are loaded on first use and protected by
+	// quotaMu.
+	usages      map[string]*Usage
+	walletSizes map[string]uint64
+
+	// walletLocks serializes opening, deleting and reading the database of
+	// each wallet, keyed by wallet UUID.  Locks are removed once unused.
+	walletLocks   map[string]*walletLock
+	walletLocksMu sync.Mutex
 
 	started bool
 	quit    chan struct{}
 	quitMu  sync.Mutex
 }
 
-func NewWalletDaemon(dbDir string, activeNet *chaincfg.Params) *WalletDaemon {
-	return &WalletDaemon{
-		dbDir:       dbDir,
-		chainParams: activeNet,
+// NewWalletDaemon creates a wallet daemon using the passed configuration and
+// opens its registry database and audit log, creating them if necessary.  The daemon's
+// background goroutines are not started until Start is called.
+func NewWalletDaemon(cfg *Config) (*WalletDaemon, error) {
+	if err := checkCreateDir(cfg.DataDir); err != nil {
+		return nil, err
+	}
+	driver := cfg.DBDriver
+	if driver == "" {
+		driver = DefaultDBDriver
+	}
+	store := &storage{dataDir: cfg.DataDir, driver: driver}
+	reg, err := openRegistry(store)
+	if err != nil {
+		return nil, err
+	}
+	audit, err := OpenAuditLog(filepath.Join(cfg.DataDir, AuditLogName))
+	if err != nil {
+		reg.close()
+		return nil, fmt.Errorf("cannot open audit log: %v", err)
+	}
+
+	pubPassphrase := cfg.PubPassphrase
+	if pubPassphrase == nil {
+		pubPassphrase = []byte(wallet.InsecurePubPassphrase)
+	}
+
+	nets := map[string]*chaincfg.Params{
+		cfg.ChainParams.Name: cfg.ChainParams,
+	}
+	for _, params := range cfg.ExtraNets {
+		nets[params.Name] = params
+	}
+	rescans := make(map[string]*rescanTracker, len(cfg.ChainClients))
+	for net := range cfg.ChainClients {
+		rescans[net] = newRescanTracker()
 	}
+
+	return &WalletDaemon{
+		dbDir:           cfg.DataDir,
+		storage:         store,
+		chainParams:     cfg.ChainParams,
+		nets:            nets,
+		idleTimeout:     cfg.IdleTimeout,
+		chainClients:    cfg.ChainClients,
+		rescans:         rescans,
+		registry:        reg,
+		audit:           audit,
+		backupKey:       cfg.BackupKey,
+		backupDir:       cfg.BackupDir,
+		backupSchedule:  cfg.BackupSchedule,
+		backupRetention: cfg.BackupRetention,
+		noInitialLoad:   cfg.NoInitialLoad,
+		pubPassphrase:   pubPassphrase,
+		createPool:      newCreatePool(createWorkers(cfg.CreateWorkers)),
+		events:          newEventPublisher(),
+		wallets:         make(map[string]*loadedWallet),
+		reserved:        make(map[string]*quotaReservation),
+		walletLocks:     make(map[string]*walletLock),
+		quit:            make(chan struct{}),
+	}, nil
 }
 
-func (w *WalletDaemon) Start() {
+// Start migrates the registry to the latest schema version, starts the
+// goroutines necessary to manage the daemon's wallets and begins opening the
+// wallets marked for autoloading in the background, unless initial loading is
+// disabled.  A stopped daemon may be started again once it has been stopped.
+// Nothing is started if the registry cannot be migrated.
+func (w *WalletDaemon) Start() error {
+	if err := w.migrate(); err != nil {
+		return err
+	}
+
 	w.quitMu.Lock()
 	select {
 	case <-w.quit:
@@ -42,19 +284,392 @@ func (w *WalletDaemon) Start() {
 		// Ignore when the walletd is still running.
 		if w.started {
 			w.quitMu.Unlock()
-			return
+			return nil
 		}
 		w.started = true
 	}
+	quit := w.quit
 	w.quitMu.Unlock()
+
+	// The goroutines are passed the quit channel of this run rather than
+	// reading it with quitChan, since a restart holds quitMu while waiting
+	// for them to exit.
+	w.wg.Add(2 + len(w.chainClients))
+	go w.registryMaintainer(quit)
+	go w.idleWalletReaper(quit)
+	for net, client := range w.chainClients {
+		go w.chainNotificationDispatcher(net, client, quit)
+	}
+	if w.backupDir != "" && w.backupSchedule != nil {
+		w.wg.Add(1)
+		go w.backupScheduler(quit)
+	}
+
+	if w.noInitialLoad {
+		log.Info("Initial loading disabled, not opening autoload wallets")
+		return nil
+	}
+	w.wg.Add(1)
+	go w.autoloadWallets(quit)
+	return nil
+}
+
+// autoloadWallets opens every wallet marked for autoloading, so that it
+// receives chain notifications from startup.  Wallets are opened concurrently
+// by as many workers as wallets are created by CreateWallets, and no more are
+// opened once quit is closed.  Wallets which cannot be opened are logged and
+// skipped.
+//
+// This must be run as a goroutine.
+func (w *WalletDaemon) autoloadWallets(quit <-chan struct{}) {
+	defer w.wg.Done()
+
+	var recs []*walletRecord
+	err := w.registry.forEachWallet(func(rec *walletRecord) error {
+		if rec.Autoload {
+			recs = append(recs, rec)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Errorf("Cannot read registry: %v", err)
+		return
+	}
+	if len(recs) == 0 {
+		return
+	}
+
+	workers := w.createPool.workers
+	if workers > len(recs) {
+		workers = len(recs)
+	}
+	jobs := make(chan *walletRecord)
+	var opened uint32
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for rec := range jobs {
+				// The opens are part of this goroutine, which
+				// shutdown already waits for, so they are not
+				// registered with beginOperation.
+				err := w.openWallet(rec.Tenant, rec.ID)
+				if err != nil {
+					logctx.WalletLog(log, rec.ID).Errorf(
+						"Cannot autoload wallet %s: %v",
+						rec.ID, err)
+					continue
+				}
+				atomic.AddUint32(&opened, 1)
+			}
+		}()
+	}
+feed:
+	for _, rec := range recs {
+		select {
+		case jobs <- rec:
+		case <-quit:
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	log.Infof("Opened %d of %d autoload wallets", opened, len(recs))
+}
+
+// beginOperation registers a wallet operation with the daemon so that
+// shutdown waits for it to complete.  ErrShuttingDown is returned, and the
+// operation must not be started, once the daemon has been stopped.  On
+// success, the returned function must be called when the operation finishes.
+func (w *WalletDaemon) beginOperation() (func(), error) {
+	w.quitMu.Lock()
+	defer w.quitMu.Unlock()
+
+	select {
+	case <-w.quit:
+		return nil, ErrShuttingDown
+	default:
+	}
+	w.wg.Add(1)
+	return w.wg.Done, nil
+}
+
+// CreateWallet creates a new wallet of the tenant on the named network and
+// returns its UUID.  The wallet is left open.  The default network is used
+// when net is empty, and ErrUnknownNetwork is returned if the network is not
+// served by the daemon.  A QuotaExceededError is returned if the tenant may
+// not create or open another wallet.  The wallet is created with the public
+// passphrase of the daemon.
+func (w *WalletDaemon) CreateWallet(tenant, net string, privPassphrase, seed []byte) (string, error) {
+	return w.createWallet(tenant, net, privPassphrase, seed, true)
 }
 
-func (w *WalletDaemon) CreateWallet(pubPassphrase, privPassphrase, seed []byte) (string, error) {
+// createWallet creates a new wallet as CreateWallet does.  The wallet is only
+// left open when keepOpen is set, and is otherwise closed once registered.
+func (w *WalletDaemon) createWallet(tenant, net string, privPassphrase, seed []byte, keepOpen bool) (string, error) {
+	chainParams := w.chainParams
+	if net != "" {
+		var ok bool
+		chainParams, ok = w.nets[net]
+		if !ok {
+			return "", ErrUnknownNetwork
+		}
+	}
+
+	done, err := w.beginOperation()
+	if err != nil {
+		return "", err
+	}
+	defer done()
+
+	release, err := w.reserveQuota(tenant, true, keepOpen)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
 	id := uuid.New().String()
-	loader := wallet.NewLoader(w.chainParams)
-	wltDir := networkDir(filepath.Join(w.dbDir, "wallets", id), w.chainParams)
-	_, err := loader.CreateNewWallet(wltDir, pubPassphrase, privPassphrase, seed)
-	return id, err
+	db, err := w.storage.createDB(id, chainParams)
+	if err != nil {
+		return "", err
+	}
+	err = wallet.Create(db, w.pubPassphrase, privPassphrase, seed, chainParams)
+	if err != nil {
+		db.Close()
+		w.storage.removeWallet(id, chainParams)
+		return "", err
+	}
+	var lw *loadedWallet
+	if keepOpen {
+		lw, err = startWallet(db, w.pubPassphrase, chainParams)
+		if err != nil {
+			db.Close()
+			w.storage.removeWallet(id, chainParams)
+			return "", err
+		}
+		lw.tenant = tenant
+		w.synchronize(lw)
+	} else if err := db.Close(); err != nil {
+		w.storage.removeWallet(id, chainParams)
+		return "", err
+	}
+
+	now := time.Now()
+	err = w.registry.putWallet(&walletRecord{
+		ID:       id,
+		Net:      chainParams.Name,
+		Tenant:   tenant,
+		Created:  now,
+		LastUsed: now,
+	})
+	if err != nil {
+		if lw != nil {
+			lw.close()
+		}
+		w.storage.removeWallet(id, chainParams)
+		return "", err
+	}
+
+	if lw != nil {
+		w.walletsMu.Lock()
+		w.wallets[id] = lw
+		w.walletsMu.Unlock()
+	}
+	w.updateWalletUsage(tenant, id, true)
+
+	w.publishWalletEvent(EventWalletCreated, tenant, id, chainParams.Name)
+	return id, nil
+}
+
+// OpenWallet opens a wallet of the tenant which is not open yet, such as one
+// closed after being idle, and marks it used.  ErrWalletNotFound is returned if
+// the wallet is not registered to the tenant, and a QuotaExceededError if the
+// tenant may not open another wallet.
+func (w *WalletDaemon) OpenWallet(tenant, id string) error {
+	done, err := w.beginOperation()
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	return w.openWallet(tenant, id)
+}
+
+// openWallet opens a wallet of the tenant as OpenWallet does.  The caller must
+// keep the daemon from shutting down until it returns.
+func (w *WalletDaemon) openWallet(tenant, id string) error {
+	rec, err := w.tenantWallet(tenant, id)
+	if err != nil {
+		return err
+	}
+	chainParams, ok := w.nets[rec.Net]
+	if !ok {
+		return ErrUnknownNetwork
+	}
+
+	// Opens of a wallet are serialized since its database cannot be
+	// opened twice.
+	defer w.lockWallet(id)()
+
+	if w.touchWallet(id) {
+		return nil
+	}
+
+	release, err := w.reserveQuota(tenant, false, true)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	db, err := w.storage.openDB(id, chainParams)
+	if err != nil {
+		return err
+	}
+	lw, err := startWallet(db, w.pubPassphrase, chainParams)
+	if err != nil {
+		db.Close()
+		return err
+	}
+	lw.tenant = tenant
+	w.synchronize(lw)
+
+	w.walletsMu.Lock()
+	w.wallets[id] = lw
+	w.walletsMu.Unlock()
+
+	logctx.WalletLog(log, id).Debugf("Opened wallet %s", id)
+	w.publishWalletEvent(EventWalletOpened, tenant, id, chainParams.Name)
+	return nil
+}
+
+// startWallet opens and starts the wallet stored in db.  The database is
+// owned by the returned wallet once it has been started.
+func startWallet(db walletdb.DB, pubPassphrase []byte, chainParams *chaincfg.Params) (*loadedWallet, error) {
+	wlt, err := wallet.Open(db, pubPassphrase, openCallbacks, chainParams)
+	if err != nil {
+		return nil, err
+	}
+	wlt.Start()
+	lw := &loadedWallet{
+		wallet:      wlt,
+		db:          db,
+		chainParams: chainParams,
+		lastUsed:    time.Now(),
+	}
+	if err := lw.indexOutpoints(); err != nil {
+		wlt.Stop()
+		wlt.WaitForShutdown()
+		return nil, err
+	}
+	lw.startNotifications()
+	return lw, nil
+}
+
+// close stops the wallet and closes its database.
+func (lw *loadedWallet) close() error {
+	lw.wallet.Stop()
+	lw.stopNotifications()
+	lw.wallet.WaitForShutdown()
+	return lw.db.Close()
+}
+
+// SetAutoload changes whether a wallet of the tenant is opened when the daemon
+// is started.  ErrWalletNotFound is returned if the wallet is not registered to
+// the tenant.  The wallet is not opened or closed by the change.
+func (w *WalletDaemon) SetAutoload(tenant, id string, autoload bool) error {
+	if _, err := w.tenantWallet(tenant, id); err != nil {
+		return err
+	}
+	return w.registry.setAutoload(id, autoload)
+}
+
+// walletLock is the lock of a wallet, shared by the refs goroutines holding or
+// waiting for it.
+type walletLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// lockWallet locks the wallet with the passed UUID, keeping it from being
+// opened or deleted, and its database from being read, by another goroutine.
+// The returned function unlocks the wallet.  Other wallets are not affected.
+func (w *WalletDaemon) lockWallet(id string) func() {
+	w.walletLocksMu.Lock()
+	l, ok := w.walletLocks[id]
+	if !ok {
+		l = &walletLock{}
+		w.walletLocks[id] = l
+	}
+	l.refs++
+	w.walletLocksMu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+		w.walletLocksMu.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(w.walletLocks, id)
+		}
+		w.walletLocksMu.Unlock()
+	}
+}
+
+// touchWallet marks an open wallet used.  It returns false if the wallet is
+// not open.
+func (w *WalletDaemon) touchWallet(id string) bool {
+	w.walletsMu.Lock()
+	defer w.walletsMu.Unlock()
+
+	lw, ok := w.wallets[id]
+	if ok {
+		lw.lastUsed = time.Now()
+	}
+	return ok
+}
+
+// UnloadWallets stops every wallet opened by the daemon and closes its
+// database.  It should only be called after WaitForShutdown returns, so that
+// no operation is still using a wallet.
+func (w *WalletDaemon) UnloadWallets() {
+	w.walletsMu.Lock()
+	defer w.walletsMu.Unlock()
+
+	for id, lw := range w.wallets {
+		if err := lw.close(); err != nil {
+			logctx.WalletLog(log, id).Errorf("Failed to close "+
+				"wallet %s: %v", id, err)
+		}
+		delete(w.wallets, id)
+		w.publishWalletEvent(EventWalletClosed, lw.tenant, id,
+			lw.chainParams.Name)
+	}
+	log.Info("Closed all wallets")
+}
+
+// SetIdleTimeout changes the duration after which an open wallet which has not
+// been used is closed.  Zero disables closing idle wallets.  It is safe to call
+// while the daemon is running.
+func (w *WalletDaemon) SetIdleTimeout(timeout time.Duration) {
+	w.walletsMu.Lock()
+	w.idleTimeout = timeout
+	w.walletsMu.Unlock()
+}
+
+// Close closes the registry database and the audit log.  It must only be
+// called after the daemon has been stopped and its wallets unloaded.
+func (w *WalletDaemon) Close() error {
+	auditErr := w.audit.Close()
+	if err := w.registry.close(); err != nil {
+		return err
+	}
+	return auditErr
+}
+
+// AuditLog returns the audit log of the daemon.
+func (w *WalletDaemon) AuditLog() *AuditLog {
+	return w.audit
 }
 
 // quitChan atomically reads the quit channel.
@@ -65,16 +680,16 @@ func (w *WalletDaemon) quitChan() <-chan struct{} {
 	return c
 }
 
-// Stop signals all wallet goroutines to shutdown.
+// Stop signals all wallet goroutines to shutdown.  New wallet operations are
+// refused with ErrShuttingDown once Stop has been called.
 func (w *WalletDaemon) Stop() {
 	w.quitMu.Lock()
-	quit := w.quit
-	w.quitMu.Unlock()
+	defer w.quitMu.Unlock()
 
 	select {
-	case <-quit:
+	case <-w.quit:
 	default:
-		close(quit)
+		close(w.quit)
 	}
 }
 
@@ -94,8 +709,30 @@ func (w *WalletDaemon) WaitForShutdown() {
 	w.wg.Wait()
 }
 
-// ChainParams returns the network parameters for the blockchain the wallet
-// belongs to.
+// ChainParams returns the parameters of the default network, which is used
+// for wallets created without specifying a network.
 func (w *WalletDaemon) ChainParams() *chaincfg.Params {
 	return w.chainParams
 }
+
+// Networks returns the parameters of every network served by the daemon.
+func (w *WalletDaemon) Networks() []*chaincfg.Params {
+	nets := make([]*chaincfg.Params, 0, len(w.nets))
+	for _, params := range knownNets {
+		if _, ok := w.nets[params.Name]; ok {
+			nets = append(nets, params)
+		}
+	}
+	return nets
+}
+
+// WalletChainParams returns the parameters of the network a wallet of the
+// tenant belongs to.  ErrWalletNotFound is returned if the wallet is not
+// registered to the tenant.
+func (w *WalletDaemon) WalletChainParams(tenant, id string) (*chaincfg.Params, error) {
+	rec, err := w.tenantWallet(tenant, id)
+	if err != nil {
+		return nil, err
+	}
+	return ParamsForNet(rec.Net)
+}
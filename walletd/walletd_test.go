@@ -0,0 +1,1499 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package walletd
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcwallet/chain"
+	"github.com/btcsuite/btcwallet/walletdb"
+	"github.com/btcsuite/btcwallet/wtxmgr"
+	"github.com/tuxcanfly/wltd/walletd/memdb"
+	"golang.org/x/crypto/nacl/box"
+)
+
+// shutdownTimeout is how long the tests wait for the daemon goroutines to
+// exit.
+const shutdownTimeout = 5 * time.Second
+
+// fakeChainClient is a chain backend whose notifications are sent by the
+// test.  Its other methods are not implemented.
+type fakeChainClient struct {
+	chain.Interface
+	ntfns chan interface{}
+}
+
+func (c *fakeChainClient) Notifications() <-chan interface{} {
+	return c.ntfns
+}
+
+// Rescan returns at once, as if the rescan had been started.
+func (c *fakeChainClient) Rescan(*chainhash.Hash, []btcutil.Address,
+	[]*wire.OutPoint) error {
+
+	return nil
+}
+
+// newTestDaemon creates a daemon keeping its registry and wallets in memory.
+// The returned function closes the daemon and removes its data directory.
+func newTestDaemon(t *testing.T, clients map[string]chain.Interface) (*WalletDaemon, func()) {
+	dir, err := ioutil.TempDir("", "walletd-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewWalletDaemon(&Config{
+		DataDir:      dir,
+		DBDriver:     memdb.DbType,
+		ChainParams:  &chaincfg.SimNetParams,
+		ChainClients: clients,
+	})
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return w, func() {
+		w.Stop()
+		w.WaitForShutdown()
+		w.UnloadWallets()
+		w.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// waitForShutdown fails the test if the daemon goroutines do not exit in
+// time.
+func waitForShutdown(t *testing.T, w *WalletDaemon) {
+	done := make(chan struct{})
+	go func() {
+		w.WaitForShutdown()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(shutdownTimeout):
+		t.Fatal("daemon goroutines did not exit after Stop")
+	}
+}
+
+// createWallet creates a wallet of the tenant on the default network with the
+// default public passphrase.
+func createWallet(w *WalletDaemon, tenant string) (string, error) {
+	return w.CreateWallet(tenant, "", []byte("private"), nil)
+}
+
+func TestStartStopRestart(t *testing.T) {
+	w, cleanup := newTestDaemon(t, nil)
+	defer cleanup()
+
+	if err := w.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	// Starting a running daemon does nothing.
+	if err := w.Start(); err != nil {
+		t.Fatalf("second Start: %v", err)
+	}
+	if w.ShuttingDown() {
+		t.Fatal("running daemon reports shutting down")
+	}
+	id, err := createWallet(w, "acme")
+	if err != nil {
+		t.Fatalf("CreateWallet: %v", err)
+	}
+
+	w.Stop()
+	// Stopping twice must not close the quit channel again.
+	w.Stop()
+	waitForShutdown(t, w)
+	if !w.ShuttingDown() {
+		t.Fatal("stopped daemon does not report shutting down")
+	}
+	if _, err := createWallet(w, "acme"); err != ErrShuttingDown {
+		t.Fatalf("CreateWallet after Stop: got %v, want %v", err,
+			ErrShuttingDown)
+	}
+
+	// The daemon serves requests again once restarted, and its wallets
+	// are still registered.
+	if err := w.Start(); err != nil {
+		t.Fatalf("restart: %v", err)
+	}
+	if w.ShuttingDown() {
+		t.Fatal("restarted daemon reports shutting down")
+	}
+	if _, err := w.registry.wallet(id); err != nil {
+		t.Fatalf("wallet not registered after restart: %v", err)
+	}
+	if _, err := createWallet(w, "acme"); err != nil {
+		t.Fatalf("CreateWallet after restart: %v", err)
+	}
+	w.Stop()
+	waitForShutdown(t, w)
+}
+
+func TestStopBeforeStart(t *testing.T) {
+	w, cleanup := newTestDaemon(t, nil)
+	defer cleanup()
+
+	w.Stop()
+	waitForShutdown(t, w)
+	if err := w.Start(); err != nil {
+		t.Fatalf("Start after Stop: %v", err)
+	}
+	if _, err := createWallet(w, "acme"); err != nil {
+		t.Fatalf("CreateWallet: %v", err)
+	}
+}
+
+func TestChainNotificationDispatcher(t *testing.T) {
+	client := &fakeChainClient{ntfns: make(chan interface{})}
+	w, cleanup := newTestDaemon(t, map[string]chain.Interface{
+		chaincfg.SimNetParams.Name: client,
+	})
+	defer cleanup()
+
+	events, cancel, err := w.SubscribeEvents("acme", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+
+	// Every run of the daemon dispatches the notifications of the chain
+	// backend, and stops reading them when stopped.
+	for run := int32(1); run <= 2; run++ {
+		if err := w.Start(); err != nil {
+			t.Fatalf("Start: %v", err)
+		}
+		n := chain.BlockConnected{Block: wtxmgr.Block{Height: run}}
+		select {
+		case client.ntfns <- n:
+		case <-time.After(shutdownTimeout):
+			t.Fatal("notification not read by the dispatcher")
+		}
+		select {
+		case e := <-events:
+			if e.Kind != EventBlockConnected || e.Height != run {
+				t.Fatalf("got event %s at height %d, want %s "+
+					"at height %d", e.Kind, e.Height,
+					EventBlockConnected, run)
+			}
+		case <-time.After(shutdownTimeout):
+			t.Fatal("no event published for the notification")
+		}
+		w.Stop()
+		waitForShutdown(t, w)
+	}
+
+	select {
+	case client.ntfns <- chain.BlockConnected{}:
+		t.Fatal("notification read by a stopped daemon")
+	default:
+	}
+}
+
+// openNotifiedWallet adds an open wallet of the simulation network to the
+// daemon which only receives chain notifications.  The returned function
+// removes it.
+func openNotifiedWallet(w *WalletDaemon, id string) (*loadedWallet, func()) {
+	lw := &loadedWallet{
+		chainParams: &chaincfg.SimNetParams,
+		outpoints:   make(map[wire.OutPoint]struct{}),
+	}
+	lw.startNotifications()
+	w.walletsMu.Lock()
+	w.wallets[id] = lw
+	w.walletsMu.Unlock()
+	return lw, func() {
+		w.walletsMu.Lock()
+		delete(w.wallets, id)
+		w.walletsMu.Unlock()
+		lw.stopNotifications()
+	}
+}
+
+// receiveNotification returns the next chain notification of a wallet.
+func receiveNotification(t *testing.T, lw *loadedWallet) interface{} {
+	select {
+	case n := <-lw.ntfns:
+		return n
+	case <-time.After(shutdownTimeout):
+		t.Fatal("notification not dispatched to the wallet")
+	}
+	return nil
+}
+
+func TestDispatchChainNotifications(t *testing.T) {
+	client := &fakeChainClient{ntfns: make(chan interface{})}
+	net := chaincfg.SimNetParams.Name
+	w, cleanup := newTestDaemon(t, map[string]chain.Interface{net: client})
+	defer cleanup()
+
+	rescanning, remove := openNotifiedWallet(w, "rescanning")
+	defer remove()
+	slow, remove := openNotifiedWallet(w, "slow")
+	defer remove()
+
+	c := &walletChainClient{
+		Interface: client,
+		lw:        rescanning,
+		rescans:   w.rescans[net],
+	}
+	if err := c.Rescan(nil, nil, nil); err != nil {
+		t.Fatalf("Rescan: %v", err)
+	}
+
+	// The slow wallet never reads its notifications, which must not hold
+	// back the other wallet.
+	const blocks = 500
+	w.dispatchChainNotification(net, chain.RescanProgress{Height: 1})
+	for i := int32(0); i < blocks; i++ {
+		w.dispatchChainNotification(net, chain.BlockConnected{
+			Block: wtxmgr.Block{Height: i},
+		})
+	}
+	w.dispatchChainNotification(net, chain.RescanFinished{Height: 2})
+
+	if n, ok := receiveNotification(t, rescanning).(chain.RescanProgress); !ok ||
+		n.Height != 1 {
+		t.Fatalf("got %#v, want the rescan progress", n)
+	}
+	for i := int32(0); i < blocks; i++ {
+		n, ok := receiveNotification(t, rescanning).(chain.BlockConnected)
+		if !ok || n.Height != i {
+			t.Fatalf("got %#v, want block %d", n, i)
+		}
+	}
+	if n, ok := receiveNotification(t, rescanning).(chain.RescanFinished); !ok ||
+		n.Height != 2 {
+		t.Fatalf("got %#v, want the end of the rescan", n)
+	}
+
+	// The rescan notifications are only dispatched to the wallet which
+	// started the rescan.
+	for i := int32(0); i < blocks; i++ {
+		n := receiveNotification(t, slow)
+		if b, ok := n.(chain.BlockConnected); !ok || b.Height != i {
+			t.Fatalf("got %#v, want block %d", n, i)
+		}
+	}
+	select {
+	case n := <-slow.ntfns:
+		t.Fatalf("got %#v dispatched to a wallet not rescanning", n)
+	default:
+	}
+
+	// Without a running rescan, rescan notifications are dropped.
+	w.dispatchChainNotification(net, chain.RescanFinished{})
+	select {
+	case n := <-rescanning.ntfns:
+		t.Fatalf("got %#v dispatched without a rescan", n)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestAutoloadWallets(t *testing.T) {
+	w, cleanup := newTestDaemon(t, nil)
+	defer cleanup()
+
+	if err := w.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	id, err := createWallet(w, "acme")
+	if err != nil {
+		t.Fatalf("CreateWallet: %v", err)
+	}
+	if err := w.SetAutoload("acme", id, true); err != nil {
+		t.Fatalf("SetAutoload: %v", err)
+	}
+	w.Stop()
+	waitForShutdown(t, w)
+	w.UnloadWallets()
+
+	// Autoload wallets are opened in the background once restarted.
+	if err := w.Start(); err != nil {
+		t.Fatalf("restart: %v", err)
+	}
+	deadline := time.Now().Add(shutdownTimeout)
+	for {
+		info, err := w.WalletInfo("acme", id)
+		if err != nil {
+			t.Fatalf("WalletInfo: %v", err)
+		}
+		if info.Open {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("autoload wallet not opened after restart")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	w.Stop()
+	waitForShutdown(t, w)
+}
+
+func TestCreateWalletsClosesWallets(t *testing.T) {
+	w, cleanup := newTestDaemon(t, nil)
+	defer cleanup()
+
+	if err := w.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	passphrases := [][]byte{[]byte("one"), []byte("two"), []byte("three")}
+	var ids []string
+	err := w.CreateWallets("acme", "", passphrases,
+		func(r *CreateResult) error {
+			if r.Err != nil {
+				t.Errorf("wallet %d: %v", r.Index, r.Err)
+				return nil
+			}
+			ids = append(ids, r.UUID)
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("CreateWallets: %v", err)
+	}
+	if len(ids) != len(passphrases) {
+		t.Fatalf("created %d wallets, want %d", len(ids),
+			len(passphrases))
+	}
+	for _, id := range ids {
+		info, err := w.WalletInfo("acme", id)
+		if err != nil {
+			t.Fatalf("WalletInfo: %v", err)
+		}
+		if info.Open {
+			t.Errorf("wallet %s left open after bulk creation", id)
+		}
+	}
+}
+
+func TestCreateWalletsOpenQuota(t *testing.T) {
+	w, cleanup := newTestDaemon(t, nil)
+	defer cleanup()
+
+	if err := w.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if err := w.SetQuota("acme", &Quota{MaxOpenWallets: 1}); err != nil {
+		t.Fatalf("SetQuota: %v", err)
+	}
+	if _, err := createWallet(w, "acme"); err != nil {
+		t.Fatalf("CreateWallet: %v", err)
+	}
+
+	// Wallets created in bulk are closed, so they do not count against
+	// the open wallets of the tenant.
+	passphrases := [][]byte{[]byte("one"), []byte("two")}
+	err := w.CreateWallets("acme", "", passphrases,
+		func(r *CreateResult) error {
+			if r.Err != nil {
+				t.Errorf("wallet %d: %v", r.Index, r.Err)
+			}
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("CreateWallets: %v", err)
+	}
+
+	_, err = createWallet(w, "acme")
+	if _, ok := err.(*QuotaExceededError); !ok {
+		t.Fatalf("CreateWallet beyond the open wallets: got %v, want "+
+			"a QuotaExceededError", err)
+	}
+}
+
+func TestCreatePoolTakesTurns(t *testing.T) {
+	p := newCreatePool(1)
+	release := p.acquire("big")
+
+	// waiting returns the number of waiting slot requests.
+	waiting := func() int {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		n := 0
+		for _, waiters := range p.waiters {
+			n += len(waiters)
+		}
+		return n
+	}
+	order := make(chan string, 3)
+	wait := func(tenant string) {
+		n := waiting()
+		go func() {
+			release := p.acquire(tenant)
+			order <- tenant
+			release()
+		}()
+		for waiting() == n {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	// The big tenant queues two wallets before the small one queues its
+	// own, which is still created before the second wallet of the big
+	// tenant.
+	wait("big")
+	wait("big")
+	wait("small")
+	release()
+
+	var got []string
+	for i := 0; i < 3; i++ {
+		select {
+		case tenant := <-order:
+			got = append(got, tenant)
+		case <-time.After(shutdownTimeout):
+			t.Fatal("slot not handed to a waiting tenant")
+		}
+	}
+	want := []string{"big", "small", "big"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("slots handed out in order %v, want %v", got, want)
+		}
+	}
+}
+
+func TestMaintainRegistryKeepsMissingWallets(t *testing.T) {
+	w, cleanup := newTestDaemon(t, nil)
+	defer cleanup()
+
+	if err := w.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	id, err := createWallet(w, "acme")
+	if err != nil {
+		t.Fatalf("CreateWallet: %v", err)
+	}
+	w.UnloadWallets()
+	w.storage.removeWallet(id, &chaincfg.SimNetParams)
+
+	// The record of a wallet whose files are missing is only reported.
+	w.maintainRegistry()
+	if _, ok := w.missingWallets[id]; !ok {
+		t.Fatal("missing wallet not reported")
+	}
+	if _, err := w.WalletInfo("acme", id); err != nil {
+		t.Fatalf("WalletInfo of missing wallet: %v", err)
+	}
+
+	// Deleting the wallet removes its record.
+	if err := w.DeleteWallet("acme", id); err != nil {
+		t.Fatalf("DeleteWallet: %v", err)
+	}
+	if _, err := w.WalletInfo("acme", id); err != ErrWalletNotFound {
+		t.Fatalf("WalletInfo of deleted wallet: got %v, want %v", err,
+			ErrWalletNotFound)
+	}
+	w.maintainRegistry()
+	if len(w.missingWallets) != 0 {
+		t.Fatalf("deleted wallet still reported missing")
+	}
+}
+
+// newTestAuditLog opens an audit log in a new directory with n records
+// appended.  The returned function closes it and removes the directory.
+func newTestAuditLog(t *testing.T, n int) (*AuditLog, string, func()) {
+	dir, err := ioutil.TempDir("", "walletd-audit-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, AuditLogName)
+	l, err := OpenAuditLog(path)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("OpenAuditLog: %v", err)
+	}
+	for i := 0; i < n; i++ {
+		err := l.Append(&AuditRecord{
+			Tenant:  "acme",
+			Method:  fmt.Sprintf("Method%d", i+1),
+			Success: true,
+		})
+		if err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+	return l, path, func() {
+		l.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// rewriteAuditLog replaces the records of the audit log at path with those
+// returned by edit.
+func rewriteAuditLog(t *testing.T, path string, edit func([]*AuditRecord) []*AuditRecord) {
+	var recs []*AuditRecord
+	err := forEachAuditRecord(path, func(r *AuditRecord, line int) error {
+		recs = append(recs, r)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	for _, r := range edit(recs) {
+		b, err := json.Marshal(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf.Write(b)
+		buf.WriteByte('\n')
+	}
+	if err := ioutil.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAuditLogReopen(t *testing.T) {
+	l, path, cleanup := newTestAuditLog(t, 2)
+	defer cleanup()
+
+	// The chain is continued from the last record once reopened.
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	l, err := OpenAuditLog(path)
+	if err != nil {
+		t.Fatalf("OpenAuditLog: %v", err)
+	}
+	defer l.Close()
+	if err := l.Append(&AuditRecord{Method: "Method3"}); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+
+	n, last, err := VerifyAuditLog(path)
+	if err != nil {
+		t.Fatalf("VerifyAuditLog: %v", err)
+	}
+	if n != 3 {
+		t.Fatalf("verified %d records, want 3", n)
+	}
+	recs, err := l.Query(&AuditFilter{}, 0)
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(recs) != 3 || recs[2].Sequence != 3 || recs[2].Hash != last {
+		t.Fatalf("reopened log does not continue the chain: %+v", recs)
+	}
+}
+
+func TestVerifyAuditLogTampering(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func([]*AuditRecord) []*AuditRecord
+		err  string
+	}{{
+		name: "modified record",
+		edit: func(recs []*AuditRecord) []*AuditRecord {
+			recs[1].Method = "DeleteWallet"
+			return recs
+		},
+		err: "line 2: record 2 has been modified",
+	}, {
+		name: "reordered records",
+		edit: func(recs []*AuditRecord) []*AuditRecord {
+			recs[1], recs[2] = recs[2], recs[1]
+			return recs
+		},
+		err: "line 2: record 3 does not follow the previous record",
+	}, {
+		name: "removed record",
+		edit: func(recs []*AuditRecord) []*AuditRecord {
+			return append(recs[:1], recs[2:]...)
+		},
+		err: "line 2: record 3 does not follow the previous record",
+	}, {
+		name: "sequence gap",
+		edit: func(recs []*AuditRecord) []*AuditRecord {
+			recs[1].Sequence = 5
+			recs[1].Hash, _ = recs[1].hash()
+			return recs
+		},
+		err: "line 2: record 5 follows record 1",
+	}}
+
+	for _, test := range tests {
+		l, path, cleanup := newTestAuditLog(t, 3)
+		l.Close()
+		if _, _, err := VerifyAuditLog(path); err != nil {
+			t.Fatalf("%s: VerifyAuditLog before tampering: %v",
+				test.name, err)
+		}
+		rewriteAuditLog(t, path, test.edit)
+		_, _, err := VerifyAuditLog(path)
+		if err == nil || err.Error() != test.err {
+			t.Errorf("%s: got error %v, want %s", test.name, err,
+				test.err)
+		}
+		cleanup()
+	}
+}
+
+func TestAuditLogIncompleteRecord(t *testing.T) {
+	l, path, cleanup := newTestAuditLog(t, 2)
+	defer cleanup()
+	l.Close()
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A crash during Append leaves a partial last line.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"seq":3,"time":"2016-`)
+	f.Close()
+	if _, _, err := VerifyAuditLog(path); err == nil {
+		t.Fatal("log with a partial record verified")
+	}
+
+	l, err = OpenAuditLog(path)
+	if err != nil {
+		t.Fatalf("OpenAuditLog: %v", err)
+	}
+	defer l.Close()
+	truncated, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if truncated.Size() != fi.Size() {
+		t.Fatalf("log has %d bytes after removing the partial record, "+
+			"want %d", truncated.Size(), fi.Size())
+	}
+	if err := l.Append(&AuditRecord{Method: "Method3"}); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	if n, _, err := VerifyAuditLog(path); err != nil || n != 3 {
+		t.Fatalf("VerifyAuditLog: %d records, %v", n, err)
+	}
+}
+
+func TestAuditLogQuery(t *testing.T) {
+	l, _, cleanup := newTestAuditLog(t, 5)
+	defer cleanup()
+
+	all, err := l.Query(&AuditFilter{}, 0)
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(all) != 5 {
+		t.Fatalf("got %d records, want 5", len(all))
+	}
+
+	// The limit keeps the last records, oldest first.
+	recs, err := l.Query(&AuditFilter{Tenant: "acme"}, 2)
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(recs) != 2 || recs[0].Sequence != 4 || recs[1].Sequence != 5 {
+		t.Fatalf("limited query returned %+v", recs)
+	}
+	recs, err = l.Query(&AuditFilter{Tenant: "other"}, 0)
+	if err != nil || len(recs) != 0 {
+		t.Fatalf("query of another tenant returned %d records, %v",
+			len(recs), err)
+	}
+	recs, err = l.Query(&AuditFilter{Method: "Method2"}, 0)
+	if err != nil || len(recs) != 1 || recs[0].Sequence != 2 {
+		t.Fatalf("query by method returned %+v, %v", recs, err)
+	}
+
+	// Since is inclusive and Until exclusive.
+	t3, err := time.Parse(auditTimeFormat, all[2].Time)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recs, err = l.Query(&AuditFilter{Since: t3}, 0)
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(recs) == 0 || recs[0].Sequence > 3 || recs[len(recs)-1].Sequence != 5 {
+		t.Fatalf("query since record 3 returned %+v", recs)
+	}
+	for _, r := range recs {
+		if r.Sequence < 3 && r.Time != all[2].Time {
+			t.Fatalf("query since record 3 returned record %d",
+				r.Sequence)
+		}
+	}
+	recs, err = l.Query(&AuditFilter{Until: t3}, 0)
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	for _, r := range recs {
+		if r.Sequence >= 3 || r.Time == all[2].Time {
+			t.Fatalf("query until record 3 returned record %d",
+				r.Sequence)
+		}
+	}
+}
+
+// sealBackup encrypts data as a backup to the public key.
+func sealBackup(t *testing.T, publicKey *[32]byte, data []byte) []byte {
+	var buf bytes.Buffer
+	bw, err := newBackupWriter(&buf, publicKey)
+	if err != nil {
+		t.Fatalf("newBackupWriter: %v", err)
+	}
+	if _, err := bw.Write(data); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := bw.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// openBackup decrypts a backup with the private key.
+func openBackup(privateKey *[32]byte, backup []byte) ([]byte, error) {
+	br, err := newBackupReader(bytes.NewReader(backup), privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(br)
+}
+
+// backupChunks splits a backup into its header and its length prefixed
+// chunks.
+func backupChunks(backup []byte) ([]byte, [][]byte) {
+	headerLen := len(backupMagic) + 32
+	header, rest := backup[:headerLen], backup[headerLen:]
+	var chunks [][]byte
+	for len(rest) != 0 {
+		n := 4 + int(binary.BigEndian.Uint32(rest[:4]))
+		chunks = append(chunks, rest[:n])
+		rest = rest[n:]
+	}
+	return header, chunks
+}
+
+// joinBackup joins a backup header and chunks.
+func joinBackup(header []byte, chunks ...[]byte) []byte {
+	b := append([]byte(nil), header...)
+	for _, c := range chunks {
+		b = append(b, c...)
+	}
+	return b
+}
+
+func TestBackupEncryption(t *testing.T) {
+	pub, priv, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := make([]byte, 2*backupChunkSize+backupChunkSize/2)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, size := range []int{0, 1, backupChunkSize, len(data)} {
+		got, err := openBackup(priv, sealBackup(t, pub, data[:size]))
+		if err != nil {
+			t.Fatalf("round trip of %d bytes: %v", size, err)
+		}
+		if !bytes.Equal(got, data[:size]) {
+			t.Fatalf("round trip of %d bytes changed the data", size)
+		}
+	}
+
+	backup := sealBackup(t, pub, data)
+	header, chunks := backupChunks(backup)
+	if len(chunks) != 3 {
+		t.Fatalf("backup has %d chunks, want 3", len(chunks))
+	}
+	_, otherPriv, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	flipped := append([]byte(nil), backup...)
+	flipped[len(header)+4+100] ^= 1
+
+	tests := []struct {
+		name   string
+		key    *[32]byte
+		backup []byte
+	}{
+		{"wrong key", otherPriv, backup},
+		{"swapped chunks", priv, joinBackup(header, chunks[1], chunks[0],
+			chunks[2])},
+		{"dropped last chunk", priv, joinBackup(header, chunks[0],
+			chunks[1])},
+		{"truncated chunk", priv, backup[:len(backup)-10]},
+		{"truncated header", priv, backup[:len(backupMagic)+10]},
+		{"flipped byte", priv, flipped},
+		{"wrong magic", priv, append([]byte("WLTDBAK0"),
+			backup[len(backupMagic):]...)},
+	}
+	for _, test := range tests {
+		_, err := openBackup(test.key, test.backup)
+		if err != ErrInvalidBackup {
+			t.Errorf("%s: got error %v, want %v", test.name, err,
+				ErrInvalidBackup)
+		}
+	}
+}
+
+func TestParseSchedule(t *testing.T) {
+	tests := []struct {
+		spec string
+		err  bool
+	}{
+		{spec: "@hourly"},
+		{spec: "@daily"},
+		{spec: "@weekly"},
+		{spec: "@monthly"},
+		{spec: "*/15 9-17 * * 1-5"},
+		{spec: "0 0 1,15 * *"},
+		{spec: "5/20 * * * *"},
+		{spec: "@yearly", err: true},
+		{spec: "* * * *", err: true},
+		{spec: "* * * * * *", err: true},
+		{spec: "60 * * * *", err: true},
+		{spec: "* 24 * * *", err: true},
+		{spec: "* * 0 * *", err: true},
+		{spec: "* * * 13 *", err: true},
+		{spec: "* * * * 7", err: true},
+		{spec: "5-1 * * * *", err: true},
+		{spec: "*/0 * * * *", err: true},
+		{spec: "*/x * * * *", err: true},
+		{spec: "a * * * *", err: true},
+		{spec: "1-b * * * *", err: true},
+	}
+	for _, test := range tests {
+		_, err := ParseSchedule(test.spec)
+		if test.err && err == nil {
+			t.Errorf("%s: parsed, want an error", test.spec)
+		}
+		if !test.err && err != nil {
+			t.Errorf("%s: %v", test.spec, err)
+		}
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	// Monday, March 7th 2016.
+	start := time.Date(2016, 3, 7, 10, 7, 30, 0, time.UTC)
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2016, month, day, hour, minute, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		spec string
+		next []time.Time
+	}{
+		{"@hourly", []time.Time{at(3, 7, 11, 0), at(3, 7, 12, 0)}},
+		{"@daily", []time.Time{at(3, 8, 0, 0), at(3, 9, 0, 0)}},
+		{"@weekly", []time.Time{at(3, 13, 0, 0), at(3, 20, 0, 0)}},
+		{"@monthly", []time.Time{at(4, 1, 0, 0), at(5, 1, 0, 0)}},
+		{"*/20 10-11 * * *", []time.Time{at(3, 7, 10, 20), at(3, 7, 10, 40),
+			at(3, 7, 11, 0), at(3, 7, 11, 20), at(3, 7, 11, 40),
+			at(3, 8, 10, 0)}},
+		{"5/30 * * * *", []time.Time{at(3, 7, 10, 35), at(3, 7, 11, 5)}},
+		{"0 12 * * 6,0", []time.Time{at(3, 12, 12, 0), at(3, 13, 12, 0),
+			at(3, 19, 12, 0)}},
+		// The days of month and week are or-ed when both are
+		// restricted: the 10th, or any Monday.
+		{"0 0 10 * 1", []time.Time{at(3, 10, 0, 0), at(3, 14, 0, 0),
+			at(3, 21, 0, 0)}},
+		// Leap day.
+		{"0 0 29 2 *", []time.Time{time.Date(2020, 2, 29, 0, 0, 0, 0,
+			time.UTC)}},
+	}
+	for _, test := range tests {
+		s, err := ParseSchedule(test.spec)
+		if err != nil {
+			t.Fatalf("%s: %v", test.spec, err)
+		}
+		next := start
+		for _, want := range test.next {
+			next = s.Next(next)
+			if !next.Equal(want) {
+				t.Errorf("%s: next %v, want %v", test.spec, next,
+					want)
+				break
+			}
+		}
+	}
+
+	// Schedules which never match have no next time.
+	for _, spec := range []string{"0 0 30 2 *", "0 0 31 4,6,9,11 *"} {
+		s, err := ParseSchedule(spec)
+		if err != nil {
+			t.Fatalf("%s: %v", spec, err)
+		}
+		if next := s.Next(start); !next.IsZero() {
+			t.Errorf("%s: next %v, want none", spec, next)
+		}
+	}
+}
+
+func TestScheduleNextDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("no time zone database: %v", err)
+	}
+
+	// 2:30 does not exist on March 13th 2016, when clocks go from 2:00 to
+	// 3:00, and is skipped.
+	s, err := ParseSchedule("30 2 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := s.Next(time.Date(2016, 3, 13, 0, 0, 0, 0, loc))
+	if want := time.Date(2016, 3, 14, 2, 30, 0, 0, loc); !next.Equal(want) {
+		t.Errorf("next %v after the start of DST, want %v", next, want)
+	}
+	s, err = ParseSchedule("0 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	next = s.Next(time.Date(2016, 3, 13, 1, 30, 0, 0, loc))
+	if want := time.Date(2016, 3, 13, 3, 0, 0, 0, loc); !next.Equal(want) {
+		t.Errorf("next %v across the start of DST, want %v", next, want)
+	}
+
+	// 1:30 happens twice on November 6th 2016, when clocks go from 2:00
+	// back to 1:00, and is only scheduled once.
+	s, err = ParseSchedule("30 1 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := s.Next(time.Date(2016, 11, 6, 0, 0, 0, 0, loc))
+	if first.Day() != 6 || first.Hour() != 1 || first.Minute() != 30 {
+		t.Fatalf("next %v at the end of DST, want 1:30", first)
+	}
+	next = s.Next(first)
+	if want := time.Date(2016, 11, 7, 1, 30, 0, 0, loc); !next.Equal(want) {
+		t.Errorf("next %v after %v, want %v", next, first, want)
+	}
+
+	// Hourly schedules still run every hour, including the repeated one
+	// once.
+	s, err = ParseSchedule("@hourly")
+	if err != nil {
+		t.Fatal(err)
+	}
+	next = s.Next(time.Date(2016, 11, 6, 0, 30, 0, 0, loc))
+	if next.Hour() != 1 || next.Minute() != 0 {
+		t.Fatalf("next %v, want 1:00", next)
+	}
+	next = s.Next(next)
+	if want := time.Date(2016, 11, 6, 2, 0, 0, 0, loc); !next.Equal(want) {
+		t.Errorf("next %v after 1:00, want %v", next, want)
+	}
+}
+
+// copyableDB is an in-memory registry database whose copies hold its schema
+// version, since in-memory databases cannot be copied.
+type copyableDB struct {
+	walletdb.DB
+}
+
+func (db copyableDB) Copy(w io.Writer) error {
+	version, err := (&registry{db: db.DB}).schemaVersion()
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "registry version %d", version)
+	return err
+}
+
+// testWalletIDs are wallet UUIDs, the last two of which share a shard.
+var testWalletIDs = []string{
+	"3f2504e0-4f89-11d3-9a0c-0305e82c3301",
+	"6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+	"6ba7b811-9dad-11d1-80b4-00c04fd430c8",
+}
+
+// newV1Daemon creates a test daemon whose registry is at schema version 1 and
+// records testWalletIDs, whose directories are stored directly in the wallets
+// directory as they were at that version.  The registry is kept in memory,
+// but the wallet directories are files so that they can be moved.
+func newV1Daemon(t *testing.T) (*WalletDaemon, func()) {
+	w, cleanup := newTestDaemon(t, nil)
+	for _, id := range testWalletIDs {
+		err := w.registry.putWallet(&walletRecord{ID: id,
+			Net: chaincfg.SimNetParams.Name, Created: time.Now()})
+		if err != nil {
+			cleanup()
+			t.Fatal(err)
+		}
+		makeWalletDir(t, filepath.Join(w.dbDir, walletsDirName, id))
+	}
+	err := walletdb.Update(w.registry.db, func(tx walletdb.ReadWriteTx) error {
+		for _, name := range [][]byte{quotasBucketName, metaBucketName} {
+			if err := tx.DeleteTopLevelBucket(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+	w.registry.db = copyableDB{w.registry.db}
+	w.storage = &storage{dataDir: w.dbDir, driver: DefaultDBDriver}
+	return w, cleanup
+}
+
+// makeWalletDir creates a wallet directory holding a simnet database file.
+func makeWalletDir(t *testing.T, dir string) {
+	path := filepath.Join(networkDir(dir, &chaincfg.SimNetParams),
+		walletDbName)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte("wallet"), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// checkMigrated fails the test unless the registry of the daemon is at the
+// latest schema version and every test wallet is stored in its shard.
+func checkMigrated(t *testing.T, w *WalletDaemon) {
+	version, steps, err := w.PendingMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != latestSchemaVersion() || len(steps) != 0 {
+		t.Fatalf("registry at version %d with %d pending migrations, "+
+			"want version %d", version, len(steps),
+			latestSchemaVersion())
+	}
+	err = walletdb.View(w.registry.db, func(tx walletdb.ReadTx) error {
+		if tx.ReadBucket(quotasBucketName) == nil {
+			return fmt.Errorf("no quotas bucket")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range testWalletIDs {
+		if fileExists(filepath.Join(w.dbDir, walletsDirName, id)) {
+			t.Errorf("wallet %s still unsharded", id)
+		}
+		path := filepath.Join(w.dbDir, relDBPath(id,
+			&chaincfg.SimNetParams))
+		if !fileExists(path) {
+			t.Errorf("wallet %s not stored in its shard", id)
+		}
+	}
+	ids, err := w.storage.walletIDs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(ids) != fmt.Sprint(testWalletIDs) {
+		t.Errorf("found wallets %v, want %v", ids, testWalletIDs)
+	}
+}
+
+// registryBackups returns the contents of the registry backups of the daemon
+// by schema version.
+func registryBackups(t *testing.T, w *WalletDaemon) map[uint32]string {
+	names, err := filepath.Glob(filepath.Join(w.dbDir, registryDbName+
+		".v*.bak"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	backups := make(map[uint32]string)
+	for _, name := range names {
+		var version uint32
+		_, err := fmt.Sscanf(filepath.Base(name), registryDbName+".v%d-",
+			&version)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		b, err := ioutil.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		backups[version] = string(b)
+	}
+	return backups
+}
+
+func TestMigrateRegistry(t *testing.T) {
+	w, cleanup := newV1Daemon(t)
+	defer cleanup()
+
+	version, steps, err := w.PendingMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 1 || len(steps) != len(migrations) {
+		t.Fatalf("registry at version %d with %d pending migrations, "+
+			"want version 1 with %d", version, len(steps),
+			len(migrations))
+	}
+
+	if err := w.migrate(); err != nil {
+		t.Fatal(err)
+	}
+	checkMigrated(t, w)
+	for _, id := range testWalletIDs {
+		if _, err := w.registry.wallet(id); err != nil {
+			t.Errorf("wallet %s: %v", id, err)
+		}
+	}
+
+	// The registry is backed up before the first migration only.
+	want := map[uint32]string{1: "registry version 1"}
+	if backups := registryBackups(t, w); fmt.Sprint(backups) != fmt.Sprint(want) {
+		t.Errorf("backups %v, want %v", backups, want)
+	}
+	if err := w.migrate(); err != nil {
+		t.Fatal(err)
+	}
+	if backups := registryBackups(t, w); len(backups) != 1 {
+		t.Errorf("backed up again without pending migrations: %v",
+			backups)
+	}
+}
+
+func TestMigrateRegistryInterrupted(t *testing.T) {
+	w, cleanup := newV1Daemon(t)
+	defer cleanup()
+
+	// A previous run moved the first wallet before being interrupted,
+	// and the last wallet cannot be moved since its shard already has a
+	// directory of the same name.
+	first := testWalletIDs[0]
+	if err := os.MkdirAll(filepath.Dir(w.storage.walletDir(first)), 0700); err != nil {
+		t.Fatal(err)
+	}
+	err := os.Rename(filepath.Join(w.dbDir, walletsDirName, first),
+		w.storage.walletDir(first))
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := testWalletIDs[len(testWalletIDs)-1]
+	makeWalletDir(t, w.storage.walletDir(last))
+
+	if err := w.migrate(); err == nil {
+		t.Fatal("migrated over an existing wallet directory")
+	}
+	version, err := w.registry.schemaVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != shardedLayoutVersion-1 {
+		t.Fatalf("registry at version %d after a failed migration, "+
+			"want %d", version, shardedLayoutVersion-1)
+	}
+
+	// Running the migrations again once the conflict is resolved moves
+	// the remaining wallets.
+	if err := os.RemoveAll(w.storage.walletDir(last)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.migrate(); err != nil {
+		t.Fatal(err)
+	}
+	checkMigrated(t, w)
+	want := map[uint32]string{
+		1: "registry version 1",
+		shardedLayoutVersion - 1: fmt.Sprintf("registry version %d",
+			shardedLayoutVersion-1),
+	}
+	if backups := registryBackups(t, w); fmt.Sprint(backups) != fmt.Sprint(want) {
+		t.Errorf("backups %v, want %v", backups, want)
+	}
+}
+
+func TestOpenRegistryNewerSchema(t *testing.T) {
+	w, cleanup := newTestDaemon(t, nil)
+	defer cleanup()
+
+	err := walletdb.Update(w.registry.db, func(tx walletdb.ReadWriteTx) error {
+		return putSchemaVersion(tx, latestSchemaVersion()+1)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, err := openRegistry(w.storage); err != ErrNewerSchema {
+		if err == nil {
+			r.close()
+		}
+		t.Fatalf("opened a newer registry: %v", err)
+	}
+}
+
+// testTxID is a transaction hash used by the label tests.
+const testTxID = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+
+// testAddress returns an address with an empty public key hash on the
+// network.
+func testAddress(t *testing.T, params *chaincfg.Params) string {
+	addr, err := btcutil.NewAddressPubKeyHash(make([]byte, 20), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return addr.EncodeAddress()
+}
+
+func TestNormalizeLabelRef(t *testing.T) {
+	params := &chaincfg.SimNetParams
+	addr := testAddress(t, params)
+	tests := []struct {
+		typ, ref string
+		want     string
+	}{
+		{LabelTx, testTxID, testTxID},
+		{LabelTx, strings.ToUpper(testTxID), testTxID},
+		{LabelTx, testTxID[2:], ""},
+		{LabelTx, testTxID + "00", ""},
+		{LabelTx, "zz" + testTxID[2:], ""},
+		{LabelAddress, addr, addr},
+		{LabelAddress, testAddress(t, &chaincfg.MainNetParams), ""},
+		{LabelAddress, "not an address", ""},
+		{LabelOutput, testTxID + ":0", testTxID + ":0"},
+		{LabelOutput, strings.ToUpper(testTxID) + ":007", testTxID + ":7"},
+		{LabelOutput, testTxID + ":4294967295", testTxID + ":4294967295"},
+		{LabelOutput, testTxID + ":4294967296", ""},
+		{LabelOutput, testTxID + ":-1", ""},
+		{LabelOutput, testTxID + ":", ""},
+		{LabelOutput, testTxID, ""},
+		{LabelOutput, testTxID[2:] + ":0", ""},
+		{"pubkey", addr, ""},
+	}
+	for _, test := range tests {
+		ref, err := normalizeLabelRef(test.typ, test.ref, params)
+		if test.want == "" {
+			if _, ok := err.(*InvalidLabelError); !ok {
+				t.Errorf("%s %s: normalized to %q, %v, want an "+
+					"InvalidLabelError", test.typ, test.ref, ref,
+					err)
+			}
+			continue
+		}
+		if err != nil || ref != test.want {
+			t.Errorf("%s %s: normalized to %q, %v, want %q",
+				test.typ, test.ref, ref, err, test.want)
+		}
+	}
+}
+
+func TestSetLabels(t *testing.T) {
+	w, cleanup := newTestDaemon(t, nil)
+	defer cleanup()
+	id, err := createWallet(w, "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := testAddress(t, &chaincfg.SimNetParams)
+
+	err = w.SetLabels("acme", id, []Label{
+		{LabelOutput, strings.ToUpper(testTxID) + ":1", "change"},
+		{LabelAddress, addr, "donations"},
+		{LabelTx, strings.ToUpper(testTxID), "rent"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Label{
+		{LabelTx, testTxID, "rent"},
+		{LabelAddress, addr, "donations"},
+		{LabelOutput, testTxID + ":1", "change"},
+	}
+	labels, err := w.Labels("acme", id, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(labels) != fmt.Sprint(want) {
+		t.Errorf("labels %v, want %v", labels, want)
+	}
+	labels, err = w.Labels("acme", id, LabelTx,
+		[]string{strings.ToUpper(testTxID)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(labels) != fmt.Sprint(want[:1]) {
+		t.Errorf("labels %v of an uppercase txid, want %v", labels,
+			want[:1])
+	}
+	if _, err := w.Labels("other", id, "", nil); err != ErrWalletNotFound {
+		t.Errorf("labels of another tenant: %v", err)
+	}
+
+	// No label is set when one is invalid.
+	invalid := [][]Label{
+		{{LabelAddress, testAddress(t, &chaincfg.MainNetParams), "x"}},
+		{{LabelTx, testTxID, strings.Repeat("x", MaxLabelLen+1)}},
+		{{LabelTx, testTxID, "\xff"}},
+		{{"pubkey", addr, "x"}},
+	}
+	for _, l := range invalid {
+		l = append([]Label{{LabelTx, testTxID, "groceries"}}, l...)
+		err := w.SetLabels("acme", id, l)
+		if _, ok := err.(*InvalidLabelError); !ok {
+			t.Errorf("%v: set, %v, want an InvalidLabelError", l, err)
+		}
+	}
+
+	// Empty labels are removed.
+	err = w.SetLabels("acme", id, []Label{{LabelAddress, addr, ""}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	labels, err = w.Labels("acme", id, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []Label{want[0], want[2]}
+	if fmt.Sprint(labels) != fmt.Sprint(want) {
+		t.Errorf("labels %v, want %v", labels, want)
+	}
+}
+
+func TestImportLabels(t *testing.T) {
+	w, cleanup := newTestDaemon(t, nil)
+	defer cleanup()
+	id, err := createWallet(w, "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := testAddress(t, &chaincfg.SimNetParams)
+
+	lines := []string{
+		`{"type":"tx","ref":"` + strings.ToUpper(testTxID) + `","label":"rent","origin":"wpkh([d34db33f/84'/0'/0'])"}`,
+		``,
+		`{"type":"addr","ref":"` + addr + `","label":"donations"}`,
+		`{"type":"output","ref":"` + testTxID + `:1","label":"change","spendable":false}`,
+		// Skipped records.
+		`{"type":"pubkey","ref":"` + addr + `","label":"key"}`,
+		`{"type":"xpub","ref":"xpub","label":"account"}`,
+		`{"type":"addr","ref":"` + testAddress(t, &chaincfg.MainNetParams) + `","label":"mainnet"}`,
+		`{"type":"tx","ref":"` + testTxID[2:] + `","label":"short"}`,
+		`{"type":"output","ref":"` + testTxID + `","label":"no index"}`,
+		`{"type":"tx","ref":"` + testTxID + `"}`,
+		`{"type":"tx","ref":"` + testTxID + `","label":"` + strings.Repeat("x", MaxLabelLen+1) + `"}`,
+	}
+	result, err := w.ImportLabels("acme", id,
+		strings.NewReader(strings.Join(lines, "\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Imported != 3 || result.Skipped != 7 {
+		t.Errorf("imported %d and skipped %d labels, want 3 and 7",
+			result.Imported, result.Skipped)
+	}
+	want := []Label{
+		{LabelTx, testTxID, "rent"},
+		{LabelAddress, addr, "donations"},
+		{LabelOutput, testTxID + ":1", "change"},
+	}
+	labels, err := w.Labels("acme", id, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(labels) != fmt.Sprint(want) {
+		t.Errorf("labels %v, want %v", labels, want)
+	}
+
+	// Nothing is imported from imports which cannot be read.
+	var tooMany bytes.Buffer
+	for i := 0; i <= MaxImportLabels; i++ {
+		var hash chainhash.Hash
+		binary.LittleEndian.PutUint32(hash[:], uint32(i))
+		fmt.Fprintf(&tooMany, `{"type":"tx","ref":"%v","label":"x"}`+"\n",
+			hash)
+	}
+	imports := map[string]string{
+		"malformed record": `{"type":"tx","ref":"` + testTxID +
+			`","label":"groceries"}` + "\n{",
+		"too many labels": tooMany.String(),
+		"over-long line": `{"type":"tx","ref":"` + testTxID +
+			`","label":"groceries","origin":"` +
+			strings.Repeat("x", maxImportLineLen) + `"}`,
+	}
+	for name, in := range imports {
+		result, err := w.ImportLabels("acme", id, strings.NewReader(in))
+		if _, ok := err.(*InvalidLabelError); !ok {
+			t.Errorf("%s: imported %v, %v, want an "+
+				"InvalidLabelError", name, result, err)
+		}
+	}
+	labels, err = w.Labels("acme", id, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(labels) != fmt.Sprint(want) {
+		t.Errorf("labels %v after failed imports, want %v", labels,
+			want)
+	}
+}
+
+func TestExportImportLabels(t *testing.T) {
+	w, cleanup := newTestDaemon(t, nil)
+	defer cleanup()
+	from, err := createWallet(w, "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	to, err := createWallet(w, "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	labels := []Label{
+		{LabelTx, testTxID, "rent \"March\""},
+		{LabelAddress, testAddress(t, &chaincfg.SimNetParams), "dons ❤"},
+		{LabelOutput, testTxID + ":1", "change"},
+	}
+	if err := w.SetLabels("acme", from, labels); err != nil {
+		t.Fatal(err)
+	}
+	var export bytes.Buffer
+	n, err := w.ExportLabels("acme", from, &export)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != len(labels) {
+		t.Errorf("exported %d labels, want %d", n, len(labels))
+	}
+
+	exported := export.String()
+	result, err := w.ImportLabels("acme", to, &export)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Imported != len(labels) || result.Skipped != 0 {
+		t.Errorf("imported %d and skipped %d labels, want %d and 0",
+			result.Imported, result.Skipped, len(labels))
+	}
+	imported, err := w.Labels("acme", to, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(imported) != fmt.Sprint(labels) {
+		t.Errorf("imported labels %v, want %v", imported, labels)
+	}
+	var reexport bytes.Buffer
+	if _, err := w.ExportLabels("acme", to, &reexport); err != nil {
+		t.Fatal(err)
+	}
+	if reexport.String() != exported {
+		t.Errorf("export of the imported labels\n%s\nwant\n%s",
+			reexport.String(), exported)
+	}
+}
@@ -0,0 +1,297 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package walletd
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/tuxcanfly/wltd/logctx"
+)
+
+// ErrWalletInUse is returned when deleting a wallet which is being used by
+// another operation, such as a backup.
+var ErrWalletInUse = errors.New("wallet is in use")
+
+// InvalidAddressError is returned when sending to an address which is invalid
+// or belongs to another network than the wallet.
+type InvalidAddressError struct {
+	Address string
+	Net     string
+}
+
+func (e *InvalidAddressError) Error() string {
+	return "invalid " + e.Net + " address " + e.Address
+}
+
+// WalletInfo describes a registered wallet.
+type WalletInfo struct {
+	UUID     string
+	Net      string
+	Tenant   string
+	Created  time.Time
+	LastUsed time.Time
+	Autoload bool
+
+	// Name, Tags and Metadata are the descriptive metadata of the wallet.
+	Name     string
+	Tags     []string
+	Metadata map[string]string
+
+	// Open is set when the wallet is open in the daemon.
+	Open bool
+}
+
+// Output is an output paid by a transaction sent from a wallet.
+type Output struct {
+	Address string
+	Amount  btcutil.Amount
+}
+
+// ListWallets returns the wallets of the tenant selected by the filter, sorted
+// by UUID.  Every wallet of the tenant is returned when filter is nil.
+func (w *WalletDaemon) ListWallets(tenant string, filter *WalletFilter) ([]*WalletInfo, error) {
+	var infos []*WalletInfo
+	err := w.registry.forEachWallet(func(rec *walletRecord) error {
+		if rec.Tenant == tenant && filter.matches(rec) {
+			infos = append(infos, w.walletInfo(rec))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Sort(walletInfosByUUID(infos))
+	return infos, nil
+}
+
+// WalletInfo describes a wallet of the tenant.  ErrWalletNotFound is returned
+// if the wallet is not registered to the tenant.
+func (w *WalletDaemon) WalletInfo(tenant, id string) (*WalletInfo, error) {
+	rec, err := w.tenantWallet(tenant, id)
+	if err != nil {
+		return nil, err
+	}
+	return w.walletInfo(rec), nil
+}
+
+// walletInfo describes the wallet of a registry record.  The last used time of
+// open wallets is the one not yet written to the registry.
+func (w *WalletDaemon) walletInfo(rec *walletRecord) *WalletInfo {
+	info := &WalletInfo{
+		UUID:     rec.ID,
+		Net:      rec.Net,
+		Tenant:   rec.Tenant,
+		Created:  rec.Created,
+		LastUsed: rec.LastUsed,
+		Autoload: rec.Autoload,
+		Name:     rec.Name,
+		Tags:     rec.Tags,
+		Metadata: rec.Metadata,
+	}
+	w.walletsMu.Lock()
+	if lw, ok := w.wallets[rec.ID]; ok {
+		info.Open = true
+		info.LastUsed = lw.lastUsed
+	}
+	w.walletsMu.Unlock()
+	return info
+}
+
+type walletInfosByUUID []*WalletInfo
+
+func (s walletInfosByUUID) Len() int           { return len(s) }
+func (s walletInfosByUUID) Less(i, j int) bool { return s[i].UUID < s[j].UUID }
+func (s walletInfosByUUID) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// tenantWallet returns the registry record of a wallet of the tenant.
+// ErrWalletNotFound is returned if the wallet is not registered to the tenant.
+func (w *WalletDaemon) tenantWallet(tenant, id string) (*walletRecord, error) {
+	rec, err := w.registry.wallet(id)
+	if err != nil {
+		return nil, err
+	}
+	if rec.Tenant != tenant {
+		return nil, ErrWalletNotFound
+	}
+	return rec, nil
+}
+
+// DeleteWallet closes a wallet of the tenant and removes its files and registry
+// record.  ErrWalletNotFound is returned if the wallet is not registered to the
+// tenant, and ErrWalletInUse if another operation is using the wallet.
+func (w *WalletDaemon) DeleteWallet(tenant, id string) error {
+	rec, err := w.tenantWallet(tenant, id)
+	if err != nil {
+		return err
+	}
+	chainParams, err := ParamsForNet(rec.Net)
+	if err != nil {
+		return err
+	}
+
+	done, err := w.beginOperation()
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	// Holding the wallet lock keeps the wallet from being opened, or its
+	// database from being read, while it is removed.
+	defer w.lockWallet(id)()
+
+	w.walletsMu.Lock()
+	if lw, ok := w.wallets[id]; ok {
+		if lw.refs != 0 {
+			w.walletsMu.Unlock()
+			return ErrWalletInUse
+		}
+		if err := lw.close(); err != nil {
+			w.walletsMu.Unlock()
+			return err
+		}
+		delete(w.wallets, id)
+	}
+	w.walletsMu.Unlock()
+
+	// The files are removed first, so a failure leaves a registry record
+	// which is reported by the registry maintenance and removed by
+	// deleting the wallet again.
+	if err := w.storage.removeWallet(id, chainParams); err != nil {
+		return err
+	}
+	if err := w.registry.deleteWallet(id); err != nil {
+		return err
+	}
+	w.removeWalletUsage(tenant, id)
+	logctx.WalletLog(log, id).Infof("Deleted wallet %s", id)
+	w.publishWalletEvent(EventWalletDeleted, tenant, id, rec.Net)
+	return nil
+}
+
+// useWallet opens a wallet of the tenant if it is not open yet, and keeps it
+// open until the returned function is called.
+func (w *WalletDaemon) useWallet(tenant, id string) (*loadedWallet, func(), error) {
+	for {
+		if err := w.OpenWallet(tenant, id); err != nil {
+			return nil, nil, err
+		}
+
+		w.walletsMu.Lock()
+		lw, ok := w.wallets[id]
+		if ok {
+			lw.refs++
+			lw.lastUsed = time.Now()
+		}
+		w.walletsMu.Unlock()
+		if !ok {
+			// The wallet was closed after being opened.
+			continue
+		}
+		return lw, func() {
+			w.walletsMu.Lock()
+			lw.refs--
+			w.walletsMu.Unlock()
+		}, nil
+	}
+}
+
+// Balance returns the balance of a wallet of the tenant, counting outputs with
+// at least minConf confirmations.  The wallet is opened if needed.
+func (w *WalletDaemon) Balance(tenant, id string, minConf int32) (btcutil.Amount, error) {
+	done, err := w.beginOperation()
+	if err != nil {
+		return 0, err
+	}
+	defer done()
+
+	lw, release, err := w.useWallet(tenant, id)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+	return lw.wallet.CalculateBalance(minConf)
+}
+
+// ListTransactions returns at most count transactions of a wallet of the
+// tenant, skipping the from most recent ones.  The wallet is opened if needed.
+func (w *WalletDaemon) ListTransactions(tenant, id string, from, count int) ([]btcjson.ListTransactionsResult, error) {
+	done, err := w.beginOperation()
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	lw, release, err := w.useWallet(tenant, id)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return lw.wallet.ListTransactions(from, count)
+}
+
+// SendOutputs creates, signs and publishes a transaction paying the outputs
+// from an account of a wallet of the tenant, spending outputs with at least
+// minConf confirmations.  The wallet is unlocked with the private passphrase
+// for the duration of the call.  The wallet is opened if needed.
+func (w *WalletDaemon) SendOutputs(tenant, id string, privPassphrase []byte,
+	outputs []Output, account uint32, minConf int32) (*chainhash.Hash, error) {
+
+	done, err := w.beginOperation()
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	lw, release, err := w.useWallet(tenant, id)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	txOuts := make([]*wire.TxOut, 0, len(outputs))
+	for _, o := range outputs {
+		addr, err := btcutil.DecodeAddress(o.Address, lw.chainParams)
+		if err != nil || !addr.IsForNet(lw.chainParams) {
+			return nil, &InvalidAddressError{o.Address,
+				lw.chainParams.Name}
+		}
+		pkScript, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			return nil, err
+		}
+		txOuts = append(txOuts, wire.NewTxOut(int64(o.Amount), pkScript))
+	}
+
+	// Sends from the same wallet are serialized so that one does not lock
+	// the wallet while another is signing.
+	lw.sendMu.Lock()
+	defer lw.sendMu.Unlock()
+	if err := lw.wallet.Unlock(privPassphrase, nil); err != nil {
+		return nil, err
+	}
+	defer lw.wallet.Lock()
+
+	hash, err := lw.wallet.SendOutputs(txOuts, account, minConf)
+	if err != nil {
+		return nil, err
+	}
+	logctx.WalletLog(log, id).Infof("Sent transaction %v from wallet %s",
+		hash, id)
+	w.events.publish(Event{
+		Kind:       EventTransactionSent,
+		Tenant:     tenant,
+		WalletUUID: id,
+		Net:        lw.chainParams.Name,
+		Hash:       hash.String(),
+	})
+	return hash, nil
+}
@@ -0,0 +1,215 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package walletd
+
+import (
+	"time"
+
+	"github.com/btcsuite/btcwallet/chain"
+	"github.com/tuxcanfly/wltd/logctx"
+)
+
+const (
+	// registryMaintenanceInterval is how often the registry is brought up
+	// to date with the wallets open in the daemon and on disk.
+	registryMaintenanceInterval = 10 * time.Minute
+
+	// reapInterval is the interval between two checks for idle
+	// wallets.
+	reapInterval = time.Minute
+)
+
+// registryMaintainer periodically records the last used time of open wallets
+// in the registry and reports the registered wallets whose directories have
+// been deleted.  It must be run as a goroutine.
+func (w *WalletDaemon) registryMaintainer(quit <-chan struct{}) {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(registryMaintenanceInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.maintainRegistry()
+		case <-quit:
+			// Flush the last used times one final time so they
+			// survive the shutdown.
+			w.flushLastUsed()
+			return
+		}
+	}
+}
+
+// maintainRegistry performs a single registry maintenance pass.  Wallets
+// whose files are missing are logged when they are first found missing, but
+// their records are kept, since the files may only be temporarily
+// unavailable.  Deleting such a wallet removes its record.
+func (w *WalletDaemon) maintainRegistry() {
+	w.flushLastUsed()
+
+	missing := make(map[string]struct{})
+	err := w.registry.forEachWallet(func(rec *walletRecord) error {
+		chainParams, err := ParamsForNet(rec.Net)
+		if err != nil {
+			return nil
+		}
+		if w.storage.walletExists(rec.ID, chainParams) {
+			if _, ok := w.missingWallets[rec.ID]; ok {
+				logctx.WalletLog(log, rec.ID).Infof("Files of "+
+					"wallet %s are no longer missing", rec.ID)
+			}
+			return nil
+		}
+		missing[rec.ID] = struct{}{}
+		if _, ok := w.missingWallets[rec.ID]; !ok {
+			logctx.WalletLog(log, rec.ID).Warnf("Files of wallet "+
+				"%s of tenant %q are missing; delete the wallet "+
+				"to remove its registry record", rec.ID,
+				rec.Tenant)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Errorf("Cannot read registry: %v", err)
+		return
+	}
+	w.missingWallets = missing
+}
+
+// flushLastUsed writes the last used time of every open wallet to the
+// registry.
+func (w *WalletDaemon) flushLastUsed() {
+	w.walletsMu.Lock()
+	times := make(map[string]time.Time, len(w.wallets))
+	for id, lw := range w.wallets {
+		times[id] = lw.lastUsed
+	}
+	w.walletsMu.Unlock()
+
+	if len(times) == 0 {
+		return
+	}
+	if err := w.registry.setLastUsed(times); err != nil {
+		log.Errorf("Cannot update registry: %v", err)
+	}
+}
+
+// idleWalletReaper closes wallets which have not been used for longer than
+// the configured idle timeout.  The timeout is read on every check so that
+// changes made with SetIdleTimeout take effect without a restart.  It must be
+// run as a goroutine.
+func (w *WalletDaemon) idleWalletReaper(quit <-chan struct{}) {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(reapInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.reapIdleWallets()
+		case <-quit:
+			return
+		}
+	}
+}
+
+// reapIdleWallets closes every open wallet which has been idle for longer
+// than the idle timeout and is not in use, and measures the size of its files
+// again for the usage of its tenant.  Nothing is closed when the timeout is
+// zero.
+func (w *WalletDaemon) reapIdleWallets() {
+	closed := make(map[string]string)
+	defer func() {
+		for id, tenant := range closed {
+			w.updateWalletUsage(tenant, id, false)
+		}
+	}()
+
+	w.walletsMu.Lock()
+	defer w.walletsMu.Unlock()
+
+	if w.idleTimeout == 0 {
+		return
+	}
+	deadline := time.Now().Add(-w.idleTimeout)
+
+	for id, lw := range w.wallets {
+		if lw.lastUsed.After(deadline) || lw.refs != 0 {
+			continue
+		}
+		wlog := logctx.WalletLog(log, id)
+		wlog.Debugf("Closing idle wallet %s", id)
+		if err := lw.close(); err != nil {
+			wlog.Errorf("Failed to close wallet %s: %v", id, err)
+			continue
+		}
+		delete(w.wallets, id)
+		closed[id] = lw.tenant
+		w.publishWalletEvent(EventWalletClosed, lw.tenant, id,
+			lw.chainParams.Name)
+	}
+}
+
+// chainNotificationDispatcher forwards notifications from the chain backend
+// of network net to every open wallet of that network.  It must be run as a
+// goroutine.
+func (w *WalletDaemon) chainNotificationDispatcher(net string,
+	client chain.Interface, quit <-chan struct{}) {
+
+	defer w.wg.Done()
+
+	ntfns := client.Notifications()
+	for {
+		select {
+		case n, ok := <-ntfns:
+			if !ok {
+				log.Warnf("Chain notifications for %s closed", net)
+				return
+			}
+			w.publishChainEvent(net, n)
+			w.dispatchChainNotification(net, n)
+		case <-quit:
+			return
+		}
+	}
+}
+
+// dispatchChainNotification queues the notification n for every open wallet
+// of network net it concerns.  Transactions are only queued for the wallets
+// they are relevant to, and rescan notifications for the wallet rescanning.
+// Notifications are queued without waiting for the wallets to consume them,
+// so that a slow wallet does not hold back the others.
+func (w *WalletDaemon) dispatchChainNotification(net string, n interface{}) {
+	switch n.(type) {
+	case chain.RescanProgress:
+		if lw := w.rescans[net].current(); lw != nil {
+			lw.notify(n)
+		}
+		return
+	case chain.RescanFinished:
+		if lw := w.rescans[net].finish(); lw != nil {
+			lw.notify(n)
+		}
+		return
+	}
+
+	w.walletsMu.Lock()
+	wallets := make([]*loadedWallet, 0, len(w.wallets))
+	for _, lw := range w.wallets {
+		if lw.chainParams.Name == net {
+			wallets = append(wallets, lw)
+		}
+	}
+	w.walletsMu.Unlock()
+
+	for _, lw := range wallets {
+		if tx, ok := n.(chain.RelevantTx); ok && !lw.isRelevant(tx) {
+			continue
+		}
+		lw.notify(n)
+	}
+}